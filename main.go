@@ -2,17 +2,77 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/abhirockzz/mcp_cosmosdb_go/tools"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// shutdownTimeout is how long the server waits for in-flight tool calls to finish after a shutdown signal
+const shutdownTimeout = 10 * time.Second
+
+// readOnlyEnvVar disables the tools that create, modify or delete resources when set to true
+const readOnlyEnvVar = "COSMOS_READONLY"
+
 func main() {
 
-	server := newServer()
+	// root context is cancelled on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	readOnly, err := isReadOnly()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// fail fast on an unsupported connection mode instead of on the first tool call
+	if err := tools.ValidateConnectionMode(); err != nil {
+		log.Fatal(err)
+	}
+
+	if _, err := tools.GetEndpointSuffix(); err != nil {
+		log.Fatal(err)
+	}
+
+	if _, err := tools.GetEndpointOverride(); err != nil {
+		log.Fatal(err)
+	}
+
+	maxConcurrency, err := tools.GetMaxConcurrency()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if _, err := tools.GetMaxResponseBytes(); err != nil {
+		log.Fatal(err)
+	}
+
+	if _, err := tools.GetDefaultPageSize(); err != nil {
+		log.Fatal(err)
+	}
+
+	queryCacheTTL, queryCacheSize, err := tools.GetQueryCacheConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	queryCursorTTL, err := tools.GetQueryCursorTTL()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var inFlight sync.WaitGroup
+	server := newServer(ctx, &inFlight, readOnly, tools.NewConcurrencyLimiter(maxConcurrency), tools.NewQueryCache(queryCacheTTL, queryCacheSize), tools.NewQueryCursorStore(queryCursorTTL))
 
 	// choose stdio or http server based on env variable
 
@@ -33,30 +93,266 @@ func main() {
 		// --- STDIO ---
 		log.Printf("Starting STDIO server")
 
-		if err := server.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
+		err := server.Run(ctx, &mcp.StdioTransport{})
+
+		// give in-flight tool calls a chance to return after cancellation
+		waitForInFlight(&inFlight, shutdownTimeout)
+
+		if err != nil && !errors.Is(err, context.Canceled) {
 			log.Fatalf("stdio server failed: %v", err)
 		}
+		log.Printf("STDIO server stopped")
+	}
+
+}
+
+// isReadOnly reports whether read-only mode is enabled in the environment
+func isReadOnly() (bool, error) {
+	value := os.Getenv(readOnlyEnvVar)
+	if value == "" {
+		return false, nil
 	}
 
+	readOnly, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value '%s' for %s, must be true or false", value, readOnlyEnvVar)
+	}
+	return readOnly, nil
 }
 
-func newServer() *mcp.Server {
+func newServer(ctx context.Context, inFlight *sync.WaitGroup, readOnly bool, limiter *tools.ConcurrencyLimiter, queryCache *tools.QueryCache, queryCursors *tools.QueryCursorStore) *mcp.Server {
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:       "mcp_azure_cosmosdb_go",
 		Title:      "Go based MCP server for Azure Cosmos DB using the Azure SDK for Go and the MCP Go SDK",
-		Version:    "0.0.1",
+		Version:    tools.ServerVersion,
 		WebsiteURL: "https://github.com/abhirockzz/mcp_cosmosdb_go",
 	}, nil)
 
-	mcp.AddTool(server, tools.ListDatabases(), tools.ListDatabasesToolHandler)
-	mcp.AddTool(server, tools.CreateDatabase(), tools.CreateDatabaseToolHandler)
-	mcp.AddTool(server, tools.ListContainers(), tools.ListContainersToolHandler)
-	mcp.AddTool(server, tools.ReadContainerMetadata(), tools.ReadContainerMetadataToolHandler)
-	mcp.AddTool(server, tools.CreateContainer(), tools.CreateContainerToolHandler)
-	mcp.AddTool(server, tools.AddItemToContainer(), tools.AddItemToContainerToolHandler)
-	mcp.AddTool(server, tools.ReadItem(), tools.ReadItemToolHandler)
-	mcp.AddTool(server, tools.ExecuteQuery(), tools.ExecuteQueryToolHandler)
-	mcp.AddTool(server, tools.BatchCreateItems(), tools.BatchCreateItemsToolHandler)
+	server.AddReceivingMiddleware(shutdownMiddleware(ctx, inFlight), concurrencyMiddleware(limiter), errorResultMiddleware(), diagnosticsMiddleware())
+
+	catalog := &tools.ToolCatalog{}
+
+	addTool(server, catalog, tools.ServerInfo(), tools.ServerInfoToolHandler(limiter, readOnly))
+	addTool(server, catalog, tools.DescribeTools(), tools.DescribeToolsToolHandler(catalog))
+	addTool(server, catalog, tools.CheckPermissions(), tools.CheckPermissionsToolHandler(readOnly))
+	addTool(server, catalog, tools.ListDatabases(), tools.ListDatabasesToolHandler)
+	addTool(server, catalog, tools.ListDatabasesDetailed(), tools.ListDatabasesDetailedToolHandler)
+	addTool(server, catalog, tools.ListContainers(), tools.ListContainersToolHandler)
+	addTool(server, catalog, tools.ListContainersDetailed(), tools.ListContainersDetailedToolHandler)
+	addTool(server, catalog, tools.ReadContainerMetadata(), tools.ReadContainerMetadataToolHandler)
+	addTool(server, catalog, tools.DiffContainers(), tools.DiffContainersToolHandler)
+	addTool(server, catalog, tools.ExplainIndexingPolicy(), tools.ExplainIndexingPolicyToolHandler)
+	addTool(server, catalog, tools.ValidateIndexingPolicy(), tools.ValidateIndexingPolicyToolHandler)
+	addTool(server, catalog, tools.ContainerStats(), tools.ContainerStatsToolHandler)
+	addTool(server, catalog, tools.ListThroughput(), tools.ListThroughputToolHandler)
+	addTool(server, catalog, tools.InferSchema(), tools.InferSchemaToolHandler)
+	addTool(server, catalog, tools.ReadItem(), tools.ReadItemToolHandler)
+	addTool(server, catalog, tools.ReadItemFields(), tools.ReadItemFieldsToolHandler)
+	addTool(server, catalog, tools.ReadItemWithMetadata(), tools.ReadItemWithMetadataToolHandler)
+	addTool(server, catalog, tools.SmartRead(), tools.SmartReadToolHandler)
+	addTool(server, catalog, tools.ReadItemHistory(), tools.ReadItemHistoryToolHandler)
+	// restore_item only reports that restoring items is not supported, it never modifies data
+	addTool(server, catalog, tools.RestoreItem(), tools.RestoreItemToolHandler)
+	addTool(server, catalog, tools.BatchReadItems(), tools.BatchReadItemsToolHandler)
+	addTool(server, catalog, tools.BuildQuery(), tools.BuildQueryToolHandler)
+	addTool(server, catalog, tools.DryRunQuery(), tools.DryRunQueryToolHandler)
+	addTool(server, catalog, tools.EstimateItemSize(), tools.EstimateItemSizeToolHandler)
+	addTool(server, catalog, tools.ExecuteQuery(), tools.CursorExecuteQueryToolHandler(queryCursors, tools.CachedExecuteQueryToolHandler(queryCache)))
+	addTool(server, catalog, tools.PaginatedQuery(), tools.PaginatedQueryToolHandler)
+	addTool(server, catalog, tools.QueryToCSV(), tools.QueryToCSVToolHandler)
+	addTool(server, catalog, tools.EstimateQueryCost(), tools.EstimateQueryCostToolHandler)
+	addTool(server, catalog, tools.DiagnoseQuery(), tools.DiagnoseQueryToolHandler)
+	addTool(server, catalog, tools.GetQueryPlan(), tools.GetQueryPlanToolHandler)
+	addTool(server, catalog, tools.QueryAcrossContainers(), tools.QueryAcrossContainersToolHandler)
+	addTool(server, catalog, tools.CountDistinct(), tools.CountDistinctToolHandler)
+	addTool(server, catalog, tools.GroupByAggregate(), tools.GroupByAggregateToolHandler)
+	addTool(server, catalog, tools.HistogramByField(), tools.HistogramByFieldToolHandler)
+	addTool(server, catalog, tools.ClientSideAggregate(), tools.ClientSideAggregateToolHandler)
+	addTool(server, catalog, tools.DistinctValues(), tools.DistinctValuesToolHandler)
+	addTool(server, catalog, tools.SearchText(), tools.SearchTextToolHandler)
+	addTool(server, catalog, tools.GeoWithinDistance(), tools.GeoWithinDistanceToolHandler)
+
+	// writing exports to files is not allowed in read-only mode
+	exportHandler := tools.ExportContainerToolHandler
+	if readOnly {
+		exportHandler = tools.ExportContainerReadOnlyToolHandler
+	}
+	addTool(server, catalog, tools.ExportContainer(), exportHandler)
+
+	// tools below create, modify or delete resources
+	if readOnly {
+		log.Printf("Read-only mode enabled, tools that modify data are not available")
+		return server
+	}
+
+	addTool(server, catalog, tools.CreateDatabase(), withQueryCacheInvalidation(queryCache, tools.CreateDatabaseToolHandler))
+	addTool(server, catalog, tools.CreateContainer(), withQueryCacheInvalidation(queryCache, tools.CreateContainerToolHandler))
+	addTool(server, catalog, tools.CreateLeasesContainer(), withQueryCacheInvalidation(queryCache, tools.CreateLeasesContainerToolHandler))
+	addTool(server, catalog, tools.CloneContainerConfig(), withQueryCacheInvalidation(queryCache, tools.CloneContainerConfigToolHandler))
+	addTool(server, catalog, tools.AddCompositeIndex(), withQueryCacheInvalidation(queryCache, tools.AddCompositeIndexToolHandler))
+	addTool(server, catalog, tools.ReplaceContainerConfig(), withQueryCacheInvalidation(queryCache, tools.ReplaceContainerConfigToolHandler))
+	addTool(server, catalog, tools.SetIndexingMode(), withQueryCacheInvalidation(queryCache, tools.SetIndexingModeToolHandler))
+	addTool(server, catalog, tools.AddItemToContainer(), withQueryCacheInvalidation(queryCache, tools.AddItemToContainerToolHandler))
+	addTool(server, catalog, tools.BatchCreateItems(), withQueryCacheInvalidation(queryCache, tools.BatchCreateItemsToolHandler))
+	addTool(server, catalog, tools.ImportContainer(), withQueryCacheInvalidation(queryCache, tools.ImportContainerToolHandler))
+	addTool(server, catalog, tools.MaterializeView(), withQueryCacheInvalidation(queryCache, tools.MaterializeViewToolHandler))
+	addTool(server, catalog, tools.PatchWhere(), withQueryCacheInvalidation(queryCache, tools.PatchWhereToolHandler))
+	addTool(server, catalog, tools.SetFieldValue(), withQueryCacheInvalidation(queryCache, tools.SetFieldValueToolHandler))
+	addTool(server, catalog, tools.DeleteWhere(), withQueryCacheInvalidation(queryCache, tools.DeleteWhereToolHandler))
+	addTool(server, catalog, tools.TruncateContainer(), withQueryCacheInvalidation(queryCache, tools.TruncateContainerToolHandler))
+	addTool(server, catalog, tools.PurgeOlderThan(), withQueryCacheInvalidation(queryCache, tools.PurgeOlderThanToolHandler))
 
 	return server
 }
+
+// addTool registers the tool after adjusting the account parameter of its input schema to the default account configuration.
+// The tool is also added to the catalog used by describe_tools.
+func addTool[In, Out any](server *mcp.Server, catalog *tools.ToolCatalog, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	tool, err := tools.WithAccountSchema[In](tool)
+	if err != nil {
+		panic(err)
+	}
+
+	description, err := tools.DescribeTool[In](tool)
+	if err != nil {
+		panic(err)
+	}
+	catalog.Add(description)
+
+	mcp.AddTool(server, tool, withErrorClassification(handler))
+}
+
+// withQueryCacheInvalidation clears the query cache after each call of a tool that modifies data, so that cached query results
+// never outlive a change made through this server. Failed calls clear it too, since they may have applied some of the changes.
+func withQueryCacheInvalidation[In, Out any](queryCache *tools.QueryCache, handler mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, Out] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		defer queryCache.Clear()
+		return handler(ctx, req, input)
+	}
+}
+
+// toolErrorKey is the context key of the ToolError recorded by withErrorClassification for errorResultMiddleware
+type toolErrorKey struct{}
+
+// withErrorClassification records the classification of the error returned by the handler, so that errorResultMiddleware
+// can add it to the error result. The error itself is returned unchanged.
+func withErrorClassification[In, Out any](handler mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, Out] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		result, output, err := handler(ctx, req, input)
+		if err != nil {
+			if toolError, ok := ctx.Value(toolErrorKey{}).(*tools.ToolError); ok {
+				*toolError = tools.ClassifyError(err)
+			}
+		}
+		return result, output, err
+	}
+}
+
+// errorResultMiddleware adds the error classification (retryable flag and category) as structured content to the results
+// of failed tool calls, so that clients can decide whether to retry
+func errorResultMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			toolError := &tools.ToolError{}
+			result, err := next(context.WithValue(ctx, toolErrorKey{}, toolError), method, req)
+
+			if callResult, ok := result.(*mcp.CallToolResult); ok && callResult != nil && callResult.IsError && toolError.Category != "" {
+				callResult.StructuredContent = toolError
+			}
+
+			return result, err
+		}
+	}
+}
+
+// diagnosticsMiddleware records the requests sent to Azure Cosmos DB during tool calls with diagnostics set to true, and
+// adds their diagnostics to the result as an additional text content, so that successful and failed calls can be debugged alike
+func diagnosticsMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || !tools.DiagnosticsRequested(call.Params.Arguments) {
+				return next(ctx, method, req)
+			}
+
+			ctx, recorder := tools.WithDiagnosticsRecorder(ctx)
+			result, err := next(ctx, method, req)
+
+			if callResult, ok := result.(*mcp.CallToolResult); ok && callResult != nil {
+				diagnostics, marshalErr := json.Marshal(recorder.Summary())
+				if marshalErr == nil {
+					callResult.Content = append(callResult.Content, &mcp.TextContent{Text: "diagnostics: " + string(diagnostics)})
+				}
+			}
+
+			return result, err
+		}
+	}
+}
+
+// shutdownMiddleware tracks in-flight tool calls and cancels them when the root context is cancelled
+func shutdownMiddleware(root context.Context, inFlight *sync.WaitGroup) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			inFlight.Add(1)
+			defer inFlight.Done()
+
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			stopAfter := context.AfterFunc(root, cancel)
+			defer stopAfter()
+
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// concurrencyMiddleware limits the number of tool calls executed at the same time, excess calls wait for a free slot.
+// server_info calls are not limited, so that the in-flight count can be checked while the server is busy.
+func concurrencyMiddleware(limiter *tools.ConcurrencyLimiter) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			if call, ok := req.(*mcp.CallToolRequest); ok && call.Params.Name == tools.ServerInfo().Name {
+				return next(ctx, method, req)
+			}
+
+			if err := limiter.Acquire(ctx); err != nil {
+				return nil, err
+			}
+			defer limiter.Release()
+
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// waitForInFlight blocks until all in-flight tool calls return or the timeout elapses
+func waitForInFlight(inFlight *sync.WaitGroup, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Printf("timed out waiting for in-flight tool calls to finish")
+	}
+}
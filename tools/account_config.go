@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+)
+
+// AccountConfigFileEnvVar points to a JSON file with per-account connection settings
+const AccountConfigFileEnvVar = "COSMOS_CONFIG_FILE"
+
+// Supported values for AccountSettings.AuthMode
+const (
+	AuthModeDefaultCredential = "default_credential"
+	AuthModeKey               = "key"
+	AuthModeConnectionString  = "connection_string"
+)
+
+// AccountSettings holds the connection settings for a single account in the config file
+type AccountSettings struct {
+	AuthMode         string   `json:"authMode,omitempty"`
+	Key              string   `json:"key,omitempty"`
+	ConnectionString string   `json:"connectionString,omitempty"`
+	Endpoint         string   `json:"endpoint,omitempty"`
+	PreferredRegions []string `json:"preferredRegions,omitempty"`
+	UseEmulator      bool     `json:"useEmulator,omitempty"`
+}
+
+// accountConfigFile is the layout of the file referenced by AccountConfigFileEnvVar
+//
+//	{
+//	  "accounts": {
+//	    "myaccount": {"authMode": "key", "key": "...", "preferredRegions": ["West US"]},
+//	    "local": {"useEmulator": true, "endpoint": "http://localhost:8081"}
+//	  }
+//	}
+type accountConfigFile struct {
+	Accounts map[string]AccountSettings `json:"accounts"`
+}
+
+// loadAccountSettings returns the settings for the account from the config file.
+// The boolean is false if no config file is configured or the account is not present in it.
+func loadAccountSettings(account string) (AccountSettings, bool, error) {
+	path := os.Getenv(AccountConfigFileEnvVar)
+	if path == "" {
+		return AccountSettings{}, false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return AccountSettings{}, false, fmt.Errorf("error reading account config file: %w", err)
+	}
+
+	var config accountConfigFile
+	if err := json.Unmarshal(data, &config); err != nil {
+		return AccountSettings{}, false, fmt.Errorf("error parsing account config file: %w", err)
+	}
+
+	settings, ok := config.Accounts[account]
+	return settings, ok, nil
+}
+
+// getConfiguredClient creates a client using the settings from the account config file
+func (c ConnectionConfig) getConfiguredClient(settings AccountSettings) (*azcosmos.Client, error) {
+	if settings.UseEmulator {
+		return ConnectionConfig{UseEmulator: true, EmulatorEndpoint: settings.Endpoint}.getEmulatorClient()
+	}
+
+	options, err := newClientOptions()
+	if err != nil {
+		return nil, err
+	}
+	options.PreferredRegions = settings.PreferredRegions
+
+	endpoint := settings.Endpoint
+	if endpoint == "" {
+		endpoint = c.GetEndpoint()
+	}
+
+	switch settings.AuthMode {
+	case "", AuthModeDefaultCredential:
+		cred, err := getDefaultCredential()
+		if err != nil {
+			return nil, err
+		}
+		client, err := azcosmos.NewClient(endpoint, withAccountScope(cred, endpoint, c.Account), options)
+		if err != nil {
+			return nil, fmt.Errorf("error creating Cosmos client: %v", err)
+		}
+		return client, nil
+
+	case AuthModeKey:
+		if settings.Key == "" {
+			return nil, errors.New("key missing in account config")
+		}
+		cred, err := azcosmos.NewKeyCredential(settings.Key)
+		if err != nil {
+			return nil, fmt.Errorf("error creating key credential: %w", err)
+		}
+		client, err := azcosmos.NewClientWithKey(endpoint, cred, options)
+		if err != nil {
+			return nil, fmt.Errorf("error creating Cosmos client: %w", err)
+		}
+		return client, nil
+
+	case AuthModeConnectionString:
+		if settings.ConnectionString == "" {
+			return nil, errors.New("connection string missing in account config")
+		}
+		client, err := azcosmos.NewClientFromConnectionString(settings.ConnectionString, options)
+		if err != nil {
+			return nil, fmt.Errorf("error creating Cosmos client: %w", err)
+		}
+		return client, nil
+
+	default:
+		return nil, fmt.Errorf("invalid auth mode '%s' in account config, must be one of: %s, %s, %s", settings.AuthMode, AuthModeDefaultCredential, AuthModeKey, AuthModeConnectionString)
+	}
+}
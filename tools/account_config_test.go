@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for the per-account config file
+
+func writeAccountConfigFile(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	t.Setenv(AccountConfigFileEnvVar, path)
+}
+
+func TestLoadAccountSettings(t *testing.T) {
+	writeAccountConfigFile(t, `{
+		"accounts": {
+			"myaccount": {"authMode": "key", "key": "secret", "preferredRegions": ["West US", "East US"]},
+			"local": {"useEmulator": true, "endpoint": "http://localhost:9000"}
+		}
+	}`)
+
+	settings, found, err := loadAccountSettings("myaccount")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, AuthModeKey, settings.AuthMode)
+	assert.Equal(t, "secret", settings.Key)
+	assert.Equal(t, []string{"West US", "East US"}, settings.PreferredRegions)
+
+	settings, found, err = loadAccountSettings("local")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.True(t, settings.UseEmulator)
+	assert.Equal(t, "http://localhost:9000", settings.Endpoint)
+
+	_, found, err = loadAccountSettings("unknown")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestLoadAccountSettings_NoConfigFile(t *testing.T) {
+	t.Setenv(AccountConfigFileEnvVar, "")
+
+	_, found, err := loadAccountSettings("myaccount")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestLoadAccountSettings_InvalidFile(t *testing.T) {
+	writeAccountConfigFile(t, `not json`)
+
+	_, _, err := loadAccountSettings("myaccount")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error parsing account config file")
+}
+
+func TestGetConfiguredClient(t *testing.T) {
+	tests := []struct {
+		name        string
+		settings    AccountSettings
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:     "key auth",
+			settings: AccountSettings{AuthMode: AuthModeKey, Key: EmulatorKey},
+		},
+		{
+			name:        "key auth without key",
+			settings:    AccountSettings{AuthMode: AuthModeKey},
+			expectError: true,
+			errorMsg:    "key missing",
+		},
+		{
+			name:        "connection string auth without connection string",
+			settings:    AccountSettings{AuthMode: AuthModeConnectionString},
+			expectError: true,
+			errorMsg:    "connection string missing",
+		},
+		{
+			name:        "invalid auth mode",
+			settings:    AccountSettings{AuthMode: "certificate"},
+			expectError: true,
+			errorMsg:    "invalid auth mode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := ConnectionConfig{Account: "myaccount"}.getConfiguredClient(tt.settings)
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "https://myaccount.documents.azure.com:443/", client.Endpoint())
+		})
+	}
+}
@@ -0,0 +1,784 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// fieldSegmentPattern matches a single property name that can be used in dot notation
+var fieldSegmentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// fieldReference converts a field path (category, address.city or /address/city) into a query reference like c.address.city.
+// Only simple property names are allowed, so the field can be safely embedded in the query text.
+func fieldReference(field string) (string, error) {
+	segments, err := fieldSegments(field)
+	if err != nil {
+		return "", err
+	}
+
+	return "c." + strings.Join(segments, "."), nil
+}
+
+// fieldSegments splits a field path (category, address.city or /address/city) into its property names
+func fieldSegments(field string) ([]string, error) {
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return nil, newInputError("field name missing")
+	}
+
+	separator := "."
+	if strings.HasPrefix(field, "/") {
+		field = strings.TrimPrefix(field, "/")
+		separator = "/"
+	}
+
+	segments := strings.Split(field, separator)
+	for _, segment := range segments {
+		if !fieldSegmentPattern.MatchString(segment) {
+			return nil, inputErrorf("invalid field name '%s': only letters, digits and underscores are allowed in each path segment", segment)
+		}
+	}
+
+	return segments, nil
+}
+
+// collectQueryResults runs the query and returns all results
+func collectQueryResults(ctx context.Context, containerClient *azcosmos.ContainerClient, query string, partitionKey azcosmos.PartitionKey, options *azcosmos.QueryOptions) ([][]byte, error) {
+	queryPager := containerClient.NewQueryItemsPager(query, partitionKey, options)
+
+	results := [][]byte{}
+
+	for queryPager.More() {
+		queryResponse, err := nextPageWithRetry(ctx, queryPager)
+		if err != nil {
+			return nil, fmt.Errorf("query page error: %w", withDiagnostics(err))
+		}
+		results = append(results, queryResponse.Items...)
+	}
+
+	return results, nil
+}
+
+func CountDistinct() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "count_distinct",
+		Description: "Count the distinct values of a field within a single logical partition of a container in Azure Cosmos DB or local emulator, and return the distinct values. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. A partition key value is REQUIRED because DISTINCT is not supported for cross-partition queries by the Azure Cosmos DB Gateway API (used by the Go SDK). The field can be a top level property (category) or a nested one (address.city). To get the distinct values across partitions, use distinct_values.",
+	}
+}
+
+type CountDistinctToolInput struct {
+	ConnectionConfig
+	Database     string `json:"database" jsonschema:"Name of the database"`
+	Container    string `json:"container" jsonschema:"Name of the container"`
+	PartitionKey string `json:"partitionKey" jsonschema:"Partition key value to scope the query to (required)"`
+	Field        string `json:"field" jsonschema:"Field to count distinct values of, e.g. category or address.city"`
+}
+
+type CountDistinctToolResult struct {
+	Field          string `json:"field"`
+	PartitionKey   string `json:"partition_key"`
+	DistinctCount  int    `json:"distinct_count" jsonschema:"Number of distinct values of the field"`
+	DistinctValues []any  `json:"distinct_values" jsonschema:"The distinct values of the field"`
+}
+
+func CountDistinctToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input CountDistinctToolInput) (*mcp.CallToolResult, CountDistinctToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, CountDistinctToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, CountDistinctToolResult{}, newInputError("database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, CountDistinctToolResult{}, newInputError("container name missing")
+	}
+
+	if input.PartitionKey == "" {
+		return nil, CountDistinctToolResult{}, newInputError("partition key missing: DISTINCT is not supported for cross-partition queries, use distinct_values instead")
+	}
+
+	field, err := fieldReference(input.Field)
+	if err != nil {
+		return nil, CountDistinctToolResult{}, err
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, CountDistinctToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, CountDistinctToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, CountDistinctToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	query := fmt.Sprintf("SELECT DISTINCT VALUE %s FROM c", field)
+
+	results, err := collectQueryResults(ctx, containerClient, query, azcosmos.NewPartitionKeyString(input.PartitionKey), nil)
+	if err != nil {
+		return nil, CountDistinctToolResult{}, err
+	}
+
+	values := make([]any, 0, len(results))
+	for _, result := range results {
+		var value any
+		if err := json.Unmarshal(result, &value); err != nil {
+			return nil, CountDistinctToolResult{}, fmt.Errorf("error parsing query result: %v", err)
+		}
+		values = append(values, value)
+	}
+
+	return nil, CountDistinctToolResult{
+		Field:          input.Field,
+		PartitionKey:   input.PartitionKey,
+		DistinctCount:  len(values),
+		DistinctValues: values,
+	}, nil
+}
+
+// supportedAggregates maps the aggregate names accepted by the tools to their SQL functions
+var supportedAggregates = map[string]string{
+	"count": "COUNT",
+	"sum":   "SUM",
+	"avg":   "AVG",
+	"min":   "MIN",
+	"max":   "MAX",
+}
+
+func GroupByAggregate() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "group_by_aggregate",
+		Description: "Group the items within a single logical partition of a container in Azure Cosmos DB or local emulator by a field, and compute an aggregate (count, sum, avg, min or max) for each group. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. A partition key value is REQUIRED because GROUP BY is not supported for cross-partition queries by the Azure Cosmos DB Gateway API (used by the Go SDK). For count, aggregateField is not needed. For sum, avg, min and max, aggregateField is the field to aggregate.",
+	}
+}
+
+type GroupByAggregateToolInput struct {
+	ConnectionConfig
+	Database       string `json:"database" jsonschema:"Name of the database"`
+	Container      string `json:"container" jsonschema:"Name of the container"`
+	PartitionKey   string `json:"partitionKey" jsonschema:"Partition key value to scope the query to (required)"`
+	GroupByField   string `json:"groupByField" jsonschema:"Field to group by, e.g. category or address.city"`
+	Aggregate      string `json:"aggregate" jsonschema:"Aggregate to compute for each group: count, sum, avg, min or max"`
+	AggregateField string `json:"aggregateField,omitempty" jsonschema:"Field to aggregate, e.g. price. Required for sum, avg, min and max"`
+}
+
+type GroupResult struct {
+	Key   any `json:"key" jsonschema:"Value of the group by field"`
+	Value any `json:"value" jsonschema:"Aggregate value for the group"`
+}
+
+type GroupByAggregateToolResult struct {
+	GroupByField string        `json:"group_by_field"`
+	Aggregate    string        `json:"aggregate"`
+	Query        string        `json:"query" jsonschema:"The query that was executed"`
+	Groups       []GroupResult `json:"groups"`
+}
+
+func GroupByAggregateToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input GroupByAggregateToolInput) (*mcp.CallToolResult, GroupByAggregateToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, GroupByAggregateToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, GroupByAggregateToolResult{}, newInputError("database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, GroupByAggregateToolResult{}, newInputError("container name missing")
+	}
+
+	if input.PartitionKey == "" {
+		return nil, GroupByAggregateToolResult{}, newInputError("partition key missing: GROUP BY is not supported for cross-partition queries")
+	}
+
+	groupByField, err := fieldReference(input.GroupByField)
+	if err != nil {
+		return nil, GroupByAggregateToolResult{}, fmt.Errorf("group by field: %w", err)
+	}
+
+	aggregate := strings.ToLower(strings.TrimSpace(input.Aggregate))
+	function, ok := supportedAggregates[aggregate]
+	if !ok {
+		return nil, GroupByAggregateToolResult{}, inputErrorf("invalid aggregate '%s', must be one of: count, sum, avg, min, max", input.Aggregate)
+	}
+
+	argument := "1"
+	if aggregate != "count" {
+		argument, err = fieldReference(input.AggregateField)
+		if err != nil {
+			return nil, GroupByAggregateToolResult{}, fmt.Errorf("aggregate field: %w", err)
+		}
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, GroupByAggregateToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, GroupByAggregateToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, GroupByAggregateToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	query := fmt.Sprintf("SELECT %s AS groupKey, %s(%s) AS aggregateValue FROM c GROUP BY %s", groupByField, function, argument, groupByField)
+
+	results, err := collectQueryResults(ctx, containerClient, query, azcosmos.NewPartitionKeyString(input.PartitionKey), nil)
+	if err != nil {
+		return nil, GroupByAggregateToolResult{}, err
+	}
+
+	groups := make([]GroupResult, 0, len(results))
+	for _, result := range results {
+		var row struct {
+			GroupKey       any `json:"groupKey"`
+			AggregateValue any `json:"aggregateValue"`
+		}
+		if err := json.Unmarshal(result, &row); err != nil {
+			return nil, GroupByAggregateToolResult{}, fmt.Errorf("error parsing query result: %v", err)
+		}
+		groups = append(groups, GroupResult{Key: row.GroupKey, Value: row.AggregateValue})
+	}
+
+	return nil, GroupByAggregateToolResult{
+		GroupByField: input.GroupByField,
+		Aggregate:    aggregate,
+		Query:        query,
+		Groups:       groups,
+	}, nil
+}
+
+// defaultHistogramTop is the number of buckets returned by histogram_by_field when top is not specified
+const defaultHistogramTop = 10
+
+// maxHistogramTop is the upper bound for the top argument of histogram_by_field
+const maxHistogramTop = 1000
+
+func HistogramByField() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "histogram_by_field",
+		Description: "Count the items within a single logical partition of a container in Azure Cosmos DB or local emulator for each value of a field, and return the top values with their counts, most frequent first. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Use this to answer questions like how many items of each type or status there are. A partition key value is REQUIRED because GROUP BY is not supported for cross-partition queries by the Azure Cosmos DB Gateway API (used by the Go SDK). The field can be a top level property (type) or a nested one (address.city). Items without the field are counted in missing_count.",
+	}
+}
+
+type HistogramByFieldToolInput struct {
+	ConnectionConfig
+	Database     string `json:"database" jsonschema:"Name of the database"`
+	Container    string `json:"container" jsonschema:"Name of the container"`
+	PartitionKey string `json:"partitionKey" jsonschema:"Partition key value to scope the query to (required)"`
+	Field        string `json:"field" jsonschema:"Field to count the values of, e.g. type or address.city"`
+	Top          int    `json:"top,omitempty" jsonschema:"Maximum number of values to return, the most frequent first (default 10, maximum 1000)"`
+}
+
+type HistogramBucket struct {
+	Value any `json:"value" jsonschema:"Value of the field"`
+	Count int `json:"count" jsonschema:"Number of items with this value"`
+}
+
+type HistogramByFieldToolResult struct {
+	Field          string            `json:"field"`
+	PartitionKey   string            `json:"partition_key"`
+	Query          string            `json:"query" jsonschema:"The query that was executed"`
+	Buckets        []HistogramBucket `json:"buckets" jsonschema:"The most frequent values of the field with their counts, ordered by count (descending)"`
+	DistinctValues int               `json:"distinct_values" jsonschema:"Number of distinct values of the field, including the ones not returned in buckets"`
+	OtherCount     int               `json:"other_count" jsonschema:"Number of items whose value is not in buckets because only the top values are returned"`
+	MissingCount   int               `json:"missing_count" jsonschema:"Number of items that do not have the field"`
+	TotalCount     int               `json:"total_count" jsonschema:"Number of items in the partition"`
+}
+
+func HistogramByFieldToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input HistogramByFieldToolInput) (*mcp.CallToolResult, HistogramByFieldToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, HistogramByFieldToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, HistogramByFieldToolResult{}, newInputError("database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, HistogramByFieldToolResult{}, newInputError("container name missing")
+	}
+
+	if input.PartitionKey == "" {
+		return nil, HistogramByFieldToolResult{}, newInputError("partition key missing: GROUP BY is not supported for cross-partition queries")
+	}
+
+	field, err := fieldReference(input.Field)
+	if err != nil {
+		return nil, HistogramByFieldToolResult{}, err
+	}
+
+	if input.Top < 0 || input.Top > maxHistogramTop {
+		return nil, HistogramByFieldToolResult{}, inputErrorf("top must be between 1 and %d", maxHistogramTop)
+	}
+
+	top := input.Top
+	if top == 0 {
+		top = defaultHistogramTop
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, HistogramByFieldToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, HistogramByFieldToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, HistogramByFieldToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	// ORDER BY can't be combined with GROUP BY, so the groups are ordered by count in the MCP server
+	query := fmt.Sprintf("SELECT %s AS groupKey, COUNT(1) AS itemCount FROM c GROUP BY %s", field, field)
+
+	results, err := collectQueryResults(ctx, containerClient, query, azcosmos.NewPartitionKeyString(input.PartitionKey), nil)
+	if err != nil {
+		return nil, HistogramByFieldToolResult{}, err
+	}
+
+	result, err := buildHistogram(results, top)
+	if err != nil {
+		return nil, HistogramByFieldToolResult{}, err
+	}
+	result.Field = input.Field
+	result.PartitionKey = input.PartitionKey
+	result.Query = query
+
+	return nil, result, nil
+}
+
+// buildHistogram orders the groups returned by the GROUP BY query by count (descending) and keeps the top groups.
+// Groups with the same count are ordered by value, so that the result is stable. Items without the field
+// form a group without groupKey, which is counted as missing instead of being returned as a bucket.
+func buildHistogram(results [][]byte, top int) (HistogramByFieldToolResult, error) {
+	type group struct {
+		bucket HistogramBucket
+		key    string
+	}
+
+	result := HistogramByFieldToolResult{Buckets: []HistogramBucket{}}
+	groups := make([]group, 0, len(results))
+
+	for _, row := range results {
+		var decoded struct {
+			GroupKey  json.RawMessage `json:"groupKey"`
+			ItemCount int             `json:"itemCount"`
+		}
+		if err := json.Unmarshal(row, &decoded); err != nil {
+			return HistogramByFieldToolResult{}, fmt.Errorf("error parsing query result: %v", err)
+		}
+		result.TotalCount += decoded.ItemCount
+
+		if decoded.GroupKey == nil {
+			result.MissingCount += decoded.ItemCount
+			continue
+		}
+
+		var value any
+		if err := json.Unmarshal(decoded.GroupKey, &value); err != nil {
+			return HistogramByFieldToolResult{}, fmt.Errorf("error parsing query result: %v", err)
+		}
+		// maps are encoded with sorted keys
+		key, err := json.Marshal(value)
+		if err != nil {
+			return HistogramByFieldToolResult{}, fmt.Errorf("error encoding query result: %v", err)
+		}
+		groups = append(groups, group{bucket: HistogramBucket{Value: value, Count: decoded.ItemCount}, key: string(key)})
+	}
+
+	slices.SortFunc(groups, func(a, b group) int {
+		if a.bucket.Count != b.bucket.Count {
+			return cmp.Compare(b.bucket.Count, a.bucket.Count)
+		}
+		return strings.Compare(a.key, b.key)
+	})
+
+	result.DistinctValues = len(groups)
+	for i, group := range groups {
+		if i < top {
+			result.Buckets = append(result.Buckets, group.bucket)
+			continue
+		}
+		result.OtherCount += group.bucket.Count
+	}
+
+	return result, nil
+}
+
+// defaultClientSideAggregateMaxItems caps the number of values fetched for client-side aggregation
+const defaultClientSideAggregateMaxItems = 10000
+
+// maxClientSideAggregateMaxItems is the upper bound for the maxItems argument
+const maxClientSideAggregateMaxItems = 100000
+
+func ClientSideAggregate() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "client_side_aggregate",
+		Description: "Compute an aggregate (count, sum, avg, min or max) over the items of a container in Azure Cosmos DB or local emulator, ACROSS PARTITIONS. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Aggregates are not supported for cross-partition queries by the Azure Cosmos DB Gateway API (used by the Go SDK), so this tool fetches the matching values with a simple SELECT/WHERE query and computes the aggregate in the MCP server (client-side). The number of values fetched is capped by maxItems - if the cap is hit the result is marked as truncated and only covers the fetched values. Prefer group_by_aggregate or execute_query with a partition key when the data is in a single partition.",
+	}
+}
+
+type ClientSideAggregateToolInput struct {
+	ConnectionConfig
+	Database     string `json:"database" jsonschema:"Name of the database"`
+	Container    string `json:"container" jsonschema:"Name of the container"`
+	Aggregate    string `json:"aggregate" jsonschema:"Aggregate to compute: count, sum, avg, min or max"`
+	Field        string `json:"field,omitempty" jsonschema:"Field to aggregate, e.g. price or address.zip. Required for sum, avg, min and max. For count, only items that have the field are counted if provided"`
+	Filter       string `json:"filter,omitempty" jsonschema:"Optional filter condition (the WHERE clause without the WHERE keyword), e.g. c.department = 'HR'"`
+	PartitionKey string `json:"partitionKey,omitempty" jsonschema:"Optional partition key value to scope the query to"`
+	MaxItems     int    `json:"maxItems,omitempty" jsonschema:"Maximum number of values to fetch (default 10000, maximum 100000)"`
+}
+
+type ClientSideAggregateToolResult struct {
+	Aggregate          string   `json:"aggregate"`
+	Field              string   `json:"field,omitempty"`
+	Value              *float64 `json:"value" jsonschema:"The aggregate value, null if there were no values to aggregate"`
+	ValuesFetched      int      `json:"values_fetched" jsonschema:"Number of values fetched from the container"`
+	ValuesSkipped      int      `json:"values_skipped" jsonschema:"Number of fetched values that were ignored because they are not numbers"`
+	Truncated          bool     `json:"truncated" jsonschema:"True if the maxItems cap was hit and the aggregate only covers part of the data"`
+	Query              string   `json:"query" jsonschema:"The query that was executed to fetch the values"`
+	RequestCharge      float32  `json:"request_charge" jsonschema:"Total request charge (RU) of fetching the values"`
+	ComputedClientSide bool     `json:"computed_client_side" jsonschema:"Always true - the aggregate was computed by the MCP server, not Azure Cosmos DB"`
+	Message            string   `json:"message"`
+}
+
+func ClientSideAggregateToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input ClientSideAggregateToolInput) (*mcp.CallToolResult, ClientSideAggregateToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, ClientSideAggregateToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, ClientSideAggregateToolResult{}, newInputError("database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, ClientSideAggregateToolResult{}, newInputError("container name missing")
+	}
+
+	aggregate := strings.ToLower(strings.TrimSpace(input.Aggregate))
+	if _, ok := supportedAggregates[aggregate]; !ok {
+		return nil, ClientSideAggregateToolResult{}, inputErrorf("invalid aggregate '%s', must be one of: count, sum, avg, min, max", input.Aggregate)
+	}
+
+	projection := "1"
+	if input.Field != "" || aggregate != "count" {
+		field, err := fieldReference(input.Field)
+		if err != nil {
+			return nil, ClientSideAggregateToolResult{}, err
+		}
+		projection = field
+	}
+
+	if input.MaxItems < 0 {
+		return nil, ClientSideAggregateToolResult{}, newInputError("maxItems must not be negative")
+	}
+
+	maxItems := input.MaxItems
+	if maxItems == 0 {
+		maxItems = defaultClientSideAggregateMaxItems
+	}
+	if maxItems > maxClientSideAggregateMaxItems {
+		maxItems = maxClientSideAggregateMaxItems
+	}
+
+	query := fmt.Sprintf("SELECT VALUE %s FROM c", projection)
+	if strings.TrimSpace(input.Filter) != "" {
+		query += " WHERE " + input.Filter
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, ClientSideAggregateToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, ClientSideAggregateToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, ClientSideAggregateToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	var partitionKey azcosmos.PartitionKey
+	if input.PartitionKey != "" {
+		partitionKey = azcosmos.NewPartitionKeyString(input.PartitionKey)
+	} else {
+		partitionKey = azcosmos.PartitionKey{} // Empty partition key for cross-partition queries
+	}
+
+	values, requestCharge, truncated, err := fetchValues(ctx, containerClient, query, partitionKey, maxItems)
+	if err != nil {
+		return nil, ClientSideAggregateToolResult{}, err
+	}
+
+	value, skipped := computeAggregate(aggregate, values)
+
+	message := fmt.Sprintf("%s computed client-side over %d values", aggregate, len(values)-skipped)
+	if truncated {
+		message += fmt.Sprintf(". The result is PARTIAL: only the first %d values were fetched, increase maxItems or narrow the filter", maxItems)
+	}
+
+	return nil, ClientSideAggregateToolResult{
+		Aggregate:          aggregate,
+		Field:              input.Field,
+		Value:              value,
+		ValuesFetched:      len(values),
+		ValuesSkipped:      skipped,
+		Truncated:          truncated,
+		Query:              query,
+		RequestCharge:      requestCharge,
+		ComputedClientSide: true,
+		Message:            message,
+	}, nil
+}
+
+// fetchValues runs the query and returns at most maxItems results, with the total request charge.
+// truncated is true if the query had more results than maxItems.
+func fetchValues(ctx context.Context, containerClient *azcosmos.ContainerClient, query string, partitionKey azcosmos.PartitionKey, maxItems int) ([][]byte, float32, bool, error) {
+	queryPager := containerClient.NewQueryItemsPager(query, partitionKey, nil)
+
+	values := [][]byte{}
+	var requestCharge float32
+	truncated := false
+
+	for queryPager.More() && !truncated {
+		queryResponse, err := nextPageWithRetry(ctx, queryPager)
+		if err != nil {
+			return nil, 0, false, fmt.Errorf("query page error: %w", withDiagnostics(err))
+		}
+		requestCharge += queryResponse.RequestCharge
+
+		for _, item := range queryResponse.Items {
+			if len(values) == maxItems {
+				truncated = true
+				break
+			}
+			values = append(values, item)
+		}
+
+		// stop before fetching another page once the cap is reached
+		if len(values) == maxItems && queryPager.More() {
+			truncated = true
+		}
+	}
+
+	return values, requestCharge, truncated, nil
+}
+
+// computeAggregate computes the aggregate over JSON values. Count includes every value, the other aggregates
+// only consider numbers and report how many values were skipped. A nil result means there was nothing to aggregate.
+func computeAggregate(aggregate string, values [][]byte) (*float64, int) {
+	if aggregate == "count" {
+		count := float64(len(values))
+		return &count, 0
+	}
+
+	numbers := make([]float64, 0, len(values))
+	for _, value := range values {
+		var decoded any
+		if err := json.Unmarshal(value, &decoded); err != nil {
+			continue
+		}
+		if number, ok := decoded.(float64); ok {
+			numbers = append(numbers, number)
+		}
+	}
+	skipped := len(values) - len(numbers)
+
+	if len(numbers) == 0 {
+		return nil, skipped
+	}
+
+	var result float64
+	switch aggregate {
+	case "sum", "avg":
+		for _, number := range numbers {
+			result += number
+		}
+		if aggregate == "avg" {
+			result /= float64(len(numbers))
+		}
+	case "min":
+		result = numbers[0]
+		for _, number := range numbers[1:] {
+			result = min(result, number)
+		}
+	case "max":
+		result = numbers[0]
+		for _, number := range numbers[1:] {
+			result = max(result, number)
+		}
+	}
+
+	return &result, skipped
+}
+
+func DistinctValues() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "distinct_values",
+		Description: "Get the distinct values of a field over the items of a container in Azure Cosmos DB or local emulator, ACROSS PARTITIONS. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. DISTINCT is not supported for cross-partition queries by the Azure Cosmos DB Gateway API (used by the Go SDK), so this tool fetches the values of the field with a simple SELECT/WHERE query and removes the duplicates in the MCP server (client-side). Items without the field are ignored. The number of values fetched is capped by maxItems - if the cap is hit the result is marked as truncated and may miss distinct values. Prefer count_distinct when the data is in a single partition.",
+	}
+}
+
+type DistinctValuesToolInput struct {
+	ConnectionConfig
+	Database     string `json:"database" jsonschema:"Name of the database"`
+	Container    string `json:"container" jsonschema:"Name of the container"`
+	Field        string `json:"field" jsonschema:"Field to get the distinct values of, e.g. category or address.city"`
+	Filter       string `json:"filter,omitempty" jsonschema:"Optional filter condition (the WHERE clause without the WHERE keyword), e.g. c.department = 'HR'"`
+	PartitionKey string `json:"partitionKey,omitempty" jsonschema:"Optional partition key value to scope the query to"`
+	MaxItems     int    `json:"maxItems,omitempty" jsonschema:"Maximum number of values to fetch before removing duplicates (default 10000, maximum 100000)"`
+}
+
+type DistinctValuesToolResult struct {
+	Field                  string  `json:"field"`
+	DistinctCount          int     `json:"distinct_count" jsonschema:"Number of distinct values of the field"`
+	DistinctValues         []any   `json:"distinct_values" jsonschema:"The distinct values of the field, in the order they were first fetched"`
+	ValuesFetched          int     `json:"values_fetched" jsonschema:"Number of values fetched from the container, including duplicates"`
+	Truncated              bool    `json:"truncated" jsonschema:"True if the maxItems cap was hit and some distinct values may be missing"`
+	Query                  string  `json:"query" jsonschema:"The query that was executed to fetch the values"`
+	RequestCharge          float32 `json:"request_charge" jsonschema:"Total request charge (RU) of fetching the values"`
+	DeduplicatedClientSide bool    `json:"deduplicated_client_side" jsonschema:"Always true - duplicates were removed by the MCP server, not Azure Cosmos DB"`
+	Message                string  `json:"message"`
+}
+
+func DistinctValuesToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input DistinctValuesToolInput) (*mcp.CallToolResult, DistinctValuesToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, DistinctValuesToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, DistinctValuesToolResult{}, newInputError("database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, DistinctValuesToolResult{}, newInputError("container name missing")
+	}
+
+	field, err := fieldReference(input.Field)
+	if err != nil {
+		return nil, DistinctValuesToolResult{}, err
+	}
+
+	if input.MaxItems < 0 {
+		return nil, DistinctValuesToolResult{}, newInputError("maxItems must not be negative")
+	}
+
+	maxItems := input.MaxItems
+	if maxItems == 0 {
+		maxItems = defaultClientSideAggregateMaxItems
+	}
+	if maxItems > maxClientSideAggregateMaxItems {
+		maxItems = maxClientSideAggregateMaxItems
+	}
+
+	query := fmt.Sprintf("SELECT VALUE %s FROM c", field)
+	if strings.TrimSpace(input.Filter) != "" {
+		query += " WHERE " + input.Filter
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, DistinctValuesToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, DistinctValuesToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, DistinctValuesToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	var partitionKey azcosmos.PartitionKey
+	if input.PartitionKey != "" {
+		partitionKey = azcosmos.NewPartitionKeyString(input.PartitionKey)
+	}
+
+	values, requestCharge, truncated, err := fetchValues(ctx, containerClient, query, partitionKey, maxItems)
+	if err != nil {
+		return nil, DistinctValuesToolResult{}, err
+	}
+
+	distinct, err := distinctJSONValues(values)
+	if err != nil {
+		return nil, DistinctValuesToolResult{}, err
+	}
+
+	message := fmt.Sprintf("%d distinct values found client-side in %d fetched values", len(distinct), len(values))
+	if truncated {
+		message += fmt.Sprintf(". The result is PARTIAL: only the first %d values were fetched, increase maxItems or narrow the filter", maxItems)
+	}
+
+	return nil, DistinctValuesToolResult{
+		Field:                  input.Field,
+		DistinctCount:          len(distinct),
+		DistinctValues:         distinct,
+		ValuesFetched:          len(values),
+		Truncated:              truncated,
+		Query:                  query,
+		RequestCharge:          requestCharge,
+		DeduplicatedClientSide: true,
+		Message:                message,
+	}, nil
+}
+
+// distinctJSONValues removes duplicate JSON values, keeping the order in which they were first seen.
+// Values are compared by their re-encoded form, so objects with the same properties in a different order are equal,
+// like with DISTINCT in Azure Cosmos DB.
+func distinctJSONValues(values [][]byte) ([]any, error) {
+	distinct := []any{}
+	seen := map[string]bool{}
+
+	for _, value := range values {
+		var decoded any
+		if err := json.Unmarshal(value, &decoded); err != nil {
+			return nil, fmt.Errorf("error parsing query result: %v", err)
+		}
+
+		// maps are encoded with sorted keys
+		key, err := json.Marshal(decoded)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding query result: %v", err)
+		}
+
+		if seen[string(key)] {
+			continue
+		}
+		seen[string(key)] = true
+		distinct = append(distinct, decoded)
+	}
+
+	return distinct, nil
+}
@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for aggregation helpers that do not need the emulator
+
+func TestFieldReference(t *testing.T) {
+	tests := []struct {
+		name        string
+		field       string
+		expected    string
+		expectError bool
+	}{
+		{name: "top level field", field: "category", expected: "c.category"},
+		{name: "nested field", field: "address.city", expected: "c.address.city"},
+		{name: "partition key path style", field: "/address/city", expected: "c.address.city"},
+		{name: "empty field", field: "", expectError: true},
+		{name: "injection attempt", field: "category FROM c WHERE 1=1 --", expectError: true},
+		{name: "empty segment", field: "address..city", expectError: true},
+		{name: "starts with digit", field: "1category", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reference, err := fieldReference(tt.field)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, reference)
+		})
+	}
+}
+
+func TestComputeAggregate(t *testing.T) {
+	values := [][]byte{[]byte(`10`), []byte(`2.5`), []byte(`"n/a"`), []byte(`30`), []byte(`null`)}
+
+	tests := []struct {
+		aggregate       string
+		expected        float64
+		expectedSkipped int
+	}{
+		{aggregate: "count", expected: 5, expectedSkipped: 0},
+		{aggregate: "sum", expected: 42.5, expectedSkipped: 2},
+		{aggregate: "avg", expected: 42.5 / 3, expectedSkipped: 2},
+		{aggregate: "min", expected: 2.5, expectedSkipped: 2},
+		{aggregate: "max", expected: 30, expectedSkipped: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.aggregate, func(t *testing.T) {
+			value, skipped := computeAggregate(tt.aggregate, values)
+			require.NotNil(t, value)
+			assert.InDelta(t, tt.expected, *value, 1e-9)
+			assert.Equal(t, tt.expectedSkipped, skipped)
+		})
+	}
+}
+
+func TestComputeAggregate_NoNumbers(t *testing.T) {
+	value, skipped := computeAggregate("sum", [][]byte{[]byte(`"a"`)})
+	assert.Nil(t, value)
+	assert.Equal(t, 1, skipped)
+
+	value, _ = computeAggregate("count", [][]byte{})
+	require.NotNil(t, value)
+	assert.Equal(t, float64(0), *value)
+}
+
+func TestDistinctJSONValues(t *testing.T) {
+	values := [][]byte{
+		[]byte(`"books"`),
+		[]byte(`42`),
+		[]byte(`"books"`),
+		[]byte(`{"a": 1, "b": 2}`),
+		[]byte(`{"b": 2, "a": 1}`),
+		[]byte(`"42"`),
+		[]byte(`null`),
+		[]byte(`42.0`),
+	}
+
+	distinct, err := distinctJSONValues(values)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"books", float64(42), map[string]any{"a": float64(1), "b": float64(2)}, "42", nil}, distinct)
+
+	_, err = distinctJSONValues([][]byte{[]byte(`{`)})
+	require.Error(t, err)
+}
+
+func TestBuildHistogram(t *testing.T) {
+	results := [][]byte{
+		[]byte(`{"groupKey": "book", "itemCount": 3}`),
+		[]byte(`{"itemCount": 2}`),
+		[]byte(`{"groupKey": "toy", "itemCount": 5}`),
+		[]byte(`{"groupKey": null, "itemCount": 1}`),
+		[]byte(`{"groupKey": 7, "itemCount": 3}`),
+	}
+
+	histogram, err := buildHistogram(results, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []HistogramBucket{
+		{Value: "toy", Count: 5},
+		{Value: "book", Count: 3},
+		{Value: float64(7), Count: 3},
+	}, histogram.Buckets)
+	assert.Equal(t, 4, histogram.DistinctValues)
+	assert.Equal(t, 1, histogram.OtherCount, "the null bucket is beyond the top 3")
+	assert.Equal(t, 2, histogram.MissingCount)
+	assert.Equal(t, 14, histogram.TotalCount)
+
+	empty, err := buildHistogram(nil, 10)
+	require.NoError(t, err)
+	assert.Empty(t, empty.Buckets)
+	assert.NotNil(t, empty.Buckets)
+
+	_, err = buildHistogram([][]byte{[]byte(`not json`)}, 10)
+	assert.Error(t, err)
+}
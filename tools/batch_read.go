@@ -0,0 +1,274 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxBatchReadItems is the upper bound for the number of items read by a single batch_read_items call
+const maxBatchReadItems = 1000
+
+// maxBatchReadConcurrency is the upper bound for concurrent partition reads
+const maxBatchReadConcurrency = 10
+
+// defaultBatchReadConcurrency is used when the concurrency is not specified
+const defaultBatchReadConcurrency = 4
+
+// readManyQuery reads the items of a partition whose id is in the @ids parameter
+const readManyQuery = "SELECT * FROM c WHERE ARRAY_CONTAINS(@ids, c.id)"
+
+func BatchReadItems() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "batch_read_items",
+		Description: "Read multiple items by ID and partition key from a container in Azure Cosmos DB or local emulator, in as few round trips as possible. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. The items are grouped by partition key: a partition with a single item is read with a point read, and the items of any other partition are read with a single query. Partitions are read concurrently. Results are keyed by item ID, each with the item or an error (e.g. item not found, or omitted because the response size limit was reached), so one missing item does not fail the whole call. Use this instead of calling read_item many times.",
+	}
+}
+
+type ItemReference struct {
+	ID           string `json:"id" jsonschema:"ID of the item to read"`
+	PartitionKey string `json:"partitionKey" jsonschema:"Partition key value of the item"`
+}
+
+type BatchReadItemsToolInput struct {
+	ConnectionConfig
+	Database       string          `json:"database" jsonschema:"Name of the database"`
+	Container      string          `json:"container" jsonschema:"Name of the container to read data from"`
+	Items          []ItemReference `json:"items" jsonschema:"IDs and partition key values of the items to read (maximum 1000). IDs must be unique"`
+	MaxConcurrency int             `json:"maxConcurrency,omitempty" jsonschema:"Maximum number of partitions to read concurrently (default 4, maximum 10)"`
+}
+
+type BatchReadItemResult struct {
+	PartitionKey string `json:"partition_key"`
+	Item         string `json:"item,omitempty" jsonschema:"The item data as JSON string, empty if the item could not be read"`
+	Error        string `json:"error,omitempty" jsonschema:"Why the item could not be read, e.g. item not found"`
+}
+
+type BatchReadItemsToolResult struct {
+	Items      map[string]BatchReadItemResult `json:"items" jsonschema:"Results keyed by item ID"`
+	Found      int                            `json:"found" jsonschema:"Number of items that were read"`
+	Failed     int                            `json:"failed" jsonschema:"Number of items that were not found or could not be read"`
+	Partitions int                            `json:"partitions" jsonschema:"Number of partitions that were read, i.e. the number of round trips"`
+	Note       string                         `json:"note,omitempty" jsonschema:"Set if items were left out to keep the response within the size limit"`
+}
+
+func BatchReadItemsToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input BatchReadItemsToolInput) (*mcp.CallToolResult, BatchReadItemsToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, BatchReadItemsToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, BatchReadItemsToolResult{}, newInputError("database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, BatchReadItemsToolResult{}, newInputError("container name missing")
+	}
+
+	if len(input.Items) > maxBatchReadItems {
+		return nil, BatchReadItemsToolResult{}, inputErrorf("too many items: at most %d items can be read in a single call", maxBatchReadItems)
+	}
+
+	partitions, err := groupItemsByPartition(input.Items)
+	if err != nil {
+		return nil, BatchReadItemsToolResult{}, err
+	}
+
+	concurrency := input.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchReadConcurrency
+	}
+	if concurrency > maxBatchReadConcurrency {
+		concurrency = maxBatchReadConcurrency
+	}
+
+	maxResponseBytes, err := GetMaxResponseBytes()
+	if err != nil {
+		return nil, BatchReadItemsToolResult{}, err
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, BatchReadItemsToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, BatchReadItemsToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, BatchReadItemsToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	type partitionResult struct {
+		items map[string][]byte
+		err   error
+	}
+
+	results := make([]partitionResult, len(partitions))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				items, err := readPartitionItems(ctx, containerClient, partitions[i])
+				results[i] = partitionResult{items: items, err: err}
+			}
+		}()
+	}
+
+	for i := range partitions {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	response := BatchReadItemsToolResult{
+		Items:      make(map[string]BatchReadItemResult, len(input.Items)),
+		Partitions: len(partitions),
+	}
+
+	var failedPartitions int
+	var firstErr error
+	var responseBytes, omitted int
+
+	for i, partition := range partitions {
+		result := results[i]
+		if result.err != nil {
+			failedPartitions++
+			if firstErr == nil {
+				firstErr = result.err
+			}
+		}
+
+		for _, id := range partition.ids {
+			itemResult := BatchReadItemResult{PartitionKey: partition.partitionKey}
+
+			item, found := result.items[id]
+			switch {
+			case result.err != nil:
+				itemResult.Error = result.err.Error()
+			case !found:
+				itemResult.Error = "item not found"
+			case maxResponseBytes > 0 && responseBytes+len(item) > maxResponseBytes:
+				itemResult.Error = "omitted to keep the response within the size limit, read it in another call"
+				omitted++
+			default:
+				itemResult.Item = string(item)
+				responseBytes += len(item)
+			}
+
+			if itemResult.Error != "" {
+				response.Failed++
+			} else {
+				response.Found++
+			}
+			response.Items[id] = itemResult
+		}
+	}
+
+	if len(partitions) > 0 && failedPartitions == len(partitions) {
+		return nil, BatchReadItemsToolResult{}, fmt.Errorf("reading items failed on all partitions: %w", firstErr)
+	}
+
+	if omitted > 0 {
+		response.Note = responseSizeNote(omitted, len(input.Items), maxResponseBytes)
+	}
+
+	return nil, response, nil
+}
+
+// partitionReads is the list of item IDs to read from a single partition
+type partitionReads struct {
+	partitionKey string
+	ids          []string
+}
+
+// groupItemsByPartition groups the items by partition key value, keeping the order in which partitions and IDs first appear
+func groupItemsByPartition(items []ItemReference) ([]partitionReads, error) {
+	if len(items) == 0 {
+		return nil, newInputError("items missing")
+	}
+
+	partitions := []partitionReads{}
+	partitionIndex := map[string]int{}
+	seen := map[string]bool{}
+
+	for i, item := range items {
+		if item.ID == "" {
+			return nil, inputErrorf("item %d: item ID missing", i)
+		}
+		if item.PartitionKey == "" {
+			return nil, inputErrorf("item %d: partition key missing", i)
+		}
+		// results are keyed by ID
+		if seen[item.ID] {
+			return nil, inputErrorf("item %d: duplicate item ID '%s', IDs must be unique", i, item.ID)
+		}
+		seen[item.ID] = true
+
+		index, ok := partitionIndex[item.PartitionKey]
+		if !ok {
+			index = len(partitions)
+			partitionIndex[item.PartitionKey] = index
+			partitions = append(partitions, partitionReads{partitionKey: item.PartitionKey})
+		}
+		partitions[index].ids = append(partitions[index].ids, item.ID)
+	}
+
+	return partitions, nil
+}
+
+// readPartitionItems reads the items of a single partition, keyed by ID. Items that do not exist are left out.
+// A single item is read with a point read, which is cheaper than a query.
+func readPartitionItems(ctx context.Context, containerClient *azcosmos.ContainerClient, partition partitionReads) (map[string][]byte, error) {
+	partitionKey := azcosmos.NewPartitionKeyString(partition.partitionKey)
+
+	if len(partition.ids) == 1 {
+		itemResponse, err := containerClient.ReadItem(ctx, partitionKey, partition.ids[0], nil)
+		if err != nil {
+			var responseErr *azcore.ResponseError
+			if errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusNotFound {
+				return map[string][]byte{}, nil
+			}
+			return nil, fmt.Errorf("error reading item: %w", withDiagnostics(err))
+		}
+		return map[string][]byte{partition.ids[0]: itemResponse.Value}, nil
+	}
+
+	options := &azcosmos.QueryOptions{
+		QueryParameters: []azcosmos.QueryParameter{{Name: "@ids", Value: partition.ids}},
+	}
+
+	rows, err := collectQueryResults(ctx, containerClient, readManyQuery, partitionKey, options)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make(map[string][]byte, len(rows))
+	for _, row := range rows {
+		var item struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(row, &item); err != nil {
+			return nil, fmt.Errorf("error parsing query result: %v", err)
+		}
+		items[item.ID] = row
+	}
+
+	return items, nil
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for batch read helpers that do not need the emulator
+
+func TestGroupItemsByPartition(t *testing.T) {
+	partitions, err := groupItemsByPartition([]ItemReference{
+		{ID: "1", PartitionKey: "books"},
+		{ID: "2", PartitionKey: "games"},
+		{ID: "3", PartitionKey: "books"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []partitionReads{
+		{partitionKey: "books", ids: []string{"1", "3"}},
+		{partitionKey: "games", ids: []string{"2"}},
+	}, partitions)
+
+	tests := []struct {
+		name           string
+		items          []ItemReference
+		expectedErrMsg string
+	}{
+		{name: "no items", items: nil, expectedErrMsg: "items missing"},
+		{name: "missing id", items: []ItemReference{{PartitionKey: "books"}}, expectedErrMsg: "item 0: item ID missing"},
+		{name: "missing partition key", items: []ItemReference{{ID: "1"}}, expectedErrMsg: "item 0: partition key missing"},
+		{
+			name:           "duplicate id",
+			items:          []ItemReference{{ID: "1", PartitionKey: "books"}, {ID: "1", PartitionKey: "games"}},
+			expectedErrMsg: "item 1: duplicate item ID '1'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := groupItemsByPartition(tt.items)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErrMsg)
+		})
+	}
+}
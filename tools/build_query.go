@@ -0,0 +1,231 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxBuildQueryLimit is the upper bound for the TOP of a built query, larger result sets should be paged with execute_query
+const maxBuildQueryLimit = 10000
+
+// filterOperators maps the comparison operators accepted by build_query to their SQL form.
+// Function operators are handled by filterCondition.
+var filterOperators = map[string]string{
+	"eq": "=",
+	"ne": "!=",
+	"lt": "<",
+	"le": "<=",
+	"gt": ">",
+	"ge": ">=",
+}
+
+func BuildQuery() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "build_query",
+		Description: "Build a valid, parameterized Azure Cosmos DB SQL query from a structured description, without executing it. Does not connect to Azure Cosmos DB. Provide the fields to select (all fields if empty), filters as field, operator and value (combined with AND), and optionally the fields to sort by and a limit. Operators: eq, ne, lt, le, gt, ge, contains and startsWith (strings), in (value is an array), exists and notExists (no value). Fields are property paths like status or address.city. Returns the parameterized query with its parameter values, and the same query with the values inlined that can be reviewed and then run with execute_query, passing partitionKey if it was provided. Sorting and limits are not supported for cross-partition queries by the Azure Cosmos DB Gateway API (used by the Go SDK), so they require a partitionKey.",
+	}
+}
+
+type QueryFilter struct {
+	Field    string `json:"field" jsonschema:"Property path to filter on, e.g. status or address.city"`
+	Operator string `json:"operator" jsonschema:"eq, ne, lt, le, gt, ge, contains, startsWith, in, exists or notExists"`
+	Value    any    `json:"value,omitempty" jsonschema:"Value to compare with, an array for in, not used for exists and notExists"`
+}
+
+type QuerySort struct {
+	Field      string `json:"field" jsonschema:"Property path to sort by"`
+	Descending bool   `json:"descending,omitempty" jsonschema:"Sort in descending order (default ascending)"`
+}
+
+type BuildQueryToolInput struct {
+	Select       []string      `json:"select,omitempty" jsonschema:"Property paths to return, e.g. id and address.city (all fields if empty)"`
+	Filters      []QueryFilter `json:"filters,omitempty" jsonschema:"Conditions the items must match, combined with AND"`
+	OrderBy      []QuerySort   `json:"orderBy,omitempty" jsonschema:"Fields to sort by, in order. Sorting by more than one field requires a composite index (requires partitionKey)"`
+	Limit        int           `json:"limit,omitempty" jsonschema:"Maximum number of results, added as TOP (maximum 10000, requires partitionKey)"`
+	PartitionKey string        `json:"partitionKey,omitempty" jsonschema:"Partition key value the query will be run with, required for orderBy and limit"`
+}
+
+type BuildQueryParameter struct {
+	Name  string `json:"name"`
+	Value any    `json:"value"`
+}
+
+type BuildQueryToolResult struct {
+	Query          string                `json:"query" jsonschema:"The parameterized query"`
+	Parameters     []BuildQueryParameter `json:"parameters" jsonschema:"The values of the query parameters"`
+	InlineQuery    string                `json:"inline_query" jsonschema:"The query with the parameter values inlined, to be run with execute_query"`
+	PartitionKey   string                `json:"partition_key,omitempty" jsonschema:"Partition key value to pass to execute_query"`
+	CrossPartition bool                  `json:"cross_partition" jsonschema:"Whether the query has to be run across all partitions (allowCrossPartition in execute_query)"`
+	Notes          []string              `json:"notes,omitempty"`
+}
+
+func BuildQueryToolHandler(_ context.Context, _ *mcp.CallToolRequest, input BuildQueryToolInput) (*mcp.CallToolResult, BuildQueryToolResult, error) {
+
+	if input.Limit < 0 || input.Limit > maxBuildQueryLimit {
+		return nil, BuildQueryToolResult{}, inputErrorf("invalid limit %d: must be between 1 and %d", input.Limit, maxBuildQueryLimit)
+	}
+
+	if input.PartitionKey == "" && (len(input.OrderBy) > 0 || input.Limit > 0) {
+		return nil, BuildQueryToolResult{}, newInputError("orderBy and limit require a partitionKey: ORDER BY and TOP are not supported for cross-partition queries by the Gateway API")
+	}
+
+	result, err := buildQuery(input)
+	if err != nil {
+		return nil, BuildQueryToolResult{}, err
+	}
+
+	result.PartitionKey = input.PartitionKey
+	result.CrossPartition = input.PartitionKey == ""
+
+	if len(input.OrderBy) > 1 {
+		result.Notes = append(result.Notes, "Sorting by more than one field requires a composite index on these fields, which can be added with add_composite_index.")
+	}
+	if result.CrossPartition {
+		result.Notes = append(result.Notes, "Without a partition key the query scans every partition, set allowCrossPartition to true in execute_query to run it.")
+	}
+
+	return nil, result, nil
+}
+
+// buildQuery assembles the query from the description. Field paths are validated so they can be embedded in the query text,
+// values are always passed as parameters (@p0, @p1 ...), and inlined as JSON literals in the inline query.
+func buildQuery(input BuildQueryToolInput) (BuildQueryToolResult, error) {
+	var query, inline strings.Builder
+	parameters := []BuildQueryParameter{}
+
+	writeBoth := func(text string) {
+		query.WriteString(text)
+		inline.WriteString(text)
+	}
+
+	writeBoth("SELECT ")
+	if input.Limit > 0 {
+		writeBoth(fmt.Sprintf("TOP %d ", input.Limit))
+	}
+
+	if len(input.Select) == 0 {
+		writeBoth("*")
+	} else {
+		fields := make([]string, len(input.Select))
+		for i, field := range input.Select {
+			reference, err := fieldReference(field)
+			if err != nil {
+				return BuildQueryToolResult{}, inputErrorf("select %d: %v", i, err)
+			}
+			fields[i] = reference
+		}
+		writeBoth(strings.Join(fields, ", "))
+	}
+	writeBoth(" FROM c")
+
+	for i, filter := range input.Filters {
+		if i == 0 {
+			writeBoth(" WHERE ")
+		} else {
+			writeBoth(" AND ")
+		}
+
+		reference, err := fieldReference(filter.Field)
+		if err != nil {
+			return BuildQueryToolResult{}, inputErrorf("filter %d: %v", i, err)
+		}
+
+		name := fmt.Sprintf("@p%d", len(parameters))
+		condition, usesValue, err := filterCondition(reference, filter.Operator, filter.Value, name)
+		if err != nil {
+			return BuildQueryToolResult{}, inputErrorf("filter %d: %v", i, err)
+		}
+		query.WriteString(condition)
+
+		if !usesValue {
+			inline.WriteString(condition)
+			continue
+		}
+
+		parameters = append(parameters, BuildQueryParameter{Name: name, Value: filter.Value})
+
+		literal, err := queryLiteral(filter.Value)
+		if err != nil {
+			return BuildQueryToolResult{}, inputErrorf("filter %d: %v", i, err)
+		}
+		inlineCondition, _, _ := filterCondition(reference, filter.Operator, filter.Value, literal)
+		inline.WriteString(inlineCondition)
+	}
+
+	for i, sort := range input.OrderBy {
+		if i == 0 {
+			writeBoth(" ORDER BY ")
+		} else {
+			writeBoth(", ")
+		}
+
+		reference, err := fieldReference(sort.Field)
+		if err != nil {
+			return BuildQueryToolResult{}, inputErrorf("orderBy %d: %v", i, err)
+		}
+
+		direction := "ASC"
+		if sort.Descending {
+			direction = "DESC"
+		}
+		writeBoth(reference + " " + direction)
+	}
+
+	return BuildQueryToolResult{
+		Query:       query.String(),
+		Parameters:  parameters,
+		InlineQuery: inline.String(),
+	}, nil
+}
+
+// filterCondition returns the condition for the operator on the field reference, with value as the parameter name or literal
+// to compare with. It also reports whether the operator uses the value.
+func filterCondition(reference, operator string, rawValue any, value string) (string, bool, error) {
+	if sqlOperator, ok := filterOperators[strings.ToLower(operator)]; ok {
+		if rawValue == nil {
+			return "", false, inputErrorf("value missing for operator '%s'", operator)
+		}
+		return fmt.Sprintf("%s %s %s", reference, sqlOperator, value), true, nil
+	}
+
+	switch strings.ToLower(operator) {
+	case "contains", "startswith":
+		if _, ok := rawValue.(string); !ok {
+			return "", false, inputErrorf("operator '%s' requires a string value", operator)
+		}
+		function := "CONTAINS"
+		if strings.EqualFold(operator, "startswith") {
+			function = "STARTSWITH"
+		}
+		return fmt.Sprintf("%s(%s, %s)", function, reference, value), true, nil
+	case "in":
+		if _, ok := rawValue.([]any); !ok {
+			return "", false, newInputError("operator 'in' requires an array value")
+		}
+		return fmt.Sprintf("ARRAY_CONTAINS(%s, %s)", value, reference), true, nil
+	case "exists":
+		return fmt.Sprintf("IS_DEFINED(%s)", reference), false, nil
+	case "notexists":
+		return fmt.Sprintf("NOT IS_DEFINED(%s)", reference), false, nil
+	case "":
+		return "", false, newInputError("operator missing")
+	default:
+		return "", false, inputErrorf("invalid operator '%s': must be one of eq, ne, lt, le, gt, ge, contains, startsWith, in, exists or notExists", operator)
+	}
+}
+
+// queryLiteral returns the value as a query literal. JSON values are valid literals in the Azure Cosmos DB query language.
+func queryLiteral(value any) (string, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", inputErrorf("invalid value: %v", err)
+	}
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
+}
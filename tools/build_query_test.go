@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for build_query, which does not connect to Azure Cosmos DB
+
+func TestBuildQueryToolHandler(t *testing.T) {
+	tests := []struct {
+		name               string
+		input              BuildQueryToolInput
+		expectedQuery      string
+		expectedInline     string
+		expectedParameters []BuildQueryParameter
+		expectedCross      bool
+		expectError        string
+	}{
+		{
+			name:               "all fields",
+			input:              BuildQueryToolInput{},
+			expectedQuery:      "SELECT * FROM c",
+			expectedInline:     "SELECT * FROM c",
+			expectedParameters: []BuildQueryParameter{},
+			expectedCross:      true,
+		},
+		{
+			name: "select, filters, sort and limit",
+			input: BuildQueryToolInput{
+				Select: []string{"id", "address.city"},
+				Filters: []QueryFilter{
+					{Field: "status", Operator: "eq", Value: "active"},
+					{Field: "age", Operator: "ge", Value: float64(21)},
+					{Field: "email", Operator: "exists"},
+				},
+				OrderBy:      []QuerySort{{Field: "age", Descending: true}},
+				Limit:        10,
+				PartitionKey: "user1",
+			},
+			expectedQuery:  "SELECT TOP 10 c.id, c.address.city FROM c WHERE c.status = @p0 AND c.age >= @p1 AND IS_DEFINED(c.email) ORDER BY c.age DESC",
+			expectedInline: `SELECT TOP 10 c.id, c.address.city FROM c WHERE c.status = "active" AND c.age >= 21 AND IS_DEFINED(c.email) ORDER BY c.age DESC`,
+			expectedParameters: []BuildQueryParameter{
+				{Name: "@p0", Value: "active"},
+				{Name: "@p1", Value: float64(21)},
+			},
+		},
+		{
+			name: "string functions and in",
+			input: BuildQueryToolInput{
+				Filters: []QueryFilter{
+					{Field: "name", Operator: "contains", Value: `O"Brien <x>`},
+					{Field: "/address/zip", Operator: "startsWith", Value: "98"},
+					{Field: "category", Operator: "in", Value: []any{"books", "music"}},
+					{Field: "deleted", Operator: "notExists"},
+				},
+			},
+			expectedQuery:  "SELECT * FROM c WHERE CONTAINS(c.name, @p0) AND STARTSWITH(c.address.zip, @p1) AND ARRAY_CONTAINS(@p2, c.category) AND NOT IS_DEFINED(c.deleted)",
+			expectedInline: `SELECT * FROM c WHERE CONTAINS(c.name, "O\"Brien <x>") AND STARTSWITH(c.address.zip, "98") AND ARRAY_CONTAINS(["books","music"], c.category) AND NOT IS_DEFINED(c.deleted)`,
+			expectedParameters: []BuildQueryParameter{
+				{Name: "@p0", Value: `O"Brien <x>`},
+				{Name: "@p1", Value: "98"},
+				{Name: "@p2", Value: []any{"books", "music"}},
+			},
+			expectedCross: true,
+		},
+		{name: "sort without partition key", input: BuildQueryToolInput{OrderBy: []QuerySort{{Field: "age"}}}, expectError: "require a partitionKey"},
+		{name: "limit without partition key", input: BuildQueryToolInput{Limit: 5}, expectError: "require a partitionKey"},
+		{name: "limit too large", input: BuildQueryToolInput{Limit: 10001, PartitionKey: "user1"}, expectError: "invalid limit"},
+		{name: "invalid select field", input: BuildQueryToolInput{Select: []string{"name; DROP"}}, expectError: "select 0: invalid field name"},
+		{name: "invalid filter field", input: BuildQueryToolInput{Filters: []QueryFilter{{Field: "a-b", Operator: "eq", Value: 1}}}, expectError: "filter 0: invalid field name"},
+		{name: "invalid operator", input: BuildQueryToolInput{Filters: []QueryFilter{{Field: "a", Operator: "like", Value: "x"}}}, expectError: "invalid operator 'like'"},
+		{name: "missing operator", input: BuildQueryToolInput{Filters: []QueryFilter{{Field: "a", Value: "x"}}}, expectError: "operator missing"},
+		{name: "missing value", input: BuildQueryToolInput{Filters: []QueryFilter{{Field: "a", Operator: "eq"}}}, expectError: "value missing"},
+		{name: "contains with number", input: BuildQueryToolInput{Filters: []QueryFilter{{Field: "a", Operator: "contains", Value: float64(1)}}}, expectError: "requires a string value"},
+		{name: "in without array", input: BuildQueryToolInput{Filters: []QueryFilter{{Field: "a", Operator: "in", Value: "x"}}}, expectError: "requires an array value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, result, err := BuildQueryToolHandler(context.Background(), nil, tt.input)
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedQuery, result.Query)
+			assert.Equal(t, tt.expectedInline, result.InlineQuery)
+			assert.Equal(t, tt.expectedParameters, result.Parameters)
+			assert.Equal(t, tt.expectedCross, result.CrossPartition)
+			assert.Equal(t, tt.input.PartitionKey, result.PartitionKey)
+		})
+	}
+}
@@ -0,0 +1,1068 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxPatchOperations is the maximum number of operations in a single patch request
+const maxPatchOperations = 10
+
+// PatchWhere creates a tool for patching all the items in a partition that match a filter.
+// See: https://learn.microsoft.com/en-us/azure/cosmos-db/partial-document-update
+func PatchWhere() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "patch_where",
+		Description: "Apply a set of patch operations (set, add, replace, remove, increment) to all items within a single logical partition of a container in Azure Cosmos DB or local emulator that match a filter. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. A partition key value is REQUIRED to keep the update scoped to one partition. The filter is the condition of a WHERE clause that refers to the item as c, e.g. c.status = 'pending'. Set transactional to true to patch all the matching items atomically in a transactional batch - otherwise items are patched one by one and failures are reported per item. For safety, at most maxItems items (default and max 100) are patched per call - if more items match, has_more is true. Patched items still match the filter unless the patch changes a field used in it, so make the filter exclude already patched items before calling the tool again. See: https://learn.microsoft.com/en-us/azure/cosmos-db/partial-document-update",
+	}
+}
+
+type PatchOperation struct {
+	Op    string `json:"op" jsonschema:"Patch operation: set, add, replace, remove or increment"`
+	Path  string `json:"path" jsonschema:"Path of the property to patch, e.g. /status or /address/city"`
+	Value any    `json:"value,omitempty" jsonschema:"Value for the operation. Not needed for remove. Must be an integer for increment"`
+}
+
+type PatchWhereToolInput struct {
+	ConnectionConfig
+	Database      string           `json:"database" jsonschema:"Azure Cosmos DB database name"`
+	Container     string           `json:"container" jsonschema:"Name of the container"`
+	PartitionKey  string           `json:"partitionKey" jsonschema:"Partition key value to scope the update to (required)"`
+	Filter        string           `json:"filter" jsonschema:"Condition of the WHERE clause used to select the items, e.g. c.status = 'pending'"`
+	Operations    []PatchOperation `json:"operations" jsonschema:"Patch operations to apply to each matching item (max 10)"`
+	Transactional bool             `json:"transactional,omitempty" jsonschema:"Patch all the matching items atomically in a single transactional batch. Fails if more than maxItems items match"`
+	MaxItems      int              `json:"maxItems,omitempty" jsonschema:"Maximum number of items to patch in this call (default and max 100)"`
+}
+
+type ItemOperationError struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+type PatchWhereToolResult struct {
+	Account      string               `json:"account"`
+	Database     string               `json:"database"`
+	Container    string               `json:"container"`
+	PartitionKey string               `json:"partition_key"`
+	Query        string               `json:"query" jsonschema:"The query that was used to select the items"`
+	ItemsMatched int                  `json:"items_matched"`
+	ItemsPatched int                  `json:"items_patched"`
+	Failures     []ItemOperationError `json:"failures,omitempty" jsonschema:"Items that could not be patched, with the reason"`
+	HasMore      bool                 `json:"has_more" jsonschema:"Whether more items match the filter than were patched because the maximum number of items was reached"`
+	Message      string               `json:"message"`
+}
+
+func PatchWhereToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input PatchWhereToolInput) (*mcp.CallToolResult, PatchWhereToolResult, error) {
+	if err := input.Validate(); err != nil {
+		return nil, PatchWhereToolResult{}, err
+	}
+
+	database := input.Database
+
+	if database == "" {
+		return nil, PatchWhereToolResult{}, newInputError("cosmos db database name missing")
+	}
+
+	container := input.Container
+
+	if container == "" {
+		return nil, PatchWhereToolResult{}, newInputError("container name missing")
+	}
+
+	if input.PartitionKey == "" {
+		return nil, PatchWhereToolResult{}, newInputError("partition key value missing")
+	}
+
+	maxItems := input.MaxItems
+	if maxItems == 0 {
+		maxItems = maxBatchOperations
+	}
+
+	if maxItems < 0 || maxItems > maxBatchOperations {
+		return nil, PatchWhereToolResult{}, inputErrorf("maxItems must be between 1 and %d", maxBatchOperations)
+	}
+
+	// one more id than the cap is selected, to know whether more items match
+	query, err := itemIDsQuery(input.Filter, maxItems+1)
+	if err != nil {
+		return nil, PatchWhereToolResult{}, err
+	}
+
+	operations, err := buildPatchOperations(input.Operations)
+	if err != nil {
+		return nil, PatchWhereToolResult{}, err
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, PatchWhereToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(database)
+	if err != nil {
+		return nil, PatchWhereToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(container)
+	if err != nil {
+		return nil, PatchWhereToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	partitionKey := azcosmos.NewPartitionKeyString(input.PartitionKey)
+
+	ids, err := matchingItemIDs(ctx, containerClient, query, partitionKey)
+	if err != nil {
+		return nil, PatchWhereToolResult{}, err
+	}
+
+	hasMore := len(ids) > maxItems
+
+	if input.Transactional && hasMore {
+		return nil, PatchWhereToolResult{}, inputErrorf("filter matched more than %d items, narrow the filter to patch them in a single transactional batch", maxItems)
+	}
+
+	if hasMore {
+		ids = ids[:maxItems]
+	}
+
+	patched := 0
+	failures := []ItemOperationError{}
+
+	if input.Transactional && len(ids) > 0 {
+		batch := containerClient.NewTransactionalBatch(partitionKey)
+		for _, id := range ids {
+			batch.PatchItem(id, operations, nil)
+		}
+
+		batchResponse, err := containerClient.ExecuteTransactionalBatch(ctx, batch, nil)
+		if err != nil {
+			return nil, PatchWhereToolResult{}, fmt.Errorf("error executing batch: %w", withDiagnostics(err))
+		}
+
+		if !batchResponse.Success {
+			for i, result := range batchResponse.OperationResults {
+				if result.StatusCode != 200 && result.StatusCode != 424 {
+					return nil, PatchWhereToolResult{}, fmt.Errorf("batch failed for item '%s' with status code %d, no items were patched", ids[i], result.StatusCode)
+				}
+			}
+			return nil, PatchWhereToolResult{}, errors.New("batch operation failed, no items were patched")
+		}
+
+		patched = len(ids)
+	} else {
+		for _, id := range ids {
+			if _, err := containerClient.PatchItem(ctx, partitionKey, id, operations, nil); err != nil {
+				failures = append(failures, ItemOperationError{ID: id, Error: withDiagnostics(err).Error()})
+				continue
+			}
+			patched++
+		}
+	}
+
+	message := fmt.Sprintf("Patched %d of %d matching items in container '%s' in database '%s'", patched, len(ids), container, database)
+	if hasMore {
+		message += fmt.Sprintf(". More items match the filter, only the first %d were patched", maxItems)
+	}
+
+	return nil, PatchWhereToolResult{
+		Account:      input.Account,
+		Database:     database,
+		Container:    container,
+		PartitionKey: input.PartitionKey,
+		Query:        query,
+		ItemsMatched: len(ids),
+		ItemsPatched: patched,
+		Failures:     failures,
+		HasMore:      hasMore,
+		Message:      message,
+	}, nil
+}
+
+// DeleteWhere creates a tool for deleting the items in a partition that match a filter.
+// The number of deletes per call is capped at the transactional batch limit.
+func DeleteWhere() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "delete_where",
+		Description: "Delete the items within a single logical partition of a container in Azure Cosmos DB or local emulator that match a filter. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. A partition key value is REQUIRED to keep the delete scoped to one partition. The filter is the condition of a WHERE clause that refers to the item as c, e.g. c.status = 'expired'. Matching items are deleted atomically in a transactional batch. For safety, at most maxItems items (default and max 100) are deleted per call - if more items match, call the tool again.",
+	}
+}
+
+type DeleteWhereToolInput struct {
+	ConnectionConfig
+	Database     string `json:"database" jsonschema:"Azure Cosmos DB database name"`
+	Container    string `json:"container" jsonschema:"Name of the container"`
+	PartitionKey string `json:"partitionKey" jsonschema:"Partition key value to scope the delete to (required)"`
+	Filter       string `json:"filter" jsonschema:"Condition of the WHERE clause used to select the items, e.g. c.status = 'expired'"`
+	MaxItems     int    `json:"maxItems,omitempty" jsonschema:"Maximum number of items to delete in this call (default and max 100)"`
+}
+
+type DeleteWhereToolResult struct {
+	Account      string               `json:"account"`
+	Database     string               `json:"database"`
+	Container    string               `json:"container"`
+	PartitionKey string               `json:"partition_key"`
+	Query        string               `json:"query" jsonschema:"The query that was used to select the items"`
+	ItemsDeleted int                  `json:"items_deleted"`
+	Failures     []ItemOperationError `json:"failures,omitempty" jsonschema:"Items that could not be deleted, with the reason"`
+	MayHaveMore  bool                 `json:"may_have_more" jsonschema:"Whether more items may match the filter because the maximum number of items was reached"`
+	Message      string               `json:"message"`
+}
+
+func DeleteWhereToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input DeleteWhereToolInput) (*mcp.CallToolResult, DeleteWhereToolResult, error) {
+	if err := input.Validate(); err != nil {
+		return nil, DeleteWhereToolResult{}, err
+	}
+
+	database := input.Database
+
+	if database == "" {
+		return nil, DeleteWhereToolResult{}, newInputError("cosmos db database name missing")
+	}
+
+	container := input.Container
+
+	if container == "" {
+		return nil, DeleteWhereToolResult{}, newInputError("container name missing")
+	}
+
+	if input.PartitionKey == "" {
+		return nil, DeleteWhereToolResult{}, newInputError("partition key value missing")
+	}
+
+	maxItems := input.MaxItems
+	if maxItems == 0 {
+		maxItems = maxBatchOperations
+	}
+
+	if maxItems < 0 || maxItems > maxBatchOperations {
+		return nil, DeleteWhereToolResult{}, inputErrorf("maxItems must be between 1 and %d", maxBatchOperations)
+	}
+
+	query, err := itemIDsQuery(input.Filter, maxItems)
+	if err != nil {
+		return nil, DeleteWhereToolResult{}, err
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, DeleteWhereToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(database)
+	if err != nil {
+		return nil, DeleteWhereToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(container)
+	if err != nil {
+		return nil, DeleteWhereToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	partitionKey := azcosmos.NewPartitionKeyString(input.PartitionKey)
+
+	ids, err := matchingItemIDs(ctx, containerClient, query, partitionKey)
+	if err != nil {
+		return nil, DeleteWhereToolResult{}, err
+	}
+
+	deleted := 0
+	failures := []ItemOperationError{}
+
+	if len(ids) > 0 {
+		batch := containerClient.NewTransactionalBatch(partitionKey)
+		for _, id := range ids {
+			batch.DeleteItem(id, nil)
+		}
+
+		batchResponse, err := containerClient.ExecuteTransactionalBatch(ctx, batch, nil)
+		if err != nil {
+			return nil, DeleteWhereToolResult{}, fmt.Errorf("error executing batch: %w", withDiagnostics(err))
+		}
+
+		if batchResponse.Success {
+			deleted = len(ids)
+		} else {
+			for i, result := range batchResponse.OperationResults {
+				message := fmt.Sprintf("failed with status code %d", result.StatusCode)
+				if result.StatusCode == 424 {
+					message = "not deleted because another item in the same batch failed"
+				}
+				failures = append(failures, ItemOperationError{ID: ids[i], Error: message})
+			}
+		}
+	}
+
+	mayHaveMore := len(ids) == maxItems
+
+	message := fmt.Sprintf("Deleted %d items from container '%s' in database '%s'", deleted, container, database)
+	if len(failures) > 0 {
+		message += fmt.Sprintf(", %d items failed", len(failures))
+	}
+	if mayHaveMore {
+		message += ". More items may match the filter, call the tool again to delete them"
+	}
+
+	return nil, DeleteWhereToolResult{
+		Account:      input.Account,
+		Database:     database,
+		Container:    container,
+		PartitionKey: input.PartitionKey,
+		Query:        query,
+		ItemsDeleted: deleted,
+		Failures:     failures,
+		MayHaveMore:  mayHaveMore,
+		Message:      message,
+	}, nil
+}
+
+// itemIDsQuery builds the query that selects the ids of the items matching the filter.
+// The filter may optionally start with the WHERE keyword. A positive top limits the number of ids.
+func itemIDsQuery(filter string, top int) (string, error) {
+	filter, err := whereCondition(filter)
+	if err != nil {
+		return "", err
+	}
+
+	if top > 0 {
+		return fmt.Sprintf("SELECT TOP %d VALUE c.id FROM c WHERE %s", top, filter), nil
+	}
+
+	return "SELECT VALUE c.id FROM c WHERE " + filter, nil
+}
+
+// whereCondition returns the condition of the filter without the optional WHERE keyword
+func whereCondition(filter string) (string, error) {
+	filter = strings.TrimSpace(filter)
+	if keyword, rest, _ := strings.Cut(filter, " "); strings.EqualFold(keyword, "where") {
+		filter = strings.TrimSpace(rest)
+	}
+
+	if filter == "" {
+		return "", newInputError("filter missing")
+	}
+
+	return filter, nil
+}
+
+// matchingItemIDs runs the query and returns the item ids
+func matchingItemIDs(ctx context.Context, containerClient *azcosmos.ContainerClient, query string, partitionKey azcosmos.PartitionKey) ([]string, error) {
+	results, err := collectQueryResults(ctx, containerClient, query, partitionKey, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		var id string
+		if err := json.Unmarshal(result, &id); err != nil {
+			return nil, fmt.Errorf("error parsing item id: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// buildPatchOperations validates the operations and converts them into SDK patch operations
+func buildPatchOperations(operations []PatchOperation) (azcosmos.PatchOperations, error) {
+	patch := azcosmos.PatchOperations{}
+
+	if len(operations) == 0 {
+		return patch, newInputError("patch operations missing")
+	}
+
+	if len(operations) > maxPatchOperations {
+		return patch, inputErrorf("too many patch operations: a maximum of %d is supported", maxPatchOperations)
+	}
+
+	for i, operation := range operations {
+		if !strings.HasPrefix(operation.Path, "/") {
+			return patch, inputErrorf("invalid path '%s' for operation %d: path must start with /", operation.Path, i)
+		}
+
+		switch strings.ToLower(operation.Op) {
+		case "set":
+			patch.AppendSet(operation.Path, operation.Value)
+		case "add":
+			patch.AppendAdd(operation.Path, operation.Value)
+		case "replace":
+			patch.AppendReplace(operation.Path, operation.Value)
+		case "remove":
+			patch.AppendRemove(operation.Path)
+		case "increment":
+			value, ok := operation.Value.(float64)
+			if !ok || value != math.Trunc(value) {
+				return patch, inputErrorf("invalid value for increment operation %d: value must be an integer", i)
+			}
+			patch.AppendIncrement(operation.Path, int64(value))
+		default:
+			return patch, inputErrorf("unsupported patch operation '%s': supported operations are set, add, replace, remove and increment", operation.Op)
+		}
+	}
+
+	return patch, nil
+}
+
+// SetFieldValue creates a tool for setting a field to a value (or to the value of another field) in the items of a partition that match a filter.
+// It is meant for data migrations, e.g. renaming a field.
+func SetFieldValue() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "set_field_value",
+		Description: "Set a field to a new value, or copy the value of another field into it, for the items within a single logical partition of a container in Azure Cosmos DB or local emulator that match a filter. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Use this for data migrations. A partition key value is REQUIRED. The filter is the condition of a WHERE clause that refers to the item as c, e.g. c.type = 'order'. Provide exactly one of value, or copyFrom with the path of the field to copy (only items that have the source field are changed). The value can't be null, to remove a field use patch_where with a remove operation. Set removeSource to true together with copyFrom to rename a field. The matching items are updated atomically in a transactional batch, at most maxItems items (default and max 100) per call - if has_more is true, call the tool again with a filter that excludes the items that were already changed.",
+	}
+}
+
+type SetFieldValueToolInput struct {
+	ConnectionConfig
+	Database     string `json:"database" jsonschema:"Azure Cosmos DB database name"`
+	Container    string `json:"container" jsonschema:"Name of the container"`
+	PartitionKey string `json:"partitionKey" jsonschema:"Partition key value to scope the update to (required)"`
+	Filter       string `json:"filter" jsonschema:"Condition of the WHERE clause used to select the items, e.g. c.type = 'order'"`
+	Field        string `json:"field" jsonschema:"Path of the field to set, e.g. /status or /address/city"`
+	Value        any    `json:"value,omitempty" jsonschema:"New value of the field, must not be null. Required unless copyFrom is provided"`
+	CopyFrom     string `json:"copyFrom,omitempty" jsonschema:"Path of the field to copy the value from, e.g. /state (optional)"`
+	RemoveSource bool   `json:"removeSource,omitempty" jsonschema:"Remove the copyFrom field after copying it, i.e. rename the field (optional)"`
+	MaxItems     int    `json:"maxItems,omitempty" jsonschema:"Maximum number of items to update in this call (default and max 100)"`
+}
+
+type SetFieldValueToolResult struct {
+	Account       string `json:"account"`
+	Database      string `json:"database"`
+	Container     string `json:"container"`
+	PartitionKey  string `json:"partition_key"`
+	Query         string `json:"query" jsonschema:"The query that was used to select the items"`
+	ItemsModified int    `json:"items_modified"`
+	HasMore       bool   `json:"has_more" jsonschema:"Whether more items match the filter than were updated because the maximum number of items was reached"`
+	Message       string `json:"message"`
+}
+
+func SetFieldValueToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input SetFieldValueToolInput) (*mcp.CallToolResult, SetFieldValueToolResult, error) {
+	if err := input.Validate(); err != nil {
+		return nil, SetFieldValueToolResult{}, err
+	}
+
+	database := input.Database
+
+	if database == "" {
+		return nil, SetFieldValueToolResult{}, newInputError("cosmos db database name missing")
+	}
+
+	container := input.Container
+
+	if container == "" {
+		return nil, SetFieldValueToolResult{}, newInputError("container name missing")
+	}
+
+	if input.PartitionKey == "" {
+		return nil, SetFieldValueToolResult{}, newInputError("partition key value missing")
+	}
+
+	if !strings.HasPrefix(input.Field, "/") {
+		return nil, SetFieldValueToolResult{}, inputErrorf("invalid field '%s': path must start with /", input.Field)
+	}
+
+	// a missing value would set the field to null on every matching item
+	if (input.CopyFrom != "") == (input.Value != nil) {
+		return nil, SetFieldValueToolResult{}, newInputError("provide either value or copyFrom, exactly one of them is required (value can't be null)")
+	}
+
+	if input.RemoveSource && input.CopyFrom == "" {
+		return nil, SetFieldValueToolResult{}, newInputError("removeSource can only be used with copyFrom")
+	}
+
+	if input.CopyFrom != "" && input.CopyFrom == input.Field {
+		return nil, SetFieldValueToolResult{}, newInputError("copyFrom must be different from field")
+	}
+
+	maxItems := input.MaxItems
+	if maxItems == 0 {
+		maxItems = maxBatchOperations
+	}
+
+	if maxItems < 0 || maxItems > maxBatchOperations {
+		return nil, SetFieldValueToolResult{}, inputErrorf("maxItems must be between 1 and %d", maxBatchOperations)
+	}
+
+	// one more item than the cap is selected, to know whether more items match
+	query, err := fieldValueQuery(input.Filter, input.CopyFrom, maxItems+1)
+	if err != nil {
+		return nil, SetFieldValueToolResult{}, err
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, SetFieldValueToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(database)
+	if err != nil {
+		return nil, SetFieldValueToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(container)
+	if err != nil {
+		return nil, SetFieldValueToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	partitionKey := azcosmos.NewPartitionKeyString(input.PartitionKey)
+
+	results, err := collectQueryResults(ctx, containerClient, query, partitionKey, nil)
+	if err != nil {
+		return nil, SetFieldValueToolResult{}, err
+	}
+
+	items, err := parseFieldValues(results, input.CopyFrom == "", input.Value)
+	if err != nil {
+		return nil, SetFieldValueToolResult{}, err
+	}
+
+	hasMore := len(items) > maxItems
+	if hasMore {
+		items = items[:maxItems]
+	}
+
+	if len(items) > 0 {
+		batch := containerClient.NewTransactionalBatch(partitionKey)
+		for _, item := range items {
+			patch := azcosmos.PatchOperations{}
+			patch.AppendSet(input.Field, item.Value)
+			if input.RemoveSource {
+				patch.AppendRemove(input.CopyFrom)
+			}
+			batch.PatchItem(item.ID, patch, nil)
+		}
+
+		batchResponse, err := containerClient.ExecuteTransactionalBatch(ctx, batch, nil)
+		if err != nil {
+			return nil, SetFieldValueToolResult{}, fmt.Errorf("error executing batch: %w", withDiagnostics(err))
+		}
+
+		if !batchResponse.Success {
+			for i, result := range batchResponse.OperationResults {
+				if result.StatusCode != 200 && result.StatusCode != 424 {
+					return nil, SetFieldValueToolResult{}, fmt.Errorf("batch failed for item '%s' with status code %d, no items were modified", items[i].ID, result.StatusCode)
+				}
+			}
+			return nil, SetFieldValueToolResult{}, errors.New("batch operation failed, no items were modified")
+		}
+	}
+
+	message := fmt.Sprintf("Modified %d items in container '%s' in database '%s'", len(items), container, database)
+	if hasMore {
+		message += fmt.Sprintf(". More items match the filter, only the first %d were modified", maxItems)
+	}
+
+	return nil, SetFieldValueToolResult{
+		Account:       input.Account,
+		Database:      database,
+		Container:     container,
+		PartitionKey:  input.PartitionKey,
+		Query:         query,
+		ItemsModified: len(items),
+		HasMore:       hasMore,
+		Message:       message,
+	}, nil
+}
+
+// fieldValueQuery builds the query that selects the items matching the filter. If source is set, the value of the source field
+// is selected as well (as v), and only the items that have the source field are selected.
+func fieldValueQuery(filter, source string, top int) (string, error) {
+	if source == "" {
+		return itemIDsQuery(filter, top)
+	}
+
+	filter, err := whereCondition(filter)
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(source, "/") {
+		return "", inputErrorf("invalid copyFrom '%s': path must start with /", source)
+	}
+
+	reference, err := fieldReference(source)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("SELECT TOP %d c.id, %s AS v FROM c WHERE (%s) AND IS_DEFINED(%s)", top, reference, filter, reference), nil
+}
+
+// fieldValue is the new value of the field for an item
+type fieldValue struct {
+	ID    string
+	Value any
+}
+
+// parseFieldValues converts the results of the fieldValueQuery into the new field values.
+// If idsOnly is set, the results are item ids and every item gets the same value.
+func parseFieldValues(results [][]byte, idsOnly bool, value any) ([]fieldValue, error) {
+	values := make([]fieldValue, 0, len(results))
+
+	for _, result := range results {
+		if idsOnly {
+			var id string
+			if err := json.Unmarshal(result, &id); err != nil {
+				return nil, fmt.Errorf("error parsing item id: %v", err)
+			}
+			values = append(values, fieldValue{ID: id, Value: value})
+			continue
+		}
+
+		var row struct {
+			ID    string `json:"id"`
+			Value any    `json:"v"`
+		}
+		if err := json.Unmarshal(result, &row); err != nil {
+			return nil, fmt.Errorf("error parsing query result: %v", err)
+		}
+		values = append(values, fieldValue{ID: row.ID, Value: row.Value})
+	}
+
+	return values, nil
+}
+
+// TruncateContainer creates a tool for deleting all the items in a container, while keeping the container and its configuration
+func TruncateContainer() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "truncate_container",
+		Description: "Delete ALL items from a container in Azure Cosmos DB or local emulator, while keeping the container and its configuration (partition key, indexing policy, throughput). Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. This cannot be undone - confirm must be set to true, ask the user for explicit confirmation before calling this tool. Items are deleted page by page in transactional batches grouped by partition key value. At most maxItems items (default 1000, max 10000) are deleted per call - if has_more is true, call the tool again to continue. Containers with hierarchical partition keys are not supported.",
+	}
+}
+
+// defaultTruncateMaxItems is the number of items truncate_container deletes per call when maxItems is not specified
+const defaultTruncateMaxItems = 1000
+
+// maxTruncateMaxItems is the upper bound for the maxItems argument of truncate_container
+const maxTruncateMaxItems = 10000
+
+type TruncateContainerToolInput struct {
+	ConnectionConfig
+	Database  string `json:"database" jsonschema:"Azure Cosmos DB database name"`
+	Container string `json:"container" jsonschema:"Name of the container to delete all items from"`
+	Confirm   bool   `json:"confirm" jsonschema:"Must be set to true to confirm that all items in the container should be deleted"`
+	MaxItems  int    `json:"maxItems,omitempty" jsonschema:"Maximum number of items to delete in this call (default 1000, max 10000)"`
+}
+
+type TruncateContainerToolResult struct {
+	Account      string               `json:"account"`
+	Database     string               `json:"database"`
+	Container    string               `json:"container"`
+	ItemsDeleted int                  `json:"items_deleted"`
+	Failures     []ItemOperationError `json:"failures,omitempty" jsonschema:"Items that could not be deleted, with the reason"`
+	HasMore      bool                 `json:"has_more" jsonschema:"Whether the container may still have items because the maximum number of items was reached, call the tool again to continue"`
+	Message      string               `json:"message"`
+}
+
+// itemKey identifies an item by id and partition key value
+type itemKey struct {
+	ID           string          `json:"id"`
+	PartitionKey json.RawMessage `json:"pk"`
+}
+
+func TruncateContainerToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input TruncateContainerToolInput) (*mcp.CallToolResult, TruncateContainerToolResult, error) {
+	if err := input.Validate(); err != nil {
+		return nil, TruncateContainerToolResult{}, err
+	}
+
+	database := input.Database
+
+	if database == "" {
+		return nil, TruncateContainerToolResult{}, newInputError("cosmos db database name missing")
+	}
+
+	container := input.Container
+
+	if container == "" {
+		return nil, TruncateContainerToolResult{}, newInputError("container name missing")
+	}
+
+	if !input.Confirm {
+		return nil, TruncateContainerToolResult{}, newInputError("confirm must be set to true to delete all items in the container")
+	}
+
+	maxItems := input.MaxItems
+	if maxItems == 0 {
+		maxItems = defaultTruncateMaxItems
+	}
+
+	if maxItems < 0 || maxItems > maxTruncateMaxItems {
+		return nil, TruncateContainerToolResult{}, inputErrorf("maxItems must be between 1 and %d", maxTruncateMaxItems)
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, TruncateContainerToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(database)
+	if err != nil {
+		return nil, TruncateContainerToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(container)
+	if err != nil {
+		return nil, TruncateContainerToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	containerResponse, err := containerClient.Read(ctx, nil)
+	if err != nil {
+		return nil, TruncateContainerToolResult{}, fmt.Errorf("error reading container: %w", withDiagnostics(err))
+	}
+
+	paths := containerResponse.ContainerProperties.PartitionKeyDefinition.Paths
+	if len(paths) != 1 {
+		return nil, TruncateContainerToolResult{}, newInputError("containers with hierarchical partition keys are not supported")
+	}
+
+	query, err := itemKeysQuery(paths[0])
+	if err != nil {
+		return nil, TruncateContainerToolResult{}, err
+	}
+
+	// items are deleted as each page arrives, so that only one page of keys is held in memory.
+	// deleted items do not come back in later calls, so each call starts a new query
+	queryPager := containerClient.NewQueryItemsPager(query, azcosmos.PartitionKey{}, &azcosmos.QueryOptions{PageSizeHint: int32(min(maxItems, maxBatchOperations))})
+
+	deleted := 0
+	failures := []ItemOperationError{}
+	processed := 0
+	hasMore := false
+
+	for queryPager.More() {
+		if processed == maxItems {
+			hasMore = true
+			break
+		}
+
+		queryResponse, err := nextPageWithRetry(ctx, queryPager)
+		if err != nil {
+			if processed == 0 {
+				return nil, TruncateContainerToolResult{}, fmt.Errorf("query page error: %w", withDiagnostics(err))
+			}
+			// keep the progress made so far, the next call picks up the remaining items
+			failures = append(failures, ItemOperationError{Error: fmt.Sprintf("query page error: %v", withDiagnostics(err))})
+			hasMore = true
+			break
+		}
+
+		keys := queryResponse.Items
+		if processed+len(keys) > maxItems {
+			keys = keys[:maxItems-processed]
+			hasMore = true
+		}
+
+		pageDeleted, pageFailures := deleteItems(ctx, containerClient, keys)
+		deleted += pageDeleted
+		failures = append(failures, pageFailures...)
+		processed += len(keys)
+
+		if hasMore {
+			break
+		}
+	}
+
+	message := fmt.Sprintf("Deleted %d items from container '%s' in database '%s'", deleted, container, database)
+	if len(failures) > 0 {
+		message += fmt.Sprintf(", %d items failed", len(failures))
+	}
+	if hasMore {
+		message += ". The container may still have items, call the tool again to continue"
+	}
+
+	return nil, TruncateContainerToolResult{
+		Account:      input.Account,
+		Database:     database,
+		Container:    container,
+		ItemsDeleted: deleted,
+		Failures:     failures,
+		HasMore:      hasMore,
+		Message:      message,
+	}, nil
+}
+
+// itemKeysQuery builds a query that returns the id and partition key value (as pk) of every item.
+// The partition key path segments are quoted, so any property name can be used.
+func itemKeysQuery(partitionKeyPath string) (string, error) {
+	segments := strings.Split(strings.Trim(partitionKeyPath, "/"), "/")
+
+	reference := "c"
+	for _, segment := range segments {
+		if segment == "" {
+			return "", fmt.Errorf("invalid partition key path '%s'", partitionKeyPath)
+		}
+		quoted, err := json.Marshal(segment)
+		if err != nil {
+			return "", err
+		}
+		reference += "[" + string(quoted) + "]"
+	}
+
+	return fmt.Sprintf(`SELECT VALUE {"id": c.id, "pk": %s} FROM c`, reference), nil
+}
+
+// deleteItems deletes the items identified by the query results (see itemKeysQuery) in transactional batches grouped by partition key value
+func deleteItems(ctx context.Context, containerClient *azcosmos.ContainerClient, results [][]byte) (int, []ItemOperationError) {
+	type deleteGroup struct {
+		partitionKey azcosmos.PartitionKey
+		ids          []string
+	}
+
+	groups := []*deleteGroup{}
+	groupsByKey := map[string]*deleteGroup{}
+	failures := []ItemOperationError{}
+
+	for _, result := range results {
+		var key itemKey
+		if err := json.Unmarshal(result, &key); err != nil {
+			failures = append(failures, ItemOperationError{Error: fmt.Sprintf("error parsing item key: %v", err)})
+			continue
+		}
+
+		// the pk property is left out of the result when the item has no partition key value
+		if key.PartitionKey == nil {
+			failures = append(failures, ItemOperationError{ID: key.ID, Error: "item has no partition key value"})
+			continue
+		}
+
+		var value any
+		if err := json.Unmarshal(key.PartitionKey, &value); err != nil {
+			failures = append(failures, ItemOperationError{ID: key.ID, Error: fmt.Sprintf("error parsing partition key value: %v", err)})
+			continue
+		}
+
+		partitionKey, err := partitionKeyFromValue(value)
+		if err != nil {
+			failures = append(failures, ItemOperationError{ID: key.ID, Error: err.Error()})
+			continue
+		}
+
+		// the JSON encoding of the value is used to group items, so that "1" and 1 are different partitions
+		groupKey := string(key.PartitionKey)
+
+		group, ok := groupsByKey[groupKey]
+		if !ok {
+			group = &deleteGroup{partitionKey: partitionKey}
+			groupsByKey[groupKey] = group
+			groups = append(groups, group)
+		}
+		group.ids = append(group.ids, key.ID)
+	}
+
+	deleted := 0
+
+	for _, group := range groups {
+		groupDeleted, groupFailures := deletePartitionItems(ctx, containerClient, group.partitionKey, group.ids)
+		deleted += groupDeleted
+		failures = append(failures, groupFailures...)
+	}
+
+	return deleted, failures
+}
+
+// deletePartitionItems deletes the items of a partition in transactional batches of up to maxBatchOperations items
+func deletePartitionItems(ctx context.Context, containerClient *azcosmos.ContainerClient, partitionKey azcosmos.PartitionKey, ids []string) (int, []ItemOperationError) {
+	deleted := 0
+	failures := []ItemOperationError{}
+
+	for start := 0; start < len(ids); start += maxBatchOperations {
+		batchIDs := ids[start:min(start+maxBatchOperations, len(ids))]
+
+		batch := containerClient.NewTransactionalBatch(partitionKey)
+		for _, id := range batchIDs {
+			batch.DeleteItem(id, nil)
+		}
+
+		batchResponse, err := containerClient.ExecuteTransactionalBatch(ctx, batch, nil)
+		if err != nil {
+			for _, id := range batchIDs {
+				failures = append(failures, ItemOperationError{ID: id, Error: fmt.Sprintf("error executing batch: %v", withDiagnostics(err))})
+			}
+			continue
+		}
+
+		if !batchResponse.Success {
+			for i, result := range batchResponse.OperationResults {
+				message := fmt.Sprintf("failed with status code %d", result.StatusCode)
+				if result.StatusCode == 424 {
+					message = "not deleted because another item in the same batch failed"
+				}
+				failures = append(failures, ItemOperationError{ID: batchIDs[i], Error: message})
+			}
+			continue
+		}
+
+		deleted += len(batchIDs)
+	}
+
+	return deleted, failures
+}
+
+// PurgeOlderThan creates a tool for deleting the items of a partition that were last modified before a cutoff,
+// for containers where TTL was not configured
+func PurgeOlderThan() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "purge_older_than",
+		Description: "Delete the items within a single logical partition of a container in Azure Cosmos DB or local emulator that were last modified (_ts) before a cutoff, e.g. for manual retention where TTL was not configured on the container. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. A partition key value is REQUIRED to keep the delete scoped to one partition. The cutoff (olderThan) is an RFC 3339 timestamp in the past, e.g. 2024-01-01T00:00:00Z. Set dryRun to true to list the ids of the items that would be deleted without deleting them. Deleting cannot be undone - confirm must be set to true, ask the user for explicit confirmation before calling this tool without dryRun. Items are deleted in transactional batches of 100. At most maxItems items (default 1000, max 10000) are deleted per call - if has_more is true, call the tool again to continue. To expire items automatically instead, enable TTL on the container.",
+	}
+}
+
+type PurgeOlderThanToolInput struct {
+	ConnectionConfig
+	Database     string `json:"database" jsonschema:"Azure Cosmos DB database name"`
+	Container    string `json:"container" jsonschema:"Name of the container"`
+	PartitionKey string `json:"partitionKey" jsonschema:"Partition key value to scope the delete to (required)"`
+	OlderThan    string `json:"olderThan" jsonschema:"Items last modified before this RFC 3339 timestamp are deleted, e.g. 2024-01-01T00:00:00Z"`
+	Confirm      bool   `json:"confirm,omitempty" jsonschema:"Must be set to true to delete the items, not needed with dryRun"`
+	DryRun       bool   `json:"dryRun,omitempty" jsonschema:"List the ids of the items that would be deleted without deleting them (optional, default false)"`
+	MaxItems     int    `json:"maxItems,omitempty" jsonschema:"Maximum number of items to delete in this call (default 1000, max 10000)"`
+}
+
+type PurgeOlderThanToolResult struct {
+	Account      string               `json:"account"`
+	Database     string               `json:"database"`
+	Container    string               `json:"container"`
+	PartitionKey string               `json:"partition_key"`
+	Cutoff       int64                `json:"cutoff" jsonschema:"The cutoff as a _ts value (seconds since the Unix epoch)"`
+	DryRun       bool                 `json:"dry_run"`
+	ItemIDs      []string             `json:"item_ids,omitempty" jsonschema:"Ids of the items that would be deleted, only set with dryRun"`
+	ItemsMatched int                  `json:"items_matched" jsonschema:"Number of items older than the cutoff found in this call"`
+	ItemsDeleted int                  `json:"items_deleted"`
+	Failures     []ItemOperationError `json:"failures,omitempty" jsonschema:"Items that could not be deleted, with the reason"`
+	HasMore      bool                 `json:"has_more" jsonschema:"Whether more items may be older than the cutoff because the maximum number of items was reached"`
+	Message      string               `json:"message"`
+}
+
+func PurgeOlderThanToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input PurgeOlderThanToolInput) (*mcp.CallToolResult, PurgeOlderThanToolResult, error) {
+	if err := input.Validate(); err != nil {
+		return nil, PurgeOlderThanToolResult{}, err
+	}
+
+	database := input.Database
+
+	if database == "" {
+		return nil, PurgeOlderThanToolResult{}, newInputError("cosmos db database name missing")
+	}
+
+	container := input.Container
+
+	if container == "" {
+		return nil, PurgeOlderThanToolResult{}, newInputError("container name missing")
+	}
+
+	if input.PartitionKey == "" {
+		return nil, PurgeOlderThanToolResult{}, newInputError("partition key value missing")
+	}
+
+	cutoff, err := parsePurgeCutoff(input.OlderThan, time.Now())
+	if err != nil {
+		return nil, PurgeOlderThanToolResult{}, err
+	}
+
+	if !input.Confirm && !input.DryRun {
+		return nil, PurgeOlderThanToolResult{}, newInputError("confirm must be set to true to delete the items, or set dryRun to true to list them")
+	}
+
+	maxItems := input.MaxItems
+	if maxItems == 0 {
+		maxItems = defaultTruncateMaxItems
+	}
+
+	if maxItems < 0 || maxItems > maxTruncateMaxItems {
+		return nil, PurgeOlderThanToolResult{}, inputErrorf("maxItems must be between 1 and %d", maxTruncateMaxItems)
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, PurgeOlderThanToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(database)
+	if err != nil {
+		return nil, PurgeOlderThanToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(container)
+	if err != nil {
+		return nil, PurgeOlderThanToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	partitionKey := azcosmos.NewPartitionKeyString(input.PartitionKey)
+
+	query := fmt.Sprintf("SELECT TOP %d VALUE c.id FROM c WHERE c._ts < @cutoff", maxItems)
+	results, err := collectQueryResults(ctx, containerClient, query, partitionKey, &azcosmos.QueryOptions{
+		QueryParameters: []azcosmos.QueryParameter{{Name: "@cutoff", Value: cutoff}},
+	})
+	if err != nil {
+		return nil, PurgeOlderThanToolResult{}, err
+	}
+
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		var id string
+		if err := json.Unmarshal(result, &id); err != nil {
+			return nil, PurgeOlderThanToolResult{}, fmt.Errorf("error parsing item id: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	result := PurgeOlderThanToolResult{
+		Account:      input.Account,
+		Database:     database,
+		Container:    container,
+		PartitionKey: input.PartitionKey,
+		Cutoff:       cutoff,
+		DryRun:       input.DryRun,
+		ItemsMatched: len(ids),
+		Failures:     []ItemOperationError{},
+		HasMore:      len(ids) == maxItems,
+	}
+
+	if input.DryRun {
+		result.ItemIDs = ids
+		result.Message = fmt.Sprintf("Found %d items older than %s in partition '%s', nothing was deleted", len(ids), input.OlderThan, input.PartitionKey)
+		if result.HasMore {
+			result.Message += ". More items may be older than the cutoff"
+		}
+		return nil, result, nil
+	}
+
+	result.ItemsDeleted, result.Failures = deletePartitionItems(ctx, containerClient, partitionKey, ids)
+
+	result.Message = fmt.Sprintf("Deleted %d items older than %s from partition '%s' of container '%s' in database '%s'", result.ItemsDeleted, input.OlderThan, input.PartitionKey, container, database)
+	if len(result.Failures) > 0 {
+		result.Message += fmt.Sprintf(", %d items failed", len(result.Failures))
+	}
+	if result.HasMore {
+		result.Message += ". More items may be older than the cutoff, call the tool again to continue"
+	}
+
+	return nil, result, nil
+}
+
+// parsePurgeCutoff converts the RFC 3339 cutoff into a _ts value. The cutoff must be in the past,
+// so that a mistyped year does not delete every item of the partition.
+func parsePurgeCutoff(olderThan string, now time.Time) (int64, error) {
+	if olderThan == "" {
+		return 0, newInputError("olderThan missing")
+	}
+
+	cutoff, err := time.Parse(time.RFC3339, olderThan)
+	if err != nil {
+		return 0, inputErrorf("invalid olderThan '%s', must be an RFC 3339 timestamp such as 2024-01-01T00:00:00Z", olderThan)
+	}
+
+	if cutoff.After(now) {
+		return 0, inputErrorf("invalid olderThan '%s', must be in the past", olderThan)
+	}
+
+	return cutoff.Unix(), nil
+}
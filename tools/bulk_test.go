@@ -0,0 +1,224 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for bulk operation helpers that do not need the emulator
+
+func TestItemIDsQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		filter      string
+		top         int
+		expected    string
+		expectError bool
+	}{
+		{name: "condition", filter: "c.status = 'pending'", expected: "SELECT VALUE c.id FROM c WHERE c.status = 'pending'"},
+		{name: "with where keyword", filter: "  where c.status = 'pending'", expected: "SELECT VALUE c.id FROM c WHERE c.status = 'pending'"},
+		{name: "with top", filter: "c.status = 'pending'", top: 10, expected: "SELECT TOP 10 VALUE c.id FROM c WHERE c.status = 'pending'"},
+		{name: "empty filter", filter: " ", expectError: true},
+		{name: "only where keyword", filter: "WHERE ", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := itemIDsQuery(tt.filter, tt.top)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, query)
+		})
+	}
+}
+
+func TestBuildPatchOperations(t *testing.T) {
+	tests := []struct {
+		name        string
+		operations  []PatchOperation
+		expected    string
+		expectError bool
+	}{
+		{
+			name: "all operations",
+			operations: []PatchOperation{
+				{Op: "set", Path: "/status", Value: "done"},
+				{Op: "Add", Path: "/tags/-", Value: "new"},
+				{Op: "replace", Path: "/name", Value: "x"},
+				{Op: "remove", Path: "/old"},
+				{Op: "increment", Path: "/visits", Value: float64(2)},
+			},
+			expected: `{"operations":[{"op":"set","path":"/status","value":"done"},{"op":"add","path":"/tags/-","value":"new"},{"op":"replace","path":"/name","value":"x"},{"op":"remove","path":"/old"},{"op":"incr","path":"/visits","value":2}]}`,
+		},
+		{name: "no operations", expectError: true},
+		{name: "unsupported operation", operations: []PatchOperation{{Op: "move", Path: "/a"}}, expectError: true},
+		{name: "path without slash", operations: []PatchOperation{{Op: "set", Path: "status", Value: "done"}}, expectError: true},
+		{name: "fractional increment", operations: []PatchOperation{{Op: "increment", Path: "/visits", Value: 1.5}}, expectError: true},
+		{name: "non numeric increment", operations: []PatchOperation{{Op: "increment", Path: "/visits", Value: "1"}}, expectError: true},
+		{name: "too many operations", operations: make([]PatchOperation, maxPatchOperations+1), expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := buildPatchOperations(tt.operations)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			encoded, err := json.Marshal(patch)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(encoded))
+		})
+	}
+}
+
+func TestItemKeysQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		expected    string
+		expectError bool
+	}{
+		{name: "top level", path: "/tenant", expected: `SELECT VALUE {"id": c.id, "pk": c["tenant"]} FROM c`},
+		{name: "nested", path: "/tenant/id", expected: `SELECT VALUE {"id": c.id, "pk": c["tenant"]["id"]} FROM c`},
+		{name: "quoted segment", path: `/te"nant`, expected: `SELECT VALUE {"id": c.id, "pk": c["te\"nant"]} FROM c`},
+		{name: "empty segment", path: "/tenant//id", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := itemKeysQuery(tt.path)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, query)
+		})
+	}
+}
+
+func TestFieldValueQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   string
+		source   string
+		expected string
+		errorMsg string
+	}{
+		{name: "set value", filter: "c.type = 'order'", expected: "SELECT TOP 101 VALUE c.id FROM c WHERE c.type = 'order'"},
+		{name: "copy field", filter: "WHERE c.type = 'order' OR c.type = 'invoice'", source: "/address/state", expected: "SELECT TOP 101 c.id, c.address.state AS v FROM c WHERE (c.type = 'order' OR c.type = 'invoice') AND IS_DEFINED(c.address.state)"},
+		{name: "missing filter", filter: " ", source: "/state", errorMsg: "filter missing"},
+		{name: "source without slash", filter: "true", source: "state", errorMsg: "path must start with /"},
+		{name: "invalid source", filter: "true", source: "/state) OR (true", errorMsg: "invalid field name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := fieldValueQuery(tt.filter, tt.source, 101)
+			if tt.errorMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, query)
+		})
+	}
+}
+
+func TestParseFieldValues(t *testing.T) {
+	values, err := parseFieldValues([][]byte{[]byte(`"1"`), []byte(`"2"`)}, true, "shipped")
+	require.NoError(t, err)
+	assert.Equal(t, []fieldValue{{ID: "1", Value: "shipped"}, {ID: "2", Value: "shipped"}}, values)
+
+	values, err = parseFieldValues([][]byte{[]byte(`{"id": "1", "v": "WA"}`), []byte(`{"id": "2", "v": null}`)}, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []fieldValue{{ID: "1", Value: "WA"}, {ID: "2", Value: nil}}, values)
+
+	_, err = parseFieldValues([][]byte{[]byte(`{"id": 1}`)}, true, nil)
+	require.Error(t, err)
+}
+
+func TestSetFieldValue_ValueOrCopyFrom(t *testing.T) {
+	input := SetFieldValueToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "account1"},
+		Database:         "db1",
+		Container:        "c1",
+		PartitionKey:     "tenant_a",
+		Filter:           "true",
+		Field:            "/status",
+	}
+
+	// without value or copyFrom the field would be set to null on every matching item
+	_, _, err := SetFieldValueToolHandler(context.Background(), nil, input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exactly one of them is required")
+
+	input.Value = "shipped"
+	input.CopyFrom = "/state"
+	_, _, err = SetFieldValueToolHandler(context.Background(), nil, input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exactly one of them is required")
+}
+
+func TestParsePurgeCutoff(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		olderThan      string
+		expected       int64
+		expectedErrMsg string
+	}{
+		{
+			name:      "utc timestamp",
+			olderThan: "2024-01-01T00:00:00Z",
+			expected:  1704067200,
+		},
+		{
+			name:      "timestamp with offset",
+			olderThan: "2024-01-01T02:00:00+02:00",
+			expected:  1704067200,
+		},
+		{
+			name:           "missing",
+			olderThan:      "",
+			expectedErrMsg: "olderThan missing",
+		},
+		{
+			name:           "not RFC 3339",
+			olderThan:      "2024-01-01",
+			expectedErrMsg: "must be an RFC 3339 timestamp",
+		},
+		{
+			name:           "in the future",
+			olderThan:      "2026-01-01T00:00:00Z",
+			expectedErrMsg: "must be in the past",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cutoff, err := parsePurgeCutoff(tt.olderThan, now)
+			if tt.expectedErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cutoff)
+		})
+	}
+}
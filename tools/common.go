@@ -2,12 +2,16 @@ package tools
 
 import (
 	"crypto/tls"
-	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
 )
 
@@ -17,43 +21,216 @@ const DefaultEmulatorEndpoint = "http://localhost:8081"
 // EmulatorKey is the well-known key for the Cosmos DB emulator
 const EmulatorKey = "C2y6yDjf5/R+ob0N8A7Cgv30VRDJIWEHLM+4QDU5DE2nQ9nDuVTqobD4b8mGGyPMbIZnqyMsEcaGQy67XIw/Jw=="
 
+// EmulatorEndpointEnvVar overrides DefaultEmulatorEndpoint when set
+const EmulatorEndpointEnvVar = "COSMOS_EMULATOR_ENDPOINT"
+
+// EmulatorKeyEnvVar overrides EmulatorKey when set
+const EmulatorKeyEnvVar = "COSMOS_EMULATOR_KEY"
+
+// ConnectionModeEnvVar selects how the client connects to Cosmos DB. Only gateway mode is supported by the Azure SDK for Go
+const ConnectionModeEnvVar = "COSMOS_CONNECTION_MODE"
+
+// UserAgentSuffixEnvVar is appended to the User-Agent of requests sent to Cosmos DB when set
+const UserAgentSuffixEnvVar = "COSMOS_USER_AGENT_SUFFIX"
+
+// InsecureTLSEnvVar disables TLS certificate verification for Azure Cosmos DB endpoints when set to true.
+// Only meant for non-production endpoints with self-signed certificates (the emulator always skips verification).
+const InsecureTLSEnvVar = "COSMOS_INSECURE_TLS"
+
+// DefaultAccountEnvVar is the account used when a tool is called without an account (single-account deployments)
+const DefaultAccountEnvVar = "COSMOS_DEFAULT_ACCOUNT"
+
+// HideAccountParameterEnvVar removes the account parameter from the tool input schemas when set to true.
+// Only takes effect together with DefaultAccountEnvVar, all tool calls then use the default account.
+const HideAccountParameterEnvVar = "COSMOS_HIDE_ACCOUNT_PARAMETER"
+
+// AllowedAccountsEnvVar restricts the accounts the tools can connect to (comma-separated) when set
+const AllowedAccountsEnvVar = "COSMOS_ALLOWED_ACCOUNTS"
+
+// EndpointSuffixEnvVar overrides DefaultEndpointSuffix for sovereign clouds, e.g. documents.azure.us for Azure Government
+const EndpointSuffixEnvVar = "COSMOS_ENDPOINT_SUFFIX"
+
+// DefaultEndpointSuffix is the DNS suffix of Azure Cosmos DB accounts in the Azure public cloud
+const DefaultEndpointSuffix = "documents.azure.com"
+
+// EndpointOverrideEnvVar is the literal endpoint used for Azure Cosmos DB accounts instead of deriving it from the account name,
+// e.g. for private endpoints behind custom DNS names
+const EndpointOverrideEnvVar = "COSMOS_ENDPOINT_OVERRIDE"
+
+// ServerVersion is the version of the MCP server
+const ServerVersion = "0.0.1"
+
+// userAgentApplicationID identifies traffic from this MCP server in the User-Agent (the SDK allows up to 24 characters)
+const userAgentApplicationID = "mcp-cosmosdb-go/" + ServerVersion
+
+// Supported values for ConnectionModeEnvVar
+const (
+	ConnectionModeGateway = "gateway"
+	ConnectionModeDirect  = "direct"
+)
+
 // ConnectionConfig holds connection settings for Azure Cosmos DB.
 // It can be embedded in tool input structs to provide consistent connection options.
 type ConnectionConfig struct {
 	Account          string `json:"account,omitempty" jsonschema:"Azure Cosmos DB account name (required when not using emulator)"`
 	UseEmulator      bool   `json:"useEmulator,omitempty" jsonschema:"Set to true to use local Cosmos DB emulator instead of Azure service"`
-	EmulatorEndpoint string `json:"emulatorEndpoint,omitempty" jsonschema:"Emulator endpoint URL (default: http://localhost:8081)"`
+	EmulatorEndpoint string `json:"emulatorEndpoint,omitempty" jsonschema:"Emulator endpoint URL (default: COSMOS_EMULATOR_ENDPOINT environment variable, or http://localhost:8081)"`
+	Diagnostics      bool   `json:"diagnostics,omitempty" jsonschema:"Set to true to also return diagnostics of the requests sent to Azure Cosmos DB: the latency of each request, the endpoints contacted and the number of retries (default false)"`
 }
 
 // Validate checks if the connection config is valid
 func (c ConnectionConfig) Validate() error {
-	if !c.UseEmulator && c.Account == "" {
-		return errors.New("account name is required when not using emulator")
+	if !c.UseEmulator && c.Account == "" && getDefaultAccount() == "" {
+		return newInputError("account name is required when not using emulator")
+	}
+	if !c.UseEmulator {
+		return nil
+	}
+	if c.EmulatorEndpoint != "" {
+		return validateEmulatorEndpoint(c.EmulatorEndpoint)
+	}
+	if err := validateEmulatorEndpoint(getDefaultEmulatorEndpoint()); err != nil {
+		return fmt.Errorf("%s: %w", EmulatorEndpointEnvVar, err)
 	}
 	return nil
 }
 
+// validateEmulatorEndpoint checks that the endpoint is an absolute http or https URL.
+// http is accepted since the emulator serves plain http by default (see DefaultEmulatorEndpoint).
+func validateEmulatorEndpoint(endpoint string) error {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return inputErrorf("invalid emulator endpoint '%s': must be a URL like %s", endpoint, DefaultEmulatorEndpoint)
+	}
+	return nil
+}
+
+// withDefaultAccount returns a copy of the config with the default account applied if no account was provided
+func (c ConnectionConfig) withDefaultAccount() ConnectionConfig {
+	if !c.UseEmulator && c.Account == "" {
+		c.Account = getDefaultAccount()
+	}
+	return c
+}
+
+// getDefaultAccount returns the default account from the environment, if any
+func getDefaultAccount() string {
+	return strings.TrimSpace(os.Getenv(DefaultAccountEnvVar))
+}
+
 // GetEndpoint returns the appropriate endpoint based on the connection mode
 func (c ConnectionConfig) GetEndpoint() string {
 	if c.UseEmulator {
 		if c.EmulatorEndpoint != "" {
 			return c.EmulatorEndpoint
 		}
-		return DefaultEmulatorEndpoint
+		return getDefaultEmulatorEndpoint()
+	}
+	// the override is the endpoint of the default account, see GetEndpointOverride
+	if override, err := GetEndpointOverride(); err == nil && override != "" && c.Account == getDefaultAccount() {
+		return override
 	}
-	return fmt.Sprintf("https://%s.documents.azure.com:443/", c.Account)
+	return accountEndpoint(c.Account)
+}
+
+// accountEndpoint returns the endpoint derived from the account name
+func accountEndpoint(account string) string {
+	return fmt.Sprintf("https://%s.%s:443/", account, getEndpointSuffix())
+}
+
+// GetEndpointOverride returns the endpoint configured in the environment for the default account, empty if not set.
+// The override restricts the server to the default account, so COSMOS_DEFAULT_ACCOUNT is required with it.
+func GetEndpointOverride() (string, error) {
+	value := strings.TrimSpace(os.Getenv(EndpointOverrideEnvVar))
+	if value == "" {
+		return "", nil
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return "", fmt.Errorf("invalid value '%s' for %s, must be a URL like https://myaccount.privatelink.documents.azure.com:443/", value, EndpointOverrideEnvVar)
+	}
+	if getDefaultAccount() == "" {
+		return "", fmt.Errorf("%s requires %s, the endpoint override is the endpoint of the default account", EndpointOverrideEnvVar, DefaultAccountEnvVar)
+	}
+	return value, nil
+}
+
+// GetEndpointSuffix returns the DNS suffix of account endpoints configured in the environment, falling back to DefaultEndpointSuffix
+func GetEndpointSuffix() (string, error) {
+	value := strings.TrimSpace(os.Getenv(EndpointSuffixEnvVar))
+	if value == "" {
+		return DefaultEndpointSuffix, nil
+	}
+
+	suffix := strings.TrimPrefix(value, ".")
+	if suffix == "" || strings.ContainsAny(suffix, ":/ ") {
+		return "", fmt.Errorf("invalid value '%s' for %s, must be a DNS suffix like documents.azure.us", value, EndpointSuffixEnvVar)
+	}
+	return suffix, nil
+}
+
+// getEndpointSuffix returns the DNS suffix of account endpoints. An invalid suffix is reported by GetEndpointSuffix
+// when the client is created, the default is used here so that GetEndpoint never fails.
+func getEndpointSuffix() string {
+	suffix, err := GetEndpointSuffix()
+	if err != nil {
+		return DefaultEndpointSuffix
+	}
+	return suffix
+}
+
+// getDefaultEmulatorEndpoint returns the emulator endpoint from the environment, falling back to DefaultEmulatorEndpoint
+func getDefaultEmulatorEndpoint() string {
+	if endpoint := os.Getenv(EmulatorEndpointEnvVar); endpoint != "" {
+		return endpoint
+	}
+	return DefaultEmulatorEndpoint
+}
+
+// getEmulatorKey returns the emulator key from the environment, falling back to the well-known EmulatorKey
+func getEmulatorKey() string {
+	if key := os.Getenv(EmulatorKeyEnvVar); key != "" {
+		return key
+	}
+	return EmulatorKey
 }
 
 // GetClientFunc is a function variable that can be overridden for testing
 // It takes a ConnectionConfig and returns a Cosmos DB client
 var GetClientFunc func(config ConnectionConfig) (*azcosmos.Client, error)
 
+// StaticClientRetriever returns a GetClientFunc that always returns the given client, regardless of the account.
+// It lets applications embedding the tools package supply a client they configured themselves (custom transport, credentials):
+//
+//	tools.GetClientFunc = tools.StaticClientRetriever(client)
+func StaticClientRetriever(client *azcosmos.Client) func(config ConnectionConfig) (*azcosmos.Client, error) {
+	return func(ConnectionConfig) (*azcosmos.Client, error) {
+		return client, nil
+	}
+}
+
 // GetClient returns a Cosmos DB client based on the connection config
 func (c ConnectionConfig) GetClient() (*azcosmos.Client, error) {
 	if err := c.Validate(); err != nil {
 		return nil, err
 	}
 
+	c = c.withDefaultAccount()
+
+	// The allow-list guards Azure accounts. The configured emulator is always permitted, but an emulator endpoint
+	// passed in the tool call could point to any host, so it is only accepted when the allow-list is not set.
+	if !c.UseEmulator && !isAccountAllowed(c.Account) {
+		return nil, fmt.Errorf("account not permitted: '%s' is not listed in %s", c.Account, AllowedAccountsEnvVar)
+	}
+	// requests (and tokens) for other accounts must not be sent to the endpoint of the default account
+	if !c.UseEmulator && os.Getenv(EndpointOverrideEnvVar) != "" && c.Account != getDefaultAccount() {
+		return nil, fmt.Errorf("account not permitted: only the default account '%s' can be used when %s is set", getDefaultAccount(), EndpointOverrideEnvVar)
+	}
+	if c.UseEmulator && !isEmulatorEndpointAllowed(c.EmulatorEndpoint) {
+		return nil, fmt.Errorf("emulator endpoint not permitted: only the configured emulator endpoint %s can be used when %s is set", getDefaultEmulatorEndpoint(), AllowedAccountsEnvVar)
+	}
+
 	// If a test override is set, use it
 	if GetClientFunc != nil {
 		return GetClientFunc(c)
@@ -62,19 +239,158 @@ func (c ConnectionConfig) GetClient() (*azcosmos.Client, error) {
 	if c.UseEmulator {
 		return c.getEmulatorClient()
 	}
+
+	// Per-account settings from the config file take precedence over the defaults
+	settings, found, err := loadAccountSettings(c.Account)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return c.getConfiguredClient(settings)
+	}
+
 	return c.getServiceClient()
 }
 
-// getServiceClient creates a client for Azure Cosmos DB service using DefaultAzureCredential
+// isEmulatorEndpointAllowed checks an emulator endpoint passed in a tool call.
+// When the allow-list is set, only the configured (or default) emulator endpoint is allowed.
+func isEmulatorEndpointAllowed(endpoint string) bool {
+	if endpoint == "" || strings.TrimSpace(os.Getenv(AllowedAccountsEnvVar)) == "" {
+		return true
+	}
+	return strings.TrimSuffix(endpoint, "/") == strings.TrimSuffix(getDefaultEmulatorEndpoint(), "/")
+}
+
+// isAccountAllowed checks the account against the allow-list in the environment.
+// All accounts are allowed when the allow-list is not set.
+func isAccountAllowed(account string) bool {
+	allowed := strings.TrimSpace(os.Getenv(AllowedAccountsEnvVar))
+	if allowed == "" {
+		return true
+	}
+
+	for _, name := range strings.Split(allowed, ",") {
+		if strings.EqualFold(strings.TrimSpace(name), account) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateConnectionMode checks the connection mode in the environment.
+// The Azure SDK for Go only supports gateway mode, so any other value is rejected instead of being silently ignored.
+func ValidateConnectionMode() error {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv(ConnectionModeEnvVar)))
+
+	switch mode {
+	case "", ConnectionModeGateway:
+		return nil
+	case ConnectionModeDirect:
+		return fmt.Errorf("%s: direct connection mode is not supported, the Azure SDK for Go only supports %s mode", ConnectionModeEnvVar, ConnectionModeGateway)
+	default:
+		return fmt.Errorf("%s: invalid connection mode '%s', the Azure SDK for Go only supports %s mode", ConnectionModeEnvVar, mode, ConnectionModeGateway)
+	}
+}
+
+// newClientOptions returns the base client options shared by service and emulator clients.
+// This is the single place where connection level settings (such as the connection mode) are applied.
+// Response compression needs no setting: the Go HTTP transport asks for gzip and decompresses responses transparently,
+// as long as no policy sets Accept-Encoding itself (which turns the transparent decompression off).
+func newClientOptions() (*azcosmos.ClientOptions, error) {
+	if err := ValidateConnectionMode(); err != nil {
+		return nil, err
+	}
+
+	if _, err := GetEndpointSuffix(); err != nil {
+		return nil, err
+	}
+
+	if _, err := GetEndpointOverride(); err != nil {
+		return nil, err
+	}
+
+	insecureTLS, err := getInsecureTLS()
+	if err != nil {
+		return nil, err
+	}
+
+	options := &azcosmos.ClientOptions{}
+	options.Telemetry.ApplicationID = userAgentApplicationID
+
+	if insecureTLS {
+		insecureTLSWarning.Do(func() {
+			log.Printf("WARNING: %s is enabled, TLS certificate verification is disabled for Azure Cosmos DB endpoints. Do not use this in production", InsecureTLSEnvVar)
+		})
+		options.Transport = newInsecureTransport()
+	}
+
+	if suffix := strings.TrimSpace(os.Getenv(UserAgentSuffixEnvVar)); suffix != "" {
+		options.PerCallPolicies = append(options.PerCallPolicies, &userAgentSuffixPolicy{suffix: suffix})
+	}
+
+	options.PerCallPolicies = append(options.PerCallPolicies, diagnosticsCallPolicy{})
+	options.PerRetryPolicies = append(options.PerRetryPolicies, diagnosticsPolicy{})
+
+	return options, nil
+}
+
+// insecureTLSWarning makes sure the warning about disabled TLS verification is logged only once
+var insecureTLSWarning sync.Once
+
+// getInsecureTLS reports whether TLS certificate verification is disabled in the environment
+func getInsecureTLS() (bool, error) {
+	value := strings.TrimSpace(os.Getenv(InsecureTLSEnvVar))
+	if value == "" {
+		return false, nil
+	}
+
+	insecure, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value '%s' for %s, must be true or false", value, InsecureTLSEnvVar)
+	}
+	return insecure, nil
+}
+
+// newInsecureTransport returns a transport that skips TLS certificate verification
+func newInsecureTransport() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+}
+
+// userAgentSuffixPolicy appends a user supplied suffix to the User-Agent header.
+// ApplicationID is limited to 24 characters by the SDK, hence the suffix is added separately.
+type userAgentSuffixPolicy struct {
+	suffix string
+}
+
+func (p *userAgentSuffixPolicy) Do(req *policy.Request) (*http.Response, error) {
+	header := req.Raw().Header
+	if userAgent := header.Get("User-Agent"); userAgent != "" {
+		header.Set("User-Agent", userAgent+" "+p.suffix)
+	} else {
+		header.Set("User-Agent", p.suffix)
+	}
+	return req.Next()
+}
+
+// getServiceClient creates a client for Azure Cosmos DB service using the shared DefaultAzureCredential
 func (c ConnectionConfig) getServiceClient() (*azcosmos.Client, error) {
 	endpoint := c.GetEndpoint()
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	options, err := newClientOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	cred, err := getDefaultCredential()
 	if err != nil {
-		return nil, fmt.Errorf("error creating credential: %v", err)
+		return nil, err
 	}
 
-	client, err := azcosmos.NewClient(endpoint, cred, nil)
+	client, err := azcosmos.NewClient(endpoint, withAccountScope(cred, endpoint, c.Account), options)
 	if err != nil {
 		return nil, fmt.Errorf("error creating Cosmos client: %v", err)
 	}
@@ -86,21 +402,16 @@ func (c ConnectionConfig) getServiceClient() (*azcosmos.Client, error) {
 func (c ConnectionConfig) getEmulatorClient() (*azcosmos.Client, error) {
 	endpoint := c.GetEndpoint()
 
-	// Create transport that skips TLS verification (emulator uses self-signed cert)
-	transport := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		},
+	options, err := newClientOptions()
+	if err != nil {
+		return nil, err
 	}
 
-	options := &azcosmos.ClientOptions{
-		ClientOptions: azcore.ClientOptions{
-			Transport: transport,
-		},
-	}
+	// Create transport that skips TLS verification (emulator uses self-signed cert)
+	options.Transport = newInsecureTransport()
 
-	// Create credential with the well-known emulator key
-	cred, err := azcosmos.NewKeyCredential(EmulatorKey)
+	// Create credential with the emulator key (well-known key unless overridden)
+	cred, err := azcosmos.NewKeyCredential(getEmulatorKey())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create emulator key credential: %w", err)
 	}
@@ -121,5 +432,5 @@ var GetCosmosClientFunc = GetCosmosDBClient
 // Deprecated: Use ConnectionConfig.GetClient() instead
 func GetCosmosDBClient(accountName string) (*azcosmos.Client, error) {
 	config := ConnectionConfig{Account: accountName, UseEmulator: false}
-	return config.getServiceClient()
+	return config.GetClient()
 }
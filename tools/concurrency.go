@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// MaxConcurrencyEnvVar limits how many tool calls are executed at the same time when set, excess calls wait for a free slot
+const MaxConcurrencyEnvVar = "COSMOS_MAX_CONCURRENCY"
+
+// ConcurrencyLimiter bounds the number of tool calls executed concurrently and keeps track of the in-flight and queued calls.
+// A limiter with no limit only keeps track of the calls.
+type ConcurrencyLimiter struct {
+	slots    chan struct{}
+	inFlight atomic.Int64
+	queued   atomic.Int64
+}
+
+// NewConcurrencyLimiter creates a limiter allowing up to max concurrent calls, a max of 0 means no limit
+func NewConcurrencyLimiter(max int) *ConcurrencyLimiter {
+	limiter := &ConcurrencyLimiter{}
+	if max > 0 {
+		limiter.slots = make(chan struct{}, max)
+	}
+	return limiter
+}
+
+// Acquire waits for a free slot. It returns an error if the context is cancelled while waiting, in which case Release must not be called.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context) error {
+	if l.slots != nil {
+		l.queued.Add(1)
+		defer l.queued.Add(-1)
+
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	l.inFlight.Add(1)
+	return nil
+}
+
+// Release frees the slot taken by a successful Acquire
+func (l *ConcurrencyLimiter) Release() {
+	l.inFlight.Add(-1)
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// Max returns the maximum number of concurrent calls, 0 means no limit
+func (l *ConcurrencyLimiter) Max() int {
+	return cap(l.slots)
+}
+
+// InFlight returns the number of calls currently executing
+func (l *ConcurrencyLimiter) InFlight() int64 {
+	return l.inFlight.Load()
+}
+
+// Queued returns the number of calls waiting for a free slot
+func (l *ConcurrencyLimiter) Queued() int64 {
+	return l.queued.Load()
+}
+
+// GetMaxConcurrency returns the maximum number of concurrent tool calls configured in the environment, 0 means no limit
+func GetMaxConcurrency() (int, error) {
+	value := strings.TrimSpace(os.Getenv(MaxConcurrencyEnvVar))
+	if value == "" {
+		return 0, nil
+	}
+
+	max, err := strconv.Atoi(value)
+	if err != nil || max < 1 {
+		return 0, fmt.Errorf("invalid value '%s' for %s, must be a positive integer", value, MaxConcurrencyEnvVar)
+	}
+	return max, nil
+}
@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for the concurrency limiter that do not need the emulator
+
+func TestConcurrencyLimiter(t *testing.T) {
+	limiter := NewConcurrencyLimiter(1)
+	assert.Equal(t, 1, limiter.Max())
+
+	require.NoError(t, limiter.Acquire(context.Background()))
+	assert.Equal(t, int64(1), limiter.InFlight())
+
+	acquired := make(chan struct{})
+	go func() {
+		if limiter.Acquire(context.Background()) == nil {
+			close(acquired)
+		}
+	}()
+
+	// the second call waits until the first one releases its slot
+	require.Eventually(t, func() bool { return limiter.Queued() == 1 }, time.Second, 10*time.Millisecond)
+	select {
+	case <-acquired:
+		t.Fatal("second call acquired a slot while the limit was reached")
+	default:
+	}
+
+	limiter.Release()
+	<-acquired
+	assert.Equal(t, int64(1), limiter.InFlight())
+	assert.Equal(t, int64(0), limiter.Queued())
+
+	limiter.Release()
+	assert.Equal(t, int64(0), limiter.InFlight())
+}
+
+func TestConcurrencyLimiter_Cancelled(t *testing.T) {
+	limiter := NewConcurrencyLimiter(1)
+	require.NoError(t, limiter.Acquire(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := limiter.Acquire(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int64(1), limiter.InFlight())
+	assert.Equal(t, int64(0), limiter.Queued())
+}
+
+func TestConcurrencyLimiter_NoLimit(t *testing.T) {
+	limiter := NewConcurrencyLimiter(0)
+	assert.Equal(t, 0, limiter.Max())
+
+	for range 10 {
+		require.NoError(t, limiter.Acquire(context.Background()))
+	}
+	assert.Equal(t, int64(10), limiter.InFlight())
+
+	for range 10 {
+		limiter.Release()
+	}
+	assert.Equal(t, int64(0), limiter.InFlight())
+}
+
+func TestGetMaxConcurrency(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    int
+		expectError bool
+	}{
+		{name: "not set", value: "", expected: 0},
+		{name: "valid", value: "8", expected: 8},
+		{name: "zero", value: "0", expectError: true},
+		{name: "negative", value: "-1", expectError: true},
+		{name: "not a number", value: "many", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(MaxConcurrencyEnvVar, tt.value)
+
+			max, err := GetMaxConcurrency()
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, max)
+		})
+	}
+}
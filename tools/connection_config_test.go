@@ -1,8 +1,15 @@
 package tools
 
 import (
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -70,6 +77,58 @@ func TestConnectionConfig_Validate_EmulatorMode(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "valid emulator mode with http endpoint",
+			config: ConnectionConfig{
+				UseEmulator:      true,
+				EmulatorEndpoint: "http://localhost:8081",
+			},
+			expectError: false,
+		},
+		{
+			name: "emulator endpoint without scheme",
+			config: ConnectionConfig{
+				UseEmulator:      true,
+				EmulatorEndpoint: "localhost:8081",
+			},
+			expectError: true,
+			errorMsg:    "invalid emulator endpoint 'localhost:8081'",
+		},
+		{
+			name: "emulator endpoint with unsupported scheme",
+			config: ConnectionConfig{
+				UseEmulator:      true,
+				EmulatorEndpoint: "ftp://localhost:8081",
+			},
+			expectError: true,
+			errorMsg:    "invalid emulator endpoint",
+		},
+		{
+			name: "emulator endpoint without host",
+			config: ConnectionConfig{
+				UseEmulator:      true,
+				EmulatorEndpoint: "https://",
+			},
+			expectError: true,
+			errorMsg:    "invalid emulator endpoint",
+		},
+		{
+			name: "malformed emulator endpoint",
+			config: ConnectionConfig{
+				UseEmulator:      true,
+				EmulatorEndpoint: "https://local host:%zz",
+			},
+			expectError: true,
+			errorMsg:    "invalid emulator endpoint",
+		},
+		{
+			name: "emulator endpoint ignored when not using emulator",
+			config: ConnectionConfig{
+				Account:          "myaccount",
+				EmulatorEndpoint: "not a url",
+			},
+			expectError: false,
+		},
 		{
 			name: "emulator mode ignores account field",
 			config: ConnectionConfig{
@@ -93,6 +152,31 @@ func TestConnectionConfig_Validate_EmulatorMode(t *testing.T) {
 	}
 }
 
+func TestConnectionConfig_Validate_EmulatorEndpointEnv(t *testing.T) {
+	t.Run("valid env endpoint", func(t *testing.T) {
+		t.Setenv(EmulatorEndpointEnvVar, "https://localhost:9999")
+		require.NoError(t, ConnectionConfig{UseEmulator: true}.Validate())
+	})
+
+	t.Run("invalid env endpoint", func(t *testing.T) {
+		t.Setenv(EmulatorEndpointEnvVar, "localhost:9999")
+		err := ConnectionConfig{UseEmulator: true}.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), EmulatorEndpointEnvVar)
+		assert.Contains(t, err.Error(), "must be a URL like "+DefaultEmulatorEndpoint)
+	})
+
+	t.Run("explicit endpoint takes precedence over invalid env endpoint", func(t *testing.T) {
+		t.Setenv(EmulatorEndpointEnvVar, "localhost:9999")
+		require.NoError(t, ConnectionConfig{UseEmulator: true, EmulatorEndpoint: "https://localhost:9000"}.Validate())
+	})
+
+	t.Run("env endpoint ignored when not using emulator", func(t *testing.T) {
+		t.Setenv(EmulatorEndpointEnvVar, "localhost:9999")
+		require.NoError(t, ConnectionConfig{Account: "myaccount"}.Validate())
+	})
+}
+
 func TestConnectionConfig_GetEndpoint(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -132,6 +216,116 @@ func TestConnectionConfig_GetEndpoint(t *testing.T) {
 	}
 }
 
+func TestConnectionConfig_GetEndpoint_EndpointSuffix(t *testing.T) {
+	t.Setenv(EndpointSuffixEnvVar, "documents.azure.us")
+
+	assert.Equal(t, "https://myaccount.documents.azure.us:443/", ConnectionConfig{Account: "myaccount"}.GetEndpoint())
+	assert.Equal(t, DefaultEmulatorEndpoint, ConnectionConfig{UseEmulator: true}.GetEndpoint())
+}
+
+func TestConnectionConfig_GetEndpoint_EndpointOverride(t *testing.T) {
+	t.Setenv(DefaultAccountEnvVar, "myaccount")
+	t.Setenv(EndpointOverrideEnvVar, "https://cosmos.internal.contoso.com:443/")
+
+	assert.Equal(t, "https://cosmos.internal.contoso.com:443/", ConnectionConfig{Account: "myaccount"}.GetEndpoint())
+	assert.Equal(t, "https://other.documents.azure.com:443/", ConnectionConfig{Account: "other"}.GetEndpoint())
+	assert.Equal(t, DefaultEmulatorEndpoint, ConnectionConfig{UseEmulator: true}.GetEndpoint())
+}
+
+func TestConnectionConfig_GetClient_EndpointOverride(t *testing.T) {
+	t.Setenv(DefaultAccountEnvVar, "myaccount")
+	t.Setenv(EndpointOverrideEnvVar, "https://cosmos.internal.contoso.com:443/")
+
+	_, err := ConnectionConfig{Account: "other"}.GetClient()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "account not permitted")
+	assert.Contains(t, err.Error(), EndpointOverrideEnvVar)
+
+	_, err = ConnectionConfig{}.GetClient()
+	require.NoError(t, err)
+
+	// the emulator is not affected by the override
+	_, err = ConnectionConfig{UseEmulator: true}.GetClient()
+	require.NoError(t, err)
+}
+
+func TestGetEndpointOverride(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    string
+		expectError bool
+	}{
+		{name: "not set", value: "", expected: ""},
+		{name: "private endpoint", value: "https://myaccount.privatelink.documents.azure.com:443/", expected: "https://myaccount.privatelink.documents.azure.com:443/"},
+		{name: "http", value: "http://cosmos.internal.contoso.com", expectError: true},
+		{name: "host only", value: "cosmos.internal.contoso.com", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(DefaultAccountEnvVar, "myaccount")
+			t.Setenv(EndpointOverrideEnvVar, tt.value)
+
+			endpoint, err := GetEndpointOverride()
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), EndpointOverrideEnvVar)
+
+				_, err = newClientOptions()
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, endpoint)
+		})
+	}
+
+	t.Run("without default account", func(t *testing.T) {
+		t.Setenv(DefaultAccountEnvVar, "")
+		t.Setenv(EndpointOverrideEnvVar, "https://cosmos.internal.contoso.com:443/")
+
+		_, err := GetEndpointOverride()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), DefaultAccountEnvVar)
+	})
+}
+
+func TestGetEndpointSuffix(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    string
+		expectError bool
+	}{
+		{name: "not set", value: "", expected: DefaultEndpointSuffix},
+		{name: "azure government", value: "documents.azure.us", expected: "documents.azure.us"},
+		{name: "leading dot", value: " .documents.azure.cn ", expected: "documents.azure.cn"},
+		{name: "url", value: "https://documents.azure.us", expectError: true},
+		{name: "port", value: "documents.azure.us:443", expectError: true},
+		{name: "only a dot", value: ".", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EndpointSuffixEnvVar, tt.value)
+
+			suffix, err := GetEndpointSuffix()
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), EndpointSuffixEnvVar)
+
+				// client creation fails instead of connecting to the wrong endpoint
+				_, err = newClientOptions()
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, suffix)
+		})
+	}
+}
+
 func TestConnectionConfig_IsEmulatorMode(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -166,3 +360,321 @@ func TestConnectionConfig_IsEmulatorMode(t *testing.T) {
 		})
 	}
 }
+
+func TestConnectionConfig_GetEndpoint_EnvOverride(t *testing.T) {
+	t.Setenv(EmulatorEndpointEnvVar, "https://localhost:9999")
+
+	tests := []struct {
+		name             string
+		config           ConnectionConfig
+		expectedEndpoint string
+	}{
+		{
+			name: "emulator mode uses env endpoint",
+			config: ConnectionConfig{
+				UseEmulator: true,
+			},
+			expectedEndpoint: "https://localhost:9999",
+		},
+		{
+			name: "explicit emulator endpoint takes precedence over env",
+			config: ConnectionConfig{
+				UseEmulator:      true,
+				EmulatorEndpoint: "https://localhost:9000",
+			},
+			expectedEndpoint: "https://localhost:9000",
+		},
+		{
+			name: "service mode ignores env endpoint",
+			config: ConnectionConfig{
+				Account: "myaccount",
+			},
+			expectedEndpoint: "https://myaccount.documents.azure.com:443/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedEndpoint, tt.config.GetEndpoint())
+		})
+	}
+}
+
+func TestGetEmulatorKey(t *testing.T) {
+	t.Run("default key when env not set", func(t *testing.T) {
+		t.Setenv(EmulatorKeyEnvVar, "")
+		assert.Equal(t, EmulatorKey, getEmulatorKey())
+	})
+
+	t.Run("env key overrides default", func(t *testing.T) {
+		t.Setenv(EmulatorKeyEnvVar, "custom-key")
+		assert.Equal(t, "custom-key", getEmulatorKey())
+	})
+}
+
+func TestIsAccountAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		allowed  string
+		account  string
+		expected bool
+	}{
+		{name: "allow-list not set", allowed: "", account: "prod", expected: true},
+		{name: "account in list", allowed: "dev,test", account: "test", expected: true},
+		{name: "account in list with spaces and different case", allowed: " dev , Test ", account: "test", expected: true},
+		{name: "account not in list", allowed: "dev,test", account: "prod", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(AllowedAccountsEnvVar, tt.allowed)
+			assert.Equal(t, tt.expected, isAccountAllowed(tt.account))
+		})
+	}
+}
+
+func TestConnectionConfig_GetClient_AccountNotPermitted(t *testing.T) {
+	t.Setenv(AllowedAccountsEnvVar, "dev")
+
+	_, err := ConnectionConfig{Account: "prod"}.GetClient()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "account not permitted")
+
+	// the legacy client function is subject to the allow-list too
+	_, err = GetCosmosDBClient("prod")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "account not permitted")
+
+	// the configured emulator is not subject to the allow-list
+	_, err = ConnectionConfig{UseEmulator: true}.GetClient()
+	require.NoError(t, err)
+
+	_, err = ConnectionConfig{UseEmulator: true, EmulatorEndpoint: DefaultEmulatorEndpoint + "/"}.GetClient()
+	require.NoError(t, err)
+
+	// but an arbitrary emulator endpoint is
+	_, err = ConnectionConfig{UseEmulator: true, EmulatorEndpoint: "https://attacker.example.com"}.GetClient()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "emulator endpoint not permitted")
+}
+
+func TestIsEmulatorEndpointAllowed(t *testing.T) {
+	tests := []struct {
+		name             string
+		allowed          string
+		emulatorEndpoint string
+		endpoint         string
+		expected         bool
+	}{
+		{name: "allow-list not set", allowed: "", endpoint: "https://somewhere.example.com", expected: true},
+		{name: "no endpoint in the call", allowed: "dev", endpoint: "", expected: true},
+		{name: "default endpoint", allowed: "dev", endpoint: DefaultEmulatorEndpoint, expected: true},
+		{name: "configured endpoint", allowed: "dev", emulatorEndpoint: "https://localhost:9000", endpoint: "https://localhost:9000/", expected: true},
+		{name: "other endpoint", allowed: "dev", endpoint: "https://somewhere.example.com", expected: false},
+		{name: "default endpoint when another one is configured", allowed: "dev", emulatorEndpoint: "https://localhost:9000", endpoint: DefaultEmulatorEndpoint, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(AllowedAccountsEnvVar, tt.allowed)
+			t.Setenv(EmulatorEndpointEnvVar, tt.emulatorEndpoint)
+			assert.Equal(t, tt.expected, isEmulatorEndpointAllowed(tt.endpoint))
+		})
+	}
+}
+
+func TestStaticClientRetriever(t *testing.T) {
+	client, err := ConnectionConfig{UseEmulator: true}.getEmulatorClient()
+	require.NoError(t, err)
+
+	previous := GetClientFunc
+	GetClientFunc = StaticClientRetriever(client)
+	t.Cleanup(func() { GetClientFunc = previous })
+
+	for _, config := range []ConnectionConfig{{Account: "first"}, {Account: "second"}, {UseEmulator: true}} {
+		got, err := config.GetClient()
+		require.NoError(t, err)
+		assert.Same(t, client, got)
+	}
+
+	// the configuration is still validated before the client is returned
+	_, err = ConnectionConfig{}.GetClient()
+	require.Error(t, err)
+}
+
+func TestValidateConnectionMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		envValue    string
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:     "defaults to gateway when unset",
+			envValue: "",
+		},
+		{
+			name:     "gateway mode",
+			envValue: "Gateway",
+		},
+		{
+			name:        "direct mode is not supported",
+			envValue:    "direct",
+			expectError: true,
+			errorMsg:    "direct connection mode is not supported, the Azure SDK for Go only supports gateway mode",
+		},
+		{
+			name:        "invalid mode",
+			envValue:    "tcp",
+			expectError: true,
+			errorMsg:    "invalid connection mode 'tcp'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ConnectionModeEnvVar, tt.envValue)
+
+			err := ValidateConnectionMode()
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				assert.Contains(t, err.Error(), ConnectionModeEnvVar)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewClientOptions_UserAgent(t *testing.T) {
+	// captures the User-Agent sent by the pipeline instead of sending the request
+	var userAgent string
+	transport := transporterFunc(func(req *http.Request) (*http.Response, error) {
+		userAgent = req.Header.Get("User-Agent")
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody, Request: req}, nil
+	})
+
+	send := func(t *testing.T) string {
+		options, err := newClientOptions()
+		require.NoError(t, err)
+		options.Transport = transport
+
+		pipeline := runtime.NewPipeline("azcosmos", "v1.3.0", runtime.PipelineOptions{}, &options.ClientOptions)
+		req, err := runtime.NewRequest(context.Background(), http.MethodGet, "https://localhost")
+		require.NoError(t, err)
+		_, err = pipeline.Do(req)
+		require.NoError(t, err)
+		return userAgent
+	}
+
+	t.Run("application id without suffix", func(t *testing.T) {
+		t.Setenv(UserAgentSuffixEnvVar, "")
+		ua := send(t)
+		assert.True(t, strings.HasPrefix(ua, userAgentApplicationID+" "), ua)
+	})
+
+	t.Run("application id with suffix", func(t *testing.T) {
+		t.Setenv(UserAgentSuffixEnvVar, "my-team")
+		ua := send(t)
+		assert.True(t, strings.HasPrefix(ua, userAgentApplicationID+" "), ua)
+		assert.True(t, strings.HasSuffix(ua, " my-team"), ua)
+	})
+}
+
+type transporterFunc func(*http.Request) (*http.Response, error)
+
+func (f transporterFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNewClientOptions_InsecureTLS(t *testing.T) {
+	t.Run("verification enabled by default", func(t *testing.T) {
+		t.Setenv(InsecureTLSEnvVar, "")
+		options, err := newClientOptions()
+		require.NoError(t, err)
+		assert.Nil(t, options.Transport)
+	})
+
+	t.Run("verification disabled", func(t *testing.T) {
+		t.Setenv(InsecureTLSEnvVar, "true")
+		options, err := newClientOptions()
+		require.NoError(t, err)
+
+		client, ok := options.Transport.(*http.Client)
+		require.True(t, ok)
+		transport, ok := client.Transport.(*http.Transport)
+		require.True(t, ok)
+		assert.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Setenv(InsecureTLSEnvVar, "maybe")
+		_, err := newClientOptions()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), InsecureTLSEnvVar)
+	})
+}
+
+func TestNewClientOptions_Compression(t *testing.T) {
+	// responds with a gzip compressed body if the client accepts it, like a gateway that compresses responses
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			_, _ = w.Write([]byte("not compressed"))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write([]byte(`{"id":"1"}`))
+		_ = gz.Close()
+	}))
+	defer server.Close()
+
+	for _, insecureTLS := range []string{"", "true"} {
+		t.Run("insecure TLS "+insecureTLS, func(t *testing.T) {
+			t.Setenv(InsecureTLSEnvVar, insecureTLS)
+			options, err := newClientOptions()
+			require.NoError(t, err)
+
+			pipeline := runtime.NewPipeline("azcosmos", "v1.3.0", runtime.PipelineOptions{}, &options.ClientOptions)
+			req, err := runtime.NewRequest(context.Background(), http.MethodGet, server.URL)
+			require.NoError(t, err)
+			resp, err := pipeline.Do(req)
+			require.NoError(t, err)
+
+			body, err := runtime.Payload(resp)
+			require.NoError(t, err)
+			assert.Equal(t, `{"id":"1"}`, string(body))
+			assert.True(t, resp.Uncompressed)
+		})
+	}
+}
+
+func TestConnectionConfig_DefaultAccount(t *testing.T) {
+	t.Run("account required without default", func(t *testing.T) {
+		t.Setenv(DefaultAccountEnvVar, "")
+		require.Error(t, ConnectionConfig{}.Validate())
+	})
+
+	t.Run("default account used when account is not provided", func(t *testing.T) {
+		t.Setenv(DefaultAccountEnvVar, "myaccount")
+
+		config := ConnectionConfig{}
+		require.NoError(t, config.Validate())
+		assert.Equal(t, "https://myaccount.documents.azure.com:443/", config.withDefaultAccount().GetEndpoint())
+	})
+
+	t.Run("explicit account takes precedence", func(t *testing.T) {
+		t.Setenv(DefaultAccountEnvVar, "myaccount")
+		assert.Equal(t, "other", ConnectionConfig{Account: "other"}.withDefaultAccount().Account)
+	})
+
+	t.Run("default account is subject to the allow-list", func(t *testing.T) {
+		t.Setenv(DefaultAccountEnvVar, "myaccount")
+		t.Setenv(AllowedAccountsEnvVar, "dev")
+
+		_, err := ConnectionConfig{}.GetClient()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "'myaccount' is not listed")
+	})
+}
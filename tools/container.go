@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -39,7 +43,7 @@ func ListContainersToolHandler(ctx context.Context, _ *mcp.CallToolRequest, inpu
 	database := input.Database
 
 	if database == "" {
-		return nil, ListContainersToolResult{}, errors.New("cosmos db database name missing")
+		return nil, ListContainersToolResult{}, newInputError("cosmos db database name missing")
 	}
 
 	client, err := input.GetClient()
@@ -59,7 +63,7 @@ func ListContainersToolHandler(ctx context.Context, _ *mcp.CallToolRequest, inpu
 	for containerPager.More() {
 		containerResponse, err := containerPager.NextPage(ctx)
 		if err != nil {
-			return nil, ListContainersToolResult{}, err
+			return nil, ListContainersToolResult{}, withDiagnostics(err)
 		}
 
 		for _, container := range containerResponse.Containers {
@@ -75,11 +79,95 @@ func ListContainersToolHandler(ctx context.Context, _ *mcp.CallToolRequest, inpu
 
 }
 
+func ListContainersDetailed() *mcp.Tool {
+
+	return &mcp.Tool{
+		Name:        "list_containers_detailed",
+		Description: "List all containers in the specified Azure Cosmos DB database or local emulator along with the partition key path(s) and default TTL of each container. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Use this instead of calling read_container_metadata for every container when planning queries, e.g. to find the partition key to filter on.",
+	}
+}
+
+type ListContainersDetailedToolInput struct {
+	ConnectionConfig
+	Database string `json:"database" jsonschema:"Azure Cosmos DB database name"`
+}
+
+type ContainerDetails struct {
+	ID                string   `json:"id"`
+	PartitionKeyPaths []string `json:"partition_key_paths" jsonschema:"Partition key path(s) of the container, more than one for hierarchical partition keys"`
+	PartitionKeyKind  string   `json:"partition_key_kind,omitempty" jsonschema:"Hash, or MultiHash for hierarchical partition keys"`
+	DefaultTTL        *int32   `json:"default_ttl,omitempty" jsonschema:"Default TTL of items in seconds, -1 if TTL is enabled without a default. Not set if TTL is disabled"`
+}
+
+type ListContainersDetailedToolResult struct {
+	Account    string             `json:"account"`
+	Database   string             `json:"database"`
+	Containers []ContainerDetails `json:"containers" jsonschema:"list of containers in the database with their partition key and TTL"`
+}
+
+func ListContainersDetailedToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input ListContainersDetailedToolInput) (*mcp.CallToolResult, ListContainersDetailedToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, ListContainersDetailedToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, ListContainersDetailedToolResult{}, newInputError("cosmos db database name missing")
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, ListContainersDetailedToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, ListContainersDetailedToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	// the query returns the full container properties, so no extra read per container is needed
+	containerPager := databaseClient.NewQueryContainersPager("select * from c", nil)
+
+	containers := []ContainerDetails{}
+
+	for containerPager.More() {
+		containerResponse, err := containerPager.NextPage(ctx)
+		if err != nil {
+			return nil, ListContainersDetailedToolResult{}, withDiagnostics(err)
+		}
+
+		for _, container := range containerResponse.Containers {
+			containers = append(containers, newContainerDetails(container))
+		}
+	}
+
+	return nil, ListContainersDetailedToolResult{
+		Account:    input.Account,
+		Database:   input.Database,
+		Containers: containers,
+	}, nil
+}
+
+// newContainerDetails returns the partition key and TTL settings of a container
+func newContainerDetails(properties azcosmos.ContainerProperties) ContainerDetails {
+	paths := properties.PartitionKeyDefinition.Paths
+	if paths == nil {
+		paths = []string{}
+	}
+
+	return ContainerDetails{
+		ID:                properties.ID,
+		PartitionKeyPaths: paths,
+		PartitionKeyKind:  string(properties.PartitionKeyDefinition.Kind),
+		DefaultTTL:        properties.DefaultTimeToLive,
+	}
+}
+
 func ReadContainerMetadata() *mcp.Tool {
 
 	return &mcp.Tool{
 		Name:        "read_container_metadata",
-		Description: "Read metadata of the specified container in Azure Cosmos DB or local emulator. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service.",
+		Description: "Read metadata of the specified container in Azure Cosmos DB or local emulator: partition key definition, indexing policy, default and analytical store TTL, unique key policy, conflict resolution policy, geospatial configuration, computed properties and throughput. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service.",
 	}
 }
 
@@ -107,13 +195,13 @@ func ReadContainerMetadataToolHandler(ctx context.Context, _ *mcp.CallToolReques
 	database := input.Database
 
 	if database == "" {
-		return nil, ReadContainerMetadataToolResult{}, errors.New("cosmos db database name missing")
+		return nil, ReadContainerMetadataToolResult{}, newInputError("cosmos db database name missing")
 	}
 
 	container := input.Container
 
 	if container == "" {
-		return nil, ReadContainerMetadataToolResult{}, errors.New("container name missing")
+		return nil, ReadContainerMetadataToolResult{}, newInputError("container name missing")
 	}
 
 	client, err := input.GetClient()
@@ -133,7 +221,7 @@ func ReadContainerMetadataToolHandler(ctx context.Context, _ *mcp.CallToolReques
 
 	response, err := containerClient.Read(ctx, nil)
 	if err != nil {
-		return nil, ReadContainerMetadataToolResult{}, err
+		return nil, ReadContainerMetadataToolResult{}, withDiagnostics(err)
 	}
 
 	// Build throughput info
@@ -183,13 +271,28 @@ func ReadContainerMetadataToolHandler(ctx context.Context, _ *mcp.CallToolReques
 		}
 	}
 
+	var extended extendedContainerProperties
+	if response.RawResponse != nil {
+		// the body was already read by the SDK, Payload returns the buffered copy
+		body, err := runtime.Payload(response.RawResponse)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading container properties: %v", err)
+		}
+		if extended, err = parseExtendedContainerProperties(body); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	metadata := map[string]any{
 		"container_id":               response.ContainerProperties.ID,
 		"default_ttl":                response.ContainerProperties.DefaultTimeToLive,
+		"analytical_store_ttl":       response.ContainerProperties.AnalyticalStoreTimeToLiveInSeconds,
 		"indexing_policy":            response.ContainerProperties.IndexingPolicy,
 		"partition_key_definition":   response.ContainerProperties.PartitionKeyDefinition,
 		"conflict_resolution_policy": response.ContainerProperties.ConflictResolutionPolicy,
 		"unique_key_policy":          response.ContainerProperties.UniqueKeyPolicy,
+		"geospatial_config":          extended.GeospatialConfig,
+		"computed_properties":        extended.ComputedProperties,
 		"throughput":                 throughputInfo,
 	}
 
@@ -214,10 +317,135 @@ func ReadContainerMetadataToolHandler(ctx context.Context, _ *mcp.CallToolReques
 
 }
 
+// extendedContainerProperties holds the container properties that azcosmos.ContainerProperties does not expose
+type extendedContainerProperties struct {
+	GeospatialConfig   any `json:"geospatialConfig,omitempty"`
+	ComputedProperties any `json:"computedProperties,omitempty"`
+}
+
+// parseExtendedContainerProperties extracts the extended properties from the raw container properties
+func parseExtendedContainerProperties(body []byte) (extendedContainerProperties, error) {
+	var extended extendedContainerProperties
+	if err := json.Unmarshal(body, &extended); err != nil {
+		return extendedContainerProperties{}, fmt.Errorf("error parsing container properties: %v", err)
+	}
+	return extended, nil
+}
+
+func ContainerStats() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "container_stats",
+		Description: "Get the approximate number of items and storage size of a container in Azure Cosmos DB or local emulator. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Use this to check how large a container is before running large operations (export, truncate, cross-partition queries). The values are reported by the service and may lag behind recent writes.",
+	}
+}
+
+type ContainerStatsToolInput struct {
+	ConnectionConfig
+	Database  string `json:"database" jsonschema:"Azure Cosmos DB database name"`
+	Container string `json:"container" jsonschema:"Azure Cosmos DB container name"`
+}
+
+type ContainerStatsToolResult struct {
+	Account         string `json:"account"`
+	Database        string `json:"database"`
+	Container       string `json:"container"`
+	DocumentCount   int64  `json:"document_count" jsonschema:"Approximate number of items in the container"`
+	DocumentsSizeKB int64  `json:"documents_size_kb" jsonschema:"Approximate size of the items in KB"`
+	StorageSizeKB   int64  `json:"storage_size_kb" jsonschema:"Approximate storage used by the container in KB, including indexes"`
+}
+
+func ContainerStatsToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input ContainerStatsToolInput) (*mcp.CallToolResult, ContainerStatsToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, ContainerStatsToolResult{}, err
+	}
+
+	database := input.Database
+
+	if database == "" {
+		return nil, ContainerStatsToolResult{}, newInputError("cosmos db database name missing")
+	}
+
+	container := input.Container
+
+	if container == "" {
+		return nil, ContainerStatsToolResult{}, newInputError("container name missing")
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, ContainerStatsToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(database)
+	if err != nil {
+		return nil, ContainerStatsToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(container)
+	if err != nil {
+		return nil, ContainerStatsToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	response, err := containerClient.Read(ctx, &azcosmos.ReadContainerOptions{PopulateQuotaInfo: true})
+	if err != nil {
+		return nil, ContainerStatsToolResult{}, fmt.Errorf("error reading container: %w", withDiagnostics(err))
+	}
+
+	var header string
+	if response.RawResponse != nil {
+		header = response.RawResponse.Header.Get(resourceUsageHeader)
+	}
+	if header == "" {
+		return nil, ContainerStatsToolResult{}, errors.New("container statistics not available: resource usage was not returned by the service")
+	}
+
+	usage, err := parseResourceUsage(header)
+	if err != nil {
+		return nil, ContainerStatsToolResult{}, err
+	}
+
+	return nil, ContainerStatsToolResult{
+		Account:         input.Account,
+		Database:        database,
+		Container:       container,
+		DocumentCount:   usage["documentsCount"],
+		DocumentsSizeKB: usage["documentsSize"],
+		StorageSizeKB:   usage["collectionSize"],
+	}, nil
+}
+
+// resourceUsageHeader is the response header with the container usage, returned when quota info is requested
+const resourceUsageHeader = "x-ms-resource-usage"
+
+// parseResourceUsage parses the resource usage header, e.g. "documentsSize=12;documentsCount=3;collectionSize=20"
+func parseResourceUsage(header string) (map[string]int64, error) {
+	usage := map[string]int64{}
+
+	for _, entry := range strings.Split(header, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid resource usage entry '%s'", entry)
+		}
+
+		number, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for resource usage '%s': %v", name, err)
+		}
+		usage[strings.TrimSpace(name)] = number
+	}
+
+	return usage, nil
+}
+
 func CreateContainer() *mcp.Tool {
 	return &mcp.Tool{
 		Name:        "create_container",
-		Description: "Create a new container in the specified Azure Cosmos DB database or local emulator. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service.",
+		Description: "Create a new container in the specified Azure Cosmos DB database or local emulator. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. For accounts with multiple write regions, a conflict resolution policy can be set with conflictResolutionMode: lastWriterWins (optionally with conflictResolutionPath, an integer property, default /_ts) or custom (optionally with the name of a conflictResolutionProcedure stored procedure in the container). The conflict resolution policy can only be set when the container is created, it cannot be changed afterwards. For location data, provide spatialIndexPaths (e.g. /location) to add spatial indexes for GeoJSON Point properties, which makes geospatial queries such as geo_within_distance efficient. For HTAP scenarios, set analyticalStoreTTL (-1 to retain forever, or a number of seconds) to enable the analytical store - this requires an account with Azure Synapse Link enabled and is not supported by the emulator. Set waitUntilReady to true to wait until the new container can be read before returning, so that items can be added right away.",
 	}
 }
 
@@ -227,6 +455,16 @@ type CreateContainerToolInput struct {
 	Container        string `json:"container" jsonschema:"Name of the container to create"`
 	PartitionKeyPath string `json:"partitionKeyPath" jsonschema:"Partition key path for the container, example /id, /tentant, /category etc."`
 	Throughput       *int32 `json:"throughput,omitempty" jsonschema:"Provisioned throughput for the container (optional)"`
+
+	ConflictResolutionMode      string `json:"conflictResolutionMode,omitempty" jsonschema:"Conflict resolution mode for multi-region write accounts: lastWriterWins or custom (optional)"`
+	ConflictResolutionPath      string `json:"conflictResolutionPath,omitempty" jsonschema:"Integer property used to pick the winner in lastWriterWins mode, example /version (default /_ts)"`
+	ConflictResolutionProcedure string `json:"conflictResolutionProcedure,omitempty" jsonschema:"Name of the stored procedure in the container that resolves conflicts in custom mode. If not set, conflicts are written to the conflicts feed"`
+
+	SpatialIndexPaths []string `json:"spatialIndexPaths,omitempty" jsonschema:"Paths of GeoJSON Point properties to create spatial indexes for, example /location or /address/location (optional)"`
+
+	AnalyticalStoreTTL *int32 `json:"analyticalStoreTTL,omitempty" jsonschema:"Time to live of items in the analytical store in seconds, -1 to retain them forever. Enables the analytical store, which requires Azure Synapse Link on the account and is not supported by the emulator (optional)"`
+
+	WaitUntilReady bool `json:"waitUntilReady,omitempty" jsonschema:"Wait until the new container can be read before returning (optional, default false)"`
 }
 
 type CreateContainerToolResult struct {
@@ -244,19 +482,27 @@ func CreateContainerToolHandler(ctx context.Context, _ *mcp.CallToolRequest, inp
 	database := input.Database
 
 	if database == "" {
-		return nil, CreateContainerToolResult{}, errors.New("cosmos db database name missing")
+		return nil, CreateContainerToolResult{}, newInputError("cosmos db database name missing")
 	}
 
 	container := input.Container
 
 	if container == "" {
-		return nil, CreateContainerToolResult{}, errors.New("container name missing")
+		return nil, CreateContainerToolResult{}, newInputError("container name missing")
 	}
 
 	partitionKeyPath := input.PartitionKeyPath
 
 	if partitionKeyPath == "" {
-		return nil, CreateContainerToolResult{}, errors.New("partition key path missing")
+		return nil, CreateContainerToolResult{}, newInputError("partition key path missing")
+	}
+
+	if err := validateAnalyticalStoreTTL(input.AnalyticalStoreTTL); err != nil {
+		return nil, CreateContainerToolResult{}, err
+	}
+
+	if input.AnalyticalStoreTTL != nil && input.UseEmulator {
+		return nil, CreateContainerToolResult{}, newInputError("analytical store is not supported by the emulator, omit analyticalStoreTTL")
 	}
 
 	client, err := input.GetClient()
@@ -269,11 +515,25 @@ func CreateContainerToolHandler(ctx context.Context, _ *mcp.CallToolRequest, inp
 		return nil, CreateContainerToolResult{}, fmt.Errorf("error creating database client: %v", err)
 	}
 
+	conflictResolutionPolicy, err := newConflictResolutionPolicy(database, container, input.ConflictResolutionMode, input.ConflictResolutionPath, input.ConflictResolutionProcedure)
+	if err != nil {
+		return nil, CreateContainerToolResult{}, err
+	}
+
+	indexingPolicy, err := newSpatialIndexingPolicy(input.SpatialIndexPaths)
+	if err != nil {
+		return nil, CreateContainerToolResult{}, err
+	}
+
 	properties := azcosmos.ContainerProperties{
 		ID: container,
 		PartitionKeyDefinition: azcosmos.PartitionKeyDefinition{
 			Paths: []string{partitionKeyPath},
 		},
+		ConflictResolutionPolicy: conflictResolutionPolicy,
+		IndexingPolicy:           indexingPolicy,
+
+		AnalyticalStoreTimeToLiveInSeconds: input.AnalyticalStoreTTL,
 	}
 
 	if input.Throughput != nil {
@@ -286,11 +546,23 @@ func CreateContainerToolHandler(ctx context.Context, _ *mcp.CallToolRequest, inp
 	}
 
 	if err != nil {
-		return nil, CreateContainerToolResult{}, fmt.Errorf("error creating container: %v", err)
+		var responseErr *azcore.ResponseError
+		if input.AnalyticalStoreTTL != nil && errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusBadRequest {
+			// the service rejects analytical store settings on accounts without Azure Synapse Link
+			return nil, CreateContainerToolResult{}, fmt.Errorf("error creating container with analytical store, make sure Azure Synapse Link is enabled on the account or omit analyticalStoreTTL: %w", withDiagnostics(err))
+		}
+		return nil, CreateContainerToolResult{}, fmt.Errorf("error creating container: %w", withDiagnostics(err))
 	}
 
 	message := fmt.Sprintf("Container '%s' created successfully in database '%s'", container, database)
 
+	if input.WaitUntilReady {
+		if err := waitForContainer(ctx, databaseClient, container); err != nil {
+			return nil, CreateContainerToolResult{}, err
+		}
+		message += " and is ready"
+	}
+
 	return nil, CreateContainerToolResult{
 		Account:   input.Account,
 		Database:  database,
@@ -299,10 +571,243 @@ func CreateContainerToolHandler(ctx context.Context, _ *mcp.CallToolRequest, inp
 	}, nil
 }
 
+// waitForContainer polls a container that was just created until it can be read
+func waitForContainer(ctx context.Context, databaseClient *azcosmos.DatabaseClient, container string) error {
+	containerClient, err := databaseClient.NewContainer(container)
+	if err != nil {
+		return fmt.Errorf("error creating container client: %v", err)
+	}
+
+	err = waitUntilReady(ctx, waitUntilReadyTimeout, func(ctx context.Context) error {
+		_, err := containerClient.Read(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("container '%s' was created but is not ready: %w", container, withDiagnostics(err))
+	}
+
+	return nil
+}
+
+// validateAnalyticalStoreTTL checks the analytical store TTL of a new container, which is -1 (retain forever) or a number of seconds
+func validateAnalyticalStoreTTL(ttl *int32) error {
+	if ttl == nil {
+		return nil
+	}
+
+	if *ttl != -1 && *ttl <= 0 {
+		return inputErrorf("invalid analyticalStoreTTL %d: use -1 to retain items forever, or a positive number of seconds", *ttl)
+	}
+
+	return nil
+}
+
+// newConflictResolutionPolicy builds the conflict resolution policy for a new container. It returns nil if no mode is specified.
+// The policy can only be set at creation time, see https://learn.microsoft.com/en-us/azure/cosmos-db/nosql/how-to-manage-conflicts
+func newConflictResolutionPolicy(database, container, mode, path, procedure string) (*azcosmos.ConflictResolutionPolicy, error) {
+	switch strings.ToLower(mode) {
+	case "":
+		if path != "" || procedure != "" {
+			return nil, newInputError("conflict resolution mode missing: set it to lastWriterWins or custom")
+		}
+		return nil, nil
+	case "lastwriterwins":
+		if procedure != "" {
+			return nil, newInputError("conflict resolution procedure can only be used with the custom mode")
+		}
+		if path != "" && !strings.HasPrefix(path, "/") {
+			return nil, inputErrorf("invalid conflict resolution path '%s': path must start with /", path)
+		}
+		return &azcosmos.ConflictResolutionPolicy{
+			Mode:           azcosmos.ConflictResolutionModeLastWriteWins,
+			ResolutionPath: path,
+		}, nil
+	case "custom":
+		if path != "" {
+			return nil, newInputError("conflict resolution path can only be used with the lastWriterWins mode")
+		}
+		policy := &azcosmos.ConflictResolutionPolicy{Mode: azcosmos.ConflictResolutionModeCustom}
+		if procedure != "" {
+			policy.ResolutionProcedure = fmt.Sprintf("dbs/%s/colls/%s/sprocs/%s", database, container, procedure)
+		}
+		return policy, nil
+	default:
+		return nil, inputErrorf("invalid conflict resolution mode '%s', must be one of: lastWriterWins, custom", mode)
+	}
+}
+
+// newSpatialIndexingPolicy builds the indexing policy for a new container with spatial indexes on the paths.
+// The rest of the policy is the default one (all paths indexed). It returns nil if no paths are specified.
+func newSpatialIndexingPolicy(paths []string) (*azcosmos.IndexingPolicy, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	policy := &azcosmos.IndexingPolicy{
+		Automatic:     true,
+		IndexingMode:  azcosmos.IndexingModeConsistent,
+		IncludedPaths: []azcosmos.IncludedPath{{Path: "/*"}},
+	}
+
+	for _, path := range paths {
+		if !strings.HasPrefix(path, "/") {
+			return nil, inputErrorf("invalid spatial index path '%s': path must start with /", path)
+		}
+		// spatial indexes apply to the property and everything below it
+		path = strings.TrimSuffix(strings.TrimSuffix(path, "/*"), "/")
+		if path == "" {
+			return nil, newInputError("invalid spatial index path '/': provide the path of the property holding the location")
+		}
+		policy.SpatialIndexes = append(policy.SpatialIndexes, azcosmos.SpatialIndex{
+			Path:         path + "/*",
+			SpatialTypes: []azcosmos.SpatialType{azcosmos.SpatialTypePoint},
+		})
+	}
+
+	return policy, nil
+}
+
+func CloneContainerConfig() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "clone_container_config",
+		Description: "Create a new, empty container with the same configuration as an existing container in Azure Cosmos DB or local emulator. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. The partition key definition, indexing policy, default TTL and unique key policy of the source container are copied, items are not. The new container is created in the same database unless targetDatabase is specified. Throughput is not copied, set throughput to provision dedicated throughput for the new container. Set waitUntilReady to true to wait until the new container can be read before returning.",
+	}
+}
+
+type CloneContainerConfigToolInput struct {
+	ConnectionConfig
+	Database        string `json:"database" jsonschema:"Azure Cosmos DB database name of the source container"`
+	SourceContainer string `json:"sourceContainer" jsonschema:"Name of the container to copy the configuration from"`
+	TargetContainer string `json:"targetContainer" jsonschema:"Name of the container to create"`
+	TargetDatabase  string `json:"targetDatabase,omitempty" jsonschema:"Database to create the new container in (optional, defaults to the source database)"`
+	Throughput      *int32 `json:"throughput,omitempty" jsonschema:"Provisioned throughput for the new container (optional)"`
+	WaitUntilReady  bool   `json:"waitUntilReady,omitempty" jsonschema:"Wait until the new container can be read before returning (optional, default false)"`
+}
+
+type CloneContainerConfigToolResult struct {
+	Account                string `json:"account"`
+	SourceDatabase         string `json:"source_database"`
+	SourceContainer        string `json:"source_container"`
+	TargetDatabase         string `json:"target_database"`
+	TargetContainer        string `json:"target_container"`
+	PartitionKeyDefinition any    `json:"partition_key_definition"`
+	IndexingPolicy         any    `json:"indexing_policy,omitempty"`
+	DefaultTTL             *int32 `json:"default_ttl,omitempty"`
+	UniqueKeyPolicy        any    `json:"unique_key_policy,omitempty"`
+	Message                string `json:"message"`
+}
+
+func CloneContainerConfigToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input CloneContainerConfigToolInput) (*mcp.CallToolResult, CloneContainerConfigToolResult, error) {
+	if err := input.Validate(); err != nil {
+		return nil, CloneContainerConfigToolResult{}, err
+	}
+
+	database := input.Database
+
+	if database == "" {
+		return nil, CloneContainerConfigToolResult{}, newInputError("cosmos db database name missing")
+	}
+
+	if input.SourceContainer == "" {
+		return nil, CloneContainerConfigToolResult{}, newInputError("source container name missing")
+	}
+
+	if input.TargetContainer == "" {
+		return nil, CloneContainerConfigToolResult{}, newInputError("target container name missing")
+	}
+
+	targetDatabase := input.TargetDatabase
+	if targetDatabase == "" {
+		targetDatabase = database
+	}
+
+	if targetDatabase == database && input.TargetContainer == input.SourceContainer {
+		return nil, CloneContainerConfigToolResult{}, newInputError("target container must be different from the source container")
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, CloneContainerConfigToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(database)
+	if err != nil {
+		return nil, CloneContainerConfigToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.SourceContainer)
+	if err != nil {
+		return nil, CloneContainerConfigToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	response, err := containerClient.Read(ctx, nil)
+	if err != nil {
+		return nil, CloneContainerConfigToolResult{}, fmt.Errorf("error reading source container: %w", withDiagnostics(err))
+	}
+
+	properties := cloneContainerProperties(*response.ContainerProperties, input.TargetContainer)
+
+	targetDatabaseClient, err := client.NewDatabase(targetDatabase)
+	if err != nil {
+		return nil, CloneContainerConfigToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	var options *azcosmos.CreateContainerOptions
+	if input.Throughput != nil {
+		throughputProps := azcosmos.NewManualThroughputProperties(*input.Throughput)
+		options = &azcosmos.CreateContainerOptions{ThroughputProperties: &throughputProps}
+	}
+
+	if _, err = targetDatabaseClient.CreateContainer(ctx, properties, options); err != nil {
+		return nil, CloneContainerConfigToolResult{}, fmt.Errorf("error creating container: %w", withDiagnostics(err))
+	}
+
+	message := fmt.Sprintf("Container '%s' created in database '%s' with the configuration of container '%s'", input.TargetContainer, targetDatabase, input.SourceContainer)
+
+	if input.WaitUntilReady {
+		if err := waitForContainer(ctx, targetDatabaseClient, input.TargetContainer); err != nil {
+			return nil, CloneContainerConfigToolResult{}, err
+		}
+		message += " and is ready"
+	}
+
+	result := CloneContainerConfigToolResult{
+		Account:                input.Account,
+		SourceDatabase:         database,
+		SourceContainer:        input.SourceContainer,
+		TargetDatabase:         targetDatabase,
+		TargetContainer:        input.TargetContainer,
+		PartitionKeyDefinition: properties.PartitionKeyDefinition,
+		DefaultTTL:             properties.DefaultTimeToLive,
+		Message:                message,
+	}
+	// assigned separately so that a nil policy is left out of the result
+	if properties.IndexingPolicy != nil {
+		result.IndexingPolicy = properties.IndexingPolicy
+	}
+	if properties.UniqueKeyPolicy != nil {
+		result.UniqueKeyPolicy = properties.UniqueKeyPolicy
+	}
+
+	return nil, result, nil
+}
+
+// cloneContainerProperties copies the schema settings of a container (partition key definition, indexing policy, default TTL and unique key policy) to new properties with the given id.
+// System properties (etag, _rid, _self) are not copied. The conflict resolution policy is not copied either, since a custom policy refers to a stored procedure of the source container.
+func cloneContainerProperties(source azcosmos.ContainerProperties, id string) azcosmos.ContainerProperties {
+	return azcosmos.ContainerProperties{
+		ID:                     id,
+		PartitionKeyDefinition: source.PartitionKeyDefinition,
+		IndexingPolicy:         source.IndexingPolicy,
+		DefaultTimeToLive:      source.DefaultTimeToLive,
+		UniqueKeyPolicy:        source.UniqueKeyPolicy,
+	}
+}
+
 func AddItemToContainer() *mcp.Tool {
 	return &mcp.Tool{
 		Name:        "add_item_to_container",
-		Description: "Add an item to the specified container in Azure Cosmos DB or local emulator. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service.",
+		Description: "Add an item to the specified container in Azure Cosmos DB or local emulator. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Set ttlSeconds to make the item expire after that many seconds (or -1 to never expire), it is written to the ttl field of the item. Per-item TTL only takes effect if TTL is enabled on the container (its default TTL is set, -1 enables TTL without expiring items by default). Set schema to a JSON Schema (draft 2020-12) to validate the item before it is written - the item is not written if it does not match, and the error has the path of the first property that does not match.",
 	}
 }
 
@@ -312,13 +817,16 @@ type AddItemToContainerToolInput struct {
 	Container    string `json:"container" jsonschema:"Name of the container to add the item to"`
 	PartitionKey string `json:"partitionKey" jsonschema:"Partition key value for the item"`
 	Item         string `json:"item" jsonschema:"The JSON representation of the item to add. id field is mandatory"`
+	TTLSeconds   *int   `json:"ttlSeconds,omitempty" jsonschema:"Time to live of the item in seconds, or -1 to never expire (optional). Overrides the default TTL of the container, which must have TTL enabled"`
+	Schema       string `json:"schema,omitempty" jsonschema:"JSON Schema (draft 2020-12) the item must match, as a JSON string (optional). The item is not written if it does not match"`
 }
 
 type AddItemToContainerToolResult struct {
-	Account   string `json:"account"`
-	Database  string `json:"database"`
-	Container string `json:"container"`
-	Message   string `json:"message"`
+	Account      string `json:"account"`
+	Database     string `json:"database"`
+	Container    string `json:"container"`
+	Message      string `json:"message"`
+	SessionToken string `json:"session_token,omitempty" jsonschema:"Session token of the write, pass it to subsequent reads and queries to read your own writes under Session consistency"`
 }
 
 func AddItemToContainerToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input AddItemToContainerToolInput) (*mcp.CallToolResult, AddItemToContainerToolResult, error) {
@@ -329,25 +837,39 @@ func AddItemToContainerToolHandler(ctx context.Context, _ *mcp.CallToolRequest,
 	database := input.Database
 
 	if database == "" {
-		return nil, AddItemToContainerToolResult{}, errors.New("cosmos db database name missing")
+		return nil, AddItemToContainerToolResult{}, newInputError("cosmos db database name missing")
 	}
 
 	container := input.Container
 
 	if container == "" {
-		return nil, AddItemToContainerToolResult{}, errors.New("container name missing")
+		return nil, AddItemToContainerToolResult{}, newInputError("container name missing")
 	}
 
 	partitionKeyValue := input.PartitionKey
 
 	if partitionKeyValue == "" {
-		return nil, AddItemToContainerToolResult{}, errors.New("value for partition key missing")
+		return nil, AddItemToContainerToolResult{}, newInputError("value for partition key missing")
 	}
 
 	itemJSON := input.Item
 
 	if itemJSON == "" {
-		return nil, AddItemToContainerToolResult{}, errors.New("item JSON missing")
+		return nil, AddItemToContainerToolResult{}, newInputError("item JSON missing")
+	}
+
+	schema, err := parseItemSchema(input.Schema)
+	if err != nil {
+		return nil, AddItemToContainerToolResult{}, err
+	}
+
+	if err := validateItem(schema, []byte(itemJSON)); err != nil {
+		return nil, AddItemToContainerToolResult{}, err
+	}
+
+	itemJSON, err = withItemTTL(itemJSON, input.TTLSeconds)
+	if err != nil {
+		return nil, AddItemToContainerToolResult{}, err
 	}
 
 	client, err := input.GetClient()
@@ -367,19 +889,62 @@ func AddItemToContainerToolHandler(ctx context.Context, _ *mcp.CallToolRequest,
 
 	partitionKey := azcosmos.NewPartitionKeyString(partitionKeyValue)
 
-	_, err = containerClient.CreateItem(ctx, partitionKey, []byte(itemJSON), nil)
+	itemResponse, err := containerClient.CreateItem(ctx, partitionKey, []byte(itemJSON), nil)
 	if err != nil {
-		return nil, AddItemToContainerToolResult{}, fmt.Errorf("error adding item to container: %v", err)
+		return nil, AddItemToContainerToolResult{}, fmt.Errorf("error adding item to container: %w", withDiagnostics(err))
 	}
 
 	message := fmt.Sprintf("Item added successfully to container '%s' in database '%s'", container, database)
 
-	return nil, AddItemToContainerToolResult{
+	result := AddItemToContainerToolResult{
 		Account:   input.Account,
 		Database:  database,
 		Container: container,
 		Message:   message,
-	}, nil
+	}
+	if itemResponse.SessionToken != nil {
+		result.SessionToken = *itemResponse.SessionToken
+	}
+
+	return nil, result, nil
+}
+
+// withItemTTL sets the ttl field of the item to ttlSeconds. The item is returned unchanged if ttlSeconds is nil.
+// An existing ttl field is kept if it has the same value, a different value is an error rather than being overwritten silently.
+func withItemTTL(item string, ttlSeconds *int) (string, error) {
+	if ttlSeconds == nil {
+		return item, nil
+	}
+
+	if *ttlSeconds == 0 || *ttlSeconds < -1 {
+		return "", newInputError("ttlSeconds must be a positive number of seconds, or -1 to never expire")
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(item), &fields); err != nil {
+		return "", inputErrorf("invalid item JSON: %v", err)
+	}
+	if fields == nil {
+		return "", newInputError("invalid item JSON: item must be a JSON object")
+	}
+
+	ttl := strconv.Itoa(*ttlSeconds)
+
+	if existing, ok := fields["ttl"]; ok {
+		if string(existing) != ttl {
+			return "", inputErrorf("item already has a ttl field (%s) that does not match ttlSeconds (%s)", existing, ttl)
+		}
+		return item, nil
+	}
+
+	fields["ttl"] = json.RawMessage(ttl)
+
+	updated, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("error serializing item: %v", err)
+	}
+
+	return string(updated), nil
 }
 
 // BatchCreateItems creates a tool for adding multiple items in a single atomic transaction.
@@ -387,7 +952,7 @@ func AddItemToContainerToolHandler(ctx context.Context, _ *mcp.CallToolRequest,
 func BatchCreateItems() *mcp.Tool {
 	return &mcp.Tool{
 		Name:        "batch_create_items",
-		Description: "Add multiple items (max 100) to a container in a single atomic transaction in Azure Cosmos DB or local emulator. All items must share the same partition key. Total payload must not exceed 2MB. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. See: https://learn.microsoft.com/en-us/azure/cosmos-db/transactional-batch?tabs=go#limitations",
+		Description: "Add multiple items (max 100) to a container in a single atomic transaction in Azure Cosmos DB or local emulator. All items must share the same partition key. Total payload must not exceed 2MB. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Set schema to a JSON Schema (draft 2020-12) to validate all items before the batch is executed - no item is written if any of them does not match. See: https://learn.microsoft.com/en-us/azure/cosmos-db/transactional-batch?tabs=go#limitations",
 	}
 }
 
@@ -397,6 +962,7 @@ type BatchCreateItemsToolInput struct {
 	Container    string   `json:"container" jsonschema:"Name of the container to add items to"`
 	PartitionKey string   `json:"partitionKey" jsonschema:"Partition key value shared by all items"`
 	Items        []string `json:"items" jsonschema:"Array of JSON items to add. Each item must have an id field. Maximum 100 items."`
+	Schema       string   `json:"schema,omitempty" jsonschema:"JSON Schema (draft 2020-12) every item must match, as a JSON string (optional). No item is written if any of them does not match"`
 }
 
 type BatchCreateItemsToolResult struct {
@@ -406,6 +972,7 @@ type BatchCreateItemsToolResult struct {
 	PartitionKey string `json:"partition_key"`
 	ItemsCreated int    `json:"items_created"`
 	Message      string `json:"message"`
+	SessionToken string `json:"session_token,omitempty" jsonschema:"Session token of the write, pass it to subsequent reads and queries to read your own writes under Session consistency"`
 }
 
 func BatchCreateItemsToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input BatchCreateItemsToolInput) (*mcp.CallToolResult, BatchCreateItemsToolResult, error) {
@@ -416,29 +983,40 @@ func BatchCreateItemsToolHandler(ctx context.Context, _ *mcp.CallToolRequest, in
 	database := input.Database
 
 	if database == "" {
-		return nil, BatchCreateItemsToolResult{}, errors.New("cosmos db database name missing")
+		return nil, BatchCreateItemsToolResult{}, newInputError("cosmos db database name missing")
 	}
 
 	container := input.Container
 
 	if container == "" {
-		return nil, BatchCreateItemsToolResult{}, errors.New("container name missing")
+		return nil, BatchCreateItemsToolResult{}, newInputError("container name missing")
 	}
 
 	partitionKeyValue := input.PartitionKey
 
 	if partitionKeyValue == "" {
-		return nil, BatchCreateItemsToolResult{}, errors.New("partition key value missing")
+		return nil, BatchCreateItemsToolResult{}, newInputError("partition key value missing")
 	}
 
 	items := input.Items
 
 	if len(items) == 0 {
-		return nil, BatchCreateItemsToolResult{}, errors.New("items array is empty")
+		return nil, BatchCreateItemsToolResult{}, newInputError("items array is empty")
 	}
 
 	if len(items) > 100 {
-		return nil, BatchCreateItemsToolResult{}, errors.New("batch exceeds maximum of 100 items per transaction")
+		return nil, BatchCreateItemsToolResult{}, newInputError("batch exceeds maximum of 100 items per transaction")
+	}
+
+	schema, err := parseItemSchema(input.Schema)
+	if err != nil {
+		return nil, BatchCreateItemsToolResult{}, err
+	}
+
+	for i, item := range items {
+		if err := validateItem(schema, []byte(item)); err != nil {
+			return nil, BatchCreateItemsToolResult{}, inputErrorf("item at index %d: %v", i, err)
+		}
 	}
 
 	client, err := input.GetClient()
@@ -469,7 +1047,7 @@ func BatchCreateItemsToolHandler(ctx context.Context, _ *mcp.CallToolRequest, in
 	// Execute the batch
 	batchResponse, err := containerClient.ExecuteTransactionalBatch(ctx, batch, nil)
 	if err != nil {
-		return nil, BatchCreateItemsToolResult{}, fmt.Errorf("error executing batch: %v", err)
+		return nil, BatchCreateItemsToolResult{}, fmt.Errorf("error executing batch: %w", withDiagnostics(err))
 	}
 
 	// Check if the batch was successful
@@ -492,5 +1070,6 @@ func BatchCreateItemsToolHandler(ctx context.Context, _ *mcp.CallToolRequest, in
 		PartitionKey: partitionKeyValue,
 		ItemsCreated: len(items),
 		Message:      message,
+		SessionToken: batchResponse.SessionToken,
 	}, nil
 }
@@ -0,0 +1,253 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func ReplaceContainerConfig() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "replace_container_config",
+		Description: "Replace the configuration of the specified container in Azure Cosmos DB or local emulator in one call, instead of editing one setting at a time. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. The configuration is a JSON object in the shape of the Azure Cosmos DB container resource, with any of: indexingPolicy, defaultTtl (null to turn TTL off, -1 to enable it without a default), analyticalStorageTtl, partitionKey, uniqueKeyPolicy, conflictResolutionPolicy and id. The properties provided replace the current ones, the others are kept. The id, partition key, unique keys and conflict resolution policy can't be changed once the container is created - they may be provided (e.g. when pasting the whole configuration) but must match the container, otherwise an error is returned and nothing is changed. System properties (_rid, _etag, _ts, _self) are ignored. computedProperties are not supported. Check a new indexing policy with validate_indexing_policy first. Returns the properties that changed, with the previous and new value.",
+	}
+}
+
+type ReplaceContainerConfigToolInput struct {
+	ConnectionConfig
+	Database      string `json:"database" jsonschema:"Azure Cosmos DB database name"`
+	Container     string `json:"container" jsonschema:"Azure Cosmos DB container name"`
+	Configuration string `json:"configuration" jsonschema:"The container configuration as a JSON object, example {\"indexingPolicy\": {\"indexingMode\": \"consistent\", \"includedPaths\": [{\"path\": \"/*\"}]}, \"defaultTtl\": 3600}"`
+}
+
+type ReplaceContainerConfigToolResult struct {
+	Database  string                `json:"database"`
+	Container string                `json:"container"`
+	Replaced  bool                  `json:"replaced" jsonschema:"Whether the configuration was replaced, false if it already matches"`
+	Changes   []ContainerDifference `json:"changes" jsonschema:"The properties that changed, left is the previous value and right the new value"`
+	Message   string                `json:"message"`
+}
+
+// containerConfigKeys are the container properties that replace_container_config accepts
+var containerConfigKeys = []string{"id", "partitionKey", "indexingPolicy", "defaultTtl", "analyticalStorageTtl", "uniqueKeyPolicy", "conflictResolutionPolicy"}
+
+// containerSystemKeys are the system properties of a container resource, ignored so that a configuration read from the service can be provided as is
+var containerSystemKeys = []string{"_rid", "_etag", "_ts", "_self", "_docs", "_sprocs", "_triggers", "_udfs", "_conflicts"}
+
+// immutableContainerSettings are the settings (as compared by containerSettings) that can't be changed once the container is created
+var immutableContainerSettings = map[string]string{
+	"partition_key.paths":        "partitionKey",
+	"partition_key.kind":         "partitionKey",
+	"partition_key.version":      "partitionKey",
+	"unique_keys":                "uniqueKeyPolicy",
+	"conflict_resolution_policy": "conflictResolutionPolicy",
+}
+
+func ReplaceContainerConfigToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input ReplaceContainerConfigToolInput) (*mcp.CallToolResult, ReplaceContainerConfigToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, ReplaceContainerConfigToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, ReplaceContainerConfigToolResult{}, newInputError("cosmos db database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, ReplaceContainerConfigToolResult{}, newInputError("container name missing")
+	}
+
+	if input.Configuration == "" {
+		return nil, ReplaceContainerConfigToolResult{}, newInputError("configuration missing")
+	}
+
+	config, err := parseContainerConfig(input.Configuration)
+	if err != nil {
+		return nil, ReplaceContainerConfigToolResult{}, err
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, ReplaceContainerConfigToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, ReplaceContainerConfigToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, ReplaceContainerConfigToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	response, err := containerClient.Read(ctx, nil)
+	if err != nil {
+		return nil, ReplaceContainerConfigToolResult{}, fmt.Errorf("error reading container: %w", withDiagnostics(err))
+	}
+
+	if err := checkReplaceKeepsProperties(response, input.Container); err != nil {
+		return nil, ReplaceContainerConfigToolResult{}, err
+	}
+
+	current := *response.ContainerProperties
+
+	updated, err := applyContainerConfig(current, config)
+	if err != nil {
+		return nil, ReplaceContainerConfigToolResult{}, err
+	}
+
+	changes := diffContainerProperties(current, updated)
+
+	result := ReplaceContainerConfigToolResult{
+		Database:  input.Database,
+		Container: input.Container,
+		Changes:   changes,
+	}
+
+	if len(changes) == 0 {
+		result.Message = fmt.Sprintf("Container '%s' already has this configuration, it was not changed", input.Container)
+		return nil, result, nil
+	}
+
+	_, err = containerClient.Replace(ctx, updated, nil)
+	if err != nil {
+		return nil, ReplaceContainerConfigToolResult{}, fmt.Errorf("error replacing container configuration: %w", withDiagnostics(err))
+	}
+
+	result.Replaced = true
+	result.Message = fmt.Sprintf("Configuration of container '%s' replaced, %d properties changed", input.Container, len(changes))
+	if slices.ContainsFunc(changes, func(change ContainerDifference) bool { return strings.HasPrefix(change.Property, "indexing_policy.") }) {
+		result.Message += ". Existing items are re-indexed in the background"
+	}
+
+	return nil, result, nil
+}
+
+// parseContainerConfig parses the configuration and checks that it only has supported properties
+func parseContainerConfig(configuration string) (map[string]json.RawMessage, error) {
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(configuration), &config); err != nil {
+		return nil, inputErrorf("invalid configuration, must be a JSON object: %v", err)
+	}
+	if config == nil {
+		return nil, newInputError("invalid configuration, must be a JSON object")
+	}
+
+	for key := range config {
+		if slices.Contains(containerSystemKeys, key) {
+			delete(config, key)
+			continue
+		}
+		if key == "computedProperties" {
+			return nil, newInputError("computedProperties can't be set, the Azure Cosmos DB Go SDK does not support them (see Known limitations in the README)")
+		}
+		if !slices.Contains(containerConfigKeys, key) {
+			return nil, inputErrorf("unsupported property '%s' in configuration, must be one of: %s", key, strings.Join(containerConfigKeys, ", "))
+		}
+	}
+
+	if len(config) == 0 {
+		return nil, inputErrorf("configuration has no properties, provide one or more of: %s", strings.Join(containerConfigKeys, ", "))
+	}
+
+	return config, nil
+}
+
+// applyContainerConfig returns the properties of the container with the configuration applied. It returns an error if the
+// configuration changes a property that is fixed when the container is created.
+func applyContainerConfig(current azcosmos.ContainerProperties, config map[string]json.RawMessage) (azcosmos.ContainerProperties, error) {
+	updated := current
+
+	for key, value := range config {
+		var err error
+		switch key {
+		case "id":
+			var id string
+			if err = json.Unmarshal(value, &id); err == nil && id != current.ID {
+				return azcosmos.ContainerProperties{}, inputErrorf("id can't be changed, the configuration is for container '%s' but the container is '%s'", id, current.ID)
+			}
+		case "partitionKey":
+			var partitionKey azcosmos.PartitionKeyDefinition
+			if err = json.Unmarshal(value, &partitionKey); err == nil {
+				// the version is optional, e.g. when the definition was written by hand
+				if partitionKey.Version == 0 {
+					partitionKey.Version = current.PartitionKeyDefinition.Version
+				}
+				updated.PartitionKeyDefinition = partitionKey
+			}
+		case "indexingPolicy":
+			if string(value) == "null" {
+				return azcosmos.ContainerProperties{}, newInputError("invalid indexingPolicy in configuration: must be an object, not null")
+			}
+			// automatic is rarely provided and can only be false with indexing mode none
+			policy := azcosmos.IndexingPolicy{Automatic: true}
+			if err = json.Unmarshal(value, &policy); err == nil {
+				updated.IndexingPolicy = &policy
+			}
+		// the pointers are shared with current, so the values are decoded into new ones
+		case "defaultTtl":
+			var ttl *int32
+			if err = json.Unmarshal(value, &ttl); err == nil {
+				updated.DefaultTimeToLive = ttl
+			}
+		case "analyticalStorageTtl":
+			var ttl *int32
+			if err = json.Unmarshal(value, &ttl); err == nil {
+				updated.AnalyticalStoreTimeToLiveInSeconds = ttl
+			}
+		case "uniqueKeyPolicy":
+			var policy *azcosmos.UniqueKeyPolicy
+			if err = json.Unmarshal(value, &policy); err == nil {
+				updated.UniqueKeyPolicy = policy
+			}
+		case "conflictResolutionPolicy":
+			var policy *azcosmos.ConflictResolutionPolicy
+			if err = json.Unmarshal(value, &policy); err == nil {
+				updated.ConflictResolutionPolicy = policy
+			}
+		}
+		if err != nil {
+			return azcosmos.ContainerProperties{}, inputErrorf("invalid %s in configuration: %v", key, err)
+		}
+	}
+
+	for _, change := range diffContainerProperties(current, updated) {
+		if key, ok := immutableContainerSettings[change.Property]; ok {
+			return azcosmos.ContainerProperties{}, inputErrorf("%s can't be changed once the container is created (%s would change from %v to %v), create a new container and copy the items instead", key, change.Property, change.Left, change.Right)
+		}
+	}
+
+	return updated, nil
+}
+
+// checkReplaceKeepsProperties returns an error if replacing the container properties read in the response would remove
+// properties the SDK does not support. The SDK does not send computed properties, so they would be removed.
+func checkReplaceKeepsProperties(response azcosmos.ContainerResponse, container string) error {
+	if response.RawResponse == nil {
+		return nil
+	}
+
+	// the body was already read by the SDK, Payload returns the buffered copy
+	body, err := runtime.Payload(response.RawResponse)
+	if err != nil {
+		return fmt.Errorf("error reading container properties: %v", err)
+	}
+
+	extended, err := parseExtendedContainerProperties(body)
+	if err != nil {
+		return err
+	}
+
+	if extended.ComputedProperties != nil {
+		return fmt.Errorf("container '%s' has computed properties, which would be removed by replacing its configuration (the SDK does not support them), update it with the Azure portal or CLI instead", container)
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for replace_container_config that do not need the emulator
+
+func TestParseContainerConfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		configuration  string
+		expectedKeys   []string
+		expectedErrMsg string
+	}{
+		{
+			name:          "supported properties",
+			configuration: `{"indexingPolicy": {"indexingMode": "consistent"}, "defaultTtl": 60}`,
+			expectedKeys:  []string{"defaultTtl", "indexingPolicy"},
+		},
+		{
+			name:          "system properties are ignored",
+			configuration: `{"id": "orders", "_rid": "abc", "_etag": "\"0\"", "_ts": 1700000000, "_self": "dbs/abc/colls/abc/"}`,
+			expectedKeys:  []string{"id"},
+		},
+		{
+			name:           "not an object",
+			configuration:  `[1]`,
+			expectedErrMsg: "invalid configuration, must be a JSON object",
+		},
+		{
+			name:           "null",
+			configuration:  `null`,
+			expectedErrMsg: "invalid configuration, must be a JSON object",
+		},
+		{
+			name:           "computed properties",
+			configuration:  `{"computedProperties": [{"name": "cp", "query": "SELECT VALUE LOWER(c.name) FROM c"}]}`,
+			expectedErrMsg: "computedProperties can't be set",
+		},
+		{
+			name:           "unsupported property",
+			configuration:  `{"defaultTTL": 60}`,
+			expectedErrMsg: "unsupported property 'defaultTTL'",
+		},
+		{
+			name:           "only system properties",
+			configuration:  `{"_rid": "abc"}`,
+			expectedErrMsg: "configuration has no properties",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := parseContainerConfig(tt.configuration)
+			if tt.expectedErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			keys := []string{}
+			for key := range config {
+				keys = append(keys, key)
+			}
+			assert.ElementsMatch(t, tt.expectedKeys, keys)
+		})
+	}
+}
+
+func TestApplyContainerConfig(t *testing.T) {
+	ttl := int32(3600)
+	current := azcosmos.ContainerProperties{
+		ID:                     "orders",
+		PartitionKeyDefinition: azcosmos.PartitionKeyDefinition{Paths: []string{"/customerId"}, Kind: azcosmos.PartitionKeyKindHash, Version: 2},
+		DefaultTimeToLive:      &ttl,
+		IndexingPolicy: &azcosmos.IndexingPolicy{
+			Automatic:     true,
+			IndexingMode:  azcosmos.IndexingModeConsistent,
+			IncludedPaths: []azcosmos.IncludedPath{{Path: "/*"}},
+		},
+		UniqueKeyPolicy:          &azcosmos.UniqueKeyPolicy{UniqueKeys: []azcosmos.UniqueKey{{Paths: []string{"/email"}}}},
+		ConflictResolutionPolicy: &azcosmos.ConflictResolutionPolicy{Mode: azcosmos.ConflictResolutionModeLastWriteWins, ResolutionPath: "/_ts"},
+	}
+
+	apply := func(t *testing.T, configuration string) (azcosmos.ContainerProperties, error) {
+		t.Helper()
+		config, err := parseContainerConfig(configuration)
+		require.NoError(t, err)
+		return applyContainerConfig(current, config)
+	}
+
+	t.Run("indexing policy is replaced, the rest is kept", func(t *testing.T) {
+		updated, err := apply(t, `{"indexingPolicy": {"indexingMode": "consistent", "includedPaths": [{"path": "/customerId/?"}], "excludedPaths": [{"path": "/*"}]}}`)
+		require.NoError(t, err)
+		require.NotNil(t, updated.IndexingPolicy)
+		assert.True(t, updated.IndexingPolicy.Automatic)
+		assert.Equal(t, []azcosmos.ExcludedPath{{Path: "/*"}}, updated.IndexingPolicy.ExcludedPaths)
+		assert.Equal(t, &ttl, updated.DefaultTimeToLive)
+		assert.Equal(t, current.UniqueKeyPolicy, updated.UniqueKeyPolicy)
+
+		// the current properties are not modified
+		assert.Equal(t, []azcosmos.IncludedPath{{Path: "/*"}}, current.IndexingPolicy.IncludedPaths)
+	})
+
+	t.Run("null default TTL turns TTL off", func(t *testing.T) {
+		updated, err := apply(t, `{"defaultTtl": null}`)
+		require.NoError(t, err)
+		assert.Nil(t, updated.DefaultTimeToLive)
+		assert.Equal(t, int32(3600), *current.DefaultTimeToLive)
+	})
+
+	t.Run("unchanged immutable properties are accepted", func(t *testing.T) {
+		updated, err := apply(t, `{
+			"id": "orders",
+			"partitionKey": {"paths": ["/customerId"], "kind": "Hash"},
+			"uniqueKeyPolicy": {"uniqueKeys": [{"paths": ["/email"]}]},
+			"conflictResolutionPolicy": {"mode": "LastWriterWins", "conflictResolutionPath": "/_ts"},
+			"defaultTtl": 60
+		}`)
+		require.NoError(t, err)
+		assert.Equal(t, 2, updated.PartitionKeyDefinition.Version)
+		assert.Equal(t, []ContainerDifference{{Property: "default_ttl", Left: int32(3600), Right: int32(60)}}, diffContainerProperties(current, updated))
+	})
+
+	errorTests := []struct {
+		name           string
+		configuration  string
+		expectedErrMsg string
+	}{
+		{"different id", `{"id": "invoices"}`, "id can't be changed"},
+		{"different partition key", `{"partitionKey": {"paths": ["/id"], "kind": "Hash"}}`, "partitionKey can't be changed"},
+		{"different partition key version", `{"partitionKey": {"paths": ["/customerId"], "kind": "Hash", "version": 1}}`, "partitionKey can't be changed"},
+		{"different unique keys", `{"uniqueKeyPolicy": {"uniqueKeys": []}}`, "uniqueKeyPolicy can't be changed"},
+		{"different conflict resolution", `{"conflictResolutionPolicy": {"mode": "Custom"}}`, "conflictResolutionPolicy can't be changed"},
+		{"null indexing policy", `{"indexingPolicy": null}`, "must be an object, not null"},
+		{"invalid TTL", `{"defaultTtl": "1h"}`, "invalid defaultTtl in configuration"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := apply(t, tt.configuration)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErrMsg)
+		})
+	}
+}
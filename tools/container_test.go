@@ -0,0 +1,261 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for container helpers that do not need the emulator
+
+func TestNewConflictResolutionPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		path        string
+		procedure   string
+		expected    *azcosmos.ConflictResolutionPolicy
+		expectError bool
+	}{
+		{name: "no policy", expected: nil},
+		{name: "last writer wins with default path", mode: "lastWriterWins", expected: &azcosmos.ConflictResolutionPolicy{Mode: azcosmos.ConflictResolutionModeLastWriteWins}},
+		{name: "last writer wins with path", mode: "LastWriterWins", path: "/version", expected: &azcosmos.ConflictResolutionPolicy{Mode: azcosmos.ConflictResolutionModeLastWriteWins, ResolutionPath: "/version"}},
+		{name: "custom with procedure", mode: "custom", procedure: "resolver", expected: &azcosmos.ConflictResolutionPolicy{Mode: azcosmos.ConflictResolutionModeCustom, ResolutionProcedure: "dbs/db1/colls/c1/sprocs/resolver"}},
+		{name: "custom without procedure", mode: "custom", expected: &azcosmos.ConflictResolutionPolicy{Mode: azcosmos.ConflictResolutionModeCustom}},
+		{name: "path without mode", path: "/version", expectError: true},
+		{name: "invalid mode", mode: "firstWriterWins", expectError: true},
+		{name: "path without slash", mode: "lastWriterWins", path: "version", expectError: true},
+		{name: "procedure with last writer wins", mode: "lastWriterWins", procedure: "resolver", expectError: true},
+		{name: "path with custom", mode: "custom", path: "/version", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := newConflictResolutionPolicy("db1", "c1", tt.mode, tt.path, tt.procedure)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, policy)
+		})
+	}
+}
+
+func TestCloneContainerProperties(t *testing.T) {
+	ttl := int32(3600)
+	source := azcosmos.ContainerProperties{
+		ID:         "source",
+		ResourceID: "abc==",
+		SelfLink:   "dbs/abc==/colls/abc=/",
+		PartitionKeyDefinition: azcosmos.PartitionKeyDefinition{
+			Paths: []string{"/tenant"},
+		},
+		IndexingPolicy: &azcosmos.IndexingPolicy{
+			Automatic:     true,
+			IndexingMode:  azcosmos.IndexingModeConsistent,
+			IncludedPaths: []azcosmos.IncludedPath{{Path: "/*"}},
+		},
+		DefaultTimeToLive: &ttl,
+		UniqueKeyPolicy: &azcosmos.UniqueKeyPolicy{
+			UniqueKeys: []azcosmos.UniqueKey{{Paths: []string{"/email"}}},
+		},
+		ConflictResolutionPolicy: &azcosmos.ConflictResolutionPolicy{
+			Mode:                azcosmos.ConflictResolutionModeCustom,
+			ResolutionProcedure: "dbs/db/colls/source/sprocs/resolver",
+		},
+	}
+
+	clone := cloneContainerProperties(source, "target")
+
+	assert.Equal(t, "target", clone.ID)
+	assert.Equal(t, source.PartitionKeyDefinition, clone.PartitionKeyDefinition)
+	assert.Equal(t, source.IndexingPolicy, clone.IndexingPolicy)
+	assert.Equal(t, source.DefaultTimeToLive, clone.DefaultTimeToLive)
+	assert.Equal(t, source.UniqueKeyPolicy, clone.UniqueKeyPolicy)
+	assert.Empty(t, clone.ResourceID)
+	assert.Empty(t, clone.SelfLink)
+	assert.Nil(t, clone.ConflictResolutionPolicy)
+}
+
+func TestParseResourceUsage(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		expected    map[string]int64
+		expectError bool
+	}{
+		{
+			name:     "usage header",
+			header:   "functions=0;storedProcedures=0;triggers=0;documentSize=0;documentsSize=125;documentsCount=42;collectionSize=210;",
+			expected: map[string]int64{"functions": 0, "storedProcedures": 0, "triggers": 0, "documentSize": 0, "documentsSize": 125, "documentsCount": 42, "collectionSize": 210},
+		},
+		{name: "empty header", header: "", expected: map[string]int64{}},
+		{name: "missing value", header: "documentsCount", expectError: true},
+		{name: "not a number", header: "documentsCount=many", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage, err := parseResourceUsage(tt.header)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, usage)
+		})
+	}
+}
+
+func TestNewSpatialIndexingPolicy(t *testing.T) {
+	policy, err := newSpatialIndexingPolicy(nil)
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+
+	policy, err = newSpatialIndexingPolicy([]string{"/location", "/address/location/*", "/home/"})
+	require.NoError(t, err)
+	require.NotNil(t, policy)
+	assert.Equal(t, []azcosmos.IncludedPath{{Path: "/*"}}, policy.IncludedPaths)
+	assert.Equal(t, []azcosmos.SpatialIndex{
+		{Path: "/location/*", SpatialTypes: []azcosmos.SpatialType{azcosmos.SpatialTypePoint}},
+		{Path: "/address/location/*", SpatialTypes: []azcosmos.SpatialType{azcosmos.SpatialTypePoint}},
+		{Path: "/home/*", SpatialTypes: []azcosmos.SpatialType{azcosmos.SpatialTypePoint}},
+	}, policy.SpatialIndexes)
+
+	_, err = newSpatialIndexingPolicy([]string{"location"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "path must start with /")
+
+	_, err = newSpatialIndexingPolicy([]string{"/*"})
+	require.Error(t, err)
+}
+
+func TestWithItemTTL(t *testing.T) {
+	ttl := func(seconds int) *int { return &seconds }
+
+	tests := []struct {
+		name       string
+		item       string
+		ttlSeconds *int
+		expected   string
+		errorMsg   string
+	}{
+		{name: "no ttl", item: `{"id": "1"}`, expected: `{"id": "1"}`},
+		{name: "ttl added", item: `{"id": "1", "name": "a"}`, ttlSeconds: ttl(3600), expected: `{"id": "1", "name": "a", "ttl": 3600}`},
+		{name: "never expire", item: `{"id": "1"}`, ttlSeconds: ttl(-1), expected: `{"id": "1", "ttl": -1}`},
+		{name: "matching existing ttl", item: `{"id": "1", "ttl": 60}`, ttlSeconds: ttl(60), expected: `{"id": "1", "ttl": 60}`},
+		{name: "conflicting existing ttl", item: `{"id": "1", "ttl": 60}`, ttlSeconds: ttl(120), errorMsg: "item already has a ttl field (60) that does not match ttlSeconds (120)"},
+		{name: "zero ttl", item: `{"id": "1"}`, ttlSeconds: ttl(0), errorMsg: "ttlSeconds must be a positive number of seconds"},
+		{name: "negative ttl", item: `{"id": "1"}`, ttlSeconds: ttl(-5), errorMsg: "ttlSeconds must be a positive number of seconds"},
+		{name: "invalid JSON", item: `{"id": `, ttlSeconds: ttl(60), errorMsg: "invalid item JSON"},
+		{name: "not an object", item: `null`, ttlSeconds: ttl(60), errorMsg: "item must be a JSON object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := withItemTTL(tt.item, tt.ttlSeconds)
+			if tt.errorMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, item)
+		})
+	}
+}
+
+func TestParseExtendedContainerProperties(t *testing.T) {
+	extended, err := parseExtendedContainerProperties([]byte(`{
+		"id": "orders",
+		"geospatialConfig": {"type": "Geography"},
+		"computedProperties": [{"name": "cp_lower_name", "query": "SELECT VALUE LOWER(c.name) FROM c"}]
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"type": "Geography"}, extended.GeospatialConfig)
+	assert.Equal(t, []any{map[string]any{"name": "cp_lower_name", "query": "SELECT VALUE LOWER(c.name) FROM c"}}, extended.ComputedProperties)
+
+	extended, err = parseExtendedContainerProperties([]byte(`{"id": "orders"}`))
+	require.NoError(t, err)
+	assert.Nil(t, extended.GeospatialConfig)
+	assert.Nil(t, extended.ComputedProperties)
+
+	_, err = parseExtendedContainerProperties([]byte(`not json`))
+	require.Error(t, err)
+}
+
+func TestValidateAnalyticalStoreTTL(t *testing.T) {
+	ttl := func(v int32) *int32 { return &v }
+
+	assert.NoError(t, validateAnalyticalStoreTTL(nil))
+	assert.NoError(t, validateAnalyticalStoreTTL(ttl(-1)))
+	assert.NoError(t, validateAnalyticalStoreTTL(ttl(3600)))
+	assert.Error(t, validateAnalyticalStoreTTL(ttl(0)))
+	assert.Error(t, validateAnalyticalStoreTTL(ttl(-2)))
+}
+
+func TestNewContainerDetails(t *testing.T) {
+	ttl := int32(-1)
+
+	details := newContainerDetails(azcosmos.ContainerProperties{
+		ID: "orders",
+		PartitionKeyDefinition: azcosmos.PartitionKeyDefinition{
+			Kind:  azcosmos.PartitionKeyKindMultiHash,
+			Paths: []string{"/tenantId", "/userId"},
+		},
+		DefaultTimeToLive: &ttl,
+	})
+	assert.Equal(t, "orders", details.ID)
+	assert.Equal(t, []string{"/tenantId", "/userId"}, details.PartitionKeyPaths)
+	assert.Equal(t, "MultiHash", details.PartitionKeyKind)
+	require.NotNil(t, details.DefaultTTL)
+	assert.Equal(t, int32(-1), *details.DefaultTTL)
+
+	// paths are never null in the result, and TTL is left out when disabled
+	details = newContainerDetails(azcosmos.ContainerProperties{ID: "empty"})
+	assert.Equal(t, []string{}, details.PartitionKeyPaths)
+	assert.Nil(t, details.DefaultTTL)
+}
+
+// newPagingEmulator starts a fake emulator that answers every query with the body and a continuation token. cancel is called
+// after the given number of query pages were served, and paging only ends 10 pages later, so that a handler ignoring the
+// cancellation fails the test instead of hanging. The returned counter has the number of query pages served.
+func newPagingEmulator(t *testing.T, body string, pages int32, cancel context.CancelFunc) (ConnectionConfig, *atomic.Int32) {
+	served := &atomic.Int32{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the client reads the account properties first, any JSON is accepted
+		if r.Method == http.MethodPost {
+			count := served.Add(1)
+			if count == pages {
+				cancel()
+			}
+			if count < pages+10 {
+				w.Header().Set("x-ms-continuation", "next-page")
+			}
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return ConnectionConfig{UseEmulator: true, EmulatorEndpoint: server.URL}, served
+}
+
+func TestListContainersToolHandler_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, served := newPagingEmulator(t, `{"_rid":"","DocumentCollections":[{"id":"c1"}],"_count":1}`, 2, cancel)
+
+	_, _, err := ListContainersToolHandler(ctx, nil, ListContainersToolInput{ConnectionConfig: config, Database: "db1"})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(2), served.Load(), "paging should stop once the request context is cancelled")
+}
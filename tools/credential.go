@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// tokenPrefetchLead is how long before expiry a token is fetched in the background.
+// azidentity only refreshes a cached token in the last 5 minutes of its lifetime, so the lead has to be shorter than that.
+const tokenPrefetchLead = 4 * time.Minute
+
+// tokenPrefetchRetryInterval is how long the background refresh waits before trying again after a failed (or early) attempt
+const tokenPrefetchRetryInterval = 30 * time.Second
+
+// tokenPrefetchTimeout bounds a single background token request
+const tokenPrefetchTimeout = time.Minute
+
+var (
+	defaultCredentialMu sync.Mutex
+	defaultCredential   *refreshingCredential
+)
+
+// getDefaultCredential returns the DefaultAzureCredential shared by all clients that use Microsoft Entra ID authentication.
+// Clients are created for every tool call, sharing the credential lets them reuse the cached token instead of acquiring a new one.
+func getDefaultCredential() (azcore.TokenCredential, error) {
+	defaultCredentialMu.Lock()
+	defer defaultCredentialMu.Unlock()
+
+	if defaultCredential != nil {
+		return defaultCredential, nil
+	}
+
+	options := &azidentity.DefaultAzureCredentialOptions{}
+	options.Cloud = endpointSuffixCloud(getEndpointSuffix())
+
+	cred, err := azidentity.NewDefaultAzureCredential(options)
+	if err != nil {
+		return nil, fmt.Errorf("error creating credential: %v", err)
+	}
+
+	defaultCredential = newRefreshingCredential(cred)
+	return defaultCredential, nil
+}
+
+// endpointSuffixCloud returns the cloud whose Microsoft Entra ID authority issues tokens for accounts with the endpoint suffix.
+// The zero configuration means the public cloud, or the authority in the AZURE_AUTHORITY_HOST environment variable,
+// which is how the authority of other clouds is set.
+func endpointSuffixCloud(suffix string) cloud.Configuration {
+	if os.Getenv("AZURE_AUTHORITY_HOST") != "" {
+		return cloud.Configuration{}
+	}
+
+	switch strings.ToLower(suffix) {
+	case "documents.azure.us":
+		return cloud.AzureGovernment
+	case "documents.azure.cn":
+		return cloud.AzureChina
+	default:
+		return cloud.Configuration{}
+	}
+}
+
+// TokenRefreshes returns the number of times the shared credential refreshed an access token
+func TokenRefreshes() int64 {
+	defaultCredentialMu.Lock()
+	defer defaultCredentialMu.Unlock()
+
+	if defaultCredential == nil {
+		return 0
+	}
+	return defaultCredential.refreshes.Load()
+}
+
+// withAccountScope makes the credential request tokens for the account when the endpoint is not the one derived from
+// the account name. The SDK requests tokens for the host of the endpoint, which Microsoft Entra ID doesn't know when
+// the endpoint is a custom DNS name, e.g. for a private endpoint.
+func withAccountScope(cred azcore.TokenCredential, endpoint, account string) azcore.TokenCredential {
+	derived, err := url.Parse(accountEndpoint(account))
+	if err != nil {
+		return cred
+	}
+
+	parsed, err := url.Parse(endpoint)
+	if err != nil || account == "" || strings.EqualFold(parsed.Hostname(), derived.Hostname()) {
+		return cred
+	}
+
+	return &accountScopeCredential{cred: cred, scope: fmt.Sprintf("https://%s/.default", derived.Hostname())}
+}
+
+// accountScopeCredential requests tokens for a fixed scope instead of the scope asked for by the SDK
+type accountScopeCredential struct {
+	cred  azcore.TokenCredential
+	scope string
+}
+
+func (c *accountScopeCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	options.Scopes = []string{c.scope}
+	return c.cred.GetToken(ctx, options)
+}
+
+// refreshingCredential wraps a token credential and fetches a new token in the background shortly before the current one expires,
+// so that the first tool call after expiry does not pay for the token request. Refreshes are logged and counted.
+type refreshingCredential struct {
+	cred azcore.TokenCredential
+
+	mu        sync.Mutex
+	expiresOn map[string]time.Time // keyed by scopes
+
+	refreshes atomic.Int64
+}
+
+func newRefreshingCredential(cred azcore.TokenCredential) *refreshingCredential {
+	return &refreshingCredential{cred: cred, expiresOn: map[string]time.Time{}}
+}
+
+func (c *refreshingCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	token, err := c.cred.GetToken(ctx, options)
+	if err != nil {
+		return token, err
+	}
+
+	key := strings.Join(options.Scopes, " ")
+	if first, _ := c.track(key, token); first {
+		// claims are specific to a challenge, the background refresh only needs the scopes
+		go c.prefetch(key, policy.TokenRequestOptions{Scopes: options.Scopes, TenantID: options.TenantID, EnableCAE: options.EnableCAE})
+	}
+
+	return token, nil
+}
+
+// track records the expiry of a token. It reports whether this is the first token for the scopes,
+// and whether the token is a refreshed one (it expires later than the previous token).
+func (c *refreshingCredential) track(key string, token azcore.AccessToken) (first, refreshed bool) {
+	c.mu.Lock()
+	previous, seen := c.expiresOn[key]
+	if !seen || token.ExpiresOn.After(previous) {
+		c.expiresOn[key] = token.ExpiresOn
+	}
+	c.mu.Unlock()
+
+	if !seen {
+		return true, false
+	}
+
+	if token.ExpiresOn.After(previous) {
+		c.refreshes.Add(1)
+		log.Printf("Access token refreshed for %s, expires at %s", key, token.ExpiresOn.Format(time.RFC3339))
+		return false, true
+	}
+
+	return false, false
+}
+
+// prefetch keeps the token for the scopes fresh for the lifetime of the process
+func (c *refreshingCredential) prefetch(key string, options policy.TokenRequestOptions) {
+	for {
+		c.mu.Lock()
+		expiresOn := c.expiresOn[key]
+		c.mu.Unlock()
+
+		time.Sleep(prefetchDelay(expiresOn, time.Now()))
+
+		refreshed, err := c.refresh(key, options)
+		if err != nil {
+			log.Printf("Background token refresh for %s failed, retrying in %s: %v", key, tokenPrefetchRetryInterval, err)
+		}
+		if !refreshed {
+			time.Sleep(tokenPrefetchRetryInterval)
+		}
+	}
+}
+
+// refresh requests a token for the scopes, it reports whether a new token was returned
+func (c *refreshingCredential) refresh(key string, options policy.TokenRequestOptions) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenPrefetchTimeout)
+	defer cancel()
+
+	token, err := c.cred.GetToken(ctx, options)
+	if err != nil {
+		return false, err
+	}
+
+	_, refreshed := c.track(key, token)
+	return refreshed, nil
+}
+
+// prefetchDelay returns how long to wait before refreshing a token that expires at expiresOn
+func prefetchDelay(expiresOn, now time.Time) time.Duration {
+	return max(expiresOn.Add(-tokenPrefetchLead).Sub(now), 0)
+}
@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for the refreshing credential that do not need Microsoft Entra ID
+
+// fakeCredential returns the queued tokens in order
+type fakeCredential struct {
+	tokens []azcore.AccessToken
+	err    error
+	calls  int
+}
+
+func (f *fakeCredential) GetToken(_ context.Context, _ policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	if f.err != nil {
+		return azcore.AccessToken{}, f.err
+	}
+	token := f.tokens[min(f.calls, len(f.tokens)-1)]
+	f.calls++
+	return token, nil
+}
+
+func TestRefreshingCredential_Track(t *testing.T) {
+	now := time.Now()
+	cred := newRefreshingCredential(&fakeCredential{})
+
+	first, refreshed := cred.track("scope", azcore.AccessToken{ExpiresOn: now.Add(time.Hour)})
+	assert.True(t, first)
+	assert.False(t, refreshed)
+
+	// the cached token is returned again
+	first, refreshed = cred.track("scope", azcore.AccessToken{ExpiresOn: now.Add(time.Hour)})
+	assert.False(t, first)
+	assert.False(t, refreshed)
+
+	first, refreshed = cred.track("scope", azcore.AccessToken{ExpiresOn: now.Add(2 * time.Hour)})
+	assert.False(t, first)
+	assert.True(t, refreshed)
+
+	// scopes are tracked separately
+	first, _ = cred.track("other scope", azcore.AccessToken{ExpiresOn: now.Add(time.Hour)})
+	assert.True(t, first)
+
+	assert.Equal(t, int64(1), cred.refreshes.Load())
+}
+
+func TestRefreshingCredential_Refresh(t *testing.T) {
+	now := time.Now()
+	fake := &fakeCredential{tokens: []azcore.AccessToken{
+		{Token: "first", ExpiresOn: now.Add(time.Hour)},
+		{Token: "first", ExpiresOn: now.Add(time.Hour)},
+		{Token: "second", ExpiresOn: now.Add(2 * time.Hour)},
+	}}
+	cred := newRefreshingCredential(fake)
+	options := policy.TokenRequestOptions{Scopes: []string{"scope"}}
+
+	_, _ = cred.track("scope", fake.tokens[0])
+	fake.calls = 1
+
+	refreshed, err := cred.refresh("scope", options)
+	require.NoError(t, err)
+	assert.False(t, refreshed)
+
+	refreshed, err = cred.refresh("scope", options)
+	require.NoError(t, err)
+	assert.True(t, refreshed)
+	assert.Equal(t, int64(1), cred.refreshes.Load())
+
+	fake.err = errors.New("token request failed")
+	_, err = cred.refresh("scope", options)
+	require.Error(t, err)
+}
+
+func TestRefreshingCredential_GetToken(t *testing.T) {
+	fake := &fakeCredential{tokens: []azcore.AccessToken{{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}}}
+	cred := newRefreshingCredential(fake)
+
+	token, err := cred.GetToken(context.Background(), policy.TokenRequestOptions{Scopes: []string{"https://account.documents.azure.com/.default"}})
+	require.NoError(t, err)
+	assert.Equal(t, "token", token.Token)
+
+	fake.err = errors.New("token request failed")
+	_, err = cred.GetToken(context.Background(), policy.TokenRequestOptions{Scopes: []string{"other"}})
+	require.Error(t, err)
+}
+
+func TestPrefetchDelay(t *testing.T) {
+	now := time.Now()
+
+	assert.Equal(t, time.Hour-tokenPrefetchLead, prefetchDelay(now.Add(time.Hour), now))
+	assert.Equal(t, time.Duration(0), prefetchDelay(now.Add(time.Minute), now))
+	assert.Equal(t, time.Duration(0), prefetchDelay(now.Add(-time.Minute), now))
+}
+
+func TestEndpointSuffixCloud(t *testing.T) {
+	t.Setenv("AZURE_AUTHORITY_HOST", "")
+
+	assert.Equal(t, cloud.Configuration{}, endpointSuffixCloud(DefaultEndpointSuffix))
+	assert.Equal(t, cloud.AzureGovernment, endpointSuffixCloud("documents.azure.us"))
+	assert.Equal(t, cloud.AzureChina, endpointSuffixCloud("Documents.Azure.CN"))
+	assert.Equal(t, cloud.Configuration{}, endpointSuffixCloud("documents.example.net"))
+
+	t.Run("authority host set", func(t *testing.T) {
+		t.Setenv("AZURE_AUTHORITY_HOST", "https://login.example.net/")
+		assert.Equal(t, cloud.Configuration{}, endpointSuffixCloud("documents.azure.us"))
+	})
+}
+
+// scopesCredential records the scopes tokens are requested for
+type scopesCredential struct {
+	scopes []string
+}
+
+func (s *scopesCredential) GetToken(_ context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	s.scopes = options.Scopes
+	return azcore.AccessToken{Token: "token"}, nil
+}
+
+func TestWithAccountScope(t *testing.T) {
+	tests := []struct {
+		name           string
+		endpoint       string
+		expectedScopes []string
+	}{
+		{name: "derived endpoint", endpoint: "https://myaccount.documents.azure.com:443/", expectedScopes: []string{"https://myaccount.documents.azure.com/.default"}},
+		{name: "private endpoint", endpoint: "https://myaccount.privatelink.documents.azure.com:443/", expectedScopes: []string{"https://myaccount.documents.azure.com/.default"}},
+		{name: "custom dns", endpoint: "https://cosmos.internal.contoso.com/", expectedScopes: []string{"https://myaccount.documents.azure.com/.default"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &scopesCredential{}
+			cred := withAccountScope(recorder, tt.endpoint, "myaccount")
+
+			// the SDK asks for the scope of the endpoint host
+			parsed, err := url.Parse(tt.endpoint)
+			require.NoError(t, err)
+			_, err = cred.GetToken(context.Background(), policy.TokenRequestOptions{Scopes: []string{"https://" + parsed.Hostname() + "/.default"}})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedScopes, recorder.scopes)
+		})
+	}
+
+	t.Run("no account", func(t *testing.T) {
+		recorder := &scopesCredential{}
+		assert.Same(t, recorder, withAccountScope(recorder, "https://cosmos.internal.contoso.com/", ""))
+	})
+}
@@ -0,0 +1,232 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func QueryToCSV() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "query_to_csv",
+		Description: "Execute a SQL query on a container in Azure Cosmos DB or local emulator and return the results as CSV text with a header row, e.g. to paste into a spreadsheet. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Provide the columns to include, either top level (name) or nested (address.city) fields; if no columns are provided, the top level fields of the results are used. Missing fields are empty cells, nested objects and arrays are written as JSON. The same cross-partition query limitations as execute_query apply.",
+	}
+}
+
+type QueryToCSVToolInput struct {
+	ConnectionConfig
+	Database     string   `json:"database" jsonschema:"Name of the database"`
+	Container    string   `json:"container" jsonschema:"Name of the container to query"`
+	Query        string   `json:"query" jsonschema:"The SQL query string to execute"`
+	PartitionKey string   `json:"partitionKey,omitempty" jsonschema:"The partition key value for the query. If provided, the query will be scoped to this partition."`
+	SessionToken string   `json:"sessionToken,omitempty" jsonschema:"Session token returned by a previous write, to read your own writes under Session consistency (optional)"`
+	Columns      []string `json:"columns,omitempty" jsonschema:"Fields to write as CSV columns, in order, e.g. id, name, address.city (optional, defaults to the top level fields of the results)"`
+}
+
+type QueryToCSVToolResult struct {
+	Columns  []string `json:"columns" jsonschema:"Columns of the CSV, in order"`
+	RowCount int      `json:"row_count" jsonschema:"Number of rows, not counting the header row"`
+	CSV      string   `json:"csv" jsonschema:"The query results as CSV text with a header row"`
+}
+
+func QueryToCSVToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input QueryToCSVToolInput) (*mcp.CallToolResult, QueryToCSVToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, QueryToCSVToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, QueryToCSVToolResult{}, newInputError("database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, QueryToCSVToolResult{}, newInputError("container name missing")
+	}
+
+	if input.Query == "" {
+		return nil, QueryToCSVToolResult{}, newInputError("query string missing")
+	}
+
+	for _, column := range input.Columns {
+		if strings.TrimSpace(column) == "" {
+			return nil, QueryToCSVToolResult{}, newInputError("column name cannot be empty")
+		}
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, QueryToCSVToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, QueryToCSVToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, QueryToCSVToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	var partitionKey azcosmos.PartitionKey
+	if input.PartitionKey != "" {
+		partitionKey = azcosmos.NewPartitionKeyString(input.PartitionKey)
+	}
+
+	options := &azcosmos.QueryOptions{}
+	if input.SessionToken != "" {
+		options.SessionToken = &input.SessionToken
+	}
+
+	results, err := collectQueryResults(ctx, containerClient, input.Query, partitionKey, options)
+	if err != nil {
+		return nil, QueryToCSVToolResult{}, err
+	}
+
+	columns, text, err := resultsToCSV(results, input.Columns)
+	if err != nil {
+		return nil, QueryToCSVToolResult{}, err
+	}
+
+	// the CSV is returned as is in the text content, so that it does not need to be unescaped from JSON
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}
+
+	return result, QueryToCSVToolResult{
+		Columns:  columns,
+		RowCount: len(results),
+		CSV:      text,
+	}, nil
+}
+
+// resultsToCSV writes the query results as CSV with a header row. If no columns are given, the top level fields of
+// the results are used in the order they are first seen. Results that are not JSON objects (e.g. from SELECT VALUE)
+// are written in a single value column.
+func resultsToCSV(results [][]byte, columns []string) ([]string, string, error) {
+	rows := make([]any, 0, len(results))
+	for _, result := range results {
+		decoder := json.NewDecoder(bytes.NewReader(result))
+		// keep numbers as they are in the item, e.g. 1000000 instead of 1e+06
+		decoder.UseNumber()
+
+		var row any
+		if err := decoder.Decode(&row); err != nil {
+			return nil, "", fmt.Errorf("error parsing query result: %v", err)
+		}
+		rows = append(rows, row)
+	}
+
+	if len(columns) == 0 {
+		columns = resultColumns(rows)
+	}
+
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	if err := writer.Write(columns); err != nil {
+		return nil, "", err
+	}
+
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, column := range columns {
+			value, ok := csvValue(row, column)
+			if !ok {
+				record[i] = ""
+				continue
+			}
+
+			cell, err := csvCell(value)
+			if err != nil {
+				return nil, "", err
+			}
+			record[i] = cell
+		}
+
+		if err := writer.Write(record); err != nil {
+			return nil, "", err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, "", err
+	}
+
+	return columns, buffer.String(), nil
+}
+
+// valueColumn is the column used for results that are not JSON objects
+const valueColumn = "value"
+
+// resultColumns returns the top level fields of the results in the order they are first seen
+func resultColumns(rows []any) []string {
+	columns := []string{}
+	seen := map[string]bool{}
+
+	for _, row := range rows {
+		object, ok := row.(map[string]any)
+		if !ok {
+			object = map[string]any{valueColumn: row}
+		}
+
+		// the field order of the item is lost when decoding into a map, so the fields of each row are sorted
+		names := make([]string, 0, len(object))
+		for name := range object {
+			if !seen[name] {
+				names = append(names, name)
+			}
+		}
+		slices.Sort(names)
+
+		for _, name := range names {
+			seen[name] = true
+			columns = append(columns, name)
+		}
+	}
+
+	return columns
+}
+
+// csvValue returns the value of a column (name, address.city or /address/city) in a row
+func csvValue(row any, column string) (any, bool) {
+	object, ok := row.(map[string]any)
+	if !ok {
+		return row, column == valueColumn
+	}
+
+	if !strings.HasPrefix(column, "/") {
+		column = "/" + strings.ReplaceAll(column, ".", "/")
+	}
+	return valueAtPath(object, column)
+}
+
+// csvCell formats a value as a CSV cell: strings as is, nested objects and arrays as JSON
+func csvCell(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return fmt.Sprintf("%t", v), nil
+	default:
+		cell, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("error marshalling value to JSON: %v", err)
+		}
+		return string(cell), nil
+	}
+}
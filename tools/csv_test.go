@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for CSV helpers that do not need the emulator
+
+func TestResultsToCSV(t *testing.T) {
+	tests := []struct {
+		name            string
+		results         []string
+		columns         []string
+		expectedColumns []string
+		expectedCSV     string
+		expectError     bool
+	}{
+		{
+			name:            "selected columns",
+			results:         []string{`{"id": "1", "name": "Jane", "address": {"city": "Seattle"}}`, `{"id": "2", "name": "John"}`},
+			columns:         []string{"id", "address.city"},
+			expectedColumns: []string{"id", "address.city"},
+			expectedCSV:     "id,address.city\n1,Seattle\n2,\n",
+		},
+		{
+			name:            "columns from results",
+			results:         []string{`{"name": "Jane", "id": "1"}`, `{"id": "2", "age": 30}`},
+			expectedColumns: []string{"id", "name", "age"},
+			expectedCSV:     "id,name,age\n1,Jane,\n2,,30\n",
+		},
+		{
+			name:            "nested values as JSON",
+			results:         []string{`{"id": "1", "tags": ["a", "b"], "address": {"city": "Seattle"}}`},
+			columns:         []string{"id", "tags", "address"},
+			expectedColumns: []string{"id", "tags", "address"},
+			expectedCSV:     "id,tags,address\n1,\"[\"\"a\"\",\"\"b\"\"]\",\"{\"\"city\"\":\"\"Seattle\"\"}\"\n",
+		},
+		{
+			name:            "numbers, booleans and null",
+			results:         []string{`{"price": 1000000, "ratio": 0.5, "active": true, "note": null}`},
+			columns:         []string{"price", "ratio", "active", "note"},
+			expectedColumns: []string{"price", "ratio", "active", "note"},
+			expectedCSV:     "price,ratio,active,note\n1000000,0.5,true,\n",
+		},
+		{
+			name:            "values",
+			results:         []string{`"Jane"`, `"John, Jr."`},
+			expectedColumns: []string{"value"},
+			expectedCSV:     "value\nJane\n\"John, Jr.\"\n",
+		},
+		{
+			name:            "no results",
+			results:         []string{},
+			columns:         []string{"id"},
+			expectedColumns: []string{"id"},
+			expectedCSV:     "id\n",
+		},
+		{
+			name:        "invalid result",
+			results:     []string{`{"id": `},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := make([][]byte, 0, len(tt.results))
+			for _, result := range tt.results {
+				results = append(results, []byte(result))
+			}
+
+			columns, text, err := resultsToCSV(results, tt.columns)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedColumns, columns)
+			assert.Equal(t, tt.expectedCSV, text)
+		})
+	}
+}
@@ -2,8 +2,8 @@ package tools
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -42,9 +42,9 @@ func ListDatabasesToolHandler(ctx context.Context, request *mcp.CallToolRequest,
 	queryPager := client.NewQueryDatabasesPager("select * from dbs d", nil)
 
 	for queryPager.More() {
-		queryResponse, err := queryPager.NextPage(context.Background())
+		queryResponse, err := queryPager.NextPage(ctx)
 		if err != nil {
-			return nil, ListDatabasesToolResult{}, err
+			return nil, ListDatabasesToolResult{}, withDiagnostics(err)
 		}
 
 		for _, db := range queryResponse.Databases {
@@ -55,16 +55,122 @@ func ListDatabasesToolHandler(ctx context.Context, request *mcp.CallToolRequest,
 	return nil, ListDatabasesToolResult{Account: input.Account, Databases: databaseNames}, nil
 }
 
+// listDatabasesDetailedConcurrency is the maximum number of databases whose containers are counted concurrently
+const listDatabasesDetailedConcurrency = 4
+
+func ListDatabasesDetailed() *mcp.Tool {
+
+	return &mcp.Tool{
+		Name:        "list_databases_detailed",
+		Description: "List all databases in the specified Azure Cosmos DB account or local emulator along with the number of containers in each database. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Use this for a one-call overview of the account topology.",
+	}
+}
+
+type ListDatabasesDetailedToolInput struct {
+	ConnectionConfig
+}
+
+type DatabaseDetails struct {
+	Name           string `json:"name"`
+	ContainerCount int    `json:"container_count"`
+	Error          string `json:"error,omitempty" jsonschema:"Set if the containers of the database could not be counted"`
+}
+
+type ListDatabasesDetailedToolResult struct {
+	Account   string            `json:"account"`
+	Databases []DatabaseDetails `json:"databases" jsonschema:"list of databases in the account with their container counts"`
+}
+
+func ListDatabasesDetailedToolHandler(ctx context.Context, request *mcp.CallToolRequest, input ListDatabasesDetailedToolInput) (*mcp.CallToolResult, ListDatabasesDetailedToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, ListDatabasesDetailedToolResult{}, err
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, ListDatabasesDetailedToolResult{}, err
+	}
+
+	databaseNames := []string{}
+
+	queryPager := client.NewQueryDatabasesPager("select * from dbs d", nil)
+
+	for queryPager.More() {
+		queryResponse, err := queryPager.NextPage(ctx)
+		if err != nil {
+			return nil, ListDatabasesDetailedToolResult{}, withDiagnostics(err)
+		}
+
+		for _, db := range queryResponse.Databases {
+			databaseNames = append(databaseNames, db.ID)
+		}
+	}
+
+	// details are stored by index so the output follows the order of the databases
+	details := make([]DatabaseDetails, len(databaseNames))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < listDatabasesDetailedConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				details[i] = DatabaseDetails{Name: databaseNames[i]}
+				count, err := countContainers(ctx, client, databaseNames[i])
+				if err != nil {
+					details[i].Error = err.Error()
+					continue
+				}
+				details[i].ContainerCount = count
+			}
+		}()
+	}
+
+	for i := range databaseNames {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return nil, ListDatabasesDetailedToolResult{Account: input.Account, Databases: details}, nil
+}
+
+// countContainers returns the number of containers in the database
+func countContainers(ctx context.Context, client *azcosmos.Client, database string) (int, error) {
+	databaseClient, err := client.NewDatabase(database)
+	if err != nil {
+		return 0, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerPager := databaseClient.NewQueryContainersPager("select * from c", nil)
+
+	count := 0
+
+	for containerPager.More() {
+		containerResponse, err := containerPager.NextPage(ctx)
+		if err != nil {
+			return 0, withDiagnostics(err)
+		}
+		count += len(containerResponse.Containers)
+	}
+
+	return count, nil
+}
+
 func CreateDatabase() *mcp.Tool {
 	return &mcp.Tool{
 		Name:        "create_database",
-		Description: "Create a new database in the specified Azure Cosmos DB account or local emulator. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service.",
+		Description: "Create a new database in the specified Azure Cosmos DB account or local emulator. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Set waitUntilReady to true to wait until the new database can be read before returning, so that it can be used right away.",
 	}
 }
 
 type CreateDatabaseToolInput struct {
 	ConnectionConfig
-	Database string `json:"database" jsonschema:"Name of the database to create"`
+	Database       string `json:"database" jsonschema:"Name of the database to create"`
+	WaitUntilReady bool   `json:"waitUntilReady,omitempty" jsonschema:"Wait until the new database can be read before returning (optional, default false)"`
 }
 
 type CreateDatabaseToolResult struct {
@@ -80,7 +186,7 @@ func CreateDatabaseToolHandler(ctx context.Context, request *mcp.CallToolRequest
 	}
 
 	if input.Database == "" {
-		return nil, CreateDatabaseToolResult{}, errors.New("database name missing")
+		return nil, CreateDatabaseToolResult{}, newInputError("database name missing")
 	}
 
 	client, err := input.GetClient()
@@ -91,12 +197,39 @@ func CreateDatabaseToolHandler(ctx context.Context, request *mcp.CallToolRequest
 	databaseProps := azcosmos.DatabaseProperties{ID: input.Database}
 	_, err = client.CreateDatabase(ctx, databaseProps, nil)
 	if err != nil {
-		return nil, CreateDatabaseToolResult{}, fmt.Errorf("error creating database: %w", err)
+		return nil, CreateDatabaseToolResult{}, fmt.Errorf("error creating database: %w", withDiagnostics(err))
+	}
+
+	message := fmt.Sprintf("Database '%s' created successfully", input.Database)
+
+	if input.WaitUntilReady {
+		if err := waitForDatabase(ctx, client, input.Database); err != nil {
+			return nil, CreateDatabaseToolResult{}, err
+		}
+		message += " and is ready"
 	}
 
 	return nil, CreateDatabaseToolResult{
 		Account:  input.Account,
 		Database: input.Database,
-		Message:  fmt.Sprintf("Database '%s' created successfully", input.Database),
+		Message:  message,
 	}, nil
 }
+
+// waitForDatabase polls a database that was just created until it can be read
+func waitForDatabase(ctx context.Context, client *azcosmos.Client, database string) error {
+	databaseClient, err := client.NewDatabase(database)
+	if err != nil {
+		return fmt.Errorf("error creating database client: %v", err)
+	}
+
+	err = waitUntilReady(ctx, waitUntilReadyTimeout, func(ctx context.Context) error {
+		_, err := databaseClient.Read(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("database '%s' was created but is not ready: %w", database, withDiagnostics(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for database tools that do not need the emulator
+
+func TestListDatabasesToolHandler_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, served := newPagingEmulator(t, `{"_rid":"","Databases":[{"id":"db1"}],"_count":1}`, 2, cancel)
+
+	_, _, err := ListDatabasesToolHandler(ctx, nil, ListDatabasesToolInput{ConnectionConfig: config})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(2), served.Load(), "paging should stop once the request context is cancelled")
+}
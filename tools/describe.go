@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func DescribeTools() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "describe_tools",
+		Description: "Describe the tools available on this MCP server: name, description, input schema and example arguments for each tool. Provide a tool name to describe a single tool. Does not connect to Azure Cosmos DB. The example arguments show the required parameters with placeholder values (<database>, <container> etc.) that must be replaced with real values.",
+	}
+}
+
+type DescribeToolsToolInput struct {
+	Name string `json:"name,omitempty" jsonschema:"Name of the tool to describe (optional, all tools are described if not provided)"`
+}
+
+type DescribeToolsToolResult struct {
+	Tools []ToolDescription `json:"tools"`
+}
+
+// ToolDescription describes a registered tool along with example arguments derived from its input schema
+type ToolDescription struct {
+	Name             string         `json:"name"`
+	Description      string         `json:"description"`
+	InputSchema      any            `json:"input_schema" jsonschema:"JSON schema of the tool arguments"`
+	ExampleArguments map[string]any `json:"example_arguments" jsonschema:"Example arguments with the required parameters, placeholder values are in angle brackets"`
+}
+
+// ToolCatalog keeps track of the registered tools for describe_tools
+type ToolCatalog struct {
+	mu    sync.Mutex
+	tools []ToolDescription
+}
+
+// Add records a tool description in the catalog
+func (c *ToolCatalog) Add(description ToolDescription) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tools = append(c.tools, description)
+}
+
+// Tools returns the descriptions in the order the tools were added
+func (c *ToolCatalog) Tools() []ToolDescription {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.tools)
+}
+
+// DescribeTool builds the description of a tool. The input schema is inferred from In unless the tool already has one.
+func DescribeTool[In any](tool *mcp.Tool) (ToolDescription, error) {
+	schema, ok := tool.InputSchema.(*jsonschema.Schema)
+	if !ok || schema == nil {
+		var err error
+		schema, err = jsonschema.For[In](nil)
+		if err != nil {
+			return ToolDescription{}, fmt.Errorf("error inferring input schema for tool %s: %v", tool.Name, err)
+		}
+	}
+
+	return ToolDescription{
+		Name:             tool.Name,
+		Description:      tool.Description,
+		InputSchema:      schema,
+		ExampleArguments: exampleArguments(schema),
+	}, nil
+}
+
+// DescribeToolsToolHandler returns the handler for the describe_tools tool, describing the tools in the catalog
+func DescribeToolsToolHandler(catalog *ToolCatalog) mcp.ToolHandlerFor[DescribeToolsToolInput, DescribeToolsToolResult] {
+	return func(_ context.Context, _ *mcp.CallToolRequest, input DescribeToolsToolInput) (*mcp.CallToolResult, DescribeToolsToolResult, error) {
+		descriptions := catalog.Tools()
+
+		if input.Name == "" {
+			return nil, DescribeToolsToolResult{Tools: descriptions}, nil
+		}
+
+		for _, description := range descriptions {
+			if description.Name == input.Name {
+				return nil, DescribeToolsToolResult{Tools: []ToolDescription{description}}, nil
+			}
+		}
+
+		return nil, DescribeToolsToolResult{}, inputErrorf("tool '%s' not found", input.Name)
+	}
+}
+
+// exampleArguments returns example values for the required properties of an input schema
+func exampleArguments(schema *jsonschema.Schema) map[string]any {
+	arguments := map[string]any{}
+	for _, name := range schema.Required {
+		if property, ok := schema.Properties[name]; ok {
+			arguments[name] = exampleValue(name, property)
+		}
+	}
+	return arguments
+}
+
+// exampleValue returns a placeholder value matching the type of the property
+func exampleValue(name string, property *jsonschema.Schema) any {
+	switch schemaType(property) {
+	case "string":
+		return "<" + name + ">"
+	case "integer", "number":
+		return 1
+	case "boolean":
+		return true
+	case "array":
+		if property.Items != nil {
+			return []any{exampleValue(name, property.Items)}
+		}
+		return []any{}
+	case "object":
+		return exampleArguments(property)
+	default:
+		return nil
+	}
+}
+
+// schemaType returns the type of the schema, ignoring null for nullable types (e.g. pointer fields)
+func schemaType(schema *jsonschema.Schema) string {
+	if schema.Type != "" {
+		return schema.Type
+	}
+	for _, t := range schema.Types {
+		if t != "null" {
+			return t
+		}
+	}
+	return ""
+}
@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for describe_tools helpers that do not need the emulator
+
+func TestExampleArguments(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   *jsonschema.Schema
+		expected map[string]any
+	}{
+		{
+			name: "only required properties",
+			schema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"database":     {Type: "string"},
+					"partitionKey": {Type: "string"},
+				},
+				Required: []string{"database"},
+			},
+			expected: map[string]any{"database": "<database>"},
+		},
+		{
+			name: "placeholder per type",
+			schema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"maxItems":   {Type: "integer"},
+					"ratio":      {Type: "number"},
+					"confirm":    {Type: "boolean"},
+					"fields":     {Type: "array", Items: &jsonschema.Schema{Type: "string"}},
+					"empty":      {Type: "array"},
+					"throughput": {Types: []string{"null", "integer"}},
+				},
+				Required: []string{"maxItems", "ratio", "confirm", "fields", "empty", "throughput"},
+			},
+			expected: map[string]any{
+				"maxItems":   1,
+				"ratio":      1,
+				"confirm":    true,
+				"fields":     []any{"<fields>"},
+				"empty":      []any{},
+				"throughput": 1,
+			},
+		},
+		{
+			name: "nested object",
+			schema: &jsonschema.Schema{
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"operations": {Type: "array", Items: &jsonschema.Schema{
+						Type: "object",
+						Properties: map[string]*jsonschema.Schema{
+							"op":   {Type: "string"},
+							"path": {Type: "string"},
+						},
+						Required: []string{"op", "path"},
+					}},
+				},
+				Required: []string{"operations"},
+			},
+			expected: map[string]any{"operations": []any{map[string]any{"op": "<op>", "path": "<path>"}}},
+		},
+		{
+			name:     "no required properties",
+			schema:   &jsonschema.Schema{Type: "object"},
+			expected: map[string]any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, exampleArguments(tt.schema))
+		})
+	}
+}
+
+func TestDescribeTool(t *testing.T) {
+	tests := []struct {
+		name             string
+		tool             *mcp.Tool
+		expectedExamples map[string]any
+	}{
+		{
+			name:             "schema inferred from input",
+			tool:             SearchText(),
+			expectedExamples: map[string]any{"database": "<database>", "container": "<container>", "field": "<field>", "text": "<text>"},
+		},
+		{
+			name: "schema set on the tool",
+			tool: &mcp.Tool{
+				Name:        "custom",
+				Description: "custom tool",
+				InputSchema: &jsonschema.Schema{
+					Type:       "object",
+					Properties: map[string]*jsonschema.Schema{"query": {Type: "string"}},
+					Required:   []string{"query"},
+				},
+			},
+			expectedExamples: map[string]any{"query": "<query>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			description, err := DescribeTool[SearchTextToolInput](tt.tool)
+			require.NoError(t, err)
+			assert.Equal(t, tt.tool.Name, description.Name)
+			assert.Equal(t, tt.tool.Description, description.Description)
+			assert.NotNil(t, description.InputSchema)
+			assert.Equal(t, tt.expectedExamples, description.ExampleArguments)
+		})
+	}
+}
+
+func TestDescribeToolsToolHandler(t *testing.T) {
+	catalog := &ToolCatalog{}
+	for _, tool := range []*mcp.Tool{SearchText(), ServerInfo()} {
+		catalog.Add(ToolDescription{Name: tool.Name, Description: tool.Description})
+	}
+	handler := DescribeToolsToolHandler(catalog)
+
+	tests := []struct {
+		name          string
+		input         DescribeToolsToolInput
+		expectedNames []string
+		expectError   bool
+	}{
+		{name: "all tools", input: DescribeToolsToolInput{}, expectedNames: []string{"search_text", "server_info"}},
+		{name: "single tool", input: DescribeToolsToolInput{Name: "server_info"}, expectedNames: []string{"server_info"}},
+		{name: "unknown tool", input: DescribeToolsToolInput{Name: "does_not_exist"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, result, err := handler(context.Background(), nil, tt.input)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			names := []string{}
+			for _, tool := range result.Tools {
+				names = append(names, tool.Name)
+			}
+			assert.Equal(t, tt.expectedNames, names)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// maxDiagnosticsRequests caps the number of requests listed in the diagnostics of a tool call, e.g. for bulk operations.
+// The totals always cover all requests.
+const maxDiagnosticsRequests = 100
+
+// RequestDiagnostics describes a single attempt of a request sent to Azure Cosmos DB
+type RequestDiagnostics struct {
+	Method        string  `json:"method"`
+	Endpoint      string  `json:"endpoint" jsonschema:"The endpoint (regional or global) the request was sent to"`
+	Path          string  `json:"path"`
+	Attempt       int     `json:"attempt" jsonschema:"1 for the first attempt of the request, higher for retries by the SDK"`
+	StatusCode    int     `json:"status_code,omitempty"`
+	SubStatusCode string  `json:"substatus_code,omitempty"`
+	DurationMs    float64 `json:"duration_ms"`
+	RequestCharge float64 `json:"request_charge,omitempty"`
+	ActivityID    string  `json:"activity_id,omitempty"`
+	Error         string  `json:"error,omitempty" jsonschema:"Set if no response was received, e.g. a connection error or timeout"`
+}
+
+// CallDiagnostics summarizes the requests sent to Azure Cosmos DB during a tool call
+type CallDiagnostics struct {
+	DurationMs         float64              `json:"duration_ms" jsonschema:"Duration of the tool call"`
+	RequestsDurationMs float64              `json:"requests_duration_ms" jsonschema:"Total time spent waiting for Azure Cosmos DB, the rest was spent in the MCP server"`
+	Requests           int                  `json:"requests" jsonschema:"Number of requests sent, including retries"`
+	Retries            int                  `json:"retries" jsonschema:"Number of requests that were retries of a failed attempt"`
+	RequestCharge      float64              `json:"request_charge" jsonschema:"Total request charge (RU) of the requests"`
+	Endpoints          []string             `json:"endpoints" jsonschema:"Endpoints contacted"`
+	RequestLog         []RequestDiagnostics `json:"request_log" jsonschema:"The requests in the order they were sent, at most 100"`
+}
+
+// DiagnosticsRecorder collects the requests sent to Azure Cosmos DB during a tool call
+type DiagnosticsRecorder struct {
+	start time.Time
+
+	mu       sync.Mutex
+	requests []RequestDiagnostics
+	summary  CallDiagnostics
+}
+
+type diagnosticsRecorderKey struct{}
+
+// WithDiagnosticsRecorder returns a context that records the requests sent to Azure Cosmos DB with it
+func WithDiagnosticsRecorder(ctx context.Context) (context.Context, *DiagnosticsRecorder) {
+	recorder := &DiagnosticsRecorder{start: time.Now()}
+	return context.WithValue(ctx, diagnosticsRecorderKey{}, recorder), recorder
+}
+
+// DiagnosticsRequested reports whether the arguments of a tool call set diagnostics to true
+func DiagnosticsRequested(arguments json.RawMessage) bool {
+	var config struct {
+		Diagnostics bool `json:"diagnostics"`
+	}
+	return json.Unmarshal(arguments, &config) == nil && config.Diagnostics
+}
+
+func (r *DiagnosticsRecorder) record(request RequestDiagnostics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.summary.Requests++
+	if request.Attempt > 1 {
+		r.summary.Retries++
+	}
+	r.summary.RequestsDurationMs += request.DurationMs
+	r.summary.RequestCharge += request.RequestCharge
+	if !slices.Contains(r.summary.Endpoints, request.Endpoint) {
+		r.summary.Endpoints = append(r.summary.Endpoints, request.Endpoint)
+	}
+
+	if len(r.requests) < maxDiagnosticsRequests {
+		r.requests = append(r.requests, request)
+	}
+}
+
+// Summary returns the diagnostics of the requests recorded so far
+func (r *DiagnosticsRecorder) Summary() CallDiagnostics {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	summary := r.summary
+	summary.DurationMs = milliseconds(time.Since(r.start))
+	summary.Endpoints = append([]string{}, r.summary.Endpoints...)
+	summary.RequestLog = append([]RequestDiagnostics{}, r.requests...)
+	return summary
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// diagnosticsAttempts counts the attempts of a request, it is shared by the retries of the request
+type diagnosticsAttempts struct {
+	count int
+}
+
+// diagnosticsCallPolicy starts counting the attempts of a request when its tool call records diagnostics.
+// It runs once per request, before the retry policy.
+type diagnosticsCallPolicy struct{}
+
+func (diagnosticsCallPolicy) Do(req *policy.Request) (*http.Response, error) {
+	if _, ok := req.Raw().Context().Value(diagnosticsRecorderKey{}).(*DiagnosticsRecorder); ok {
+		req.SetOperationValue(&diagnosticsAttempts{})
+	}
+	return req.Next()
+}
+
+// diagnosticsPolicy records each attempt of a request when its tool call records diagnostics. It runs after the retry policy.
+type diagnosticsPolicy struct{}
+
+func (diagnosticsPolicy) Do(req *policy.Request) (*http.Response, error) {
+	recorder, ok := req.Raw().Context().Value(diagnosticsRecorderKey{}).(*DiagnosticsRecorder)
+	if !ok {
+		return req.Next()
+	}
+
+	request := RequestDiagnostics{
+		Method:   req.Raw().Method,
+		Endpoint: req.Raw().URL.Scheme + "://" + req.Raw().URL.Host,
+		Path:     req.Raw().URL.Path,
+		Attempt:  1,
+	}
+
+	var attempts *diagnosticsAttempts
+	if req.OperationValue(&attempts) {
+		attempts.count++
+		request.Attempt = attempts.count
+	}
+
+	start := time.Now()
+	resp, err := req.Next()
+	request.DurationMs = milliseconds(time.Since(start))
+
+	if err != nil {
+		request.Error = err.Error()
+	}
+	if resp != nil {
+		request.StatusCode = resp.StatusCode
+		request.SubStatusCode = resp.Header.Get("x-ms-substatus")
+		request.ActivityID = resp.Header.Get("x-ms-activity-id")
+		request.RequestCharge, _ = strconv.ParseFloat(resp.Header.Get("x-ms-request-charge"), 64)
+	}
+
+	recorder.record(request)
+
+	return resp, err
+}
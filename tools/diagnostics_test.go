@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for request diagnostics that do not need the emulator
+
+// throttlingTransport throttles the first request it receives and accepts the rest
+type throttlingTransport struct {
+	requests int
+}
+
+func (t *throttlingTransport) Do(req *http.Request) (*http.Response, error) {
+	t.requests++
+	response := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody, Request: req}
+	response.Header.Set("x-ms-activity-id", "activity-"+strconv.Itoa(t.requests))
+	response.Header.Set("x-ms-request-charge", "2.5")
+	if t.requests == 1 {
+		response.StatusCode = http.StatusTooManyRequests
+		response.Header.Set("x-ms-substatus", "3200")
+		response.Header.Set("Retry-After", "0")
+	}
+	return response, nil
+}
+
+func newDiagnosticsTestPipeline(transport policy.Transporter) runtime.Pipeline {
+	return runtime.NewPipeline("test", "v1", runtime.PipelineOptions{}, &policy.ClientOptions{
+		Transport:        transport,
+		PerCallPolicies:  []policy.Policy{diagnosticsCallPolicy{}},
+		PerRetryPolicies: []policy.Policy{diagnosticsPolicy{}},
+		Retry:            policy.RetryOptions{RetryDelay: time.Millisecond, MaxRetryDelay: time.Millisecond},
+	})
+}
+
+func TestDiagnosticsPolicy(t *testing.T) {
+	pipeline := newDiagnosticsTestPipeline(&throttlingTransport{})
+
+	ctx, recorder := WithDiagnosticsRecorder(context.Background())
+
+	for range 2 {
+		req, err := runtime.NewRequest(ctx, http.MethodGet, "https://account1-westus.documents.azure.com/dbs/db1")
+		require.NoError(t, err)
+		_, err = pipeline.Do(req)
+		require.NoError(t, err)
+	}
+
+	summary := recorder.Summary()
+	assert.Equal(t, 3, summary.Requests)
+	assert.Equal(t, 1, summary.Retries)
+	assert.Equal(t, 7.5, summary.RequestCharge)
+	assert.Equal(t, []string{"https://account1-westus.documents.azure.com"}, summary.Endpoints)
+	require.Len(t, summary.RequestLog, 3)
+
+	throttled := summary.RequestLog[0]
+	assert.Equal(t, http.MethodGet, throttled.Method)
+	assert.Equal(t, "/dbs/db1", throttled.Path)
+	assert.Equal(t, 1, throttled.Attempt)
+	assert.Equal(t, http.StatusTooManyRequests, throttled.StatusCode)
+	assert.Equal(t, "3200", throttled.SubStatusCode)
+	assert.Equal(t, "activity-1", throttled.ActivityID)
+
+	assert.Equal(t, 2, summary.RequestLog[1].Attempt, "the retry of the throttled request")
+	assert.Equal(t, 1, summary.RequestLog[2].Attempt, "the second request")
+	assert.GreaterOrEqual(t, summary.DurationMs, summary.RequestsDurationMs)
+}
+
+func TestDiagnosticsPolicy_NotRequested(t *testing.T) {
+	transport := &throttlingTransport{}
+	pipeline := newDiagnosticsTestPipeline(transport)
+
+	req, err := runtime.NewRequest(context.Background(), http.MethodGet, "https://account1.documents.azure.com/dbs")
+	require.NoError(t, err)
+	_, err = pipeline.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, 2, transport.requests)
+}
+
+func TestDiagnosticsRecorder_MaxRequests(t *testing.T) {
+	_, recorder := WithDiagnosticsRecorder(context.Background())
+	for range maxDiagnosticsRequests + 5 {
+		recorder.record(RequestDiagnostics{Endpoint: "https://account1.documents.azure.com", Attempt: 1})
+	}
+
+	summary := recorder.Summary()
+	assert.Equal(t, maxDiagnosticsRequests+5, summary.Requests)
+	assert.Len(t, summary.RequestLog, maxDiagnosticsRequests)
+
+	// the summary serializes with empty lists rather than null
+	_, empty := WithDiagnosticsRecorder(context.Background())
+	encoded, err := json.Marshal(empty.Summary())
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"endpoints":[]`)
+}
+
+func TestDiagnosticsRequested(t *testing.T) {
+	assert.True(t, DiagnosticsRequested(json.RawMessage(`{"account": "a", "diagnostics": true}`)))
+	assert.False(t, DiagnosticsRequested(json.RawMessage(`{"account": "a"}`)))
+	assert.False(t, DiagnosticsRequested(json.RawMessage(`{"diagnostics": "yes"}`)))
+	assert.False(t, DiagnosticsRequested(nil))
+}
@@ -0,0 +1,245 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func DiffContainers() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "diff_containers",
+		Description: "Compare the configuration of two containers in Azure Cosmos DB or local emulator, e.g. to check that a staging container matches production. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Compares the partition key definition, default and analytical store TTL, indexing policy (mode, included and excluded paths, composite and spatial indexes), unique keys and conflict resolution policy, and returns the properties that differ with the value of each container. The order of paths and indexes is ignored. The second container can be in another database (otherDatabase) or account (otherAccount), both default to the first container's. Does not modify anything.",
+	}
+}
+
+type DiffContainersToolInput struct {
+	ConnectionConfig
+	Database       string `json:"database" jsonschema:"Name of the database of the first container"`
+	Container      string `json:"container" jsonschema:"Name of the first container"`
+	OtherAccount   string `json:"otherAccount,omitempty" jsonschema:"Account of the second container (optional, defaults to the account of the first container)"`
+	OtherDatabase  string `json:"otherDatabase,omitempty" jsonschema:"Database of the second container (optional, defaults to the database of the first container)"`
+	OtherContainer string `json:"otherContainer" jsonschema:"Name of the second container"`
+}
+
+type ContainerDifference struct {
+	Property string `json:"property" jsonschema:"The property that differs, e.g. indexing_policy.excluded_paths"`
+	Left     any    `json:"left" jsonschema:"Value of the first container, null if not set"`
+	Right    any    `json:"right" jsonschema:"Value of the second container, null if not set"`
+}
+
+type DiffContainersToolResult struct {
+	Left        string                `json:"left" jsonschema:"The first container as account/database/container"`
+	Right       string                `json:"right" jsonschema:"The second container as account/database/container"`
+	Identical   bool                  `json:"identical" jsonschema:"Whether all compared properties are the same"`
+	Differences []ContainerDifference `json:"differences"`
+}
+
+func DiffContainersToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input DiffContainersToolInput) (*mcp.CallToolResult, DiffContainersToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, DiffContainersToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, DiffContainersToolResult{}, newInputError("database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, DiffContainersToolResult{}, newInputError("container name missing")
+	}
+
+	if input.OtherContainer == "" {
+		return nil, DiffContainersToolResult{}, newInputError("other container name missing")
+	}
+
+	otherConfig := input.ConnectionConfig
+	if input.OtherAccount != "" {
+		otherConfig.Account = input.OtherAccount
+	}
+
+	otherDatabase := input.OtherDatabase
+	if otherDatabase == "" {
+		otherDatabase = input.Database
+	}
+
+	if otherConfig == input.ConnectionConfig && otherDatabase == input.Database && input.OtherContainer == input.Container {
+		return nil, DiffContainersToolResult{}, newInputError("the containers to compare are the same container")
+	}
+
+	left, err := readContainerProperties(ctx, input.ConnectionConfig, input.Database, input.Container)
+	if err != nil {
+		return nil, DiffContainersToolResult{}, err
+	}
+
+	right, err := readContainerProperties(ctx, otherConfig, otherDatabase, input.OtherContainer)
+	if err != nil {
+		return nil, DiffContainersToolResult{}, err
+	}
+
+	differences := diffContainerProperties(left, right)
+
+	return nil, DiffContainersToolResult{
+		Left:        fmt.Sprintf("%s/%s/%s", input.Account, input.Database, input.Container),
+		Right:       fmt.Sprintf("%s/%s/%s", otherConfig.Account, otherDatabase, input.OtherContainer),
+		Identical:   len(differences) == 0,
+		Differences: differences,
+	}, nil
+}
+
+// readContainerProperties reads the properties of a container, the connection config is validated when creating the client
+func readContainerProperties(ctx context.Context, config ConnectionConfig, database, container string) (azcosmos.ContainerProperties, error) {
+	if err := config.Validate(); err != nil {
+		return azcosmos.ContainerProperties{}, err
+	}
+
+	client, err := config.GetClient()
+	if err != nil {
+		return azcosmos.ContainerProperties{}, err
+	}
+
+	containerClient, err := client.NewContainer(database, container)
+	if err != nil {
+		return azcosmos.ContainerProperties{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	response, err := containerClient.Read(ctx, nil)
+	if err != nil {
+		return azcosmos.ContainerProperties{}, fmt.Errorf("error reading container '%s' in database '%s': %w", container, database, withDiagnostics(err))
+	}
+
+	if response.ContainerProperties == nil {
+		return azcosmos.ContainerProperties{}, fmt.Errorf("error reading container '%s' in database '%s': empty response", container, database)
+	}
+
+	return *response.ContainerProperties, nil
+}
+
+// containerSetting is a compared container property, in a normalized form that does not depend on the order of paths and indexes
+type containerSetting struct {
+	property string
+	value    any
+}
+
+// diffContainerProperties returns the settings that differ between the containers, in a fixed order
+func diffContainerProperties(left, right azcosmos.ContainerProperties) []ContainerDifference {
+	leftSettings := containerSettings(left)
+	rightSettings := containerSettings(right)
+
+	differences := []ContainerDifference{}
+	for i := range leftSettings {
+		if !reflect.DeepEqual(leftSettings[i].value, rightSettings[i].value) {
+			differences = append(differences, ContainerDifference{
+				Property: leftSettings[i].property,
+				Left:     leftSettings[i].value,
+				Right:    rightSettings[i].value,
+			})
+		}
+	}
+
+	return differences
+}
+
+// containerSettings normalizes the compared properties of a container. Unset values are nil.
+func containerSettings(properties azcosmos.ContainerProperties) []containerSetting {
+	partitionKey := properties.PartitionKeyDefinition
+
+	// the kind is inferred from the number of paths when not set
+	kind := string(partitionKey.Kind)
+	if kind == "" {
+		kind = string(azcosmos.PartitionKeyKindHash)
+		if len(partitionKey.Paths) > 1 {
+			kind = string(azcosmos.PartitionKeyKindMultiHash)
+		}
+	}
+
+	var defaultTTL, analyticalStoreTTL any
+	if properties.DefaultTimeToLive != nil {
+		defaultTTL = *properties.DefaultTimeToLive
+	}
+	if properties.AnalyticalStoreTimeToLiveInSeconds != nil {
+		analyticalStoreTTL = *properties.AnalyticalStoreTimeToLiveInSeconds
+	}
+
+	settings := []containerSetting{
+		{"partition_key.paths", nonNilStrings(partitionKey.Paths)},
+		{"partition_key.kind", kind},
+		{"partition_key.version", partitionKey.Version},
+		{"default_ttl", defaultTTL},
+		{"analytical_store_ttl", analyticalStoreTTL},
+	}
+
+	var indexingMode, automatic any
+	includedPaths, excludedPaths, compositeIndexes, spatialIndexes := []string{}, []string{}, []string{}, []string{}
+
+	if policy := properties.IndexingPolicy; policy != nil {
+		indexingMode = strings.ToLower(string(policy.IndexingMode))
+		automatic = policy.Automatic
+
+		for _, path := range policy.IncludedPaths {
+			includedPaths = append(includedPaths, path.Path)
+		}
+		for _, path := range policy.ExcludedPaths {
+			excludedPaths = append(excludedPaths, path.Path)
+		}
+		for _, composite := range policy.CompositeIndexes {
+			paths := make([]string, len(composite))
+			for i, index := range composite {
+				order := index.Order
+				if order == "" {
+					order = azcosmos.CompositeIndexAscending
+				}
+				paths[i] = fmt.Sprintf("%s %s", index.Path, order)
+			}
+			compositeIndexes = append(compositeIndexes, strings.Join(paths, ", "))
+		}
+		for _, spatial := range policy.SpatialIndexes {
+			types := make([]string, len(spatial.SpatialTypes))
+			for i, spatialType := range spatial.SpatialTypes {
+				types[i] = string(spatialType)
+			}
+			slices.Sort(types)
+			spatialIndexes = append(spatialIndexes, fmt.Sprintf("%s (%s)", spatial.Path, strings.Join(types, ", ")))
+		}
+	}
+
+	uniqueKeys := []string{}
+	if policy := properties.UniqueKeyPolicy; policy != nil {
+		for _, key := range policy.UniqueKeys {
+			uniqueKeys = append(uniqueKeys, strings.Join(key.Paths, ", "))
+		}
+	}
+
+	for _, list := range [][]string{includedPaths, excludedPaths, compositeIndexes, spatialIndexes, uniqueKeys} {
+		slices.Sort(list)
+	}
+
+	var conflictResolution any
+	if policy := properties.ConflictResolutionPolicy; policy != nil {
+		conflictResolution = fmt.Sprintf("mode=%s path=%s procedure=%s", policy.Mode, policy.ResolutionPath, policy.ResolutionProcedure)
+	}
+
+	return append(settings,
+		containerSetting{"indexing_policy.indexing_mode", indexingMode},
+		containerSetting{"indexing_policy.automatic", automatic},
+		containerSetting{"indexing_policy.included_paths", includedPaths},
+		containerSetting{"indexing_policy.excluded_paths", excludedPaths},
+		containerSetting{"indexing_policy.composite_indexes", compositeIndexes},
+		containerSetting{"indexing_policy.spatial_indexes", spatialIndexes},
+		containerSetting{"unique_keys", uniqueKeys},
+		containerSetting{"conflict_resolution_policy", conflictResolution},
+	)
+}
+
+// nonNilStrings returns an empty list instead of nil, so that unset and empty lists compare equal
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
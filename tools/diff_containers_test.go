@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/stretchr/testify/assert"
+)
+
+// Unit tests for diff_containers helpers that do not need the emulator
+
+func TestDiffContainerProperties(t *testing.T) {
+	ttl := int32(3600)
+
+	base := func() azcosmos.ContainerProperties {
+		return azcosmos.ContainerProperties{
+			ID:                     "orders",
+			PartitionKeyDefinition: azcosmos.PartitionKeyDefinition{Paths: []string{"/customerId"}, Version: 2},
+			DefaultTimeToLive:      &ttl,
+			IndexingPolicy: &azcosmos.IndexingPolicy{
+				Automatic:     true,
+				IndexingMode:  azcosmos.IndexingModeConsistent,
+				IncludedPaths: []azcosmos.IncludedPath{{Path: "/*"}},
+				ExcludedPaths: []azcosmos.ExcludedPath{{Path: "/payload/*"}, {Path: "/\"_etag\"/?"}},
+				CompositeIndexes: [][]azcosmos.CompositeIndex{
+					{{Path: "/name", Order: azcosmos.CompositeIndexAscending}, {Path: "/age", Order: azcosmos.CompositeIndexDescending}},
+				},
+			},
+			UniqueKeyPolicy: &azcosmos.UniqueKeyPolicy{UniqueKeys: []azcosmos.UniqueKey{{Paths: []string{"/email"}}}},
+		}
+	}
+
+	t.Run("identical", func(t *testing.T) {
+		right := base()
+		right.ID = "orders_staging"
+		// order of paths is ignored, and an unset kind is inferred
+		right.PartitionKeyDefinition.Kind = azcosmos.PartitionKeyKindHash
+		right.IndexingPolicy.ExcludedPaths = []azcosmos.ExcludedPath{{Path: "/\"_etag\"/?"}, {Path: "/payload/*"}}
+		right.IndexingPolicy.IndexingMode = "Consistent"
+
+		assert.Empty(t, diffContainerProperties(base(), right))
+	})
+
+	t.Run("differences", func(t *testing.T) {
+		right := base()
+		right.DefaultTimeToLive = nil
+		right.IndexingPolicy.ExcludedPaths = []azcosmos.ExcludedPath{{Path: "/\"_etag\"/?"}}
+		right.IndexingPolicy.CompositeIndexes = nil
+		right.UniqueKeyPolicy = nil
+		right.PartitionKeyDefinition.Paths = []string{"/tenantId", "/customerId"}
+
+		assert.Equal(t, []ContainerDifference{
+			{Property: "partition_key.paths", Left: []string{"/customerId"}, Right: []string{"/tenantId", "/customerId"}},
+			{Property: "partition_key.kind", Left: "Hash", Right: "MultiHash"},
+			{Property: "default_ttl", Left: int32(3600), Right: nil},
+			{Property: "indexing_policy.excluded_paths", Left: []string{"/\"_etag\"/?", "/payload/*"}, Right: []string{"/\"_etag\"/?"}},
+			{Property: "indexing_policy.composite_indexes", Left: []string{"/name ascending, /age descending"}, Right: []string{}},
+			{Property: "unique_keys", Left: []string{"/email"}, Right: []string{}},
+		}, diffContainerProperties(base(), right))
+	})
+
+	t.Run("no indexing policy", func(t *testing.T) {
+		right := base()
+		right.IndexingPolicy = nil
+
+		differences := diffContainerProperties(base(), right)
+		properties := []string{}
+		for _, difference := range differences {
+			properties = append(properties, difference.Property)
+		}
+		assert.Equal(t, []string{
+			"indexing_policy.indexing_mode",
+			"indexing_policy.automatic",
+			"indexing_policy.included_paths",
+			"indexing_policy.excluded_paths",
+			"indexing_policy.composite_indexes",
+		}, properties)
+	})
+}
@@ -0,0 +1,845 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultDryRunSampleSize is the number of items sampled by dry_run_query when the sample size is not specified
+const defaultDryRunSampleSize = 100
+
+// maxDryRunSampleSize is the upper bound for the sampleSize argument of dry_run_query
+const maxDryRunSampleSize = 1000
+
+// maxDryRunMatchingIDs is the number of matching item IDs returned by dry_run_query
+const maxDryRunMatchingIDs = 10
+
+// dryRunFunctions are the functions dry_run_query can evaluate
+var dryRunFunctions = []string{"IS_DEFINED", "IS_NULL", "IS_STRING", "IS_NUMBER", "IS_BOOL", "IS_ARRAY", "IS_OBJECT", "CONTAINS", "STARTSWITH", "ENDSWITH", "LOWER", "UPPER", "ARRAY_CONTAINS", "ARRAY_LENGTH"}
+
+func DryRunQuery() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "dry_run_query",
+		Description: "Try the WHERE condition of a query against a small sample of items of a container in Azure Cosmos DB or local emulator, to refine a filter before running the real query with execute_query. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Fetches up to sampleSize items (default 100, maximum 1000), from a single partition if partitionKey is provided, and evaluates the condition in the MCP server, reporting how many sampled items match, how many make the condition undefined (e.g. a missing field or a comparison between different types - Azure Cosmos DB does not return these items either) and the IDs of the first matching items. Only a subset of the query language is supported: field references like c.status or c.address.city, string, number, boolean and null literals, the comparison operators =, !=, <>, <, <=, >, >=, IN, BETWEEN, AND, OR, NOT, parentheses, and the functions " + strings.Join(dryRunFunctions, ", ") + ". Query parameters, JOIN and subqueries are not supported. The sample is the first items returned by the container, not a random sample, so the share of matching items is only an indication.",
+	}
+}
+
+type DryRunQueryToolInput struct {
+	ConnectionConfig
+	Database     string `json:"database" jsonschema:"Name of the database"`
+	Container    string `json:"container" jsonschema:"Name of the container"`
+	Query        string `json:"query" jsonschema:"The query whose WHERE condition is evaluated, example SELECT * FROM c WHERE c.status = 'active' AND c.total > 100"`
+	PartitionKey string `json:"partitionKey,omitempty" jsonschema:"Optional partition key value to sample items from a single partition"`
+	SampleSize   int    `json:"sampleSize,omitempty" jsonschema:"Number of items to sample (default 100, maximum 1000)"`
+}
+
+type DryRunQueryToolResult struct {
+	Database     string   `json:"database"`
+	Container    string   `json:"container"`
+	Filter       string   `json:"filter" jsonschema:"The WHERE condition that was evaluated, empty if the query has none"`
+	ItemsSampled int      `json:"items_sampled" jsonschema:"Number of items the condition was evaluated on"`
+	Matched      int      `json:"matched" jsonschema:"Number of sampled items that match the condition"`
+	Undefined    int      `json:"undefined" jsonschema:"Number of sampled items for which the condition is undefined, e.g. because a field is missing or has another type"`
+	MatchRatio   float64  `json:"match_ratio" jsonschema:"Share of the sampled items that match, between 0 and 1"`
+	MatchingIDs  []string `json:"matching_ids" jsonschema:"IDs of the first matching items"`
+	Message      string   `json:"message"`
+}
+
+func DryRunQueryToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input DryRunQueryToolInput) (*mcp.CallToolResult, DryRunQueryToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, DryRunQueryToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, DryRunQueryToolResult{}, newInputError("database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, DryRunQueryToolResult{}, newInputError("container name missing")
+	}
+
+	if input.Query == "" {
+		return nil, DryRunQueryToolResult{}, newInputError("query missing")
+	}
+
+	if input.SampleSize < 0 {
+		return nil, DryRunQueryToolResult{}, newInputError("sampleSize must not be negative")
+	}
+
+	sampleSize := input.SampleSize
+	if sampleSize == 0 {
+		sampleSize = defaultDryRunSampleSize
+	}
+	if sampleSize > maxDryRunSampleSize {
+		sampleSize = maxDryRunSampleSize
+	}
+
+	filter, condition, err := parseDryRunQuery(input.Query)
+	if err != nil {
+		return nil, DryRunQueryToolResult{}, err
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, DryRunQueryToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, DryRunQueryToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, DryRunQueryToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	var partitionKey azcosmos.PartitionKey
+	if input.PartitionKey != "" {
+		partitionKey = azcosmos.NewPartitionKeyString(input.PartitionKey)
+	}
+
+	items, err := sampleItems(ctx, containerClient, partitionKey, sampleSize)
+	if err != nil {
+		return nil, DryRunQueryToolResult{}, err
+	}
+
+	result := evaluateDryRun(items, condition)
+	result.Database = input.Database
+	result.Container = input.Container
+	result.Filter = filter
+
+	switch {
+	case result.ItemsSampled == 0:
+		result.Message = "The container has no items to evaluate the condition on"
+	case filter == "":
+		result.Message = fmt.Sprintf("The query has no WHERE condition, all %d sampled items match", result.ItemsSampled)
+	default:
+		result.Message = fmt.Sprintf("%d of %d sampled items match the condition (%d undefined). The sample is not random, run the query with execute_query for the actual results", result.Matched, result.ItemsSampled, result.Undefined)
+	}
+
+	return nil, result, nil
+}
+
+// evaluateDryRun evaluates the condition on each item, a nil condition matches all items
+func evaluateDryRun(items [][]byte, condition sqlExpr) DryRunQueryToolResult {
+	result := DryRunQueryToolResult{ItemsSampled: len(items), MatchingIDs: []string{}}
+
+	for _, item := range items {
+		var object map[string]any
+		if err := json.Unmarshal(item, &object); err != nil {
+			result.Undefined++
+			continue
+		}
+
+		matched := true
+		if condition != nil {
+			value, defined := condition(object)
+			if !defined {
+				result.Undefined++
+				continue
+			}
+			matched = value == true
+		}
+
+		if matched {
+			result.Matched++
+			if id, ok := object["id"].(string); ok && len(result.MatchingIDs) < maxDryRunMatchingIDs {
+				result.MatchingIDs = append(result.MatchingIDs, id)
+			}
+		}
+	}
+
+	if result.ItemsSampled > 0 {
+		result.MatchRatio = math.Round(float64(result.Matched)/float64(result.ItemsSampled)*1000) / 1000
+	}
+
+	return result
+}
+
+// sqlExpr evaluates an expression on an item, it returns false as second value if the result is undefined
+type sqlExpr func(item map[string]any) (any, bool)
+
+type sqlTokenKind int
+
+const (
+	sqlIdentifier sqlTokenKind = iota
+	sqlNumber
+	sqlString
+	sqlSymbol
+)
+
+type sqlToken struct {
+	kind  sqlTokenKind
+	text  string
+	start int
+	end   int
+}
+
+// tokenizeQuery splits a query into identifiers (including keywords), numbers, strings and symbols
+func tokenizeQuery(query string) ([]sqlToken, error) {
+	tokens := []sqlToken{}
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		start := i
+
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+			continue
+
+		case strings.HasPrefix(query[i:], "--"):
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+			continue
+
+		case c == '_' || isASCIILetter(c):
+			for i < len(query) && (query[i] == '_' || isASCIILetter(query[i]) || isASCIIDigit(query[i])) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: sqlIdentifier, text: query[start:i], start: start, end: i})
+
+		case isASCIIDigit(c):
+			for i < len(query) && (isASCIIDigit(query[i]) || query[i] == '.') {
+				i++
+			}
+			if i < len(query) && (query[i] == 'e' || query[i] == 'E') {
+				i++
+				if i < len(query) && (query[i] == '+' || query[i] == '-') {
+					i++
+				}
+				for i < len(query) && isASCIIDigit(query[i]) {
+					i++
+				}
+			}
+			tokens = append(tokens, sqlToken{kind: sqlNumber, text: query[start:i], start: start, end: i})
+
+		case c == '\'' || c == '"':
+			var value strings.Builder
+			i++
+			for {
+				if i >= len(query) {
+					return nil, inputErrorf("unterminated string starting at position %d", start)
+				}
+				if query[i] == c {
+					i++
+					break
+				}
+				if query[i] == '\\' && i+1 < len(query) {
+					i++
+					switch query[i] {
+					case 'n':
+						value.WriteByte('\n')
+					case 't':
+						value.WriteByte('\t')
+					default:
+						value.WriteByte(query[i])
+					}
+					i++
+					continue
+				}
+				value.WriteByte(query[i])
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: sqlString, text: value.String(), start: start, end: i})
+
+		case c == '@':
+			return nil, newInputError("query parameters are not supported by dry_run_query, inline the values in the query")
+
+		default:
+			i++
+			if i < len(query) && slices.Contains([]string{"!=", "<>", "<=", ">="}, query[start:i+1]) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: sqlSymbol, text: query[start:i], start: start, end: i})
+		}
+	}
+
+	return tokens, nil
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isASCIIDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// parseDryRunQuery extracts the WHERE condition of a query and compiles it. The condition is nil if the query has none.
+func parseDryRunQuery(query string) (string, sqlExpr, error) {
+	tokens, err := tokenizeQuery(query)
+	if err != nil {
+		return "", nil, inputErrorf("invalid query: %v", err)
+	}
+
+	// the clauses are found at the top level, outside of parentheses
+	depth := 0
+	from, where, end := -1, -1, len(tokens)
+	for i, token := range tokens {
+		switch {
+		case token.kind == sqlSymbol && token.text == "(":
+			depth++
+		case token.kind == sqlSymbol && token.text == ")":
+			depth--
+		case depth > 0 || token.kind != sqlIdentifier:
+		case isKeyword(token, "FROM") && from < 0:
+			from = i
+		case isKeyword(token, "JOIN"):
+			return "", nil, newInputError("JOIN is not supported by dry_run_query")
+		case isKeyword(token, "WHERE") && from >= 0 && where < 0:
+			where = i
+		case (isKeyword(token, "ORDER") || isKeyword(token, "GROUP") || isKeyword(token, "OFFSET")) && where >= 0 && end == len(tokens):
+			end = i
+		}
+	}
+
+	if from < 0 || from+1 >= len(tokens) || tokens[from+1].kind != sqlIdentifier {
+		return "", nil, newInputError("invalid query: FROM clause missing, e.g. SELECT * FROM c WHERE c.status = 'active'")
+	}
+
+	// FROM c, FROM root r or FROM root AS r
+	alias := tokens[from+1].text
+	if next := from + 2; next < len(tokens) && tokens[next].kind == sqlIdentifier && !isKeyword(tokens[next], "WHERE") && !isKeyword(tokens[next], "ORDER") && !isKeyword(tokens[next], "GROUP") && !isKeyword(tokens[next], "OFFSET") {
+		if isKeyword(tokens[next], "IN") {
+			return "", nil, newInputError("iterating over an array with FROM ... IN is not supported by dry_run_query")
+		}
+		if isKeyword(tokens[next], "AS") {
+			next++
+		}
+		if next < len(tokens) && tokens[next].kind == sqlIdentifier {
+			alias = tokens[next].text
+		}
+	}
+
+	if where < 0 {
+		return "", nil, nil
+	}
+
+	if where+1 >= end {
+		return "", nil, newInputError("invalid query: WHERE condition missing")
+	}
+
+	parser := &sqlParser{tokens: tokens[where+1 : end], alias: alias}
+	condition, err := parser.parseOr()
+	if err != nil {
+		return "", nil, inputErrorf("can't evaluate the WHERE condition: %v", err)
+	}
+	if parser.pos < len(parser.tokens) {
+		return "", nil, inputErrorf("can't evaluate the WHERE condition: unexpected '%s'", parser.tokens[parser.pos].text)
+	}
+
+	return strings.TrimSpace(query[tokens[where+1].start:tokens[end-1].end]), condition, nil
+}
+
+func isKeyword(token sqlToken, keyword string) bool {
+	return token.kind == sqlIdentifier && strings.EqualFold(token.text, keyword)
+}
+
+// sqlParser compiles a condition with recursive descent: OR binds weaker than AND, which binds weaker than NOT
+type sqlParser struct {
+	tokens []sqlToken
+	pos    int
+	alias  string
+}
+
+func (p *sqlParser) peek() (sqlToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return sqlToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *sqlParser) acceptKeyword(keyword string) bool {
+	if token, ok := p.peek(); ok && isKeyword(token, keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *sqlParser) acceptSymbol(symbol string) bool {
+	if token, ok := p.peek(); ok && token.kind == sqlSymbol && token.text == symbol {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *sqlParser) expectSymbol(symbol string) error {
+	if !p.acceptSymbol(symbol) {
+		if token, ok := p.peek(); ok {
+			return inputErrorf("expected '%s' but found '%s'", symbol, token.text)
+		}
+		return inputErrorf("expected '%s' at the end of the condition", symbol)
+	}
+	return nil
+}
+
+func (p *sqlParser) parseOr() (sqlExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr(left, right)
+	}
+	return left, nil
+}
+
+func (p *sqlParser) parseAnd() (sqlExpr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr(left, right)
+	}
+	return left, nil
+}
+
+func (p *sqlParser) parseNot() (sqlExpr, error) {
+	if p.acceptKeyword("NOT") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr(operand), nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses a value optionally followed by a comparison, IN or BETWEEN
+func (p *sqlParser) parseComparison() (sqlExpr, error) {
+	left, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	token, ok := p.peek()
+	if !ok {
+		return left, nil
+	}
+
+	if token.kind == sqlSymbol && slices.Contains([]string{"=", "!=", "<>", "<", "<=", ">", ">="}, token.text) {
+		p.pos++
+		right, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return compareExpr(token.text, left, right), nil
+	}
+
+	negate := false
+	if isKeyword(token, "NOT") && p.pos+1 < len(p.tokens) && (isKeyword(p.tokens[p.pos+1], "IN") || isKeyword(p.tokens[p.pos+1], "BETWEEN")) {
+		p.pos++
+		negate = true
+	}
+
+	var expr sqlExpr
+	switch {
+	case p.acceptKeyword("IN"):
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		for {
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			equal := compareExpr("=", left, value)
+			if expr == nil {
+				expr = equal
+			} else {
+				expr = orExpr(expr, equal)
+			}
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+	case p.acceptKeyword("BETWEEN"):
+		low, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if !p.acceptKeyword("AND") {
+			return nil, newInputError("expected AND in BETWEEN")
+		}
+		high, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		expr = andExpr(compareExpr(">=", left, low), compareExpr("<=", left, high))
+	default:
+		return left, nil
+	}
+
+	if negate {
+		return notExpr(expr), nil
+	}
+	return expr, nil
+}
+
+// parseValue parses a literal, a field reference, a function call or a parenthesized condition
+func (p *sqlParser) parseValue() (sqlExpr, error) {
+	token, ok := p.peek()
+	if !ok {
+		return nil, newInputError("unexpected end of the condition")
+	}
+	p.pos++
+
+	switch token.kind {
+	case sqlNumber:
+		return numberLiteral(token.text)
+
+	case sqlString:
+		return literalExpr(token.text), nil
+
+	case sqlSymbol:
+		switch token.text {
+		case "(":
+			expr, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return expr, p.expectSymbol(")")
+		case "-":
+			if next, ok := p.peek(); ok && next.kind == sqlNumber {
+				p.pos++
+				return numberLiteral("-" + next.text)
+			}
+		}
+		return nil, inputErrorf("unexpected '%s'", token.text)
+	}
+
+	switch strings.ToUpper(token.text) {
+	case "TRUE":
+		return literalExpr(true), nil
+	case "FALSE":
+		return literalExpr(false), nil
+	case "NULL":
+		return literalExpr(nil), nil
+	case "UNDEFINED":
+		return func(map[string]any) (any, bool) { return nil, false }, nil
+	}
+
+	if p.acceptSymbol("(") {
+		return p.parseFunction(strings.ToUpper(token.text))
+	}
+
+	if token.text != p.alias {
+		return nil, inputErrorf("unknown identifier '%s', field references must start with the alias '%s', e.g. %s.status", token.text, p.alias, p.alias)
+	}
+
+	return p.parsePath()
+}
+
+// parsePath parses the rest of a field reference like c.address.city, c["first name"] or c.tags[0]
+func (p *sqlParser) parsePath() (sqlExpr, error) {
+	segments := []any{}
+	for {
+		switch {
+		case p.acceptSymbol("."):
+			token, ok := p.peek()
+			if !ok || token.kind != sqlIdentifier {
+				return nil, newInputError("expected a property name after '.'")
+			}
+			p.pos++
+			segments = append(segments, token.text)
+		case p.acceptSymbol("["):
+			token, ok := p.peek()
+			if !ok || (token.kind != sqlString && token.kind != sqlNumber) {
+				return nil, newInputError("expected a property name or array index after '['")
+			}
+			p.pos++
+			if token.kind == sqlNumber {
+				index, err := strconv.Atoi(token.text)
+				if err != nil {
+					return nil, inputErrorf("invalid array index '%s'", token.text)
+				}
+				segments = append(segments, index)
+			} else {
+				segments = append(segments, token.text)
+			}
+			if err := p.expectSymbol("]"); err != nil {
+				return nil, err
+			}
+		default:
+			return pathExpr(segments), nil
+		}
+	}
+}
+
+// parseFunction parses the arguments of a function call and compiles the function
+func (p *sqlParser) parseFunction(name string) (sqlExpr, error) {
+	if !slices.Contains(dryRunFunctions, name) {
+		return nil, inputErrorf("function %s is not supported by dry_run_query, supported functions: %s", name, strings.Join(dryRunFunctions, ", "))
+	}
+
+	args := []sqlExpr{}
+	if !p.acceptSymbol(")") {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	arity := map[string][2]int{"CONTAINS": {2, 3}, "STARTSWITH": {2, 3}, "ENDSWITH": {2, 3}, "ARRAY_CONTAINS": {2, 2}}
+	bounds, ok := arity[name]
+	if !ok {
+		bounds = [2]int{1, 1}
+	}
+	if len(args) < bounds[0] || len(args) > bounds[1] {
+		return nil, inputErrorf("wrong number of arguments for %s: %d", name, len(args))
+	}
+
+	return functionExpr(name, args), nil
+}
+
+func literalExpr(value any) sqlExpr {
+	return func(map[string]any) (any, bool) { return value, true }
+}
+
+func numberLiteral(text string) (sqlExpr, error) {
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, inputErrorf("invalid number '%s'", text)
+	}
+	return literalExpr(number), nil
+}
+
+// pathExpr returns the value of a property of the item, undefined if it is missing
+func pathExpr(segments []any) sqlExpr {
+	return func(item map[string]any) (any, bool) {
+		var value any = item
+		for _, segment := range segments {
+			switch key := segment.(type) {
+			case string:
+				object, ok := value.(map[string]any)
+				if !ok {
+					return nil, false
+				}
+				if value, ok = object[key]; !ok {
+					return nil, false
+				}
+			case int:
+				array, ok := value.([]any)
+				if !ok || key < 0 || key >= len(array) {
+					return nil, false
+				}
+				value = array[key]
+			}
+		}
+		return value, true
+	}
+}
+
+// andExpr is false if either side is false, true if both are true and undefined otherwise
+func andExpr(left, right sqlExpr) sqlExpr {
+	return func(item map[string]any) (any, bool) {
+		l, lok := boolValue(left(item))
+		r, rok := boolValue(right(item))
+		if (lok && !l) || (rok && !r) {
+			return false, true
+		}
+		if lok && rok {
+			return true, true
+		}
+		return nil, false
+	}
+}
+
+// orExpr is true if either side is true, false if both are false and undefined otherwise
+func orExpr(left, right sqlExpr) sqlExpr {
+	return func(item map[string]any) (any, bool) {
+		l, lok := boolValue(left(item))
+		r, rok := boolValue(right(item))
+		if (lok && l) || (rok && r) {
+			return true, true
+		}
+		if lok && rok {
+			return false, true
+		}
+		return nil, false
+	}
+}
+
+func notExpr(operand sqlExpr) sqlExpr {
+	return func(item map[string]any) (any, bool) {
+		if value, ok := boolValue(operand(item)); ok {
+			return !value, true
+		}
+		return nil, false
+	}
+}
+
+// boolValue returns a defined boolean value, other types are undefined in logical expressions
+func boolValue(value any, defined bool) (bool, bool) {
+	b, ok := value.(bool)
+	return b, defined && ok
+}
+
+// compareExpr compares two values of the same type. Like in Azure Cosmos DB, comparing values of different types or
+// undefined values is undefined. Only numbers and strings can be ordered.
+func compareExpr(operator string, left, right sqlExpr) sqlExpr {
+	return func(item map[string]any) (any, bool) {
+		l, lok := left(item)
+		r, rok := right(item)
+		if !lok || !rok {
+			return nil, false
+		}
+
+		var order int
+		switch lv := l.(type) {
+		case float64:
+			rv, ok := r.(float64)
+			if !ok {
+				return nil, false
+			}
+			order = cmp.Compare(lv, rv)
+		case string:
+			rv, ok := r.(string)
+			if !ok {
+				return nil, false
+			}
+			order = strings.Compare(lv, rv)
+		case bool, nil:
+			if reflect.TypeOf(l) != reflect.TypeOf(r) || (operator != "=" && operator != "!=" && operator != "<>") {
+				return nil, false
+			}
+			if l != r {
+				order = 1
+			}
+		default:
+			return nil, false
+		}
+
+		switch operator {
+		case "=":
+			return order == 0, true
+		case "!=", "<>":
+			return order != 0, true
+		case "<":
+			return order < 0, true
+		case "<=":
+			return order <= 0, true
+		case ">":
+			return order > 0, true
+		default:
+			return order >= 0, true
+		}
+	}
+}
+
+// functionExpr compiles a supported function, the number of arguments has been checked
+func functionExpr(name string, args []sqlExpr) sqlExpr {
+	return func(item map[string]any) (any, bool) {
+		value, defined := args[0](item)
+
+		switch name {
+		case "IS_DEFINED":
+			return defined, true
+		case "IS_NULL":
+			return defined && value == nil, true
+		case "IS_STRING", "IS_NUMBER", "IS_BOOL", "IS_ARRAY", "IS_OBJECT":
+			expected := map[string]string{"IS_STRING": "string", "IS_NUMBER": "number", "IS_BOOL": "boolean", "IS_ARRAY": "array", "IS_OBJECT": "object"}[name]
+			return defined && jsonType(value) == expected, true
+		}
+
+		if !defined {
+			return nil, false
+		}
+
+		switch name {
+		case "LOWER", "UPPER":
+			s, ok := value.(string)
+			if !ok {
+				return nil, false
+			}
+			if name == "LOWER" {
+				return strings.ToLower(s), true
+			}
+			return strings.ToUpper(s), true
+
+		case "ARRAY_LENGTH":
+			array, ok := value.([]any)
+			if !ok {
+				return nil, false
+			}
+			return float64(len(array)), true
+
+		case "ARRAY_CONTAINS":
+			array, ok := value.([]any)
+			element, elementDefined := args[1](item)
+			if !ok || !elementDefined {
+				return nil, false
+			}
+			for _, candidate := range array {
+				if reflect.DeepEqual(candidate, element) {
+					return true, true
+				}
+			}
+			return false, true
+		}
+
+		// CONTAINS, STARTSWITH and ENDSWITH, with an optional ignore case argument
+		s, ok := value.(string)
+		other, otherDefined := args[1](item)
+		substring, substringOk := other.(string)
+		if !ok || !otherDefined || !substringOk {
+			return nil, false
+		}
+		if len(args) == 3 {
+			ignoreCase, ignoreCaseDefined := boolValue(args[2](item))
+			if !ignoreCaseDefined {
+				return nil, false
+			}
+			if ignoreCase {
+				s, substring = strings.ToLower(s), strings.ToLower(substring)
+			}
+		}
+
+		switch name {
+		case "CONTAINS":
+			return strings.Contains(s, substring), true
+		case "STARTSWITH":
+			return strings.HasPrefix(s, substring), true
+		default:
+			return strings.HasSuffix(s, substring), true
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for dry_run_query that do not need the emulator
+
+func TestParseDryRunQuery_Evaluate(t *testing.T) {
+	item := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "1",
+		"status": "Active",
+		"total": 150,
+		"vip": true,
+		"note": null,
+		"address": {"city": "Seattle", "first line": "1 Main St"},
+		"tags": ["new", "priority"]
+	}`), &item))
+
+	tests := []struct {
+		query           string
+		expected        any
+		expectUndefined bool
+	}{
+		{query: "SELECT * FROM c WHERE c.status = 'Active'", expected: true},
+		{query: `SELECT * FROM c WHERE c.status = "active"`, expected: false},
+		{query: "SELECT * FROM c WHERE c.total > 100 AND c.total <= 150", expected: true},
+		{query: "SELECT * FROM c WHERE c.total != 150 OR c.vip", expected: true},
+		{query: "SELECT * FROM c WHERE NOT (c.total > -5.5)", expected: false},
+		{query: "SELECT * FROM c WHERE c.total BETWEEN 100 AND 200", expected: true},
+		{query: "SELECT * FROM c WHERE c.total NOT BETWEEN 100 AND 200", expected: false},
+		{query: "SELECT * FROM c WHERE c.status IN ('Active', 'Pending')", expected: true},
+		{query: "SELECT * FROM c WHERE c.status NOT IN ('Closed')", expected: true},
+		{query: "SELECT * FROM c WHERE c.address.city = 'Seattle'", expected: true},
+		{query: `SELECT * FROM c WHERE c.address["first line"] = '1 Main St'`, expected: true},
+		{query: "SELECT * FROM c WHERE c.tags[1] = 'priority'", expected: true},
+		{query: "SELECT * FROM c WHERE c.note = null", expected: true},
+		{query: "SELECT * FROM c WHERE IS_NULL(c.note) AND IS_DEFINED(c.vip) AND NOT IS_DEFINED(c.missing)", expected: true},
+		{query: "SELECT * FROM c WHERE IS_NUMBER(c.total) AND IS_STRING(c.status) AND IS_ARRAY(c.tags) AND IS_OBJECT(c.address) AND IS_BOOL(c.vip)", expected: true},
+		{query: "SELECT * FROM c WHERE CONTAINS(c.status, 'tiv')", expected: true},
+		{query: "SELECT * FROM c WHERE STARTSWITH(c.status, 'act')", expected: false},
+		{query: "SELECT * FROM c WHERE STARTSWITH(c.status, 'act', true)", expected: true},
+		{query: "SELECT * FROM c WHERE ENDSWITH(LOWER(c.status), 'ive')", expected: true},
+		{query: "SELECT * FROM c WHERE UPPER(c.address.city) = 'SEATTLE'", expected: true},
+		{query: "SELECT * FROM c WHERE ARRAY_CONTAINS(c.tags, 'new') AND ARRAY_LENGTH(c.tags) = 2", expected: true},
+		{query: "SELECT c.id FROM root r WHERE r.vip = true ORDER BY r.total", expected: true},
+		{query: "SELECT * FROM items AS i WHERE i.total >= 150 -- high value\nOFFSET 0 LIMIT 10", expected: true},
+
+		// undefined, like in Azure Cosmos DB
+		{query: "SELECT * FROM c WHERE c.missing = 1", expectUndefined: true},
+		{query: "SELECT * FROM c WHERE c.total = '150'", expectUndefined: true},
+		{query: "SELECT * FROM c WHERE c.vip > false", expectUndefined: true},
+		{query: "SELECT * FROM c WHERE NOT (c.missing = 1)", expectUndefined: true},
+		{query: "SELECT * FROM c WHERE c.missing = 1 AND c.total > 100", expectUndefined: true},
+		{query: "SELECT * FROM c WHERE c.missing = 1 OR c.total > 100", expected: true},
+		{query: "SELECT * FROM c WHERE c.missing = 1 AND c.total < 100", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, condition, err := parseDryRunQuery(tt.query)
+			require.NoError(t, err)
+			require.NotNil(t, condition)
+
+			value, defined := condition(item)
+			if tt.expectUndefined {
+				assert.False(t, defined, "expected undefined, got %v", value)
+				return
+			}
+			require.True(t, defined)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestParseDryRunQuery(t *testing.T) {
+	filter, condition, err := parseDryRunQuery("SELECT * FROM c WHERE c.status = 'active' ORDER BY c._ts DESC")
+	require.NoError(t, err)
+	assert.Equal(t, "c.status = 'active'", filter)
+	assert.NotNil(t, condition)
+
+	// a query without WHERE matches all items
+	filter, condition, err = parseDryRunQuery("SELECT VALUE COUNT(1) FROM c")
+	require.NoError(t, err)
+	assert.Empty(t, filter)
+	assert.Nil(t, condition)
+
+	errorTests := []struct {
+		query          string
+		expectedErrMsg string
+	}{
+		{"SELECT * WHERE c.a = 1", "FROM clause missing"},
+		{"SELECT * FROM c WHERE", "WHERE condition missing"},
+		{"SELECT * FROM c WHERE c.status = @status", "query parameters are not supported"},
+		{"SELECT * FROM c JOIN t IN c.tags WHERE t = 'new'", "JOIN is not supported"},
+		{"SELECT * FROM t IN c.tags", "FROM ... IN is not supported"},
+		{"SELECT * FROM c WHERE status = 'active'", "field references must start with the alias 'c'"},
+		{"SELECT * FROM c WHERE REGEXMATCH(c.name, 'a.*')", "function REGEXMATCH is not supported"},
+		{"SELECT * FROM c WHERE CONTAINS(c.name)", "wrong number of arguments for CONTAINS"},
+		{"SELECT * FROM c WHERE c.total + 1 > 2", "unexpected '+'"},
+		{"SELECT * FROM c WHERE (c.a = 1", "expected ')'"},
+		{"SELECT * FROM c WHERE c.a = 'open", "unterminated string"},
+		{"SELECT * FROM c WHERE EXISTS(SELECT VALUE t FROM t IN c.tags)", "function EXISTS is not supported"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, _, err := parseDryRunQuery(tt.query)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErrMsg)
+		})
+	}
+}
+
+func TestEvaluateDryRun(t *testing.T) {
+	items := [][]byte{
+		[]byte(`{"id": "1", "status": "active"}`),
+		[]byte(`{"id": "2", "status": "closed"}`),
+		[]byte(`{"id": "3"}`),
+		[]byte(`{"id": "4", "status": "active"}`),
+	}
+
+	_, condition, err := parseDryRunQuery("SELECT * FROM c WHERE c.status = 'active'")
+	require.NoError(t, err)
+
+	result := evaluateDryRun(items, condition)
+	assert.Equal(t, 4, result.ItemsSampled)
+	assert.Equal(t, 2, result.Matched)
+	assert.Equal(t, 1, result.Undefined)
+	assert.Equal(t, 0.5, result.MatchRatio)
+	assert.Equal(t, []string{"1", "4"}, result.MatchingIDs)
+
+	all := evaluateDryRun(items, nil)
+	assert.Equal(t, 4, all.Matched)
+	assert.Equal(t, float64(1), all.MatchRatio)
+
+	empty := evaluateDryRun(nil, condition)
+	assert.Equal(t, 0, empty.ItemsSampled)
+	assert.Equal(t, float64(0), empty.MatchRatio)
+	assert.Empty(t, empty.MatchingIDs)
+}
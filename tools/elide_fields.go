@@ -0,0 +1,138 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MaxFieldBytesEnvVar makes the read and query tools replace string values larger than the given number of bytes
+// with a placeholder by default, e.g. base64 encoded blobs. The maxFieldBytes argument of a tool call overrides it.
+const MaxFieldBytesEnvVar = "COSMOS_MAX_FIELD_BYTES"
+
+// GetMaxFieldBytes returns the size above which string values are elided as configured in the environment, 0 means values are never elided
+func GetMaxFieldBytes() (int, error) {
+	value := strings.TrimSpace(os.Getenv(MaxFieldBytesEnvVar))
+	if value == "" {
+		return 0, nil
+	}
+
+	max, err := strconv.Atoi(value)
+	if err != nil || max < 0 {
+		return 0, fmt.Errorf("invalid value '%s' for %s, must be 0 (disabled) or a positive number of bytes", value, MaxFieldBytesEnvVar)
+	}
+	return max, nil
+}
+
+// useMaxFieldBytes returns the size above which string values are elided for a tool call, the maxFieldBytes argument takes precedence over the environment
+func useMaxFieldBytes(maxFieldBytes *int) (int, error) {
+	if maxFieldBytes == nil {
+		return GetMaxFieldBytes()
+	}
+	if *maxFieldBytes < 0 {
+		return 0, newInputError("maxFieldBytes must not be negative")
+	}
+	return *maxFieldBytes, nil
+}
+
+// elideLargeFields replaces the string values of a JSON document that are larger than maxBytes with a placeholder
+// such as "<elided 50KB>", and returns the number of values replaced. The order of the properties is kept.
+// The document is returned unchanged if nothing was elided, if maxBytes is 0 or if it is not valid JSON.
+func elideLargeFields(document []byte, maxBytes int) ([]byte, int) {
+	if maxBytes == 0 || len(document) <= maxBytes {
+		return document, 0
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var elided bytes.Buffer
+	count, err := elideJSONValue(decoder, &elided, maxBytes)
+	if err != nil || count == 0 {
+		return document, 0
+	}
+
+	return elided.Bytes(), count
+}
+
+// elideJSONValue copies the next value of the decoder to out, eliding large strings
+func elideJSONValue(decoder *json.Decoder, out *bytes.Buffer, maxBytes int) (int, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return 0, err
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		closing := json.Delim('}')
+		if value == '[' {
+			closing = ']'
+		}
+		out.WriteString(value.String())
+
+		count := 0
+		for i := 0; decoder.More(); i++ {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			if value == '{' {
+				key, err := decoder.Token()
+				if err != nil {
+					return 0, err
+				}
+				writeJSONString(out, key.(string))
+				out.WriteByte(':')
+			}
+
+			elided, err := elideJSONValue(decoder, out, maxBytes)
+			if err != nil {
+				return 0, err
+			}
+			count += elided
+		}
+
+		if _, err := decoder.Token(); err != nil {
+			return 0, err
+		}
+		out.WriteString(closing.String())
+		return count, nil
+	case string:
+		if len(value) > maxBytes {
+			writeJSONString(out, fmt.Sprintf("<elided %s>", formatByteSize(len(value))))
+			return 1, nil
+		}
+		writeJSONString(out, value)
+	case json.Number:
+		out.WriteString(value.String())
+	case bool:
+		out.WriteString(strconv.FormatBool(value))
+	case nil:
+		out.WriteString("null")
+	}
+
+	return 0, nil
+}
+
+// writeJSONString writes a JSON string without escaping HTML characters, so that placeholders stay readable
+func writeJSONString(out *bytes.Buffer, value string) {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+	// Encode appends a newline
+	out.Truncate(out.Len() - 1)
+}
+
+// formatByteSize formats a size in bytes, KB or MB
+func formatByteSize(size int) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1fMB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%dKB", (size+512)/1024)
+	default:
+		return fmt.Sprintf("%dB", size)
+	}
+}
@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for eliding large field values that do not need the emulator
+
+func TestElideLargeFields(t *testing.T) {
+	blob := strings.Repeat("QUJD", 12800) // 50 KB of base64
+
+	document := `{"id":"1","image":{"name":"a<b>.png","data":"` + blob + `"},"tags":["x","` + blob + `"],"size":1.50,"ok":true,"note":null}`
+
+	elided, count := elideLargeFields([]byte(document), 1024)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, `{"id":"1","image":{"name":"a<b>.png","data":"<elided 50KB>"},"tags":["x","<elided 50KB>"],"size":1.50,"ok":true,"note":null}`, string(elided))
+
+	t.Run("nothing to elide", func(t *testing.T) {
+		small := `{"b": 1, "a": "text"}`
+		elided, count := elideLargeFields([]byte(small), 4)
+		assert.Equal(t, 0, count)
+		assert.Equal(t, small, string(elided), "documents are returned unchanged")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		elided, count := elideLargeFields([]byte(document), 0)
+		assert.Equal(t, 0, count)
+		assert.Equal(t, document, string(elided))
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		invalid := `{"data": "` + blob + `"`
+		elided, count := elideLargeFields([]byte(invalid), 10)
+		assert.Equal(t, 0, count)
+		assert.Equal(t, invalid, string(elided))
+	})
+}
+
+func TestUseMaxFieldBytes(t *testing.T) {
+	zero, negative := 0, -1
+
+	tests := []struct {
+		name          string
+		value         string
+		maxFieldBytes *int
+		expected      int
+		expectError   bool
+	}{
+		{name: "not set", expected: 0},
+		{name: "environment", value: "4096", expected: 4096},
+		{name: "argument overrides environment", value: "4096", maxFieldBytes: &zero, expected: 0},
+		{name: "negative argument", maxFieldBytes: &negative, expectError: true},
+		{name: "invalid environment", value: "4KB", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(MaxFieldBytesEnvVar, tt.value)
+
+			maxFieldBytes, err := useMaxFieldBytes(tt.maxFieldBytes)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, maxFieldBytes)
+		})
+	}
+}
+
+func TestFormatByteSize(t *testing.T) {
+	assert.Equal(t, "512B", formatByteSize(512))
+	assert.Equal(t, "50KB", formatByteSize(50*1024+100))
+	assert.Equal(t, "2.5MB", formatByteSize(5*1024*1024/2))
+}
@@ -0,0 +1,193 @@
+package tools
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// DiagnosticError wraps a Cosmos DB response error with the details needed when opening an Azure support case
+type DiagnosticError struct {
+	Err           error
+	ActivityID    string
+	StatusCode    int
+	SubStatusCode string
+	ErrorCode     string
+	RequestCharge string
+}
+
+func (e *DiagnosticError) Error() string {
+	details := []string{fmt.Sprintf("status code: %d", e.StatusCode)}
+	if e.SubStatusCode != "" {
+		details = append(details, "substatus: "+e.SubStatusCode)
+	}
+	if e.ErrorCode != "" {
+		details = append(details, "error code: "+e.ErrorCode)
+	}
+	if e.ActivityID != "" {
+		details = append(details, "activity id: "+e.ActivityID)
+	}
+	if e.RequestCharge != "" {
+		details = append(details, "request charge: "+e.RequestCharge)
+	}
+	return fmt.Sprintf("%v [%s]", e.Err, strings.Join(details, ", "))
+}
+
+func (e *DiagnosticError) Unwrap() error {
+	return e.Err
+}
+
+// withDiagnostics adds the activity id and status details of a Cosmos DB response error to err.
+// Errors that did not come from a Cosmos DB response are returned as is.
+func withDiagnostics(err error) error {
+	var diagnosticErr *DiagnosticError
+	if errors.As(err, &diagnosticErr) {
+		return err
+	}
+
+	var responseErr *azcore.ResponseError
+	if !errors.As(err, &responseErr) {
+		return err
+	}
+
+	diagnosticErr = &DiagnosticError{
+		Err:        err,
+		StatusCode: responseErr.StatusCode,
+		ErrorCode:  responseErr.ErrorCode,
+	}
+
+	if responseErr.RawResponse != nil {
+		header := responseErr.RawResponse.Header
+		diagnosticErr.ActivityID = header.Get("x-ms-activity-id")
+		diagnosticErr.SubStatusCode = header.Get("x-ms-substatus")
+		diagnosticErr.RequestCharge = header.Get("x-ms-request-charge")
+	}
+
+	return diagnosticErr
+}
+
+// Error categories reported by ClassifyError
+const (
+	ErrorCategoryThrottled          = "throttled"
+	ErrorCategoryUnavailable        = "unavailable"
+	ErrorCategoryTimeout            = "timeout"
+	ErrorCategoryCanceled           = "canceled"
+	ErrorCategoryBadRequest         = "bad_request"
+	ErrorCategoryUnauthorized       = "unauthorized"
+	ErrorCategoryForbidden          = "forbidden"
+	ErrorCategoryNotFound           = "not_found"
+	ErrorCategoryConflict           = "conflict"
+	ErrorCategoryPreconditionFailed = "precondition_failed"
+	ErrorCategoryTooLarge           = "too_large"
+	ErrorCategoryServerError        = "server_error"
+	ErrorCategoryInvalidInput       = "invalid_input"
+	ErrorCategoryUnknown            = "unknown"
+)
+
+// InputError is an error caused by invalid tool arguments, which ClassifyError reports as invalid_input
+type InputError struct {
+	Err error
+}
+
+func (e *InputError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *InputError) Unwrap() error {
+	return e.Err
+}
+
+// newInputError returns an InputError with the given message
+func newInputError(message string) error {
+	return &InputError{Err: errors.New(message)}
+}
+
+// inputErrorf returns an InputError with a formatted message
+func inputErrorf(format string, args ...any) error {
+	return &InputError{Err: fmt.Errorf(format, args...)}
+}
+
+// ToolError is the structured result of a failed tool call, so that MCP clients can decide whether to retry
+type ToolError struct {
+	Error     string `json:"error"`
+	Retryable bool   `json:"retryable" jsonschema:"Whether the same call may succeed if retried later"`
+	Category  string `json:"category" jsonschema:"Category of the error, e.g. throttled, timeout, not_found or invalid_input"`
+}
+
+// ClassifyError reports whether a tool call that failed with err can be retried, along with the category of the error.
+// Throttling (429), unavailability (502, 503, 504, 410, 449), timeouts and network errors are retryable. Client errors such
+// as 400, 401, 403, 404 and 409 are not, and neither are invalid arguments (InputError) or failed authentication. Other
+// errors are reported as unknown.
+func ClassifyError(err error) ToolError {
+	toolError := ToolError{Error: err.Error()}
+
+	var responseErr *azcore.ResponseError
+	if errors.As(err, &responseErr) {
+		toolError.Category, toolError.Retryable = classifyStatusCode(responseErr.StatusCode)
+		return toolError
+	}
+
+	var inputErr *InputError
+	var authErr *azidentity.AuthenticationFailedError
+	var certErr *tls.CertificateVerificationError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &inputErr):
+		toolError.Category = ErrorCategoryInvalidInput
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		toolError.Category, toolError.Retryable = ErrorCategoryTimeout, true
+	case errors.Is(err, context.Canceled):
+		toolError.Category = ErrorCategoryCanceled
+	case errors.As(err, &authErr):
+		toolError.Category = ErrorCategoryUnauthorized
+	case errors.As(err, &certErr):
+		// an untrusted certificate won't become trusted by retrying
+		toolError.Category = ErrorCategoryUnavailable
+	case errors.As(err, &netErr):
+		// connection refused or reset, DNS and TLS handshake failures
+		toolError.Category, toolError.Retryable = ErrorCategoryUnavailable, true
+	default:
+		toolError.Category = ErrorCategoryUnknown
+	}
+
+	return toolError
+}
+
+// classifyStatusCode maps the status code of a Cosmos DB response to an error category, and whether it is retryable
+func classifyStatusCode(statusCode int) (string, bool) {
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		return ErrorCategoryThrottled, true
+	case http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusGatewayTimeout, http.StatusGone, 449:
+		// 410 (partition moved) and 449 (retry with) are transient conditions in Cosmos DB
+		return ErrorCategoryUnavailable, true
+	case http.StatusRequestTimeout:
+		return ErrorCategoryTimeout, true
+	case http.StatusBadRequest:
+		return ErrorCategoryBadRequest, false
+	case http.StatusUnauthorized:
+		return ErrorCategoryUnauthorized, false
+	case http.StatusForbidden:
+		return ErrorCategoryForbidden, false
+	case http.StatusNotFound:
+		return ErrorCategoryNotFound, false
+	case http.StatusConflict:
+		return ErrorCategoryConflict, false
+	case http.StatusPreconditionFailed:
+		return ErrorCategoryPreconditionFailed, false
+	case http.StatusRequestEntityTooLarge:
+		return ErrorCategoryTooLarge, false
+	}
+
+	if statusCode >= 500 {
+		return ErrorCategoryServerError, false
+	}
+	return ErrorCategoryBadRequest, false
+}
@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for surfacing Cosmos DB diagnostics on errors
+
+func TestWithDiagnostics(t *testing.T) {
+	header := http.Header{}
+	header.Set("x-ms-activity-id", "2a7d5f8e-1111-2222-3333-444455556666")
+	header.Set("x-ms-substatus", "1002")
+	header.Set("x-ms-request-charge", "1.5")
+
+	responseErr := &azcore.ResponseError{
+		StatusCode:  http.StatusNotFound,
+		ErrorCode:   "NotFound",
+		RawResponse: &http.Response{StatusCode: http.StatusNotFound, Header: header},
+	}
+
+	err := withDiagnostics(fmt.Errorf("wrapped: %w", responseErr))
+
+	var diagnosticErr *DiagnosticError
+	require.True(t, errors.As(err, &diagnosticErr))
+	assert.Equal(t, "2a7d5f8e-1111-2222-3333-444455556666", diagnosticErr.ActivityID)
+	assert.Equal(t, http.StatusNotFound, diagnosticErr.StatusCode)
+	assert.Equal(t, "1002", diagnosticErr.SubStatusCode)
+	assert.Equal(t, "NotFound", diagnosticErr.ErrorCode)
+	assert.Contains(t, err.Error(), "activity id: 2a7d5f8e-1111-2222-3333-444455556666")
+	assert.Contains(t, err.Error(), "substatus: 1002")
+
+	// the original response error is still reachable
+	var unwrapped *azcore.ResponseError
+	require.True(t, errors.As(err, &unwrapped))
+	assert.Equal(t, responseErr, unwrapped)
+
+	// wrapping again does not duplicate the details
+	assert.Equal(t, err, withDiagnostics(err))
+}
+
+func TestWithDiagnostics_OtherErrors(t *testing.T) {
+	err := errors.New("boom")
+	assert.Equal(t, err, withDiagnostics(err))
+}
+
+func TestClassifyError(t *testing.T) {
+	responseErr := func(statusCode int) error {
+		return withDiagnostics(fmt.Errorf("error reading item: %w", &azcore.ResponseError{
+			StatusCode:  statusCode,
+			RawResponse: &http.Response{StatusCode: statusCode, Header: http.Header{}},
+		}))
+	}
+
+	tests := []struct {
+		name      string
+		err       error
+		category  string
+		retryable bool
+	}{
+		{name: "throttled", err: responseErr(http.StatusTooManyRequests), category: ErrorCategoryThrottled, retryable: true},
+		{name: "service unavailable", err: responseErr(http.StatusServiceUnavailable), category: ErrorCategoryUnavailable, retryable: true},
+		{name: "gone", err: responseErr(http.StatusGone), category: ErrorCategoryUnavailable, retryable: true},
+		{name: "retry with", err: responseErr(449), category: ErrorCategoryUnavailable, retryable: true},
+		{name: "request timeout", err: responseErr(http.StatusRequestTimeout), category: ErrorCategoryTimeout, retryable: true},
+		{name: "bad request", err: responseErr(http.StatusBadRequest), category: ErrorCategoryBadRequest},
+		{name: "unauthorized", err: responseErr(http.StatusUnauthorized), category: ErrorCategoryUnauthorized},
+		{name: "forbidden", err: responseErr(http.StatusForbidden), category: ErrorCategoryForbidden},
+		{name: "not found", err: responseErr(http.StatusNotFound), category: ErrorCategoryNotFound},
+		{name: "conflict", err: responseErr(http.StatusConflict), category: ErrorCategoryConflict},
+		{name: "precondition failed", err: responseErr(http.StatusPreconditionFailed), category: ErrorCategoryPreconditionFailed},
+		{name: "too large", err: responseErr(http.StatusRequestEntityTooLarge), category: ErrorCategoryTooLarge},
+		{name: "bad gateway", err: responseErr(http.StatusBadGateway), category: ErrorCategoryUnavailable, retryable: true},
+		{name: "gateway timeout", err: responseErr(http.StatusGatewayTimeout), category: ErrorCategoryUnavailable, retryable: true},
+		{name: "internal server error", err: responseErr(http.StatusInternalServerError), category: ErrorCategoryServerError},
+		{name: "deadline exceeded", err: fmt.Errorf("query page error: %w", context.DeadlineExceeded), category: ErrorCategoryTimeout, retryable: true},
+		{name: "canceled", err: fmt.Errorf("query page error: %w", context.Canceled), category: ErrorCategoryCanceled},
+		{name: "invalid input", err: newInputError("database name missing"), category: ErrorCategoryInvalidInput},
+		{name: "wrapped invalid input", err: fmt.Errorf("filter 1: %w", inputErrorf("invalid operator '%s'", "like")), category: ErrorCategoryInvalidInput},
+		{name: "connection refused", err: &url.Error{Op: "Get", URL: "https://myaccount.documents.azure.com", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}, category: ErrorCategoryUnavailable, retryable: true},
+		{name: "dns", err: &net.DNSError{Err: "no such host", Name: "myaccount.documents.azure.com", IsNotFound: true}, category: ErrorCategoryUnavailable, retryable: true},
+		{name: "network timeout", err: &net.DNSError{Err: "i/o timeout", Name: "myaccount.documents.azure.com", IsTimeout: true}, category: ErrorCategoryTimeout, retryable: true},
+		{name: "untrusted certificate", err: &url.Error{Op: "Get", URL: "https://localhost:8081", Err: &tls.CertificateVerificationError{Err: errors.New("x509: certificate signed by unknown authority")}}, category: ErrorCategoryUnavailable},
+		{name: "authentication failed", err: fmt.Errorf("error getting token: %w", &azidentity.AuthenticationFailedError{}), category: ErrorCategoryUnauthorized},
+		{name: "other error", err: errors.New("key missing in account config"), category: ErrorCategoryUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toolError := ClassifyError(tt.err)
+			assert.Equal(t, tt.err.Error(), toolError.Error)
+			assert.Equal(t, tt.category, toolError.Category)
+			assert.Equal(t, tt.retryable, toolError.Retryable)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field types accepted in fieldTypes by read_item and execute_query
+const (
+	FieldTypeString       = "string"
+	FieldTypeNumber       = "number"
+	FieldTypeInteger      = "integer"
+	FieldTypeBoolean      = "boolean"
+	FieldTypeDateTime     = "dateTime"
+	FieldTypeEpochSeconds = "epochSeconds"
+	FieldTypeEpochMillis  = "epochMillis"
+)
+
+var supportedFieldTypes = []string{FieldTypeString, FieldTypeNumber, FieldTypeInteger, FieldTypeBoolean, FieldTypeDateTime, FieldTypeEpochSeconds, FieldTypeEpochMillis}
+
+// maxFieldTypes is the maximum number of fields in fieldTypes
+const maxFieldTypes = 20
+
+// TypedField is the value of a field of an item, interpreted as the type given in fieldTypes
+type TypedField struct {
+	Field    string `json:"field"`
+	Type     string `json:"type" jsonschema:"The type the field was interpreted as"`
+	JSONType string `json:"json_type" jsonschema:"The JSON type of the stored value: string, number, boolean, null, object, array or missing"`
+	Value    any    `json:"value,omitempty" jsonschema:"The stored value"`
+	Typed    any    `json:"typed,omitempty" jsonschema:"The value converted to the type, e.g. an RFC 3339 UTC timestamp for epochSeconds, epochMillis and dateTime, or a number for a numeric string"`
+	Error    string `json:"error,omitempty" jsonschema:"Why the value could not be interpreted as the type"`
+}
+
+// fieldTypeHint is a validated entry of fieldTypes
+type fieldTypeHint struct {
+	field    string
+	segments []string
+	typ      string
+}
+
+// parseFieldTypes validates the fieldTypes argument, a map of field path (e.g. _ts or order.total) to type.
+// The hints are sorted by field, so that the typed fields are always in the same order.
+func parseFieldTypes(fieldTypes map[string]string) ([]fieldTypeHint, error) {
+	if len(fieldTypes) > maxFieldTypes {
+		return nil, inputErrorf("too many fieldTypes: %d (max %d)", len(fieldTypes), maxFieldTypes)
+	}
+
+	hints := make([]fieldTypeHint, 0, len(fieldTypes))
+	for field, typ := range fieldTypes {
+		segments, err := fieldSegments(field)
+		if err != nil {
+			return nil, inputErrorf("invalid fieldTypes: %v", err)
+		}
+		if !slices.Contains(supportedFieldTypes, typ) {
+			return nil, inputErrorf("invalid type '%s' for field '%s' in fieldTypes, must be one of: %s", typ, field, strings.Join(supportedFieldTypes, ", "))
+		}
+		hints = append(hints, fieldTypeHint{field: field, segments: segments, typ: typ})
+	}
+
+	slices.SortFunc(hints, func(a, b fieldTypeHint) int { return strings.Compare(a.field, b.field) })
+
+	return hints, nil
+}
+
+// typedFields interprets the fields of an item according to the hints. Numbers keep their exact representation.
+func typedFields(item []byte, hints []fieldTypeHint) []TypedField {
+	decoder := json.NewDecoder(bytes.NewReader(item))
+	decoder.UseNumber()
+
+	var object map[string]any
+	decodeErr := decoder.Decode(&object)
+
+	fields := make([]TypedField, 0, len(hints))
+	for _, hint := range hints {
+		field := TypedField{Field: hint.field, Type: hint.typ}
+
+		if decodeErr != nil || object == nil {
+			field.JSONType = "missing"
+			field.Error = "the result is not a JSON object"
+			fields = append(fields, field)
+			continue
+		}
+
+		value, found := lookupField(object, hint.segments)
+		if !found {
+			field.JSONType = "missing"
+			field.Error = "field not present"
+			fields = append(fields, field)
+			continue
+		}
+
+		field.JSONType = jsonType(value)
+		field.Value = value
+
+		typed, err := convertFieldValue(value, hint.typ)
+		if err != nil {
+			field.Error = err.Error()
+		} else {
+			field.Typed = typed
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// resultTypedFields interprets the fields of each query result, nil if there are no hints
+func resultTypedFields(results []string, hints []fieldTypeHint) [][]TypedField {
+	if len(hints) == 0 {
+		return nil
+	}
+
+	fields := make([][]TypedField, len(results))
+	for i, result := range results {
+		fields[i] = typedFields([]byte(result), hints)
+	}
+	return fields
+}
+
+// lookupField returns the value of a nested property
+func lookupField(object map[string]any, segments []string) (any, bool) {
+	var value any = object
+	for _, segment := range segments {
+		properties, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		value, ok = properties[segment]
+		if !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// convertFieldValue converts a value to the type. Numbers and booleans stored as strings are converted as well,
+// which is the ambiguity the hints are meant to resolve.
+func convertFieldValue(value any, typ string) (any, error) {
+	switch typ {
+	case FieldTypeString:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case json.Number:
+			return v.String(), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+
+	case FieldTypeNumber:
+		if number, ok := numberValue(value); ok {
+			if f, err := number.Float64(); err == nil {
+				return f, nil
+			}
+		}
+
+	case FieldTypeInteger:
+		if number, ok := numberValue(value); ok {
+			if i, err := number.Int64(); err == nil {
+				return i, nil
+			}
+			if f, err := number.Float64(); err == nil && f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+				return int64(f), nil
+			}
+		}
+
+	case FieldTypeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, nil
+			}
+		}
+
+	case FieldTypeDateTime:
+		if s, ok := value.(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				return t.UTC().Format(time.RFC3339Nano), nil
+			}
+			return nil, errors.New("not an RFC 3339 timestamp")
+		}
+
+	case FieldTypeEpochSeconds, FieldTypeEpochMillis:
+		if number, ok := numberValue(value); ok {
+			if f, err := number.Float64(); err == nil {
+				if typ == FieldTypeEpochMillis {
+					return time.UnixMilli(int64(f)).UTC().Format(time.RFC3339Nano), nil
+				}
+				seconds, fraction := math.Modf(f)
+				return time.Unix(int64(seconds), int64(fraction*1e9)).UTC().Format(time.RFC3339Nano), nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("a %s value can't be interpreted as %s", jsonType(value), typ)
+}
+
+// numberValue returns the value as a number, also if it is a numeric string
+func numberValue(value any) (json.Number, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		return v, true
+	case string:
+		v = strings.TrimSpace(v)
+		if f, err := strconv.ParseFloat(v, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return json.Number(v), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for the fieldTypes hints of read_item and execute_query that do not need the emulator
+
+func TestParseFieldTypes(t *testing.T) {
+	hints, err := parseFieldTypes(map[string]string{"_ts": FieldTypeEpochSeconds, "order.total": FieldTypeNumber})
+	require.NoError(t, err)
+	require.Len(t, hints, 2)
+	assert.Equal(t, "_ts", hints[0].field)
+	assert.Equal(t, []string{"order", "total"}, hints[1].segments)
+
+	hints, err = parseFieldTypes(nil)
+	require.NoError(t, err)
+	assert.Empty(t, hints)
+
+	_, err = parseFieldTypes(map[string]string{"_ts": "timestamp"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid type 'timestamp' for field '_ts'")
+
+	_, err = parseFieldTypes(map[string]string{"c.name'": FieldTypeString})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid fieldTypes")
+}
+
+func TestTypedFields(t *testing.T) {
+	item := []byte(`{"id": "1", "name": "Jane", "_ts": 1700000000, "created": "2024-01-01T02:00:00+02:00", "zip": "01234", "count": "42", "price": 9.5, "active": "true", "big": 12345678901234567890, "order": {"placed": 1700000000123}}`)
+
+	hints, err := parseFieldTypes(map[string]string{
+		"_ts":          FieldTypeEpochSeconds,
+		"created":      FieldTypeDateTime,
+		"zip":          FieldTypeString,
+		"count":        FieldTypeInteger,
+		"price":        FieldTypeInteger,
+		"active":       FieldTypeBoolean,
+		"big":          FieldTypeString,
+		"order.placed": FieldTypeEpochMillis,
+		"missing":      FieldTypeNumber,
+		"name":         FieldTypeNumber,
+	})
+	require.NoError(t, err)
+
+	fields := map[string]TypedField{}
+	for _, field := range typedFields(item, hints) {
+		fields[field.Field] = field
+	}
+
+	assert.Equal(t, "2023-11-14T22:13:20Z", fields["_ts"].Typed)
+	assert.Equal(t, "number", fields["_ts"].JSONType)
+	assert.Equal(t, "2024-01-01T00:00:00Z", fields["created"].Typed)
+	assert.Equal(t, "01234", fields["zip"].Typed)
+	assert.Equal(t, int64(42), fields["count"].Typed)
+	assert.Equal(t, "string", fields["count"].JSONType)
+	assert.Contains(t, fields["price"].Error, "can't be interpreted as integer")
+	assert.Equal(t, true, fields["active"].Typed)
+	assert.Equal(t, "12345678901234567890", fields["big"].Typed)
+	assert.Equal(t, "2023-11-14T22:13:20.123Z", fields["order.placed"].Typed)
+	assert.Equal(t, "missing", fields["missing"].JSONType)
+	assert.Equal(t, "field not present", fields["missing"].Error)
+	assert.Contains(t, fields["name"].Error, "a string value can't be interpreted as number")
+
+	// the typed fields can be encoded in the tool result
+	_, err = json.Marshal(fields)
+	require.NoError(t, err)
+}
+
+func TestTypedFields_NotAnObject(t *testing.T) {
+	hints, err := parseFieldTypes(map[string]string{"_ts": FieldTypeEpochSeconds})
+	require.NoError(t, err)
+
+	fields := typedFields([]byte(`42`), hints)
+	require.Len(t, fields, 1)
+	assert.Equal(t, "the result is not a JSON object", fields[0].Error)
+
+	assert.Nil(t, resultTypedFields([]string{`{}`}, nil))
+	assert.Len(t, resultTypedFields([]string{`{}`, `{}`}, hints), 2)
+}
+
+func TestConvertFieldValue_NumericStrings(t *testing.T) {
+	_, err := convertFieldValue("NaN", FieldTypeNumber)
+	require.Error(t, err)
+
+	value, err := convertFieldValue(" 3.5 ", FieldTypeNumber)
+	require.NoError(t, err)
+	assert.Equal(t, 3.5, value)
+
+	_, err = convertFieldValue("not a date", FieldTypeDateTime)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not an RFC 3339 timestamp")
+}
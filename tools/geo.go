@@ -0,0 +1,208 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultGeoMaxItems caps the number of results returned by geo_within_distance when maxItems is not specified
+const defaultGeoMaxItems = 100
+
+// maxGeoMaxItems is the upper bound for the maxItems argument of geo_within_distance
+const maxGeoMaxItems = 1000
+
+func GeoWithinDistance() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "geo_within_distance",
+		Description: "Find items in a container in Azure Cosmos DB or local emulator whose location is within a distance (in meters) of a center point, using ST_DISTANCE. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. The field must hold a GeoJSON Point, e.g. {\"type\": \"Point\", \"coordinates\": [longitude, latitude]}, and can be a top level property (location) or a nested one (address.location). Each result includes its distance from the center. Set orderByDistance to true to sort the results from nearest to farthest - this requires a partition key, and if has_more is true only the returned results are sorted, so use a smaller radius. Queries are much cheaper if the field has a spatial index (see spatialIndexPaths of create_container). At most maxItems results are returned (default 100), has_more is true if there are more.",
+	}
+}
+
+type GeoWithinDistanceToolInput struct {
+	ConnectionConfig
+	Database        string  `json:"database" jsonschema:"Name of the database"`
+	Container       string  `json:"container" jsonschema:"Name of the container to query"`
+	Field           string  `json:"field" jsonschema:"Field holding the GeoJSON Point, e.g. location or address.location"`
+	Longitude       float64 `json:"longitude" jsonschema:"Longitude of the center point, between -180 and 180"`
+	Latitude        float64 `json:"latitude" jsonschema:"Latitude of the center point, between -90 and 90"`
+	RadiusMeters    float64 `json:"radiusMeters" jsonschema:"Maximum distance from the center point, in meters"`
+	PartitionKey    string  `json:"partitionKey,omitempty" jsonschema:"The partition key value to scope the query to. If not provided, all partitions are queried. Required if orderByDistance is true"`
+	OrderByDistance bool    `json:"orderByDistance,omitempty" jsonschema:"Set to true to sort the results by distance, nearest first (requires partitionKey)"`
+	MaxItems        int     `json:"maxItems,omitempty" jsonschema:"Maximum number of results to return (default 100, maximum 1000)"`
+}
+
+type GeoResult struct {
+	Item           string  `json:"item" jsonschema:"The matching item as JSON string"`
+	DistanceMeters float64 `json:"distance_meters" jsonschema:"Distance of the item from the center point, in meters"`
+}
+
+type GeoWithinDistanceToolResult struct {
+	Query   string      `json:"query" jsonschema:"The query that was executed, the center point and radius are passed as the @center and @radius parameters"`
+	Results []GeoResult `json:"results"`
+	Count   int         `json:"count" jsonschema:"Number of results returned"`
+	HasMore bool        `json:"has_more" jsonschema:"Whether more items are within the distance than were returned"`
+	Note    string      `json:"note,omitempty" jsonschema:"Set if results were left out to keep the response within the size limit"`
+}
+
+func GeoWithinDistanceToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input GeoWithinDistanceToolInput) (*mcp.CallToolResult, GeoWithinDistanceToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, GeoWithinDistanceToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, GeoWithinDistanceToolResult{}, newInputError("database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, GeoWithinDistanceToolResult{}, newInputError("container name missing")
+	}
+
+	if input.Longitude < -180 || input.Longitude > 180 {
+		return nil, GeoWithinDistanceToolResult{}, newInputError("longitude must be between -180 and 180")
+	}
+
+	if input.Latitude < -90 || input.Latitude > 90 {
+		return nil, GeoWithinDistanceToolResult{}, newInputError("latitude must be between -90 and 90")
+	}
+
+	if input.RadiusMeters <= 0 {
+		return nil, GeoWithinDistanceToolResult{}, newInputError("radiusMeters must be greater than 0")
+	}
+
+	// results are sorted in the MCP server, so ordered queries are limited to a single partition
+	if input.OrderByDistance && input.PartitionKey == "" {
+		return nil, GeoWithinDistanceToolResult{}, newInputError("partition key missing: orderByDistance requires a partitionKey")
+	}
+
+	if input.MaxItems < 0 || input.MaxItems > maxGeoMaxItems {
+		return nil, GeoWithinDistanceToolResult{}, inputErrorf("maxItems must be between 1 and %d", maxGeoMaxItems)
+	}
+
+	maxItems := input.MaxItems
+	if maxItems == 0 {
+		maxItems = defaultGeoMaxItems
+	}
+
+	maxResponseBytes, err := GetMaxResponseBytes()
+	if err != nil {
+		return nil, GeoWithinDistanceToolResult{}, err
+	}
+
+	query, err := geoWithinDistanceQuery(input.Field)
+	if err != nil {
+		return nil, GeoWithinDistanceToolResult{}, err
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, GeoWithinDistanceToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, GeoWithinDistanceToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, GeoWithinDistanceToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	var partitionKey azcosmos.PartitionKey
+	if input.PartitionKey != "" {
+		partitionKey = azcosmos.NewPartitionKeyString(input.PartitionKey)
+	}
+
+	options := &azcosmos.QueryOptions{
+		QueryParameters: []azcosmos.QueryParameter{
+			{Name: "@center", Value: geoJSONPoint(input.Longitude, input.Latitude)},
+			{Name: "@radius", Value: input.RadiusMeters},
+		},
+	}
+
+	queryPager := containerClient.NewQueryItemsPager(query, partitionKey, options)
+
+	rows := [][]byte{}
+	// one more than the cap is fetched to find out if there are more results
+	for queryPager.More() && len(rows) <= maxItems {
+		queryResponse, err := nextPageWithRetry(ctx, queryPager)
+		if err != nil {
+			return nil, GeoWithinDistanceToolResult{}, fmt.Errorf("query page error: %w", withDiagnostics(err))
+		}
+		rows = append(rows, queryResponse.Items...)
+	}
+
+	results, err := parseGeoResults(rows)
+	if err != nil {
+		return nil, GeoWithinDistanceToolResult{}, err
+	}
+
+	if input.OrderByDistance {
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].DistanceMeters < results[j].DistanceMeters
+		})
+	}
+
+	hasMore := len(results) > maxItems || queryPager.More()
+	if len(results) > maxItems {
+		results = results[:maxItems]
+	}
+
+	var note string
+	if kept, omitted := limitResponseSize(results, func(result GeoResult) int { return len(result.Item) }, maxResponseBytes); omitted > 0 {
+		note = responseSizeNote(omitted, len(results), maxResponseBytes)
+		results = kept
+		hasMore = true
+	}
+
+	return nil, GeoWithinDistanceToolResult{
+		Query:   query,
+		Results: results,
+		Count:   len(results),
+		HasMore: hasMore,
+		Note:    note,
+	}, nil
+}
+
+// geoWithinDistanceQuery builds a parameterized ST_DISTANCE query for the field.
+// The center point and the radius are passed as the @center and @radius parameters.
+func geoWithinDistanceQuery(field string) (string, error) {
+	reference, err := fieldReference(field)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("SELECT c AS item, ST_DISTANCE(%[1]s, @center) AS distance FROM c WHERE ST_DISTANCE(%[1]s, @center) <= @radius", reference), nil
+}
+
+// geoJSONPoint returns a GeoJSON Point, note that GeoJSON coordinates are in longitude, latitude order
+func geoJSONPoint(longitude, latitude float64) map[string]any {
+	return map[string]any{
+		"type":        "Point",
+		"coordinates": []float64{longitude, latitude},
+	}
+}
+
+// parseGeoResults converts the rows returned by the geoWithinDistanceQuery into results
+func parseGeoResults(rows [][]byte) ([]GeoResult, error) {
+	results := make([]GeoResult, 0, len(rows))
+
+	for _, row := range rows {
+		var match struct {
+			Item     json.RawMessage `json:"item"`
+			Distance float64         `json:"distance"`
+		}
+		if err := json.Unmarshal(row, &match); err != nil {
+			return nil, fmt.Errorf("error parsing query result: %v", err)
+		}
+		results = append(results, GeoResult{Item: string(match.Item), DistanceMeters: match.Distance})
+	}
+
+	return results, nil
+}
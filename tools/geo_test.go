@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for geospatial helpers that do not need the emulator
+
+func TestGeoWithinDistanceQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		field       string
+		expected    string
+		expectError bool
+	}{
+		{name: "top level field", field: "location", expected: "SELECT c AS item, ST_DISTANCE(c.location, @center) AS distance FROM c WHERE ST_DISTANCE(c.location, @center) <= @radius"},
+		{name: "nested field", field: "address.location", expected: "SELECT c AS item, ST_DISTANCE(c.address.location, @center) AS distance FROM c WHERE ST_DISTANCE(c.address.location, @center) <= @radius"},
+		{name: "missing field", field: "", expectError: true},
+		{name: "invalid field", field: "location, @center) OR (true", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := geoWithinDistanceQuery(tt.field)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, query)
+		})
+	}
+}
+
+func TestGeoJSONPoint(t *testing.T) {
+	point, err := json.Marshal(geoJSONPoint(-122.12, 47.66))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "Point", "coordinates": [-122.12, 47.66]}`, string(point))
+}
+
+func TestParseGeoResults(t *testing.T) {
+	results, err := parseGeoResults([][]byte{
+		[]byte(`{"item": {"id": "1", "location": {"type": "Point", "coordinates": [1, 2]}}, "distance": 12.5}`),
+		[]byte(`{"item": {"id": "2"}, "distance": 0}`),
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.JSONEq(t, `{"id": "1", "location": {"type": "Point", "coordinates": [1, 2]}}`, results[0].Item)
+	assert.Equal(t, 12.5, results[0].DistanceMeters)
+	assert.Equal(t, `{"id": "2"}`, results[1].Item)
+
+	_, err = parseGeoResults([][]byte{[]byte(`not json`)})
+	require.Error(t, err)
+}
@@ -0,0 +1,688 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// etagPath is excluded from indexing by default in every container, so it is left out of explanations
+const etagPath = `/"_etag"/?`
+
+func ExplainIndexingPolicy() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "explain_indexing_policy",
+		Description: "Explain the indexing policy of the specified container in Azure Cosmos DB or local emulator in plain terms - which paths are indexed or excluded, composite indexes and spatial indexes. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Use this to advise on query efficiency, e.g. whether an ORDER BY on multiple properties is backed by a composite index.",
+	}
+}
+
+type ExplainIndexingPolicyToolInput struct {
+	ConnectionConfig
+	Database  string `json:"database" jsonschema:"Azure Cosmos DB database name"`
+	Container string `json:"container" jsonschema:"Azure Cosmos DB container name"`
+}
+
+type ExplainIndexingPolicyToolResult struct {
+	Database       string                   `json:"database"`
+	Container      string                   `json:"container"`
+	Explanation    []string                 `json:"explanation" jsonschema:"Human-readable summary of the indexing policy"`
+	IndexingPolicy *azcosmos.IndexingPolicy `json:"indexing_policy,omitempty" jsonschema:"The raw indexing policy"`
+}
+
+func ExplainIndexingPolicyToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input ExplainIndexingPolicyToolInput) (*mcp.CallToolResult, ExplainIndexingPolicyToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, ExplainIndexingPolicyToolResult{}, err
+	}
+
+	database := input.Database
+
+	if database == "" {
+		return nil, ExplainIndexingPolicyToolResult{}, newInputError("cosmos db database name missing")
+	}
+
+	container := input.Container
+
+	if container == "" {
+		return nil, ExplainIndexingPolicyToolResult{}, newInputError("container name missing")
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, ExplainIndexingPolicyToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(database)
+	if err != nil {
+		return nil, ExplainIndexingPolicyToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(container)
+	if err != nil {
+		return nil, ExplainIndexingPolicyToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	response, err := containerClient.Read(ctx, nil)
+	if err != nil {
+		return nil, ExplainIndexingPolicyToolResult{}, withDiagnostics(err)
+	}
+
+	policy := response.ContainerProperties.IndexingPolicy
+
+	return nil, ExplainIndexingPolicyToolResult{
+		Database:       database,
+		Container:      container,
+		Explanation:    explainIndexingPolicy(policy),
+		IndexingPolicy: policy,
+	}, nil
+}
+
+// explainIndexingPolicy converts an indexing policy into human-readable statements
+func explainIndexingPolicy(policy *azcosmos.IndexingPolicy) []string {
+	if policy == nil {
+		return []string{"No indexing policy is defined, the default policy (all paths indexed) applies"}
+	}
+
+	explanation := []string{}
+
+	switch strings.ToLower(string(policy.IndexingMode)) {
+	case "none":
+		explanation = append(explanation, "Indexing mode is none: nothing is indexed, so only point reads (by id and partition key) are efficient and queries will do full scans")
+		return explanation
+	case "lazy":
+		explanation = append(explanation, "Indexing mode is lazy: the index is updated asynchronously, so query results may be inconsistent right after writes")
+	default:
+		explanation = append(explanation, "Indexing mode is consistent: the index is updated synchronously with every write")
+	}
+
+	if !policy.Automatic {
+		explanation = append(explanation, "Automatic indexing is disabled: items are only indexed if explicitly requested when writing them")
+	}
+
+	included := []string{}
+	allPathsIncluded := false
+	for _, path := range policy.IncludedPaths {
+		if path.Path == "/*" {
+			allPathsIncluded = true
+			continue
+		}
+		included = append(included, path.Path)
+	}
+
+	excluded := []string{}
+	allPathsExcluded := false
+	for _, path := range policy.ExcludedPaths {
+		if path.Path == etagPath {
+			continue
+		}
+		if path.Path == "/*" {
+			allPathsExcluded = true
+			continue
+		}
+		excluded = append(excluded, path.Path)
+	}
+
+	switch {
+	case allPathsIncluded && len(excluded) == 0:
+		explanation = append(explanation, "All paths are indexed")
+	case allPathsIncluded:
+		explanation = append(explanation, fmt.Sprintf("All paths are indexed except %s", strings.Join(excluded, ", ")))
+	case len(included) > 0:
+		explanation = append(explanation, fmt.Sprintf("Only these paths are indexed: %s. Filters on other properties will scan", strings.Join(included, ", ")))
+		if len(excluded) > 0 {
+			explanation = append(explanation, fmt.Sprintf("These paths are explicitly excluded: %s", strings.Join(excluded, ", ")))
+		}
+	case allPathsExcluded || len(policy.IncludedPaths) == 0:
+		explanation = append(explanation, "No paths are indexed (other than system properties). Filters and ORDER BY on item properties will scan")
+	}
+
+	for _, composite := range policy.CompositeIndexes {
+		parts := make([]string, 0, len(composite))
+		for _, index := range composite {
+			order := "asc"
+			if index.Order == azcosmos.CompositeIndexDescending {
+				order = "desc"
+			}
+			parts = append(parts, fmt.Sprintf("%s %s", strings.TrimPrefix(index.Path, "/"), order))
+		}
+		explanation = append(explanation, fmt.Sprintf("Composite index on (%s)", strings.Join(parts, ", ")))
+	}
+
+	if len(policy.CompositeIndexes) == 0 {
+		explanation = append(explanation, "No composite indexes: queries with ORDER BY on multiple properties, or filters combined with ORDER BY, may be expensive or fail")
+	}
+
+	for _, spatial := range policy.SpatialIndexes {
+		types := make([]string, 0, len(spatial.SpatialTypes))
+		for _, spatialType := range spatial.SpatialTypes {
+			types = append(types, string(spatialType))
+		}
+		explanation = append(explanation, fmt.Sprintf("Spatial index on %s for %s", spatial.Path, strings.Join(types, ", ")))
+	}
+
+	return explanation
+}
+
+func AddCompositeIndex() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "add_composite_index",
+		Description: "Add a composite index to the indexing policy of the specified container in Azure Cosmos DB or local emulator, keeping the rest of the policy (including the existing composite indexes) unchanged. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Provide two or more paths, each with an order (ascending or descending, default ascending), e.g. /name ascending and /age descending. If an identical composite index already exists, the policy is not changed. Use this instead of rebuilding the whole indexing policy, e.g. to support an ORDER BY on multiple properties.",
+	}
+}
+
+type CompositeIndexPath struct {
+	Path  string `json:"path" jsonschema:"Path of the property, example /name or /address/city"`
+	Order string `json:"order,omitempty" jsonschema:"Sort order of the path: ascending or descending (default ascending)"`
+}
+
+type AddCompositeIndexToolInput struct {
+	ConnectionConfig
+	Database  string               `json:"database" jsonschema:"Azure Cosmos DB database name"`
+	Container string               `json:"container" jsonschema:"Azure Cosmos DB container name"`
+	Paths     []CompositeIndexPath `json:"paths" jsonschema:"Paths of the composite index, in order (at least two)"`
+}
+
+type AddCompositeIndexToolResult struct {
+	Database         string                      `json:"database"`
+	Container        string                      `json:"container"`
+	Added            bool                        `json:"added" jsonschema:"Whether the composite index was added, false if an identical one already exists"`
+	CompositeIndexes [][]azcosmos.CompositeIndex `json:"composite_indexes" jsonschema:"All composite indexes of the container after the change"`
+	Message          string                      `json:"message"`
+}
+
+func AddCompositeIndexToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input AddCompositeIndexToolInput) (*mcp.CallToolResult, AddCompositeIndexToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, AddCompositeIndexToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, AddCompositeIndexToolResult{}, newInputError("cosmos db database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, AddCompositeIndexToolResult{}, newInputError("container name missing")
+	}
+
+	compositeIndex, err := newCompositeIndex(input.Paths)
+	if err != nil {
+		return nil, AddCompositeIndexToolResult{}, err
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, AddCompositeIndexToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, AddCompositeIndexToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, AddCompositeIndexToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	response, err := containerClient.Read(ctx, nil)
+	if err != nil {
+		return nil, AddCompositeIndexToolResult{}, fmt.Errorf("error reading container: %w", withDiagnostics(err))
+	}
+
+	properties := *response.ContainerProperties
+	if properties.IndexingPolicy == nil {
+		// no policy means the default one, which has to be spelled out so that all paths stay indexed
+		properties.IndexingPolicy = &azcosmos.IndexingPolicy{
+			Automatic:     true,
+			IndexingMode:  azcosmos.IndexingModeConsistent,
+			IncludedPaths: []azcosmos.IncludedPath{{Path: "/*"}},
+		}
+	}
+
+	result := AddCompositeIndexToolResult{
+		Database:  input.Database,
+		Container: input.Container,
+	}
+
+	if hasCompositeIndex(properties.IndexingPolicy.CompositeIndexes, compositeIndex) {
+		result.CompositeIndexes = properties.IndexingPolicy.CompositeIndexes
+		result.Message = fmt.Sprintf("Container '%s' already has the composite index, the indexing policy was not changed", input.Container)
+		return nil, result, nil
+	}
+
+	properties.IndexingPolicy.CompositeIndexes = append(properties.IndexingPolicy.CompositeIndexes, compositeIndex)
+
+	replaceResponse, err := containerClient.Replace(ctx, properties, nil)
+	if err != nil {
+		return nil, AddCompositeIndexToolResult{}, fmt.Errorf("error updating indexing policy: %w", withDiagnostics(err))
+	}
+
+	result.Added = true
+	result.CompositeIndexes = properties.IndexingPolicy.CompositeIndexes
+	if replaceResponse.ContainerProperties != nil && replaceResponse.ContainerProperties.IndexingPolicy != nil {
+		result.CompositeIndexes = replaceResponse.ContainerProperties.IndexingPolicy.CompositeIndexes
+	}
+	result.Message = fmt.Sprintf("Composite index added to container '%s'. Existing items are re-indexed in the background", input.Container)
+
+	return nil, result, nil
+}
+
+// newCompositeIndex validates the paths of a composite index and converts them to the SDK type
+func newCompositeIndex(paths []CompositeIndexPath) ([]azcosmos.CompositeIndex, error) {
+	if len(paths) < 2 {
+		return nil, newInputError("a composite index needs at least two paths")
+	}
+
+	index := make([]azcosmos.CompositeIndex, 0, len(paths))
+	seen := map[string]bool{}
+
+	for _, path := range paths {
+		if !strings.HasPrefix(path.Path, "/") {
+			return nil, inputErrorf("invalid composite index path '%s': path must start with /", path.Path)
+		}
+		if strings.HasSuffix(path.Path, "/?") || strings.HasSuffix(path.Path, "/*") {
+			return nil, inputErrorf("invalid composite index path '%s': composite index paths must not end with /? or /*", path.Path)
+		}
+		if seen[path.Path] {
+			return nil, inputErrorf("duplicate composite index path '%s'", path.Path)
+		}
+		seen[path.Path] = true
+
+		var order azcosmos.CompositeIndexOrder
+		switch strings.ToLower(path.Order) {
+		case "", "asc", string(azcosmos.CompositeIndexAscending):
+			order = azcosmos.CompositeIndexAscending
+		case "desc", string(azcosmos.CompositeIndexDescending):
+			order = azcosmos.CompositeIndexDescending
+		default:
+			return nil, inputErrorf("invalid order '%s' for path '%s', must be one of: ascending, descending", path.Order, path.Path)
+		}
+
+		index = append(index, azcosmos.CompositeIndex{Path: path.Path, Order: order})
+	}
+
+	return index, nil
+}
+
+// hasCompositeIndex checks if an identical composite index (same paths in the same order and with the same sort orders) exists.
+// The service omits the order for ascending paths, so a missing order is treated as ascending.
+func hasCompositeIndex(existing [][]azcosmos.CompositeIndex, index []azcosmos.CompositeIndex) bool {
+	for _, candidate := range existing {
+		if len(candidate) != len(index) {
+			continue
+		}
+
+		identical := true
+		for i := range candidate {
+			if candidate[i].Path != index[i].Path || compositeIndexOrder(candidate[i].Order) != compositeIndexOrder(index[i].Order) {
+				identical = false
+				break
+			}
+		}
+		if identical {
+			return true
+		}
+	}
+	return false
+}
+
+func compositeIndexOrder(order azcosmos.CompositeIndexOrder) azcosmos.CompositeIndexOrder {
+	if order == "" {
+		return azcosmos.CompositeIndexAscending
+	}
+	return azcosmos.CompositeIndexOrder(strings.ToLower(string(order)))
+}
+
+func SetIndexingMode() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "set_indexing_mode",
+		Description: "Set the indexing mode of the specified container in Azure Cosmos DB or local emulator to consistent, lazy or none. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. A common bulk load optimization is to set the mode to none before loading a large amount of data, which makes writes cheaper and faster, and back to consistent afterwards. WARNING: none drops all indexes - the included and excluded paths, composite and spatial indexes are removed from the policy, queries that filter or sort become expensive scans or fail, and switching back to consistent indexes all paths and re-indexes every item in the background. The previous indexing policy is returned so that it can be restored with replace_container_config. lazy is deprecated and rejected by most accounts.",
+	}
+}
+
+type SetIndexingModeToolInput struct {
+	ConnectionConfig
+	Database  string `json:"database" jsonschema:"Azure Cosmos DB database name"`
+	Container string `json:"container" jsonschema:"Azure Cosmos DB container name"`
+	Mode      string `json:"mode" jsonschema:"The indexing mode: consistent, lazy or none"`
+}
+
+type SetIndexingModeToolResult struct {
+	Database               string                   `json:"database"`
+	Container              string                   `json:"container"`
+	PreviousMode           string                   `json:"previous_mode"`
+	Mode                   string                   `json:"mode"`
+	Changed                bool                     `json:"changed" jsonschema:"Whether the indexing mode was changed, false if the container already had it"`
+	PreviousIndexingPolicy *azcosmos.IndexingPolicy `json:"previous_indexing_policy,omitempty" jsonschema:"The indexing policy before the change, to restore it with replace_container_config"`
+	Warning                string                   `json:"warning,omitempty"`
+	Message                string                   `json:"message"`
+}
+
+// indexingModeLazy is deprecated and not defined by the SDK, but still accepted by some existing accounts
+const indexingModeLazy azcosmos.IndexingMode = "Lazy"
+
+func SetIndexingModeToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input SetIndexingModeToolInput) (*mcp.CallToolResult, SetIndexingModeToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, SetIndexingModeToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, SetIndexingModeToolResult{}, newInputError("cosmos db database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, SetIndexingModeToolResult{}, newInputError("container name missing")
+	}
+
+	mode, err := parseIndexingMode(input.Mode)
+	if err != nil {
+		return nil, SetIndexingModeToolResult{}, err
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, SetIndexingModeToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, SetIndexingModeToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, SetIndexingModeToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	response, err := containerClient.Read(ctx, nil)
+	if err != nil {
+		return nil, SetIndexingModeToolResult{}, fmt.Errorf("error reading container: %w", withDiagnostics(err))
+	}
+
+	properties := *response.ContainerProperties
+	previous := properties.IndexingPolicy
+
+	result := SetIndexingModeToolResult{
+		Database:               input.Database,
+		Container:              input.Container,
+		PreviousMode:           indexingModeName(previous),
+		Mode:                   strings.ToLower(string(mode)),
+		PreviousIndexingPolicy: previous,
+	}
+
+	if result.PreviousMode == result.Mode {
+		result.Message = fmt.Sprintf("Container '%s' already has indexing mode %s, the indexing policy was not changed", input.Container, result.Mode)
+		return nil, result, nil
+	}
+
+	if err := checkReplaceKeepsProperties(response, input.Container); err != nil {
+		return nil, SetIndexingModeToolResult{}, err
+	}
+
+	properties.IndexingPolicy = withIndexingMode(previous, mode)
+
+	_, err = containerClient.Replace(ctx, properties, nil)
+	if err != nil {
+		return nil, SetIndexingModeToolResult{}, fmt.Errorf("error updating indexing mode: %w", withDiagnostics(err))
+	}
+
+	result.Changed = true
+	result.Message = fmt.Sprintf("Indexing mode of container '%s' changed from %s to %s", input.Container, result.PreviousMode, result.Mode)
+
+	switch {
+	case mode == azcosmos.IndexingModeNone:
+		result.Warning = "all indexes were dropped: queries that filter or sort scan the container or fail until indexing is turned back on. Setting the mode back to consistent indexes all paths, use replace_container_config with previous_indexing_policy to restore the previous policy"
+	case result.PreviousMode == "none":
+		result.Message += ". All paths are indexed, existing items are re-indexed in the background and queries may return incomplete results until it completes"
+	case mode == indexingModeLazy:
+		result.Warning = "lazy indexing is deprecated, queries may return stale or incomplete results while the index catches up"
+	}
+
+	return nil, result, nil
+}
+
+// parseIndexingMode validates an indexing mode, case-insensitively
+func parseIndexingMode(mode string) (azcosmos.IndexingMode, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "consistent":
+		return azcosmos.IndexingModeConsistent, nil
+	case "lazy":
+		return indexingModeLazy, nil
+	case "none":
+		return azcosmos.IndexingModeNone, nil
+	case "":
+		return "", newInputError("indexing mode missing")
+	}
+	return "", inputErrorf("invalid indexing mode '%s', must be one of: consistent, lazy, none", mode)
+}
+
+// indexingModeName returns the lowercase indexing mode of a policy, no policy means the default consistent mode
+func indexingModeName(policy *azcosmos.IndexingPolicy) string {
+	if policy == nil || policy.IndexingMode == "" {
+		return "consistent"
+	}
+	return strings.ToLower(string(policy.IndexingMode))
+}
+
+// withIndexingMode returns a copy of the policy with the indexing mode. The service rejects paths and indexes with mode none,
+// so they are removed, and a policy without paths (e.g. after mode none) indexes all paths again.
+func withIndexingMode(policy *azcosmos.IndexingPolicy, mode azcosmos.IndexingMode) *azcosmos.IndexingPolicy {
+	if mode == azcosmos.IndexingModeNone {
+		return &azcosmos.IndexingPolicy{IndexingMode: mode}
+	}
+
+	updated := azcosmos.IndexingPolicy{}
+	if policy != nil {
+		updated = *policy
+	}
+	updated.IndexingMode = mode
+	updated.Automatic = true
+	if len(updated.IncludedPaths) == 0 {
+		updated.IncludedPaths = []azcosmos.IncludedPath{{Path: "/*"}}
+		updated.ExcludedPaths = []azcosmos.ExcludedPath{{Path: etagPath}}
+	}
+
+	return &updated
+}
+
+func DiagnoseQuery() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "diagnose_query",
+		Description: "Diagnose how a SQL query uses the indexes of a container in Azure Cosmos DB or local emulator. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. The query is executed with index metrics enabled (only the first page of results is fetched, and the results are not returned) and the tool reports which indexes were used, which filters could benefit from additional single or composite indexes, and recommendations to update the indexing policy. Provide a partition key value to scope the query to a single partition.",
+	}
+}
+
+type DiagnoseQueryToolInput struct {
+	ConnectionConfig
+	Database     string `json:"database" jsonschema:"Name of the database"`
+	Container    string `json:"container" jsonschema:"Name of the container to query"`
+	Query        string `json:"query" jsonschema:"The SQL query string to diagnose"`
+	PartitionKey string `json:"partitionKey,omitempty" jsonschema:"The partition key value for the query. If provided, the query will be scoped to this partition."`
+}
+
+type SingleIndexMetric struct {
+	IndexSpec        string `json:"index_spec" jsonschema:"Indexed path, e.g. /name/?"`
+	FilterExpression string `json:"filter_expression,omitempty" jsonschema:"Filter of the query that uses (or would use) the index"`
+	ImpactScore      string `json:"impact_score,omitempty" jsonschema:"Estimated impact of the index on the query: High or Low"`
+}
+
+type CompositeIndexMetric struct {
+	IndexSpecs  []string `json:"index_specs" jsonschema:"Paths of the composite index with their order, e.g. /name ASC"`
+	ImpactScore string   `json:"impact_score,omitempty" jsonschema:"Estimated impact of the index on the query: High or Low"`
+}
+
+type DiagnoseQueryToolResult struct {
+	Query                     string                 `json:"query"`
+	RequestCharge             float32                `json:"request_charge" jsonschema:"Request charge (RU) of fetching the first page of results"`
+	UtilizedSingleIndexes     []SingleIndexMetric    `json:"utilized_single_indexes,omitempty"`
+	PotentialSingleIndexes    []SingleIndexMetric    `json:"potential_single_indexes,omitempty" jsonschema:"Indexes that are not in the indexing policy but could be used by the query"`
+	UtilizedCompositeIndexes  []CompositeIndexMetric `json:"utilized_composite_indexes,omitempty"`
+	PotentialCompositeIndexes []CompositeIndexMetric `json:"potential_composite_indexes,omitempty" jsonschema:"Composite indexes that are not in the indexing policy but could be used by the query"`
+	Recommendations           []string               `json:"recommendations" jsonschema:"Human-readable indexing advice"`
+}
+
+// indexMetrics is the index utilization information returned by the service when index metrics are requested
+type indexMetrics struct {
+	UtilizedSingleIndexes     []singleIndexMetric    `json:"UtilizedSingleIndexes"`
+	PotentialSingleIndexes    []singleIndexMetric    `json:"PotentialSingleIndexes"`
+	UtilizedCompositeIndexes  []compositeIndexMetric `json:"UtilizedCompositeIndexes"`
+	PotentialCompositeIndexes []compositeIndexMetric `json:"PotentialCompositeIndexes"`
+}
+
+type singleIndexMetric struct {
+	FilterExpression string `json:"FilterExpression"`
+	IndexSpec        string `json:"IndexSpec"`
+	IndexImpactScore string `json:"IndexImpactScore"`
+}
+
+type compositeIndexMetric struct {
+	IndexSpecs       []string `json:"IndexSpecs"`
+	IndexImpactScore string   `json:"IndexImpactScore"`
+}
+
+func DiagnoseQueryToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input DiagnoseQueryToolInput) (*mcp.CallToolResult, DiagnoseQueryToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, DiagnoseQueryToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, DiagnoseQueryToolResult{}, newInputError("database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, DiagnoseQueryToolResult{}, newInputError("container name missing")
+	}
+
+	if input.Query == "" {
+		return nil, DiagnoseQueryToolResult{}, newInputError("query string missing")
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, DiagnoseQueryToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, DiagnoseQueryToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, DiagnoseQueryToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	var partitionKey azcosmos.PartitionKey
+	if input.PartitionKey != "" {
+		partitionKey = azcosmos.NewPartitionKeyString(input.PartitionKey)
+	}
+
+	queryPager := containerClient.NewQueryItemsPager(input.Query, partitionKey, &azcosmos.QueryOptions{PopulateIndexMetrics: true})
+
+	queryResponse, err := nextPageWithRetry(ctx, queryPager)
+	if err != nil {
+		return nil, DiagnoseQueryToolResult{}, fmt.Errorf("query page error: %w", withDiagnostics(err))
+	}
+
+	result := DiagnoseQueryToolResult{
+		Query:         input.Query,
+		RequestCharge: queryResponse.RequestCharge,
+	}
+
+	if queryResponse.IndexMetrics == nil {
+		result.Recommendations = []string{"Index metrics were not returned for this query, the endpoint may not support them (e.g. the emulator)"}
+		return nil, result, nil
+	}
+
+	metrics, err := parseIndexMetrics(*queryResponse.IndexMetrics)
+	if err != nil {
+		return nil, DiagnoseQueryToolResult{}, err
+	}
+
+	result.UtilizedSingleIndexes = toSingleIndexMetrics(metrics.UtilizedSingleIndexes)
+	result.PotentialSingleIndexes = toSingleIndexMetrics(metrics.PotentialSingleIndexes)
+	result.UtilizedCompositeIndexes = toCompositeIndexMetrics(metrics.UtilizedCompositeIndexes)
+	result.PotentialCompositeIndexes = toCompositeIndexMetrics(metrics.PotentialCompositeIndexes)
+	result.Recommendations = indexRecommendations(metrics)
+
+	return nil, result, nil
+}
+
+// parseIndexMetrics parses the index metrics header value, which is base64 encoded JSON
+func parseIndexMetrics(value string) (indexMetrics, error) {
+	raw := []byte(value)
+	if decoded, err := base64.StdEncoding.DecodeString(value); err == nil {
+		raw = decoded
+	}
+
+	var metrics indexMetrics
+	if err := json.Unmarshal(raw, &metrics); err != nil {
+		return indexMetrics{}, fmt.Errorf("error parsing index metrics: %v", err)
+	}
+
+	return metrics, nil
+}
+
+func toSingleIndexMetrics(metrics []singleIndexMetric) []SingleIndexMetric {
+	result := make([]SingleIndexMetric, 0, len(metrics))
+	for _, metric := range metrics {
+		result = append(result, SingleIndexMetric{
+			IndexSpec:        metric.IndexSpec,
+			FilterExpression: metric.FilterExpression,
+			ImpactScore:      metric.IndexImpactScore,
+		})
+	}
+	return result
+}
+
+func toCompositeIndexMetrics(metrics []compositeIndexMetric) []CompositeIndexMetric {
+	result := make([]CompositeIndexMetric, 0, len(metrics))
+	for _, metric := range metrics {
+		result = append(result, CompositeIndexMetric{
+			IndexSpecs:  metric.IndexSpecs,
+			ImpactScore: metric.IndexImpactScore,
+		})
+	}
+	return result
+}
+
+// indexRecommendations turns the index metrics into human-readable advice
+func indexRecommendations(metrics indexMetrics) []string {
+	recommendations := []string{}
+
+	for _, index := range metrics.PotentialSingleIndexes {
+		recommendation := fmt.Sprintf("Include the path %s in the indexing policy", index.IndexSpec)
+		if index.FilterExpression != "" {
+			recommendation += fmt.Sprintf(" to serve the filter %s", index.FilterExpression)
+		}
+		recommendations = append(recommendations, recommendation+impactSuffix(index.IndexImpactScore))
+	}
+
+	for _, index := range metrics.PotentialCompositeIndexes {
+		recommendations = append(recommendations, fmt.Sprintf("Add a composite index on (%s)%s", strings.Join(index.IndexSpecs, ", "), impactSuffix(index.IndexImpactScore)))
+	}
+
+	if len(recommendations) > 0 {
+		return recommendations
+	}
+
+	if len(metrics.UtilizedSingleIndexes) == 0 && len(metrics.UtilizedCompositeIndexes) == 0 {
+		return append(recommendations, "No indexes were used by the query. If the query has a filter, it was evaluated with a full scan - check that the filtered paths are included in the indexing policy")
+	}
+
+	return append(recommendations, "The query uses the existing indexes, no indexing policy changes are recommended")
+}
+
+func impactSuffix(score string) string {
+	if score == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (estimated impact: %s)", score)
+}
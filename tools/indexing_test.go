@@ -0,0 +1,310 @@
+package tools
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for indexing policy helpers that do not need the emulator
+
+func TestExplainIndexingPolicy_Explanation(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   *azcosmos.IndexingPolicy
+		expected []string
+	}{
+		{
+			name: "default policy",
+			policy: &azcosmos.IndexingPolicy{
+				Automatic:     true,
+				IndexingMode:  azcosmos.IndexingModeConsistent,
+				IncludedPaths: []azcosmos.IncludedPath{{Path: "/*"}},
+				ExcludedPaths: []azcosmos.ExcludedPath{{Path: `/"_etag"/?`}},
+			},
+			expected: []string{
+				"Indexing mode is consistent: the index is updated synchronously with every write",
+				"All paths are indexed",
+				"No composite indexes: queries with ORDER BY on multiple properties, or filters combined with ORDER BY, may be expensive or fail",
+			},
+		},
+		{
+			name: "excluded paths and composite index",
+			policy: &azcosmos.IndexingPolicy{
+				Automatic:     true,
+				IndexingMode:  azcosmos.IndexingModeConsistent,
+				IncludedPaths: []azcosmos.IncludedPath{{Path: "/*"}},
+				ExcludedPaths: []azcosmos.ExcludedPath{{Path: "/largeBlob/*"}},
+				CompositeIndexes: [][]azcosmos.CompositeIndex{{
+					{Path: "/lastName", Order: azcosmos.CompositeIndexAscending},
+					{Path: "/firstName", Order: azcosmos.CompositeIndexDescending},
+				}},
+				SpatialIndexes: []azcosmos.SpatialIndex{{
+					Path:         "/location/*",
+					SpatialTypes: []azcosmos.SpatialType{azcosmos.SpatialTypePoint},
+				}},
+			},
+			expected: []string{
+				"Indexing mode is consistent: the index is updated synchronously with every write",
+				"All paths are indexed except /largeBlob/*",
+				"Composite index on (lastName asc, firstName desc)",
+				"Spatial index on /location/* for Point",
+			},
+		},
+		{
+			name: "only selected paths",
+			policy: &azcosmos.IndexingPolicy{
+				Automatic:     true,
+				IndexingMode:  azcosmos.IndexingModeConsistent,
+				IncludedPaths: []azcosmos.IncludedPath{{Path: "/category/?"}},
+				ExcludedPaths: []azcosmos.ExcludedPath{{Path: "/*"}},
+			},
+			expected: []string{
+				"Indexing mode is consistent: the index is updated synchronously with every write",
+				"Only these paths are indexed: /category/?. Filters on other properties will scan",
+				"No composite indexes: queries with ORDER BY on multiple properties, or filters combined with ORDER BY, may be expensive or fail",
+			},
+		},
+		{
+			name: "indexing disabled",
+			policy: &azcosmos.IndexingPolicy{
+				IndexingMode: azcosmos.IndexingModeNone,
+			},
+			expected: []string{
+				"Indexing mode is none: nothing is indexed, so only point reads (by id and partition key) are efficient and queries will do full scans",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, explainIndexingPolicy(tt.policy))
+		})
+	}
+}
+
+func TestParseIndexMetrics(t *testing.T) {
+	raw := `{"UtilizedSingleIndexes":[{"FilterExpression":"","IndexSpec":"/name/?","FilterPreciseSet":true,"IndexPreciseSet":true,"IndexImpactScore":"High"}],"PotentialSingleIndexes":[],"UtilizedCompositeIndexes":[],"PotentialCompositeIndexes":[{"IndexSpecs":["/name ASC","/age ASC"],"IndexPreciseSet":false,"IndexImpactScore":"High"}]}`
+
+	expected := indexMetrics{
+		UtilizedSingleIndexes:     []singleIndexMetric{{IndexSpec: "/name/?", IndexImpactScore: "High"}},
+		PotentialSingleIndexes:    []singleIndexMetric{},
+		UtilizedCompositeIndexes:  []compositeIndexMetric{},
+		PotentialCompositeIndexes: []compositeIndexMetric{{IndexSpecs: []string{"/name ASC", "/age ASC"}, IndexImpactScore: "High"}},
+	}
+
+	t.Run("base64 encoded", func(t *testing.T) {
+		metrics, err := parseIndexMetrics(base64.StdEncoding.EncodeToString([]byte(raw)))
+		require.NoError(t, err)
+		assert.Equal(t, expected, metrics)
+	})
+
+	t.Run("plain JSON", func(t *testing.T) {
+		metrics, err := parseIndexMetrics(raw)
+		require.NoError(t, err)
+		assert.Equal(t, expected, metrics)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := parseIndexMetrics("not metrics")
+		require.Error(t, err)
+	})
+}
+
+func TestIndexRecommendations(t *testing.T) {
+	tests := []struct {
+		name     string
+		metrics  indexMetrics
+		expected []string
+	}{
+		{
+			name: "potential indexes",
+			metrics: indexMetrics{
+				PotentialSingleIndexes:    []singleIndexMetric{{IndexSpec: "/city/?", FilterExpression: "(c.city = \"Seattle\")", IndexImpactScore: "High"}},
+				PotentialCompositeIndexes: []compositeIndexMetric{{IndexSpecs: []string{"/name ASC", "/age DESC"}}},
+			},
+			expected: []string{
+				"Include the path /city/? in the indexing policy to serve the filter (c.city = \"Seattle\") (estimated impact: High)",
+				"Add a composite index on (/name ASC, /age DESC)",
+			},
+		},
+		{
+			name:     "indexes used",
+			metrics:  indexMetrics{UtilizedSingleIndexes: []singleIndexMetric{{IndexSpec: "/name/?"}}},
+			expected: []string{"The query uses the existing indexes, no indexing policy changes are recommended"},
+		},
+		{
+			name:     "no indexes used",
+			metrics:  indexMetrics{},
+			expected: []string{"No indexes were used by the query. If the query has a filter, it was evaluated with a full scan - check that the filtered paths are included in the indexing policy"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, indexRecommendations(tt.metrics))
+		})
+	}
+}
+
+func TestNewCompositeIndex(t *testing.T) {
+	tests := []struct {
+		name     string
+		paths    []CompositeIndexPath
+		expected []azcosmos.CompositeIndex
+		errorMsg string
+	}{
+		{
+			name:  "default and explicit orders",
+			paths: []CompositeIndexPath{{Path: "/name"}, {Path: "/age", Order: "Descending"}, {Path: "/city", Order: "asc"}},
+			expected: []azcosmos.CompositeIndex{
+				{Path: "/name", Order: azcosmos.CompositeIndexAscending},
+				{Path: "/age", Order: azcosmos.CompositeIndexDescending},
+				{Path: "/city", Order: azcosmos.CompositeIndexAscending},
+			},
+		},
+		{
+			name:     "single path",
+			paths:    []CompositeIndexPath{{Path: "/name"}},
+			errorMsg: "at least two paths",
+		},
+		{
+			name:     "path without leading slash",
+			paths:    []CompositeIndexPath{{Path: "/name"}, {Path: "age"}},
+			errorMsg: "path must start with /",
+		},
+		{
+			name:     "wildcard path",
+			paths:    []CompositeIndexPath{{Path: "/name"}, {Path: "/age/?"}},
+			errorMsg: "must not end with /? or /*",
+		},
+		{
+			name:     "duplicate path",
+			paths:    []CompositeIndexPath{{Path: "/name"}, {Path: "/name", Order: "descending"}},
+			errorMsg: "duplicate composite index path '/name'",
+		},
+		{
+			name:     "invalid order",
+			paths:    []CompositeIndexPath{{Path: "/name"}, {Path: "/age", Order: "up"}},
+			errorMsg: "invalid order 'up' for path '/age'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, err := newCompositeIndex(tt.paths)
+			if tt.errorMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, index)
+		})
+	}
+}
+
+func TestHasCompositeIndex(t *testing.T) {
+	existing := [][]azcosmos.CompositeIndex{
+		{{Path: "/name"}, {Path: "/age", Order: azcosmos.CompositeIndexDescending}},
+	}
+
+	tests := []struct {
+		name     string
+		index    []azcosmos.CompositeIndex
+		expected bool
+	}{
+		{
+			name:     "identical, missing order is ascending",
+			index:    []azcosmos.CompositeIndex{{Path: "/name", Order: azcosmos.CompositeIndexAscending}, {Path: "/age", Order: azcosmos.CompositeIndexDescending}},
+			expected: true,
+		},
+		{
+			name:     "different order",
+			index:    []azcosmos.CompositeIndex{{Path: "/name", Order: azcosmos.CompositeIndexAscending}, {Path: "/age", Order: azcosmos.CompositeIndexAscending}},
+			expected: false,
+		},
+		{
+			name:     "different path order",
+			index:    []azcosmos.CompositeIndex{{Path: "/age", Order: azcosmos.CompositeIndexDescending}, {Path: "/name", Order: azcosmos.CompositeIndexAscending}},
+			expected: false,
+		},
+		{
+			name:     "prefix of an existing index",
+			index:    []azcosmos.CompositeIndex{{Path: "/name", Order: azcosmos.CompositeIndexAscending}},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, hasCompositeIndex(existing, tt.index))
+		})
+	}
+
+	assert.False(t, hasCompositeIndex(nil, existing[0]))
+}
+
+func TestParseIndexingMode(t *testing.T) {
+	tests := []struct {
+		mode           string
+		expected       azcosmos.IndexingMode
+		expectedErrMsg string
+	}{
+		{mode: "consistent", expected: azcosmos.IndexingModeConsistent},
+		{mode: "Consistent", expected: azcosmos.IndexingModeConsistent},
+		{mode: " none ", expected: azcosmos.IndexingModeNone},
+		{mode: "lazy", expected: indexingModeLazy},
+		{mode: "", expectedErrMsg: "indexing mode missing"},
+		{mode: "off", expectedErrMsg: "invalid indexing mode 'off'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			mode, err := parseIndexingMode(tt.mode)
+			if tt.expectedErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, mode)
+		})
+	}
+}
+
+func TestWithIndexingMode(t *testing.T) {
+	policy := &azcosmos.IndexingPolicy{
+		Automatic:        true,
+		IndexingMode:     azcosmos.IndexingModeConsistent,
+		IncludedPaths:    []azcosmos.IncludedPath{{Path: "/name/?"}},
+		ExcludedPaths:    []azcosmos.ExcludedPath{{Path: "/*"}},
+		CompositeIndexes: [][]azcosmos.CompositeIndex{{{Path: "/name"}, {Path: "/age"}}},
+	}
+
+	// none drops all paths and indexes, which the service rejects in that mode
+	none := withIndexingMode(policy, azcosmos.IndexingModeNone)
+	assert.Equal(t, &azcosmos.IndexingPolicy{IndexingMode: azcosmos.IndexingModeNone}, none)
+	assert.Len(t, policy.IncludedPaths, 1, "the policy should not be modified")
+
+	// switching back indexes all paths
+	consistent := withIndexingMode(none, azcosmos.IndexingModeConsistent)
+	assert.True(t, consistent.Automatic)
+	assert.Equal(t, []azcosmos.IncludedPath{{Path: "/*"}}, consistent.IncludedPaths)
+	assert.Equal(t, []azcosmos.ExcludedPath{{Path: etagPath}}, consistent.ExcludedPaths)
+
+	// other modes keep the paths and indexes
+	lazy := withIndexingMode(policy, indexingModeLazy)
+	assert.Equal(t, indexingModeLazy, lazy.IndexingMode)
+	assert.Equal(t, policy.IncludedPaths, lazy.IncludedPaths)
+	assert.Equal(t, policy.CompositeIndexes, lazy.CompositeIndexes)
+
+	// no policy is the default policy
+	assert.Equal(t, []azcosmos.IncludedPath{{Path: "/*"}}, withIndexingMode(nil, azcosmos.IndexingModeConsistent).IncludedPaths)
+	assert.Equal(t, "consistent", indexingModeName(nil))
+	assert.Equal(t, "none", indexingModeName(none))
+}
@@ -0,0 +1,231 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultInferSchemaSampleSize is the number of items sampled when the sample size is not specified
+const defaultInferSchemaSampleSize = 100
+
+// maxInferSchemaSampleSize is the upper bound for the sampleSize argument
+const maxInferSchemaSampleSize = 1000
+
+// systemProperties are added to every item by Azure Cosmos DB and are left out of the inferred schema
+var systemProperties = map[string]bool{"_rid": true, "_self": true, "_etag": true, "_attachments": true, "_ts": true, "_lsn": true}
+
+func InferSchema() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "infer_schema",
+		Description: "Infer the schema of the items in a container in Azure Cosmos DB or local emulator by sampling items. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Reports every field path found (nested fields as address.city, array elements as tags[]) with the JSON types observed and how many of the sampled items have it. Use this to learn the shape of the data before writing queries. Provide a partition key value to sample a single partition, otherwise items are sampled across partitions. System properties (_rid, _etag, _ts etc.) are not included.",
+	}
+}
+
+type InferSchemaToolInput struct {
+	ConnectionConfig
+	Database     string `json:"database" jsonschema:"Name of the database"`
+	Container    string `json:"container" jsonschema:"Name of the container"`
+	PartitionKey string `json:"partitionKey,omitempty" jsonschema:"Optional partition key value to sample items from a single partition"`
+	SampleSize   int    `json:"sampleSize,omitempty" jsonschema:"Number of items to sample (default 100, maximum 1000)"`
+}
+
+type InferSchemaToolResult struct {
+	Database     string        `json:"database"`
+	Container    string        `json:"container"`
+	ItemsSampled int           `json:"items_sampled" jsonschema:"Number of items the schema was inferred from"`
+	Fields       []FieldSchema `json:"fields" jsonschema:"Field paths sorted by path"`
+}
+
+// FieldSchema describes a field observed in the sampled items
+type FieldSchema struct {
+	Path      string   `json:"path" jsonschema:"Field path, e.g. address.city or tags[]"`
+	Types     []string `json:"types" jsonschema:"JSON types observed: string, number, boolean, null, object or array"`
+	Count     int      `json:"count" jsonschema:"Number of sampled items that have the field"`
+	Frequency float64  `json:"frequency" jsonschema:"Fraction of the sampled items that have the field, between 0 and 1"`
+}
+
+func InferSchemaToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input InferSchemaToolInput) (*mcp.CallToolResult, InferSchemaToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, InferSchemaToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, InferSchemaToolResult{}, newInputError("database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, InferSchemaToolResult{}, newInputError("container name missing")
+	}
+
+	if input.SampleSize < 0 {
+		return nil, InferSchemaToolResult{}, newInputError("sampleSize must not be negative")
+	}
+
+	sampleSize := input.SampleSize
+	if sampleSize == 0 {
+		sampleSize = defaultInferSchemaSampleSize
+	}
+	if sampleSize > maxInferSchemaSampleSize {
+		sampleSize = maxInferSchemaSampleSize
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, InferSchemaToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, InferSchemaToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, InferSchemaToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	var partitionKey azcosmos.PartitionKey
+	if input.PartitionKey != "" {
+		partitionKey = azcosmos.NewPartitionKeyString(input.PartitionKey)
+	}
+
+	items, err := sampleItems(ctx, containerClient, partitionKey, sampleSize)
+	if err != nil {
+		return nil, InferSchemaToolResult{}, err
+	}
+
+	fields, err := inferFields(items)
+	if err != nil {
+		return nil, InferSchemaToolResult{}, err
+	}
+
+	return nil, InferSchemaToolResult{
+		Database:     input.Database,
+		Container:    input.Container,
+		ItemsSampled: len(items),
+		Fields:       fields,
+	}, nil
+}
+
+// sampleItems returns up to sampleSize items of the container, or of a partition if partitionKey is set
+func sampleItems(ctx context.Context, containerClient *azcosmos.ContainerClient, partitionKey azcosmos.PartitionKey, sampleSize int) ([][]byte, error) {
+	// TOP is not supported for cross-partition queries by the Gateway API, so the sample is capped while paging
+	queryPager := containerClient.NewQueryItemsPager("SELECT * FROM c", partitionKey, &azcosmos.QueryOptions{PageSizeHint: int32(sampleSize)})
+
+	items := [][]byte{}
+
+	for queryPager.More() && len(items) < sampleSize {
+		queryResponse, err := nextPageWithRetry(ctx, queryPager)
+		if err != nil {
+			return nil, fmt.Errorf("query page error: %w", withDiagnostics(err))
+		}
+
+		for _, item := range queryResponse.Items {
+			if len(items) == sampleSize {
+				break
+			}
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
+
+// inferFields returns the union of the field paths of the items with their observed types and frequency
+func inferFields(items [][]byte) ([]FieldSchema, error) {
+	types := map[string]map[string]bool{}
+	counts := map[string]int{}
+
+	for _, item := range items {
+		var document map[string]any
+		if err := json.Unmarshal(item, &document); err != nil {
+			return nil, fmt.Errorf("error parsing item: %v", err)
+		}
+
+		for name := range systemProperties {
+			delete(document, name)
+		}
+
+		// a field is counted once per item, even if it appears in several array elements
+		seen := map[string]bool{}
+		collectFieldTypes(document, "", types, seen)
+		for path := range seen {
+			counts[path]++
+		}
+	}
+
+	fields := make([]FieldSchema, 0, len(types))
+	for path, observed := range types {
+		fieldTypes := make([]string, 0, len(observed))
+		for fieldType := range observed {
+			fieldTypes = append(fieldTypes, fieldType)
+		}
+		slices.Sort(fieldTypes)
+
+		fields = append(fields, FieldSchema{
+			Path:      path,
+			Types:     fieldTypes,
+			Count:     counts[path],
+			Frequency: float64(counts[path]) / float64(len(items)),
+		})
+	}
+
+	slices.SortFunc(fields, func(a, b FieldSchema) int { return strings.Compare(a.Path, b.Path) })
+
+	return fields, nil
+}
+
+// collectFieldTypes records the type of every field of the object, recursing into nested objects and arrays
+func collectFieldTypes(object map[string]any, prefix string, types map[string]map[string]bool, seen map[string]bool) {
+	for name, value := range object {
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		collectValueType(path, value, types, seen)
+	}
+}
+
+func collectValueType(path string, value any, types map[string]map[string]bool, seen map[string]bool) {
+	if types[path] == nil {
+		types[path] = map[string]bool{}
+	}
+	types[path][jsonType(value)] = true
+	seen[path] = true
+
+	switch v := value.(type) {
+	case map[string]any:
+		collectFieldTypes(v, path, types, seen)
+	case []any:
+		for _, element := range v {
+			collectValueType(path+"[]", element, types, seen)
+		}
+	}
+}
+
+// jsonType returns the JSON type name of a decoded value, numbers can be decoded as float64 or json.Number (UseNumber)
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64, json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	default:
+		return "unknown"
+	}
+}
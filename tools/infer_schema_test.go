@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for schema inference helpers that do not need the emulator
+
+func TestInferFields(t *testing.T) {
+	items := [][]byte{
+		[]byte(`{"id": "1", "name": "Jane", "age": 30, "address": {"city": "Seattle"}, "tags": ["a", "b"], "_rid": "abc==", "_ts": 1700000000}`),
+		[]byte(`{"id": "2", "name": null, "age": "thirty", "orders": [{"sku": "x"}, {"sku": "y", "qty": 2}]}`),
+	}
+
+	fields, err := inferFields(items)
+	require.NoError(t, err)
+
+	expected := []FieldSchema{
+		{Path: "address", Types: []string{"object"}, Count: 1, Frequency: 0.5},
+		{Path: "address.city", Types: []string{"string"}, Count: 1, Frequency: 0.5},
+		{Path: "age", Types: []string{"number", "string"}, Count: 2, Frequency: 1},
+		{Path: "id", Types: []string{"string"}, Count: 2, Frequency: 1},
+		{Path: "name", Types: []string{"null", "string"}, Count: 2, Frequency: 1},
+		{Path: "orders", Types: []string{"array"}, Count: 1, Frequency: 0.5},
+		{Path: "orders[]", Types: []string{"object"}, Count: 1, Frequency: 0.5},
+		{Path: "orders[].qty", Types: []string{"number"}, Count: 1, Frequency: 0.5},
+		{Path: "orders[].sku", Types: []string{"string"}, Count: 1, Frequency: 0.5},
+		{Path: "tags", Types: []string{"array"}, Count: 1, Frequency: 0.5},
+		{Path: "tags[]", Types: []string{"string"}, Count: 1, Frequency: 0.5},
+	}
+	assert.Equal(t, expected, fields)
+}
+
+func TestInferFields_NoItems(t *testing.T) {
+	fields, err := inferFields(nil)
+	require.NoError(t, err)
+	assert.Empty(t, fields)
+}
+
+func TestInferFields_InvalidItem(t *testing.T) {
+	_, err := inferFields([][]byte{[]byte(`"not an object"`)})
+	require.Error(t, err)
+}
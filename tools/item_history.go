@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// itemHistoryUnsupportedReason explains why prior versions of an item can't be read
+const itemHistoryUnsupportedReason = "Azure Cosmos DB only stores the current version of an item. Prior versions can only be read from the change feed in all versions and deletes mode, which requires continuous backup, and the Azure SDK for Go has no change feed API."
+
+func ReadItemHistory() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "read_item_history",
+		Description: "Read the prior versions (revisions) of an item in Azure Cosmos DB, e.g. to audit changes to a critical document. Point-in-time item history is NOT available through this MCP server: the tool does not connect to Azure Cosmos DB and always returns supported false, the reason and alternatives, such as read_item_with_metadata to see when the item was last modified.",
+	}
+}
+
+type ReadItemHistoryToolInput struct {
+	Database     string `json:"database,omitempty" jsonschema:"Name of the database (optional)"`
+	Container    string `json:"container,omitempty" jsonschema:"Name of the container (optional)"`
+	ItemID       string `json:"itemID,omitempty" jsonschema:"ID of the item to read the history of (optional)"`
+	PartitionKey string `json:"partitionKey,omitempty" jsonschema:"Partition key value of the item (optional)"`
+}
+
+type ReadItemHistoryToolResult struct {
+	Supported    bool     `json:"supported" jsonschema:"Whether the history of the item can be read, always false"`
+	Reason       string   `json:"reason" jsonschema:"Why the history can't be read"`
+	Alternatives []string `json:"alternatives" jsonschema:"Other ways to find out how the item changed"`
+	Message      string   `json:"message"`
+}
+
+func ReadItemHistoryToolHandler(_ context.Context, _ *mcp.CallToolRequest, input ReadItemHistoryToolInput) (*mcp.CallToolResult, ReadItemHistoryToolResult, error) {
+
+	item := "items"
+	if input.ItemID != "" {
+		item = fmt.Sprintf("item '%s'", input.ItemID)
+	}
+
+	return nil, ReadItemHistoryToolResult{
+		Supported: false,
+		Reason:    itemHistoryUnsupportedReason,
+		Alternatives: []string{
+			"Use read_item_with_metadata to see when the item was last modified (_ts) and its current _etag.",
+			"Read prior versions from the change feed in all versions and deletes mode with the .NET or Java SDK, on an account with continuous backup.",
+			"To keep an audit trail from now on, copy each change into a history container with a change feed processor (create_leases_container prepares its leases container).",
+		},
+		Message: fmt.Sprintf("Point-in-time history of %s is not available through this MCP server", item),
+	}, nil
+}
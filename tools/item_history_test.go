@@ -0,0 +1,29 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for read_item_history that do not need the emulator
+
+func TestReadItemHistoryToolHandler(t *testing.T) {
+	_, result, err := ReadItemHistoryToolHandler(context.Background(), nil, ReadItemHistoryToolInput{
+		Database:  "db1",
+		Container: "orders",
+		ItemID:    "order1",
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Supported)
+	assert.Contains(t, result.Reason, "change feed")
+	assert.NotEmpty(t, result.Alternatives)
+	assert.Contains(t, result.Message, "item 'order1'")
+
+	// the tool answers without any arguments too
+	_, result, err = ReadItemHistoryToolHandler(context.Background(), nil, ReadItemHistoryToolInput{})
+	require.NoError(t, err)
+	assert.False(t, result.Supported)
+}
@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"encoding/json"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// itemSchemaVersion is the only JSON Schema version the validator supports
+const itemSchemaVersion = "https://json-schema.org/draft/2020-12/schema"
+
+// parseItemSchema parses the JSON Schema that items must match before they are written. An empty schema means no validation.
+func parseItemSchema(schema string) (*jsonschema.Resolved, error) {
+	if schema == "" {
+		return nil, nil
+	}
+
+	var parsed jsonschema.Schema
+	if err := json.Unmarshal([]byte(schema), &parsed); err != nil {
+		return nil, inputErrorf("invalid schema: %v", err)
+	}
+
+	if parsed.Schema != "" && parsed.Schema != itemSchemaVersion {
+		return nil, inputErrorf("invalid schema: only JSON Schema draft 2020-12 is supported, remove $schema or set it to %s", itemSchemaVersion)
+	}
+
+	resolved, err := parsed.Resolve(nil)
+	if err != nil {
+		return nil, inputErrorf("invalid schema: %v", err)
+	}
+
+	return resolved, nil
+}
+
+// validateItem checks the item against the schema. The error has the path of the first property that does not match,
+// e.g. validating /properties/address/properties/zip. A nil schema accepts every item.
+func validateItem(schema *jsonschema.Resolved, item []byte) error {
+	if schema == nil {
+		return nil
+	}
+
+	var instance any
+	if err := json.Unmarshal(item, &instance); err != nil {
+		return newInputError("invalid JSON")
+	}
+
+	if err := schema.Validate(instance); err != nil {
+		return inputErrorf("item does not match the schema: %v", err)
+	}
+
+	return nil
+}
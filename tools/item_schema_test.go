@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for item schema validation that do not need the emulator
+
+func TestParseItemSchema(t *testing.T) {
+	tests := []struct {
+		name        string
+		schema      string
+		expectNil   bool
+		expectError string
+	}{
+		{name: "no schema", schema: "", expectNil: true},
+		{name: "valid", schema: `{"type": "object", "required": ["id"]}`},
+		{name: "draft 2020-12", schema: `{"$schema": "https://json-schema.org/draft/2020-12/schema", "type": "object"}`},
+		{name: "other draft", schema: `{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object"}`, expectError: "only JSON Schema draft 2020-12 is supported"},
+		{name: "not JSON", schema: `{"type": `, expectError: "invalid schema"},
+		{name: "invalid keyword value", schema: `{"required": "id"}`, expectError: "invalid schema"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parseItemSchema(tt.schema)
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectNil, schema == nil)
+		})
+	}
+}
+
+func TestValidateItem(t *testing.T) {
+	schema, err := parseItemSchema(`{
+		"type": "object",
+		"required": ["id", "name"],
+		"properties": {
+			"age": {"type": "integer", "minimum": 0},
+			"address": {"type": "object", "properties": {"zip": {"type": "string"}}}
+		}
+	}`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		item        string
+		expectError string
+	}{
+		{name: "valid", item: `{"id": "1", "name": "Jane", "age": 30, "address": {"zip": "98052"}}`},
+		{name: "missing property", item: `{"id": "1"}`, expectError: `missing properties: ["name"]`},
+		{name: "wrong type", item: `{"id": "1", "name": "Jane", "age": 1.5}`, expectError: "/properties/age"},
+		{name: "nested property", item: `{"id": "1", "name": "Jane", "address": {"zip": 98052}}`, expectError: "/properties/address/properties/zip"},
+		{name: "not JSON", item: `{"id": `, expectError: "invalid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateItem(schema, []byte(tt.item))
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	// without a schema every item is accepted
+	assert.NoError(t, validateItem(nil, []byte(`{"id": "1"}`)))
+}
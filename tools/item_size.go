@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxItemSizeBytes is the maximum size of an item in Azure Cosmos DB (2 MB), larger items are rejected with 413 Request Entity Too Large
+const maxItemSizeBytes = 2 * 1024 * 1024
+
+// itemSizeWarningRatio is the share of the item size limit above which estimate_item_size warns
+const itemSizeWarningRatio = 0.9
+
+func EstimateItemSize() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "estimate_item_size",
+		Description: "Estimate the size of an item before inserting it into Azure Cosmos DB, to avoid 413 Request Entity Too Large errors. Does not connect to Azure Cosmos DB. Returns the size of the item serialized as compact JSON, the size of the request body when the item is added as is (including whitespace), how much of the 2 MB limit the request uses, and a warning if it exceeds 90% of the limit. System properties (_rid, _etag, _ts and so on) added by the service take a few hundred more bytes.",
+	}
+}
+
+type EstimateItemSizeToolInput struct {
+	Item string `json:"item" jsonschema:"The item as a JSON object"`
+}
+
+type EstimateItemSizeToolResult struct {
+	SizeBytes      int     `json:"size_bytes" jsonschema:"Size of the item serialized as compact JSON"`
+	RequestBytes   int     `json:"request_bytes" jsonschema:"Size of the item as provided, which is the request body when it is added as is"`
+	LimitBytes     int     `json:"limit_bytes" jsonschema:"Maximum size of an item"`
+	PercentOfLimit float64 `json:"percent_of_limit" jsonschema:"Share of the limit used by the request, in percent"`
+	ExceedsLimit   bool    `json:"exceeds_limit" jsonschema:"Whether the item is too large to be inserted"`
+	Warning        string  `json:"warning,omitempty"`
+}
+
+func EstimateItemSizeToolHandler(_ context.Context, _ *mcp.CallToolRequest, input EstimateItemSizeToolInput) (*mcp.CallToolResult, EstimateItemSizeToolResult, error) {
+
+	if input.Item == "" {
+		return nil, EstimateItemSizeToolResult{}, newInputError("item missing")
+	}
+
+	size, err := itemSize(input.Item)
+	if err != nil {
+		return nil, EstimateItemSizeToolResult{}, err
+	}
+
+	return nil, newItemSizeEstimate(size, len(input.Item)), nil
+}
+
+// itemSize returns the size of the item as compact JSON, without the whitespace of the input
+func itemSize(item string) (int, error) {
+	var object map[string]any
+	if err := json.Unmarshal([]byte(item), &object); err != nil {
+		return 0, inputErrorf("invalid item, must be a JSON object: %v", err)
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(item)); err != nil {
+		return 0, inputErrorf("invalid item, must be a JSON object: %v", err)
+	}
+
+	return compact.Len(), nil
+}
+
+// newItemSizeEstimate compares the size of an item with the item size limit. The item is sent as provided, so the limit
+// applies to requestSize, which can only be larger than the compact size because of whitespace.
+func newItemSizeEstimate(size, requestSize int) EstimateItemSizeToolResult {
+	result := EstimateItemSizeToolResult{
+		SizeBytes:      size,
+		RequestBytes:   requestSize,
+		LimitBytes:     maxItemSizeBytes,
+		PercentOfLimit: math.Round(float64(requestSize)/maxItemSizeBytes*10000) / 100,
+		ExceedsLimit:   requestSize > maxItemSizeBytes,
+	}
+
+	switch {
+	case size > maxItemSizeBytes:
+		result.Warning = fmt.Sprintf("the item is %d bytes larger than the 2 MB limit and will be rejected, split it into several items or move large values (e.g. to Azure Blob Storage) and store a reference", size-maxItemSizeBytes)
+	case result.ExceedsLimit:
+		result.Warning = "the request is larger than the 2 MB limit because of whitespace, remove the whitespace (compact JSON) before adding the item"
+	case float64(requestSize) > maxItemSizeBytes*itemSizeWarningRatio:
+		result.Warning = "the item is close to the 2 MB limit, it may be rejected once system properties are added or when it grows"
+	}
+
+	return result
+}
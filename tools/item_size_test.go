@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for estimate_item_size, it does not connect to the emulator
+
+func TestItemSize(t *testing.T) {
+	tests := []struct {
+		name           string
+		item           string
+		expected       int
+		expectedErrMsg string
+	}{
+		{
+			name:     "compact item",
+			item:     `{"id":"1","name":"Jane"}`,
+			expected: 24,
+		},
+		{
+			name:     "whitespace is not counted",
+			item:     "{\n  \"id\": \"1\",\n  \"name\": \"Jane\"\n}",
+			expected: 24,
+		},
+		{
+			name:     "multi-byte characters",
+			item:     `{"id":"é"}`,
+			expected: 11,
+		},
+		{
+			name:           "invalid JSON",
+			item:           `{"id":`,
+			expectedErrMsg: "invalid item, must be a JSON object",
+		},
+		{
+			name:           "not an object",
+			item:           `[1, 2]`,
+			expectedErrMsg: "invalid item, must be a JSON object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, err := itemSize(tt.item)
+			if tt.expectedErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, size)
+		})
+	}
+}
+
+func TestNewItemSizeEstimate(t *testing.T) {
+	small := newItemSizeEstimate(1000, 1024)
+	assert.Equal(t, 1000, small.SizeBytes)
+	assert.Equal(t, 1024, small.RequestBytes)
+	assert.Equal(t, maxItemSizeBytes, small.LimitBytes)
+	assert.Equal(t, 0.05, small.PercentOfLimit)
+	assert.False(t, small.ExceedsLimit)
+	assert.Empty(t, small.Warning)
+
+	nearLimit := newItemSizeEstimate(maxItemSizeBytes-1024, maxItemSizeBytes-1024)
+	assert.False(t, nearLimit.ExceedsLimit)
+	assert.Contains(t, nearLimit.Warning, "close to the 2 MB limit")
+
+	atLimit := newItemSizeEstimate(maxItemSizeBytes, maxItemSizeBytes)
+	assert.Equal(t, float64(100), atLimit.PercentOfLimit)
+	assert.False(t, atLimit.ExceedsLimit)
+
+	tooLarge := newItemSizeEstimate(maxItemSizeBytes+10, maxItemSizeBytes+20)
+	assert.True(t, tooLarge.ExceedsLimit)
+	assert.Contains(t, tooLarge.Warning, "10 bytes larger than the 2 MB limit")
+
+	whitespace := newItemSizeEstimate(maxItemSizeBytes-10, maxItemSizeBytes+10)
+	assert.True(t, whitespace.ExceedsLimit)
+	assert.Contains(t, whitespace.Warning, "because of whitespace")
+}
+
+func TestEstimateItemSizeToolHandler(t *testing.T) {
+	_, _, err := EstimateItemSizeToolHandler(context.Background(), nil, EstimateItemSizeToolInput{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "item missing")
+
+	large := `{"id": "1", "data": "` + strings.Repeat("a", maxItemSizeBytes) + `"}`
+	_, result, err := EstimateItemSizeToolHandler(context.Background(), nil, EstimateItemSizeToolInput{Item: large})
+	require.NoError(t, err)
+	assert.Equal(t, len(`{"id":"1","data":""}`)+maxItemSizeBytes, result.SizeBytes)
+	assert.True(t, result.ExceedsLimit)
+}
@@ -0,0 +1,147 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultLeasesContainer is the name change feed processors use for their leases container by convention
+const defaultLeasesContainer = "leases"
+
+// leasesPartitionKeyPath is the partition key path change feed processors require for their leases container
+const leasesPartitionKeyPath = "/id"
+
+func CreateLeasesContainer() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "create_leases_container",
+		Description: "Create the leases container used by change feed processors (e.g. the change feed processor of the .NET or Java SDK, or the Azure Functions Cosmos DB trigger) in the specified Azure Cosmos DB database or local emulator, partitioned by /id and without TTL, so that leases never expire. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. The container name defaults to leases. This is idempotent - if the container already exists it is left unchanged, and the result reports whether its configuration is suitable for leases.",
+	}
+}
+
+type CreateLeasesContainerToolInput struct {
+	ConnectionConfig
+	Database   string `json:"database" jsonschema:"Azure Cosmos DB database name"`
+	Container  string `json:"container,omitempty" jsonschema:"Name of the leases container (default leases)"`
+	Throughput *int32 `json:"throughput,omitempty" jsonschema:"Provisioned throughput for the leases container, if it is created (optional, shares the database throughput if not set)"`
+}
+
+type CreateLeasesContainerToolResult struct {
+	Database  string `json:"database"`
+	Container string `json:"container"`
+	Created   bool   `json:"created" jsonschema:"Whether the container was created, false if it already existed"`
+	Warning   string `json:"warning,omitempty" jsonschema:"Set if the existing container is not configured as a leases container"`
+	Message   string `json:"message"`
+}
+
+func CreateLeasesContainerToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input CreateLeasesContainerToolInput) (*mcp.CallToolResult, CreateLeasesContainerToolResult, error) {
+	if err := input.Validate(); err != nil {
+		return nil, CreateLeasesContainerToolResult{}, err
+	}
+
+	database := input.Database
+
+	if database == "" {
+		return nil, CreateLeasesContainerToolResult{}, newInputError("cosmos db database name missing")
+	}
+
+	container := input.Container
+	if container == "" {
+		container = defaultLeasesContainer
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, CreateLeasesContainerToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(database)
+	if err != nil {
+		return nil, CreateLeasesContainerToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(container)
+	if err != nil {
+		return nil, CreateLeasesContainerToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	result := CreateLeasesContainerToolResult{Database: database, Container: container}
+
+	response, err := containerClient.Read(ctx, nil)
+	if err == nil {
+		return nil, existingLeasesContainer(result, *response.ContainerProperties), nil
+	}
+
+	var responseErr *azcore.ResponseError
+	if !errors.As(err, &responseErr) || responseErr.StatusCode != http.StatusNotFound {
+		return nil, CreateLeasesContainerToolResult{}, fmt.Errorf("error reading container: %w", withDiagnostics(err))
+	}
+
+	properties := azcosmos.ContainerProperties{
+		ID: container,
+		PartitionKeyDefinition: azcosmos.PartitionKeyDefinition{
+			Paths: []string{leasesPartitionKeyPath},
+		},
+	}
+
+	var options *azcosmos.CreateContainerOptions
+	if input.Throughput != nil {
+		throughputProps := azcosmos.NewManualThroughputProperties(*input.Throughput)
+		options = &azcosmos.CreateContainerOptions{ThroughputProperties: &throughputProps}
+	}
+
+	_, err = databaseClient.CreateContainer(ctx, properties, options)
+	if err != nil {
+		// created concurrently by another caller, e.g. a change feed processor starting up
+		if errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusConflict {
+			response, readErr := containerClient.Read(ctx, nil)
+			if readErr != nil {
+				return nil, CreateLeasesContainerToolResult{}, fmt.Errorf("error reading container: %w", withDiagnostics(readErr))
+			}
+			return nil, existingLeasesContainer(result, *response.ContainerProperties), nil
+		}
+		return nil, CreateLeasesContainerToolResult{}, fmt.Errorf("error creating leases container: %w", withDiagnostics(err))
+	}
+
+	if err := waitForContainer(ctx, databaseClient, container); err != nil {
+		return nil, CreateLeasesContainerToolResult{}, err
+	}
+
+	result.Created = true
+	result.Message = fmt.Sprintf("Leases container '%s' created in database '%s' with partition key %s and no TTL", container, database, leasesPartitionKeyPath)
+
+	return nil, result, nil
+}
+
+// existingLeasesContainer completes the result for a container that already exists, warning if it can't be used for leases
+func existingLeasesContainer(result CreateLeasesContainerToolResult, properties azcosmos.ContainerProperties) CreateLeasesContainerToolResult {
+	result.Message = fmt.Sprintf("Container '%s' already exists in database '%s', it was not changed", result.Container, result.Database)
+
+	if problems := leasesContainerProblems(properties); len(problems) > 0 {
+		result.Warning = fmt.Sprintf("the existing container is not suitable for leases: %s. Use a different container name", strings.Join(problems, ", "))
+	}
+
+	return result
+}
+
+// leasesContainerProblems lists the settings of a container that prevent change feed processors from using it for leases
+func leasesContainerProblems(properties azcosmos.ContainerProperties) []string {
+	var problems []string
+
+	paths := properties.PartitionKeyDefinition.Paths
+	if len(paths) != 1 || paths[0] != leasesPartitionKeyPath {
+		problems = append(problems, fmt.Sprintf("the partition key is %s instead of %s", strings.Join(paths, ", "), leasesPartitionKeyPath))
+	}
+
+	if ttl := properties.DefaultTimeToLive; ttl != nil && *ttl > 0 {
+		problems = append(problems, fmt.Sprintf("leases expire after the default TTL of %d seconds", *ttl))
+	}
+
+	return problems
+}
@@ -0,0 +1,38 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/stretchr/testify/assert"
+)
+
+// Unit tests for create_leases_container that do not need the emulator
+
+func TestLeasesContainerProblems(t *testing.T) {
+	leases := azcosmos.ContainerProperties{
+		ID:                     "leases",
+		PartitionKeyDefinition: azcosmos.PartitionKeyDefinition{Paths: []string{"/id"}},
+	}
+	assert.Empty(t, leasesContainerProblems(leases))
+
+	// TTL enabled without a default does not expire leases
+	leases.DefaultTimeToLive = to.Ptr[int32](-1)
+	assert.Empty(t, leasesContainerProblems(leases))
+
+	orders := azcosmos.ContainerProperties{
+		ID:                     "orders",
+		PartitionKeyDefinition: azcosmos.PartitionKeyDefinition{Paths: []string{"/customerId"}},
+		DefaultTimeToLive:      to.Ptr[int32](3600),
+	}
+	assert.Equal(t, []string{
+		"the partition key is /customerId instead of /id",
+		"leases expire after the default TTL of 3600 seconds",
+	}, leasesContainerProblems(orders))
+
+	result := existingLeasesContainer(CreateLeasesContainerToolResult{Database: "db1", Container: "orders"}, orders)
+	assert.False(t, result.Created)
+	assert.Contains(t, result.Warning, "not suitable for leases")
+	assert.Contains(t, result.Message, "already exists")
+}
@@ -0,0 +1,357 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultMaterializeViewMaxItems is used when the maximum number of source items is not specified
+const defaultMaterializeViewMaxItems = 1000
+
+// maxMaterializeViewMaxItems is the upper bound for the number of source items processed by a single materialize_view call
+const maxMaterializeViewMaxItems = 10000
+
+// defaultMaterializeViewConcurrency is used when the concurrency is not specified
+const defaultMaterializeViewConcurrency = 4
+
+// maxMaterializeViewConcurrency is the upper bound for concurrent upserts
+const maxMaterializeViewConcurrency = 10
+
+func MaterializeView() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "materialize_view",
+		Description: "Refresh a materialized view: run a query on a source container in Azure Cosmos DB or local emulator, transform each result with a field mapping, and upsert the results into a target container in the same database. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Each mapping copies the value of a source field to a target field (e.g. customer.name to customerName), fields that are not mapped are left out and mapped fields missing in a result are skipped. The mapped items must have a string id, and a value for the partition key path of the target container. Source queries without a partitionKey are rejected unless allowCrossPartition is set to true. At most maxItems source results (default 1000) are processed per call - if has_more is true, pass the returned continuation token back as continuationToken to process the next results. Upserts run concurrently (maxConcurrency, default 4) and failures are reported per item. Upserts are idempotent, so the view can be refreshed by running the tool again.",
+	}
+}
+
+type FieldMapping struct {
+	Source string `json:"source" jsonschema:"Field of the source query result to copy, e.g. id or customer.name"`
+	Target string `json:"target" jsonschema:"Field of the target item to set, e.g. id or customerName"`
+}
+
+type MaterializeViewToolInput struct {
+	ConnectionConfig
+	Database               string         `json:"database" jsonschema:"Name of the database of the source and target containers"`
+	SourceContainer        string         `json:"sourceContainer" jsonschema:"Name of the container to query"`
+	Query                  string         `json:"query" jsonschema:"The SQL query whose results are transformed, it must return JSON objects"`
+	PartitionKey           string         `json:"partitionKey,omitempty" jsonschema:"Partition key value to scope the source query to"`
+	AllowCrossPartition    bool           `json:"allowCrossPartition,omitempty" jsonschema:"Set to true to run the source query across all partitions when no partitionKey is provided (default false)"`
+	TargetContainer        string         `json:"targetContainer" jsonschema:"Name of the container to upsert the transformed items into"`
+	TargetPartitionKeyPath string         `json:"targetPartitionKeyPath" jsonschema:"Partition key path of the target container, example /id, /customerId etc."`
+	Mappings               []FieldMapping `json:"mappings" jsonschema:"Fields to copy from each source result to the target item, one of them must set id"`
+	ContinuationToken      string         `json:"continuationToken,omitempty" jsonschema:"Continuation token returned by a previous call, to resume the source query from where it stopped (optional)"`
+	MaxItems               int            `json:"maxItems,omitempty" jsonschema:"Maximum number of source results to process (default 1000, maximum 10000)"`
+	MaxConcurrency         int            `json:"maxConcurrency,omitempty" jsonschema:"Maximum number of concurrent upserts (default 4, maximum 10)"`
+}
+
+type MaterializeViewFailure struct {
+	Index int    `json:"index" jsonschema:"Position of the source result in this call, starting from 0"`
+	ID    string `json:"id,omitempty" jsonschema:"ID of the target item, if it could be mapped"`
+	Error string `json:"error"`
+}
+
+type MaterializeViewToolResult struct {
+	Database          string                   `json:"database"`
+	SourceContainer   string                   `json:"source_container"`
+	TargetContainer   string                   `json:"target_container"`
+	SourceItems       int                      `json:"source_items" jsonschema:"Number of source results processed"`
+	Upserted          int                      `json:"upserted" jsonschema:"Number of items upserted into the target container"`
+	Failed            int                      `json:"failed" jsonschema:"Number of source results that could not be mapped or upserted"`
+	Failures          []MaterializeViewFailure `json:"failures,omitempty"`
+	HasMore           bool                     `json:"has_more" jsonschema:"Whether the source query has more results that were not processed"`
+	ContinuationToken string                   `json:"continuation_token,omitempty" jsonschema:"Continuation token to process the remaining source results, set if has_more is true"`
+	Message           string                   `json:"message"`
+}
+
+func MaterializeViewToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input MaterializeViewToolInput) (*mcp.CallToolResult, MaterializeViewToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, MaterializeViewToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, MaterializeViewToolResult{}, newInputError("database name missing")
+	}
+
+	if input.SourceContainer == "" {
+		return nil, MaterializeViewToolResult{}, newInputError("source container name missing")
+	}
+
+	if input.TargetContainer == "" {
+		return nil, MaterializeViewToolResult{}, newInputError("target container name missing")
+	}
+
+	if input.Query == "" {
+		return nil, MaterializeViewToolResult{}, newInputError("query string missing")
+	}
+
+	if input.TargetPartitionKeyPath == "" {
+		return nil, MaterializeViewToolResult{}, newInputError("target partition key path missing")
+	}
+
+	mappings, err := parseFieldMappings(input.Mappings)
+	if err != nil {
+		return nil, MaterializeViewToolResult{}, err
+	}
+
+	// guard against accidental full scans
+	if input.PartitionKey == "" && !input.AllowCrossPartition {
+		return nil, MaterializeViewToolResult{}, newInputError("partition key missing: provide a partitionKey to scope the source query to a single partition, or set allowCrossPartition to true to run a cross-partition query that scans all partitions")
+	}
+
+	if input.MaxItems < 0 {
+		return nil, MaterializeViewToolResult{}, newInputError("maxItems must not be negative")
+	}
+
+	maxItems := input.MaxItems
+	if maxItems == 0 {
+		maxItems = defaultMaterializeViewMaxItems
+	}
+	if maxItems > maxMaterializeViewMaxItems {
+		maxItems = maxMaterializeViewMaxItems
+	}
+
+	concurrency := input.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultMaterializeViewConcurrency
+	}
+	if concurrency > maxMaterializeViewConcurrency {
+		concurrency = maxMaterializeViewConcurrency
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, MaterializeViewToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, MaterializeViewToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	sourceClient, err := databaseClient.NewContainer(input.SourceContainer)
+	if err != nil {
+		return nil, MaterializeViewToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	targetClient, err := databaseClient.NewContainer(input.TargetContainer)
+	if err != nil {
+		return nil, MaterializeViewToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	var partitionKey azcosmos.PartitionKey
+	if input.PartitionKey != "" {
+		partitionKey = azcosmos.NewPartitionKeyString(input.PartitionKey)
+	}
+
+	// pages are never larger than the cap, so that the cap can be applied at page boundaries
+	options := &azcosmos.QueryOptions{PageSizeHint: int32(maxItems)}
+	if input.ContinuationToken != "" {
+		options.ContinuationToken = &input.ContinuationToken
+	}
+
+	queryPager := sourceClient.NewQueryItemsPager(input.Query, partitionKey, options)
+
+	response := MaterializeViewToolResult{
+		Database:        input.Database,
+		SourceContainer: input.SourceContainer,
+		TargetContainer: input.TargetContainer,
+	}
+
+	sourceItems := [][]byte{}
+	// token to resume after the last page that was fetched
+	continuationToken := input.ContinuationToken
+
+	for queryPager.More() {
+		queryResponse, err := nextPageWithRetry(ctx, queryPager)
+		if err != nil {
+			return nil, MaterializeViewToolResult{}, fmt.Errorf("query page error: %w", withDiagnostics(err))
+		}
+
+		// stop before exceeding the cap, and resume from this page next time
+		if len(sourceItems)+len(queryResponse.Items) > maxItems && len(sourceItems) > 0 {
+			response.HasMore = true
+			response.ContinuationToken = continuationToken
+			break
+		}
+
+		sourceItems = append(sourceItems, queryResponse.Items...)
+
+		if queryResponse.ContinuationToken != nil {
+			continuationToken = *queryResponse.ContinuationToken
+		}
+
+		if len(sourceItems) >= maxItems {
+			response.HasMore = queryPager.More()
+			if response.HasMore {
+				response.ContinuationToken = continuationToken
+			}
+			break
+		}
+	}
+
+	failures := make([]*MaterializeViewFailure, len(sourceItems))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				failures[i] = upsertMappedItem(ctx, targetClient, sourceItems[i], mappings, input.TargetPartitionKeyPath)
+				if failures[i] != nil {
+					failures[i].Index = i
+				}
+			}
+		}()
+	}
+
+	for i := range sourceItems {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, failure := range failures {
+		if failure != nil {
+			response.Failures = append(response.Failures, *failure)
+		}
+	}
+
+	response.SourceItems = len(sourceItems)
+	response.Failed = len(response.Failures)
+	response.Upserted = response.SourceItems - response.Failed
+
+	response.Message = fmt.Sprintf("Upserted %d of %d items from container '%s' into container '%s'", response.Upserted, response.SourceItems, input.SourceContainer, input.TargetContainer)
+	if response.HasMore {
+		response.Message += ". The source query has more results, pass continuation_token to process them"
+	}
+
+	return nil, response, nil
+}
+
+// fieldMapping is a validated field mapping, with the paths split into property names
+type fieldMapping struct {
+	source []string
+	target []string
+}
+
+// parseFieldMappings validates the mappings. One of them has to set the id of the target item, and no target field can be set twice.
+func parseFieldMappings(mappings []FieldMapping) ([]fieldMapping, error) {
+	if len(mappings) == 0 {
+		return nil, newInputError("mappings missing")
+	}
+
+	parsed := make([]fieldMapping, len(mappings))
+	targets := map[string]bool{}
+
+	for i, mapping := range mappings {
+		source, err := fieldSegments(mapping.Source)
+		if err != nil {
+			return nil, inputErrorf("mapping %d: source: %v", i, err)
+		}
+
+		target, err := fieldSegments(mapping.Target)
+		if err != nil {
+			return nil, inputErrorf("mapping %d: target: %v", i, err)
+		}
+
+		key := fmt.Sprint(target)
+		if targets[key] {
+			return nil, inputErrorf("mapping %d: target field '%s' is set more than once", i, mapping.Target)
+		}
+		targets[key] = true
+
+		parsed[i] = fieldMapping{source: source, target: target}
+	}
+
+	if !targets[fmt.Sprint([]string{"id"})] {
+		return nil, newInputError("mappings must set the id of the target item")
+	}
+
+	return parsed, nil
+}
+
+// mapItem builds the target item from a source query result. Source fields that are missing are skipped.
+func mapItem(source []byte, mappings []fieldMapping) (map[string]any, error) {
+	var object map[string]any
+	if err := json.Unmarshal(source, &object); err != nil || object == nil {
+		return nil, newInputError("source result is not a JSON object, select whole items or fields (not VALUE) in the query")
+	}
+
+	target := map[string]any{}
+
+	for _, mapping := range mappings {
+		var value any = object
+		found := true
+		for _, segment := range mapping.source {
+			parent, ok := value.(map[string]any)
+			if !ok {
+				found = false
+				break
+			}
+			if value, ok = parent[segment]; !ok {
+				found = false
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		parent := target
+		for _, segment := range mapping.target[:len(mapping.target)-1] {
+			child, ok := parent[segment].(map[string]any)
+			if !ok {
+				child = map[string]any{}
+				parent[segment] = child
+			}
+			parent = child
+		}
+		parent[mapping.target[len(mapping.target)-1]] = value
+	}
+
+	if id, ok := target["id"].(string); !ok || id == "" {
+		return nil, newInputError("mapped item has no string id")
+	}
+
+	return target, nil
+}
+
+// upsertMappedItem maps the source result and upserts it into the target container, it returns the failure if any
+func upsertMappedItem(ctx context.Context, targetClient *azcosmos.ContainerClient, source []byte, mappings []fieldMapping, partitionKeyPath string) *MaterializeViewFailure {
+	item, err := mapItem(source, mappings)
+	if err != nil {
+		return &MaterializeViewFailure{Error: err.Error()}
+	}
+
+	id := item["id"].(string)
+
+	value, found := valueAtPath(item, partitionKeyPath)
+	if !found {
+		return &MaterializeViewFailure{ID: id, Error: fmt.Sprintf("partition key path %s not found in mapped item", partitionKeyPath)}
+	}
+
+	partitionKey, err := partitionKeyFromValue(value)
+	if err != nil {
+		return &MaterializeViewFailure{ID: id, Error: err.Error()}
+	}
+
+	data, err := json.Marshal(item)
+	if err != nil {
+		return &MaterializeViewFailure{ID: id, Error: fmt.Sprintf("error marshalling mapped item: %v", err)}
+	}
+
+	if _, err := targetClient.UpsertItem(ctx, partitionKey, data, nil); err != nil {
+		return &MaterializeViewFailure{ID: id, Error: fmt.Sprintf("error upserting item: %v", withDiagnostics(err))}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for materialize_view helpers that do not need the emulator
+
+func TestParseFieldMappings(t *testing.T) {
+	tests := []struct {
+		name        string
+		mappings    []FieldMapping
+		expected    []fieldMapping
+		expectError string
+	}{
+		{
+			name:     "dot and slash paths",
+			mappings: []FieldMapping{{Source: "orderId", Target: "id"}, {Source: "/customer/name", Target: "customer.displayName"}},
+			expected: []fieldMapping{
+				{source: []string{"orderId"}, target: []string{"id"}},
+				{source: []string{"customer", "name"}, target: []string{"customer", "displayName"}},
+			},
+		},
+		{name: "no mappings", expectError: "mappings missing"},
+		{name: "no id", mappings: []FieldMapping{{Source: "name", Target: "name"}}, expectError: "must set the id"},
+		{name: "invalid source", mappings: []FieldMapping{{Source: "a-b", Target: "id"}}, expectError: "mapping 0: source: invalid field name"},
+		{name: "missing target", mappings: []FieldMapping{{Source: "id"}}, expectError: "mapping 0: target: field name missing"},
+		{name: "duplicate target", mappings: []FieldMapping{{Source: "id", Target: "id"}, {Source: "orderId", Target: "/id"}}, expectError: "target field '/id' is set more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mappings, err := parseFieldMappings(tt.mappings)
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, mappings)
+		})
+	}
+}
+
+func TestMapItem(t *testing.T) {
+	mappings, err := parseFieldMappings([]FieldMapping{
+		{Source: "orderId", Target: "id"},
+		{Source: "customer.id", Target: "customerId"},
+		{Source: "customer.name", Target: "summary.customer"},
+		{Source: "total", Target: "summary.total"},
+		{Source: "notes", Target: "notes"},
+	})
+	require.NoError(t, err)
+
+	item, err := mapItem([]byte(`{"id": "1", "orderId": "o1", "customer": {"id": "c1", "name": "Jane"}, "total": 42.5, "_etag": "x"}`), mappings)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"id":         "o1",
+		"customerId": "c1",
+		"summary":    map[string]any{"customer": "Jane", "total": 42.5},
+	}, item, "unmapped fields should be left out and missing fields skipped")
+
+	_, err = mapItem([]byte(`{"orderId": 1}`), mappings)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no string id")
+
+	_, err = mapItem([]byte(`"o1"`), mappings)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a JSON object")
+}
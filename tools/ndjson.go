@@ -0,0 +1,459 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultExportInlineMaxBytes caps the size of NDJSON content returned directly in the tool result
+const defaultExportInlineMaxBytes = 1024 * 1024
+
+// defaultExportFileMaxBytes caps the size of NDJSON content written to a file
+const defaultExportFileMaxBytes = 100 * 1024 * 1024
+
+// ExportDirEnvVar is the directory export_container writes files to. Writing to files is disabled when it is not set.
+const ExportDirEnvVar = "COSMOS_EXPORT_DIR"
+
+func ExportContainer() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "export_container",
+		Description: "Export all items in a container in Azure Cosmos DB or local emulator as newline-delimited JSON (NDJSON). Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. If outputPath is provided, the items are written to a new file at that path relative to the export directory configured on the MCP server (COSMOS_EXPORT_DIR), otherwise the NDJSON content is returned directly (recommended only for small containers). Existing files are never overwritten, and writing to files is not available in read-only mode. The export stops once maxBytes is reached (content returned directly is limited to 1MB) - in that case a continuation token is returned, pass it back (with a different outputPath) to export the remaining items.",
+	}
+}
+
+type ExportContainerToolInput struct {
+	ConnectionConfig
+	Database          string `json:"database" jsonschema:"Azure Cosmos DB database name"`
+	Container         string `json:"container" jsonschema:"Name of the container to export"`
+	OutputPath        string `json:"outputPath,omitempty" jsonschema:"Path of the file to write the NDJSON to, relative to the export directory of the MCP server (e.g. orders.ndjson). The file must not exist. If not provided, the content is returned in the result"`
+	MaxBytes          int    `json:"maxBytes,omitempty" jsonschema:"Maximum size of the exported NDJSON in bytes (default 100MB when writing to a file, default and max 1MB when returning content)"`
+	ContinuationToken string `json:"continuationToken,omitempty" jsonschema:"Continuation token returned by a previous export, to resume from where it stopped"`
+}
+
+type ExportContainerToolResult struct {
+	Account           string `json:"account"`
+	Database          string `json:"database"`
+	Container         string `json:"container"`
+	ItemsExported     int    `json:"items_exported"`
+	Content           string `json:"content,omitempty" jsonschema:"The exported NDJSON content, if outputPath was not provided"`
+	OutputPath        string `json:"output_path,omitempty" jsonschema:"The file the NDJSON content was written to, if outputPath was provided"`
+	ContinuationToken string `json:"continuation_token,omitempty" jsonschema:"Set if the export stopped because of the size limit. Use it to export the remaining items"`
+	Message           string `json:"message"`
+}
+
+func ExportContainerToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input ExportContainerToolInput) (*mcp.CallToolResult, ExportContainerToolResult, error) {
+	if err := input.Validate(); err != nil {
+		return nil, ExportContainerToolResult{}, err
+	}
+
+	database := input.Database
+
+	if database == "" {
+		return nil, ExportContainerToolResult{}, newInputError("cosmos db database name missing")
+	}
+
+	container := input.Container
+
+	if container == "" {
+		return nil, ExportContainerToolResult{}, newInputError("container name missing")
+	}
+
+	if input.MaxBytes < 0 {
+		return nil, ExportContainerToolResult{}, newInputError("maxBytes must not be negative")
+	}
+
+	// the path is checked before querying, so that a bad path does not cost a full export
+	var outputPath string
+	if input.OutputPath != "" {
+		var err error
+		outputPath, err = resolveExportPath(input.OutputPath)
+		if err != nil {
+			return nil, ExportContainerToolResult{}, err
+		}
+		if _, err := os.Stat(outputPath); err == nil {
+			return nil, ExportContainerToolResult{}, inputErrorf("export file '%s' already exists", input.OutputPath)
+		}
+	}
+
+	maxBytes := exportMaxBytes(input.MaxBytes, outputPath != "")
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, ExportContainerToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(database)
+	if err != nil {
+		return nil, ExportContainerToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(container)
+	if err != nil {
+		return nil, ExportContainerToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	options := &azcosmos.QueryOptions{}
+	if input.ContinuationToken != "" {
+		options.ContinuationToken = &input.ContinuationToken
+	}
+
+	queryPager := containerClient.NewQueryItemsPager("SELECT * FROM c", azcosmos.PartitionKey{}, options)
+
+	var content bytes.Buffer
+	itemsExported := 0
+
+	// token to resume from the page currently being processed
+	pageToken := input.ContinuationToken
+	continuationToken := ""
+
+	for queryPager.More() {
+		queryResponse, err := nextPageWithRetry(ctx, queryPager)
+		if err != nil {
+			return nil, ExportContainerToolResult{}, fmt.Errorf("query page error: %w", withDiagnostics(err))
+		}
+
+		var page bytes.Buffer
+		for _, item := range queryResponse.Items {
+			page.Write(item)
+			page.WriteByte('\n')
+		}
+
+		// stop before exceeding the cap, and resume from this page next time.
+		// a single page larger than the cap is still exported so that the export always makes progress
+		if content.Len()+page.Len() > maxBytes && itemsExported > 0 {
+			continuationToken = pageToken
+			break
+		}
+
+		content.Write(page.Bytes())
+		itemsExported += len(queryResponse.Items)
+
+		if queryResponse.ContinuationToken != nil {
+			pageToken = *queryResponse.ContinuationToken
+		}
+	}
+
+	result := ExportContainerToolResult{
+		Account:           input.Account,
+		Database:          database,
+		Container:         container,
+		ItemsExported:     itemsExported,
+		ContinuationToken: continuationToken,
+	}
+
+	if outputPath != "" {
+		if err := writeNewFile(outputPath, content.Bytes()); err != nil {
+			return nil, ExportContainerToolResult{}, fmt.Errorf("error writing export file: %v", err)
+		}
+		result.OutputPath = outputPath
+		result.Message = fmt.Sprintf("Exported %d items from container '%s' in database '%s' to '%s'", itemsExported, container, database, outputPath)
+	} else {
+		result.Content = content.String()
+		result.Message = fmt.Sprintf("Exported %d items from container '%s' in database '%s'", itemsExported, container, database)
+	}
+
+	if continuationToken != "" {
+		result.Message += fmt.Sprintf(". Export stopped at the %d bytes limit, use the continuation token to export the remaining items", maxBytes)
+	}
+
+	return nil, result, nil
+}
+
+// exportMaxBytes returns the size limit of an export. Content returned in the result is capped at defaultExportInlineMaxBytes
+// whatever the requested size, larger exports continue with the continuation token or go to a file.
+func exportMaxBytes(requested int, toFile bool) int {
+	switch {
+	case toFile && requested == 0:
+		return defaultExportFileMaxBytes
+	case toFile:
+		return requested
+	case requested == 0 || requested > defaultExportInlineMaxBytes:
+		return defaultExportInlineMaxBytes
+	default:
+		return requested
+	}
+}
+
+// ExportContainerReadOnlyToolHandler is the export_container handler used in read-only mode, where only returning the content is allowed
+func ExportContainerReadOnlyToolHandler(ctx context.Context, request *mcp.CallToolRequest, input ExportContainerToolInput) (*mcp.CallToolResult, ExportContainerToolResult, error) {
+	if input.OutputPath != "" {
+		return nil, ExportContainerToolResult{}, newInputError("outputPath is not available in read-only mode, omit it to return the content directly")
+	}
+	return ExportContainerToolHandler(ctx, request, input)
+}
+
+// resolveExportPath resolves a path relative to the export directory.
+// Absolute paths and paths that escape the export directory (e.g. ../file) are rejected.
+func resolveExportPath(path string) (string, error) {
+	dir := strings.TrimSpace(os.Getenv(ExportDirEnvVar))
+	if dir == "" {
+		return "", inputErrorf("writing exports to files is disabled, set %s to the directory to write them to", ExportDirEnvVar)
+	}
+
+	path = filepath.Clean(path)
+	if !filepath.IsLocal(path) {
+		return "", inputErrorf("invalid outputPath '%s': must be a relative path inside the export directory", path)
+	}
+
+	return filepath.Join(dir, path), nil
+}
+
+// writeNewFile writes the data to a file that must not exist yet
+func writeNewFile(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// maxBatchOperations is the maximum number of operations in a single transactional batch
+const maxBatchOperations = 100
+
+func ImportContainer() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "import_container",
+		Description: "Import items from newline-delimited JSON (NDJSON) content into a container in Azure Cosmos DB or local emulator. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Each line must be a JSON object with an id field and a value for the partition key path. Items are upserted using transactional batches grouped by partition key value (up to 100 items per batch). The result reports failures with line numbers - note that if one item in a batch fails, the other items in the same batch are not written either. Set schema to a JSON Schema (draft 2020-12) to validate each item before it is written, items that do not match are reported as failures and not imported.",
+	}
+}
+
+type ImportContainerToolInput struct {
+	ConnectionConfig
+	Database         string `json:"database" jsonschema:"Azure Cosmos DB database name"`
+	Container        string `json:"container" jsonschema:"Name of the container to import the items into"`
+	PartitionKeyPath string `json:"partitionKeyPath" jsonschema:"Partition key path of the container, example /id, /tenant, /address/city etc."`
+	Content          string `json:"content" jsonschema:"The NDJSON content to import, one JSON item per line"`
+	Schema           string `json:"schema,omitempty" jsonschema:"JSON Schema (draft 2020-12) every item must match, as a JSON string (optional). Items that do not match are not imported"`
+}
+
+type ImportLineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type ImportContainerToolResult struct {
+	Account       string            `json:"account"`
+	Database      string            `json:"database"`
+	Container     string            `json:"container"`
+	ItemsImported int               `json:"items_imported"`
+	ItemsFailed   int               `json:"items_failed"`
+	Failures      []ImportLineError `json:"failures,omitempty" jsonschema:"Line numbers (starting from 1) of the items that could not be imported, with the reason"`
+	Message       string            `json:"message"`
+}
+
+// importLine is a parsed NDJSON line waiting to be imported
+type importLine struct {
+	number int
+	item   []byte
+}
+
+// importGroup holds the lines that share a partition key value
+type importGroup struct {
+	partitionKey azcosmos.PartitionKey
+	lines        []importLine
+}
+
+func ImportContainerToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input ImportContainerToolInput) (*mcp.CallToolResult, ImportContainerToolResult, error) {
+	if err := input.Validate(); err != nil {
+		return nil, ImportContainerToolResult{}, err
+	}
+
+	database := input.Database
+
+	if database == "" {
+		return nil, ImportContainerToolResult{}, newInputError("cosmos db database name missing")
+	}
+
+	container := input.Container
+
+	if container == "" {
+		return nil, ImportContainerToolResult{}, newInputError("container name missing")
+	}
+
+	partitionKeyPath := input.PartitionKeyPath
+
+	if partitionKeyPath == "" {
+		return nil, ImportContainerToolResult{}, newInputError("partition key path missing")
+	}
+
+	if strings.TrimSpace(input.Content) == "" {
+		return nil, ImportContainerToolResult{}, newInputError("NDJSON content missing")
+	}
+
+	schema, err := parseItemSchema(input.Schema)
+	if err != nil {
+		return nil, ImportContainerToolResult{}, err
+	}
+
+	groups, failures := parseNDJSON(input.Content, partitionKeyPath, schema)
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, ImportContainerToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(database)
+	if err != nil {
+		return nil, ImportContainerToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(container)
+	if err != nil {
+		return nil, ImportContainerToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	imported := 0
+
+	for _, group := range groups {
+		for start := 0; start < len(group.lines); start += maxBatchOperations {
+			lines := group.lines[start:min(start+maxBatchOperations, len(group.lines))]
+
+			batch := containerClient.NewTransactionalBatch(group.partitionKey)
+			for _, line := range lines {
+				batch.UpsertItem(line.item, nil)
+			}
+
+			batchResponse, err := containerClient.ExecuteTransactionalBatch(ctx, batch, nil)
+			if err != nil {
+				for _, line := range lines {
+					failures = append(failures, ImportLineError{Line: line.number, Error: fmt.Sprintf("error executing batch: %v", withDiagnostics(err))})
+				}
+				continue
+			}
+
+			if !batchResponse.Success {
+				for i, result := range batchResponse.OperationResults {
+					message := fmt.Sprintf("failed with status code %d", result.StatusCode)
+					if result.StatusCode == 424 {
+						message = "not written because another item in the same batch failed"
+					}
+					failures = append(failures, ImportLineError{Line: lines[i].number, Error: message})
+				}
+				continue
+			}
+
+			imported += len(lines)
+		}
+	}
+
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Line < failures[j].Line })
+
+	message := fmt.Sprintf("Imported %d items into container '%s' in database '%s'", imported, container, database)
+	if len(failures) > 0 {
+		message += fmt.Sprintf(", %d items failed", len(failures))
+	}
+
+	return nil, ImportContainerToolResult{
+		Account:       input.Account,
+		Database:      database,
+		Container:     container,
+		ItemsImported: imported,
+		ItemsFailed:   len(failures),
+		Failures:      failures,
+		Message:       message,
+	}, nil
+}
+
+// parseNDJSON parses the content line by line and groups the items by partition key value.
+// Blank lines are skipped, invalid lines (including items that do not match the optional schema) are returned as failures.
+func parseNDJSON(content, partitionKeyPath string, schema *jsonschema.Resolved) ([]*importGroup, []ImportLineError) {
+	groups := []*importGroup{}
+	groupsByKey := map[string]*importGroup{}
+	failures := []ImportLineError{}
+
+	for i, raw := range strings.Split(content, "\n") {
+		lineNumber := i + 1
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+
+		var item map[string]any
+		if err := json.Unmarshal([]byte(line), &item); err != nil || item == nil {
+			failures = append(failures, ImportLineError{Line: lineNumber, Error: "invalid JSON object"})
+			continue
+		}
+
+		if id, ok := item["id"].(string); !ok || id == "" {
+			failures = append(failures, ImportLineError{Line: lineNumber, Error: "id field missing or not a string"})
+			continue
+		}
+
+		if err := validateItem(schema, []byte(line)); err != nil {
+			failures = append(failures, ImportLineError{Line: lineNumber, Error: err.Error()})
+			continue
+		}
+
+		value, found := valueAtPath(item, partitionKeyPath)
+		if !found {
+			failures = append(failures, ImportLineError{Line: lineNumber, Error: fmt.Sprintf("partition key path %s not found in item", partitionKeyPath)})
+			continue
+		}
+
+		partitionKey, err := partitionKeyFromValue(value)
+		if err != nil {
+			failures = append(failures, ImportLineError{Line: lineNumber, Error: err.Error()})
+			continue
+		}
+
+		// the JSON encoding of the value is used to group items, so that "1" and 1 are different partitions
+		groupKey, _ := json.Marshal(value)
+
+		group, ok := groupsByKey[string(groupKey)]
+		if !ok {
+			group = &importGroup{partitionKey: partitionKey}
+			groupsByKey[string(groupKey)] = group
+			groups = append(groups, group)
+		}
+		group.lines = append(group.lines, importLine{number: lineNumber, item: []byte(line)})
+	}
+
+	return groups, failures
+}
+
+// valueAtPath returns the value at a partition key path such as /tenant or /address/city
+func valueAtPath(item map[string]any, path string) (any, bool) {
+	var current any = item
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = object[segment]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// partitionKeyFromValue converts a JSON value into a partition key
+func partitionKeyFromValue(value any) (azcosmos.PartitionKey, error) {
+	switch v := value.(type) {
+	case string:
+		return azcosmos.NewPartitionKeyString(v), nil
+	case float64:
+		return azcosmos.NewPartitionKeyNumber(v), nil
+	case bool:
+		return azcosmos.NewPartitionKeyBool(v), nil
+	case nil:
+		return azcosmos.NullPartitionKey, nil
+	default:
+		return azcosmos.PartitionKey{}, newInputError("partition key value must be a string, number, boolean or null")
+	}
+}
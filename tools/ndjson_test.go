@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for NDJSON parsing that do not need the emulator
+
+func TestParseNDJSON(t *testing.T) {
+	content := `{"id": "1", "category": "books"}
+{"id": "2", "category": "books"}
+
+{"id": "3", "category": "music"}
+not json
+{"category": "books"}
+{"id": "4"}
+{"id": "5", "category": ["a"]}
+{"id": "6", "category": 1}
+{"id": "7", "category": null}`
+
+	groups, failures := parseNDJSON(content, "/category", nil)
+
+	require.Len(t, groups, 4)
+	assert.Len(t, groups[0].lines, 2)
+	assert.Equal(t, 1, groups[0].lines[0].number)
+	assert.Equal(t, 2, groups[0].lines[1].number)
+	assert.Len(t, groups[1].lines, 1)
+	assert.Equal(t, 4, groups[1].lines[0].number)
+
+	require.Len(t, failures, 4)
+	assert.Equal(t, 5, failures[0].Line)
+	assert.Contains(t, failures[0].Error, "invalid JSON")
+	assert.Equal(t, 6, failures[1].Line)
+	assert.Contains(t, failures[1].Error, "id field missing")
+	assert.Equal(t, 7, failures[2].Line)
+	assert.Contains(t, failures[2].Error, "partition key path /category not found")
+	assert.Equal(t, 8, failures[3].Line)
+	assert.Contains(t, failures[3].Error, "partition key value must be")
+}
+
+func TestParseNDJSON_Schema(t *testing.T) {
+	schema, err := parseItemSchema(`{"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}}`)
+	require.NoError(t, err)
+
+	content := `{"id": "1", "category": "books", "name": "Go"}
+{"id": "2", "category": "books"}
+{"id": "3", "category": "books", "name": 3}`
+
+	groups, failures := parseNDJSON(content, "/category", schema)
+
+	require.Len(t, groups, 1)
+	require.Len(t, groups[0].lines, 1)
+	assert.Equal(t, 1, groups[0].lines[0].number)
+
+	require.Len(t, failures, 2)
+	assert.Equal(t, 2, failures[0].Line)
+	assert.Contains(t, failures[0].Error, "missing properties")
+	assert.Equal(t, 3, failures[1].Line)
+	assert.Contains(t, failures[1].Error, "/properties/name")
+}
+
+func TestValueAtPath(t *testing.T) {
+	item := map[string]any{
+		"id":      "1",
+		"address": map[string]any{"city": "Seattle"},
+	}
+
+	value, found := valueAtPath(item, "/address/city")
+	require.True(t, found)
+	assert.Equal(t, "Seattle", value)
+
+	value, found = valueAtPath(item, "/id")
+	require.True(t, found)
+	assert.Equal(t, "1", value)
+
+	_, found = valueAtPath(item, "/address/zip")
+	assert.False(t, found)
+
+	_, found = valueAtPath(item, "/id/nested")
+	assert.False(t, found)
+}
+
+func TestResolveExportPath(t *testing.T) {
+	exportDir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		exportDir   string
+		path        string
+		expected    string
+		expectError string
+	}{
+		{name: "file in export directory", exportDir: exportDir, path: "orders.ndjson", expected: filepath.Join(exportDir, "orders.ndjson")},
+		{name: "file in sub directory", exportDir: exportDir, path: "daily/./orders.ndjson", expected: filepath.Join(exportDir, "daily", "orders.ndjson")},
+		{name: "export directory not set", exportDir: "", path: "orders.ndjson", expectError: "writing exports to files is disabled"},
+		{name: "absolute path", exportDir: exportDir, path: "/etc/passwd", expectError: "invalid outputPath"},
+		{name: "parent directory", exportDir: exportDir, path: "../orders.ndjson", expectError: "invalid outputPath"},
+		{name: "escape after clean", exportDir: exportDir, path: "daily/../../orders.ndjson", expectError: "invalid outputPath"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ExportDirEnvVar, tt.exportDir)
+
+			path, err := resolveExportPath(tt.path)
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, path)
+		})
+	}
+}
+
+func TestWriteNewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.ndjson")
+
+	require.NoError(t, writeNewFile(path, []byte("{}\n")))
+
+	err := writeNewFile(path, []byte("overwritten\n"))
+	require.Error(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "{}\n", string(data))
+}
+
+func TestExportMaxBytes(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested int
+		toFile    bool
+		expected  int
+	}{
+		{name: "inline default", requested: 0, expected: defaultExportInlineMaxBytes},
+		{name: "inline smaller", requested: 1000, expected: 1000},
+		{name: "inline larger is capped", requested: 50 * 1024 * 1024, expected: defaultExportInlineMaxBytes},
+		{name: "file default", requested: 0, toFile: true, expected: defaultExportFileMaxBytes},
+		{name: "file larger", requested: 500 * 1024 * 1024, toFile: true, expected: 500 * 1024 * 1024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, exportMaxBytes(tt.requested, tt.toFile))
+		})
+	}
+}
+
+func TestExportContainerReadOnlyToolHandler(t *testing.T) {
+	t.Setenv(ExportDirEnvVar, t.TempDir())
+
+	_, _, err := ExportContainerReadOnlyToolHandler(context.Background(), nil, ExportContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         "db",
+		Container:        "container",
+		OutputPath:       "export.ndjson",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not available in read-only mode")
+}
@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultPageSizeEnvVar sets the page size hint of execute_query when maxItems is not specified, so that operators can
+// tune how many results are fetched per request without every caller specifying it
+const DefaultPageSizeEnvVar = "COSMOS_DEFAULT_PAGE_SIZE"
+
+// GetDefaultPageSize returns the default page size hint configured in the environment, 0 means the page size is the maxItems cap
+func GetDefaultPageSize() (int, error) {
+	value := strings.TrimSpace(os.Getenv(DefaultPageSizeEnvVar))
+	if value == "" {
+		return 0, nil
+	}
+
+	pageSize, err := strconv.Atoi(value)
+	if err != nil || pageSize <= 0 {
+		return 0, fmt.Errorf("invalid value '%s' for %s, must be a positive number of items", value, DefaultPageSizeEnvVar)
+	}
+	return pageSize, nil
+}
+
+// queryPageSize returns the page size hint of a query capped at maxItems results. The default page size only applies
+// when the caller did not specify maxItems, and pages are never larger than the cap so that it can be applied at page boundaries.
+func queryPageSize(maxItems int, maxItemsSpecified bool) (int32, error) {
+	if maxItemsSpecified {
+		return int32(maxItems), nil
+	}
+
+	pageSize, err := GetDefaultPageSize()
+	if err != nil {
+		return 0, err
+	}
+	if pageSize == 0 || pageSize > maxItems {
+		return int32(maxItems), nil
+	}
+	return int32(pageSize), nil
+}
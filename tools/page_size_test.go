@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for the default page size that do not need the emulator
+
+func TestGetDefaultPageSize(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    int
+		expectError bool
+	}{
+		{name: "not set", value: "", expected: 0},
+		{name: "valid", value: "100", expected: 100},
+		{name: "zero", value: "0", expectError: true},
+		{name: "negative", value: "-1", expectError: true},
+		{name: "not a number", value: "many", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(DefaultPageSizeEnvVar, tt.value)
+
+			pageSize, err := GetDefaultPageSize()
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, pageSize)
+		})
+	}
+}
+
+func TestQueryPageSize(t *testing.T) {
+	tests := []struct {
+		name              string
+		value             string
+		maxItems          int
+		maxItemsSpecified bool
+		expected          int32
+		expectError       bool
+	}{
+		{name: "no default", maxItems: 1000, expected: 1000},
+		{name: "default", value: "100", maxItems: 1000, expected: 100},
+		{name: "default larger than the cap", value: "5000", maxItems: 1000, expected: 1000},
+		{name: "maxItems specified", value: "100", maxItems: 500, maxItemsSpecified: true, expected: 500},
+		{name: "invalid default", value: "0", maxItems: 1000, expectError: true},
+		{name: "invalid default ignored when maxItems is specified", value: "0", maxItems: 500, maxItemsSpecified: true, expected: 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(DefaultPageSizeEnvVar, tt.value)
+
+			pageSize, err := queryPageSize(tt.maxItems, tt.maxItemsSpecified)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, pageSize)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Authentication types reported by check_permissions
+const (
+	AuthTypeKey     = "key"
+	AuthTypeEntraID = "entra_id"
+	AuthTypeUnknown = "unknown"
+)
+
+// Permission statuses reported by check_permissions, for single checks and operation categories
+const (
+	PermissionPermitted  = "permitted"
+	PermissionDenied     = "denied"
+	PermissionUnknown    = "unknown"
+	PermissionError      = "error"
+	PermissionNotChecked = "not_checked"
+)
+
+// Operation categories reported by check_permissions
+const (
+	PermissionCategoryRead  = "read"
+	PermissionCategoryWrite = "write"
+)
+
+// permissionProbeID is the ID (and partition key value) of the item used by the read probe. It is not expected to exist,
+// a 404 means the request was authorized.
+const permissionProbeID = "__mcp_cosmosdb_permission_probe__"
+
+func CheckPermissions() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "check_permissions",
+		Description: "Check connectivity to an Azure Cosmos DB account or local emulator and which operation categories (read, write, manage) the configured credential is likely permitted to perform, before attempting them. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Reports whether the credential is key based (all operations permitted) or Microsoft Entra ID (RBAC, permissions depend on the assigned data plane roles, which never allow creating or deleting databases and containers). The account is probed by listing databases. If a database and container are provided, the container metadata is read, and item read permission is probed with a point read of an item that does not exist. No write requests are sent: write permission is inferred from the auth type for keys, and reported as not_checked for Microsoft Entra ID (it depends on the assigned data plane role) or when the server is read-only. Denied checks are reported as insufficient permission for the operation.",
+	}
+}
+
+type CheckPermissionsToolInput struct {
+	ConnectionConfig
+	Database  string `json:"database,omitempty" jsonschema:"Name of the database to check item permissions in (optional, requires container)"`
+	Container string `json:"container,omitempty" jsonschema:"Name of the container to check item permissions in (optional, requires database)"`
+}
+
+type PermissionCheck struct {
+	Operation string `json:"operation" jsonschema:"The probed operation, e.g. list databases"`
+	Category  string `json:"category" jsonschema:"Operation category: read, write or manage"`
+	Status    string `json:"status" jsonschema:"permitted, denied or error"`
+	Detail    string `json:"detail,omitempty" jsonschema:"Why the operation is denied or failed"`
+}
+
+type CheckPermissionsToolResult struct {
+	Account  string            `json:"account"`
+	AuthType string            `json:"auth_type" jsonschema:"key (account key, connection string or emulator), entra_id (Microsoft Entra ID with RBAC) or unknown (client provided by the host application)"`
+	Read     string            `json:"read" jsonschema:"Whether reads (listing and reading databases, containers and items, queries) are likely permitted: permitted, denied or unknown"`
+	Write    string            `json:"write" jsonschema:"Whether item writes (create, upsert, patch, delete) are likely permitted: permitted, unknown or not_checked"`
+	Manage   string            `json:"manage" jsonschema:"Whether creating and changing databases and containers is likely permitted: permitted, denied, unknown or not_checked"`
+	Checks   []PermissionCheck `json:"checks" jsonschema:"The probes that were run"`
+	Notes    []string          `json:"notes,omitempty"`
+}
+
+// CheckPermissionsToolHandler returns the handler of check_permissions. It never sends a request that could modify data, and in
+// read-only mode it doesn't report write or manage permissions since the tools that need them are disabled.
+func CheckPermissionsToolHandler(readOnly bool) mcp.ToolHandlerFor[CheckPermissionsToolInput, CheckPermissionsToolResult] {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, input CheckPermissionsToolInput) (*mcp.CallToolResult, CheckPermissionsToolResult, error) {
+
+		if err := input.Validate(); err != nil {
+			return nil, CheckPermissionsToolResult{}, err
+		}
+
+		if (input.Database == "") != (input.Container == "") {
+			return nil, CheckPermissionsToolResult{}, newInputError("database and container must be provided together")
+		}
+
+		input.ConnectionConfig = input.withDefaultAccount()
+
+		authType, err := input.authType()
+		if err != nil {
+			return nil, CheckPermissionsToolResult{}, err
+		}
+
+		client, err := input.GetClient()
+		if err != nil {
+			return nil, CheckPermissionsToolResult{}, err
+		}
+
+		checks := []PermissionCheck{}
+
+		_, err = client.NewQueryDatabasesPager("SELECT * FROM c", nil).NextPage(ctx)
+		checks = append(checks, newPermissionCheck("list databases", PermissionCategoryRead, err, false))
+
+		var notes []string
+
+		if input.Container != "" {
+			containerClient, err := client.NewContainer(input.Database, input.Container)
+			if err != nil {
+				return nil, CheckPermissionsToolResult{}, fmt.Errorf("error creating container client: %v", err)
+			}
+
+			_, err = containerClient.Read(ctx, nil)
+			containerCheck := newPermissionCheck("read container metadata", PermissionCategoryRead, err, false)
+			checks = append(checks, containerCheck)
+
+			// the item probe relies on a 404 for the item, which is ambiguous if the container does not exist
+			if containerCheck.Status == PermissionPermitted {
+				_, err = containerClient.ReadItem(ctx, azcosmos.NewPartitionKeyString(permissionProbeID), permissionProbeID, nil)
+				checks = append(checks, newPermissionCheck("read items", PermissionCategoryRead, err, true))
+			} else {
+				notes = append(notes, "Item read permission was not checked because the container could not be read.")
+			}
+		} else {
+			notes = append(notes, "Item read permission was not checked, provide a database and container to check it.")
+		}
+
+		result := CheckPermissionsToolResult{
+			Account:  input.Account,
+			AuthType: authType,
+			Read:     summarizePermissions(checks, PermissionCategoryRead),
+			Write:    PermissionNotChecked,
+			Manage:   PermissionNotChecked,
+			Checks:   checks,
+		}
+
+		switch {
+		case readOnly:
+			notes = append(notes, "The server is read-only, tools that write items or manage databases and containers are disabled. Write and manage permissions were not checked.")
+		case authType == AuthTypeKey:
+			// keys grant full access, but an invalid key fails every probe
+			result.Write = result.Read
+			result.Manage = result.Read
+		case authType == AuthTypeEntraID:
+			result.Manage = PermissionDenied
+			notes = append(notes, "Item write permission was not checked, it depends on the assigned data plane role (e.g. Cosmos DB Built-in Data Contributor allows writes, Cosmos DB Built-in Data Reader does not).")
+			notes = append(notes, "Microsoft Entra ID data plane roles cannot create, change or delete databases and containers. Use an account key, or the Azure portal, Azure CLI or ARM to manage them.")
+		default:
+			notes = append(notes, "The client is provided by the host application, so its credential and permissions are not known. Write and manage permissions were not checked.")
+		}
+
+		result.Notes = notes
+
+		return nil, result, nil
+	}
+}
+
+// authType reports how the client for the connection config authenticates, following the same precedence as GetClient
+func (c ConnectionConfig) authType() (string, error) {
+	// a client injected by the host application can use any credential
+	if GetClientFunc != nil {
+		return AuthTypeUnknown, nil
+	}
+
+	c = c.withDefaultAccount()
+
+	if c.UseEmulator {
+		return AuthTypeKey, nil
+	}
+
+	settings, found, err := loadAccountSettings(c.Account)
+	if err != nil {
+		return "", err
+	}
+	if found && (settings.UseEmulator || settings.AuthMode == AuthModeKey || settings.AuthMode == AuthModeConnectionString) {
+		return AuthTypeKey, nil
+	}
+
+	return AuthTypeEntraID, nil
+}
+
+// newPermissionCheck turns the outcome of a probe into a check. A 403 means the credential is not permitted to perform the operation.
+// For probes on an item that does not exist, a 404 means the request was authorized.
+func newPermissionCheck(operation, category string, err error, notFoundPermitted bool) PermissionCheck {
+	check := PermissionCheck{Operation: operation, Category: category, Status: PermissionPermitted}
+	if err == nil {
+		return check
+	}
+
+	var responseErr *azcore.ResponseError
+	if errors.As(err, &responseErr) {
+		switch responseErr.StatusCode {
+		case http.StatusNotFound:
+			if notFoundPermitted {
+				return check
+			}
+		case http.StatusForbidden, http.StatusUnauthorized:
+			check.Status = PermissionDenied
+			check.Detail = fmt.Sprintf("insufficient permission for %s: %v", operation, withDiagnostics(err))
+			return check
+		}
+	}
+
+	check.Status = PermissionError
+	check.Detail = withDiagnostics(err).Error()
+	return check
+}
+
+// summarizePermissions reports whether the operations of a category are likely permitted, based on the checks of that category.
+// Any denied check means denied, and all checks have to succeed for permitted. Otherwise (errors, or no checks) it is not known.
+func summarizePermissions(checks []PermissionCheck, category string) string {
+	permitted, failed := 0, 0
+
+	for _, check := range checks {
+		if check.Category != category {
+			continue
+		}
+
+		switch check.Status {
+		case PermissionDenied:
+			return PermissionDenied
+		case PermissionPermitted:
+			permitted++
+		default:
+			failed++
+		}
+	}
+
+	if permitted > 0 && failed == 0 {
+		return PermissionPermitted
+	}
+	return PermissionUnknown
+}
@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for permission checks that do not need the emulator
+
+func TestNewPermissionCheck(t *testing.T) {
+	responseErr := func(statusCode int) error {
+		return fmt.Errorf("wrapped: %w", &azcore.ResponseError{
+			StatusCode:  statusCode,
+			RawResponse: &http.Response{StatusCode: statusCode, Header: http.Header{}},
+		})
+	}
+
+	tests := []struct {
+		name              string
+		err               error
+		notFoundPermitted bool
+		expectedStatus    string
+		expectedDetail    string
+	}{
+		{name: "success", err: nil, expectedStatus: PermissionPermitted},
+		{name: "probe item not found", err: responseErr(http.StatusNotFound), notFoundPermitted: true, expectedStatus: PermissionPermitted},
+		{name: "resource not found", err: responseErr(http.StatusNotFound), expectedStatus: PermissionError},
+		{name: "forbidden", err: responseErr(http.StatusForbidden), expectedStatus: PermissionDenied, expectedDetail: "insufficient permission for write items"},
+		{name: "unauthorized", err: responseErr(http.StatusUnauthorized), expectedStatus: PermissionDenied, expectedDetail: "insufficient permission for write items"},
+		{name: "other error", err: errors.New("connection refused"), expectedStatus: PermissionError, expectedDetail: "connection refused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := newPermissionCheck("write items", PermissionCategoryWrite, tt.err, tt.notFoundPermitted)
+			assert.Equal(t, "write items", check.Operation)
+			assert.Equal(t, PermissionCategoryWrite, check.Category)
+			assert.Equal(t, tt.expectedStatus, check.Status)
+			assert.Contains(t, check.Detail, tt.expectedDetail)
+		})
+	}
+}
+
+func TestSummarizePermissions(t *testing.T) {
+	read := func(status string) PermissionCheck {
+		return PermissionCheck{Category: PermissionCategoryRead, Status: status}
+	}
+	write := PermissionCheck{Category: PermissionCategoryWrite, Status: PermissionDenied}
+
+	assert.Equal(t, PermissionPermitted, summarizePermissions([]PermissionCheck{read(PermissionPermitted), read(PermissionPermitted), write}, PermissionCategoryRead))
+	assert.Equal(t, PermissionDenied, summarizePermissions([]PermissionCheck{read(PermissionPermitted), read(PermissionDenied)}, PermissionCategoryRead))
+	assert.Equal(t, PermissionUnknown, summarizePermissions([]PermissionCheck{read(PermissionPermitted), read(PermissionError)}, PermissionCategoryRead))
+	assert.Equal(t, PermissionUnknown, summarizePermissions([]PermissionCheck{write}, PermissionCategoryRead))
+	assert.Equal(t, PermissionDenied, summarizePermissions([]PermissionCheck{write}, PermissionCategoryWrite))
+}
+
+func TestConnectionConfig_AuthType(t *testing.T) {
+	// the integration tests inject the emulator client
+	previous := GetClientFunc
+	GetClientFunc = nil
+	t.Cleanup(func() { GetClientFunc = previous })
+
+	writeAccountConfigFile(t, `{
+		"accounts": {
+			"keyaccount": {"authMode": "key", "key": "secret"},
+			"connstr": {"authMode": "connection_string", "connectionString": "AccountEndpoint=https://x.documents.azure.com:443/;AccountKey=secret;"},
+			"rbac": {"authMode": "default_credential"},
+			"local": {"useEmulator": true}
+		}
+	}`)
+
+	tests := []struct {
+		config   ConnectionConfig
+		expected string
+	}{
+		{config: ConnectionConfig{UseEmulator: true}, expected: AuthTypeKey},
+		{config: ConnectionConfig{Account: "keyaccount"}, expected: AuthTypeKey},
+		{config: ConnectionConfig{Account: "connstr"}, expected: AuthTypeKey},
+		{config: ConnectionConfig{Account: "local"}, expected: AuthTypeKey},
+		{config: ConnectionConfig{Account: "rbac"}, expected: AuthTypeEntraID},
+		{config: ConnectionConfig{Account: "not_in_config"}, expected: AuthTypeEntraID},
+		{config: ConnectionConfig{}, expected: AuthTypeKey},
+	}
+
+	// the default account is resolved before the config file is looked up
+	t.Setenv(DefaultAccountEnvVar, "keyaccount")
+
+	for _, tt := range tests {
+		authType, err := tt.config.authType()
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, authType, tt.config.Account)
+	}
+
+	t.Run("client provided by the host application", func(t *testing.T) {
+		GetClientFunc = StaticClientRetriever(nil)
+
+		for _, config := range []ConnectionConfig{{UseEmulator: true}, {Account: "keyaccount"}, {Account: "rbac"}} {
+			authType, err := config.authType()
+			require.NoError(t, err)
+			assert.Equal(t, AuthTypeUnknown, authType, config.Account)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PrettyJSONEnvVar makes the read and query tools return indented JSON by default when set to true.
+// The pretty argument of a tool call overrides it.
+const PrettyJSONEnvVar = "COSMOS_PRETTY_JSON"
+
+// prettyJSONIndent is the indentation of pretty-printed JSON
+const prettyJSONIndent = "  "
+
+// GetPrettyJSON returns whether JSON results are pretty-printed by default as configured in the environment
+func GetPrettyJSON() (bool, error) {
+	value := strings.TrimSpace(os.Getenv(PrettyJSONEnvVar))
+	if value == "" {
+		return false, nil
+	}
+
+	pretty, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value '%s' for %s, must be true or false", value, PrettyJSONEnvVar)
+	}
+	return pretty, nil
+}
+
+// usePrettyJSON returns whether to pretty-print the results of a tool call, the pretty argument takes precedence over the environment
+func usePrettyJSON(pretty *bool) (bool, error) {
+	if pretty != nil {
+		return *pretty, nil
+	}
+	return GetPrettyJSON()
+}
+
+// prettyJSON indents a JSON document, values that are not valid JSON are returned unchanged
+func prettyJSON(value string) string {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(value), "", prettyJSONIndent); err != nil {
+		return value
+	}
+	return indented.String()
+}
+
+// prettyJSONResults indents each JSON document in results in place
+func prettyJSONResults(results []string) {
+	for i, result := range results {
+		results[i] = prettyJSON(result)
+	}
+}
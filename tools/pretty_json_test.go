@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for pretty-printed JSON results that do not need the emulator
+
+func TestUsePrettyJSON(t *testing.T) {
+	yes, no := true, false
+
+	tests := []struct {
+		name        string
+		value       string
+		pretty      *bool
+		expected    bool
+		expectError bool
+	}{
+		{name: "not set", expected: false},
+		{name: "enabled", value: "true", expected: true},
+		{name: "argument overrides environment", value: "true", pretty: &no, expected: false},
+		{name: "argument without environment", pretty: &yes, expected: true},
+		{name: "invalid", value: "yes please", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(PrettyJSONEnvVar, tt.value)
+
+			pretty, err := usePrettyJSON(tt.pretty)
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), PrettyJSONEnvVar)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, pretty)
+		})
+	}
+}
+
+func TestPrettyJSON(t *testing.T) {
+	assert.Equal(t, "{\n  \"id\": \"1\",\n  \"tags\": [\n    \"a\"\n  ]\n}", prettyJSON(`{"id":"1","tags":["a"]}`))
+	assert.Equal(t, "42", prettyJSON("42"))
+	assert.Equal(t, "not json", prettyJSON("not json"))
+
+	results := []string{`{"a":1}`, `"text"`}
+	prettyJSONResults(results)
+	assert.Equal(t, []string{"{\n  \"a\": 1\n}", `"text"`}, results)
+}
@@ -2,9 +2,15 @@ package tools
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -13,7 +19,7 @@ func ReadItem() *mcp.Tool {
 
 	return &mcp.Tool{
 		Name:        "read_item",
-		Description: "Read a specific item from a container in an Azure Cosmos DB database or local emulator using the item ID and partition key. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service.",
+		Description: "Read a specific item from a container in an Azure Cosmos DB database or local emulator using the item ID and partition key. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. To read an item that has no value for the partition key property, set partitionKeyNone to true instead of providing partitionKey. To avoid guessing what ambiguous values mean, set fieldTypes to a map of field to type (string, number, integer, boolean, dateTime, epochSeconds or epochMillis), e.g. {\"_ts\": \"epochSeconds\"} - typed_fields then has each field's value converted to that type, such as an RFC 3339 timestamp for epoch values or a number for a numeric string.",
 	}
 }
 
@@ -22,11 +28,23 @@ type ReadItemToolInput struct {
 	Database     string `json:"database" jsonschema:"Name of the database"`
 	Container    string `json:"container" jsonschema:"Name of the container to read data from"`
 	ItemID       string `json:"itemID" jsonschema:"ID of the item to read"`
-	PartitionKey string `json:"partitionKey" jsonschema:"Partition key value of the item"`
+	PartitionKey string `json:"partitionKey,omitempty" jsonschema:"Partition key value of the item (required unless partitionKeyNone is set)"`
+	SessionToken string `json:"sessionToken,omitempty" jsonschema:"Session token returned by a previous write, to read your own writes under Session consistency (optional)"`
+
+	PartitionKeyNone bool `json:"partitionKeyNone,omitempty" jsonschema:"Set to true to read an item that has no value for the partition key property, instead of providing partitionKey"`
+
+	FieldTypes map[string]string `json:"fieldTypes,omitempty" jsonschema:"Map of field path to type (string, number, integer, boolean, dateTime, epochSeconds or epochMillis) to return the fields converted to that type in typed_fields, e.g. {\"_ts\": \"epochSeconds\"} (optional)"`
+
+	Pretty *bool `json:"pretty,omitempty" jsonschema:"Set to true to return the item as indented JSON for a human reader, or false for compact JSON. Compact JSON uses fewer tokens (default false, unless the server default is changed)"`
+
+	MaxFieldBytes *int `json:"maxFieldBytes,omitempty" jsonschema:"Replace string values larger than this number of bytes, such as base64 encoded data, with a placeholder like <elided 50KB>. Set to 0 to return all values in full (default 0, unless the server default is changed)"`
 }
 
 type ReadItemToolResult struct {
-	Item string `json:"item" jsonschema:"The item data as JSON string"`
+	Item         string       `json:"item" jsonschema:"The item data as JSON string"`
+	SessionToken string       `json:"session_token,omitempty" jsonschema:"Session token of the response, pass it to subsequent reads and queries under Session consistency"`
+	TypedFields  []TypedField `json:"typed_fields,omitempty" jsonschema:"The fields listed in fieldTypes, converted to their type"`
+	ElidedFields int          `json:"elided_fields,omitempty" jsonschema:"Number of string values replaced with a placeholder because they are larger than maxFieldBytes"`
 }
 
 func ReadItemToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input ReadItemToolInput) (*mcp.CallToolResult, ReadItemToolResult, error) {
@@ -36,19 +54,38 @@ func ReadItemToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input Read
 	}
 
 	if input.Database == "" {
-		return nil, ReadItemToolResult{}, errors.New("database name missing")
+		return nil, ReadItemToolResult{}, newInputError("database name missing")
 	}
 
 	if input.Container == "" {
-		return nil, ReadItemToolResult{}, errors.New("container name missing")
+		return nil, ReadItemToolResult{}, newInputError("container name missing")
 	}
 
 	if input.ItemID == "" {
-		return nil, ReadItemToolResult{}, errors.New("item ID missing")
+		return nil, ReadItemToolResult{}, newInputError("item ID missing")
 	}
 
-	if input.PartitionKey == "" {
-		return nil, ReadItemToolResult{}, errors.New("partition key missing")
+	if err := checkPartitionKeyNone(input.PartitionKey, input.PartitionKeyNone); err != nil {
+		return nil, ReadItemToolResult{}, err
+	}
+
+	if input.PartitionKey == "" && !input.PartitionKeyNone {
+		return nil, ReadItemToolResult{}, newInputError("partition key missing")
+	}
+
+	fieldTypes, err := parseFieldTypes(input.FieldTypes)
+	if err != nil {
+		return nil, ReadItemToolResult{}, err
+	}
+
+	pretty, err := usePrettyJSON(input.Pretty)
+	if err != nil {
+		return nil, ReadItemToolResult{}, err
+	}
+
+	maxFieldBytes, err := useMaxFieldBytes(input.MaxFieldBytes)
+	if err != nil {
+		return nil, ReadItemToolResult{}, err
 	}
 
 	client, err := input.GetClient()
@@ -67,20 +104,229 @@ func ReadItemToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input Read
 	}
 
 	partitionKey := azcosmos.NewPartitionKeyString(input.PartitionKey)
+	if input.PartitionKeyNone {
+		ctx = withPartitionKeyNone(ctx)
+		partitionKey = azcosmos.NewPartitionKey()
+	}
+
+	var options *azcosmos.ItemOptions
+	if input.SessionToken != "" {
+		options = &azcosmos.ItemOptions{SessionToken: &input.SessionToken}
+	}
+
+	itemResponse, err := containerClient.ReadItem(ctx, partitionKey, input.ItemID, options)
+	if err != nil {
+		return nil, ReadItemToolResult{}, fmt.Errorf("error reading item: %w", withDiagnostics(err))
+	}
+
+	result := ReadItemToolResult{Item: string(itemResponse.Value)}
+	if itemResponse.SessionToken != nil {
+		result.SessionToken = *itemResponse.SessionToken
+	}
+	if len(fieldTypes) > 0 {
+		result.TypedFields = typedFields(itemResponse.Value, fieldTypes)
+	}
+	if maxFieldBytes > 0 {
+		item, elided := elideLargeFields(itemResponse.Value, maxFieldBytes)
+		result.Item = string(item)
+		result.ElidedFields = elided
+	}
+	if pretty {
+		result.Item = prettyJSON(result.Item)
+	}
+
+	return nil, result, nil
+}
+
+func ReadItemFields() *mcp.Tool {
+
+	return &mcp.Tool{
+		Name:        "read_item_fields",
+		Description: "Read a subset of the fields of a specific item from a container in an Azure Cosmos DB database or local emulator using the item ID and partition key. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Use this instead of read_item for large items when only a few fields are needed. Fields can be top level properties (name) or nested ones (address.city).",
+	}
+}
+
+type ReadItemFieldsToolInput struct {
+	ConnectionConfig
+	Database     string   `json:"database" jsonschema:"Name of the database"`
+	Container    string   `json:"container" jsonschema:"Name of the container to read data from"`
+	ItemID       string   `json:"itemID" jsonschema:"ID of the item to read"`
+	PartitionKey string   `json:"partitionKey" jsonschema:"Partition key value of the item"`
+	Fields       []string `json:"fields" jsonschema:"Fields to read, e.g. name or address.city"`
+}
+
+type ReadItemFieldsToolResult struct {
+	Fields        map[string]any `json:"fields" jsonschema:"The projected fields of the item, keyed by the requested field"`
+	MissingFields []string       `json:"missing_fields,omitempty" jsonschema:"Requested fields that are not present in the item"`
+}
+
+func ReadItemFieldsToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input ReadItemFieldsToolInput) (*mcp.CallToolResult, ReadItemFieldsToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, ReadItemFieldsToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, ReadItemFieldsToolResult{}, newInputError("database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, ReadItemFieldsToolResult{}, newInputError("container name missing")
+	}
+
+	if input.ItemID == "" {
+		return nil, ReadItemFieldsToolResult{}, newInputError("item ID missing")
+	}
+
+	if input.PartitionKey == "" {
+		return nil, ReadItemFieldsToolResult{}, newInputError("partition key missing")
+	}
+
+	query, err := projectionQuery(input.Fields)
+	if err != nil {
+		return nil, ReadItemFieldsToolResult{}, err
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, ReadItemFieldsToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, ReadItemFieldsToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, ReadItemFieldsToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	options := &azcosmos.QueryOptions{
+		QueryParameters: []azcosmos.QueryParameter{{Name: "@id", Value: input.ItemID}},
+	}
+
+	results, err := collectQueryResults(ctx, containerClient, query, azcosmos.NewPartitionKeyString(input.PartitionKey), options)
+	if err != nil {
+		return nil, ReadItemFieldsToolResult{}, err
+	}
+
+	if len(results) == 0 {
+		return nil, ReadItemFieldsToolResult{}, fmt.Errorf("item '%s' not found", input.ItemID)
+	}
+
+	var projected map[string]any
+	if err := json.Unmarshal(results[0], &projected); err != nil {
+		return nil, ReadItemFieldsToolResult{}, fmt.Errorf("error parsing query result: %v", err)
+	}
+
+	result := ReadItemFieldsToolResult{Fields: map[string]any{}}
+	for i, field := range input.Fields {
+		value, ok := projected[fmt.Sprintf("f%d", i)]
+		if !ok {
+			result.MissingFields = append(result.MissingFields, field)
+			continue
+		}
+		result.Fields[field] = value
+	}
+
+	return nil, result, nil
+}
+
+// projectionQuery builds a query that reads the fields of a single item (identified by the @id parameter).
+// Each field is aliased by its position (f0, f1, ...) so that nested fields with the same name do not collide.
+func projectionQuery(fields []string) (string, error) {
+	if len(fields) == 0 {
+		return "", newInputError("fields missing")
+	}
+
+	projections := make([]string, 0, len(fields))
+	for i, field := range fields {
+		reference, err := fieldReference(field)
+		if err != nil {
+			return "", err
+		}
+		projections = append(projections, fmt.Sprintf("%s AS f%d", reference, i))
+	}
+
+	return fmt.Sprintf("SELECT %s FROM c WHERE c.id = @id", strings.Join(projections, ", ")), nil
+}
+
+func ReadItemWithMetadata() *mcp.Tool {
+
+	return &mcp.Tool{
+		Name:        "read_item_with_metadata",
+		Description: "Read a specific item from a container in an Azure Cosmos DB database or local emulator using the item ID and partition key, along with its system metadata: last modified time (_ts as an RFC3339 timestamp), _etag, _self and _rid. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Use this when debugging, e.g. to find out when an item was last modified.",
+	}
+}
+
+type ItemMetadata struct {
+	LastModified string `json:"last_modified" jsonschema:"Last modified time of the item (from _ts) as an RFC3339 timestamp"`
+	Timestamp    int64  `json:"ts" jsonschema:"Raw _ts value (seconds since the Unix epoch)"`
+	ETag         string `json:"etag" jsonschema:"The _etag of the item, used for optimistic concurrency control"`
+	Self         string `json:"self" jsonschema:"The _self link of the item"`
+	ResourceID   string `json:"rid" jsonschema:"The _rid (resource ID) of the item"`
+}
+
+type ReadItemWithMetadataToolResult struct {
+	Item         string       `json:"item" jsonschema:"The item data as JSON string"`
+	Metadata     ItemMetadata `json:"metadata" jsonschema:"System metadata of the item"`
+	SessionToken string       `json:"session_token,omitempty" jsonschema:"Session token of the response, pass it to subsequent reads and queries under Session consistency"`
+}
+
+func ReadItemWithMetadataToolHandler(ctx context.Context, request *mcp.CallToolRequest, input ReadItemToolInput) (*mcp.CallToolResult, ReadItemWithMetadataToolResult, error) {
+
+	_, item, err := ReadItemToolHandler(ctx, request, input)
+	if err != nil {
+		return nil, ReadItemWithMetadataToolResult{}, err
+	}
 
-	itemResponse, err := containerClient.ReadItem(ctx, partitionKey, input.ItemID, nil)
+	metadata, err := itemMetadata([]byte(item.Item))
 	if err != nil {
-		return nil, ReadItemToolResult{}, fmt.Errorf("error reading item: %v", err)
+		return nil, ReadItemWithMetadataToolResult{}, err
+	}
+
+	return nil, ReadItemWithMetadataToolResult{Item: item.Item, Metadata: metadata, SessionToken: item.SessionToken}, nil
+}
+
+// itemMetadata extracts the system properties from the raw item
+func itemMetadata(item []byte) (ItemMetadata, error) {
+	var system struct {
+		Ts   int64  `json:"_ts"`
+		ETag string `json:"_etag"`
+		Self string `json:"_self"`
+		Rid  string `json:"_rid"`
+	}
+
+	if err := json.Unmarshal(item, &system); err != nil {
+		return ItemMetadata{}, fmt.Errorf("error parsing item: %v", err)
+	}
+
+	metadata := ItemMetadata{
+		Timestamp:  system.Ts,
+		ETag:       system.ETag,
+		Self:       system.Self,
+		ResourceID: system.Rid,
 	}
 
-	return nil, ReadItemToolResult{Item: string(itemResponse.Value)}, nil
+	if system.Ts > 0 {
+		metadata.LastModified = time.Unix(system.Ts, 0).UTC().Format(time.RFC3339)
+	}
+
+	return metadata, nil
 }
 
+// defaultExecuteQueryMaxItems caps the number of results returned by execute_query when maxItems is not specified
+const defaultExecuteQueryMaxItems = 1000
+
+// maxExecuteQueryMaxItems is the upper bound for the maxItems argument of execute_query
+const maxExecuteQueryMaxItems = 10000
+
 func ExecuteQuery() *mcp.Tool {
 
 	return &mcp.Tool{
 		Name: "execute_query",
-		Description: `Execute a SQL query on a Cosmos DB container in Azure Cosmos DB or local emulator. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Ensure that the query string is valid and adheres to Cosmos DB SQL syntax. To use a partition key in the query directly, add it in the WHERE clause. Example: SELECT * FROM c WHERE c.department='HR'.
+		Description: `Execute a SQL query on a Cosmos DB container in Azure Cosmos DB or local emulator. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Ensure that the query string is valid and adheres to Cosmos DB SQL syntax. To use a partition key in the query directly, add it in the WHERE clause. Example: SELECT * FROM c WHERE c.department='HR'. Queries without a partitionKey scan all partitions and can consume a lot of RUs, so they are rejected unless allowCrossPartition is set to true - prefer providing a partitionKey. To query the items that have no value for the partition key property, set partitionKeyNone to true instead of providing a partitionKey. At most maxItems results are returned (default 1000) - if has_more is true, pass the returned continuation token back as continuationToken to fetch the next results (if cursors are enabled on the server, the continuation token is a short cursor id starting with cur_ that is valid for a limited time and only for the same query). If the query cache is enabled on the server, repeated identical queries may be served from the cache (cached is true) - set bypassCache to true when fresh results are needed, e.g. right after a change made outside of this server. To avoid guessing what ambiguous values mean, set fieldTypes to a map of field to type (string, number, integer, boolean, dateTime, epochSeconds or epochMillis), e.g. {"_ts": "epochSeconds"} - typed_fields then has each result's fields converted to that type, such as an RFC 3339 timestamp for epoch values or a number for a numeric string.
 
 IMPORTANT LIMITATION: The Azure Cosmos DB Gateway API (used by the Go SDK) only supports simple projections and filtering for cross-partition queries.
 
@@ -90,6 +336,9 @@ WORKAROUNDS (in this order):
 - Unless required, for cross-partition queries, use only SELECT and WHERE clauses, then sort/limit/aggregate the results. For example, assume you have formed this query - SELECT c.desc, c.price FROM c ORDER BY c.price DESC. Figure out if ORDER BY is really needed based on user requirements.
 - Check if you can use a different query without the unsupported features.
 - If that does not work, add a partition key value to scope the query to a single partition.
+- For aggregates (COUNT, SUM, AVG, MIN, MAX) that must span partitions, use the client_side_aggregate tool instead.
+- For DISTINCT values of a field that must span partitions, use the distinct_values tool instead.
+- To skip results with OFFSET LIMIT within a partition, use the paginated_query tool.
 
 For details, refer to https://learn.microsoft.com/en-us/rest/api/cosmos-db/querying-cosmosdb-resources-using-the-rest-api#queries-that-cannot-be-served-by-gateway`,
 	}
@@ -101,12 +350,39 @@ type ExecuteQueryToolInput struct {
 	Container    string `json:"container" jsonschema:"Name of the container to query"`
 	Query        string `json:"query" jsonschema:"The SQL query string to execute"`
 	PartitionKey string `json:"partitionKey,omitempty" jsonschema:"The partition key value for the query. If provided, the query will be scoped to this partition."`
+	SessionToken string `json:"sessionToken,omitempty" jsonschema:"Session token returned by a previous write, to read your own writes under Session consistency (optional)"`
+
+	ContinuationToken string `json:"continuationToken,omitempty" jsonschema:"Continuation token returned by a previous call of the same query, to resume from where it stopped (optional)"`
+	MaxItems          int    `json:"maxItems,omitempty" jsonschema:"Maximum number of results to return (default 1000, maximum 10000). If there are more, has_more is true and a continuation token is returned"`
+
+	AllowCrossPartition bool `json:"allowCrossPartition,omitempty" jsonschema:"Set to true to run the query across all partitions when no partitionKey is provided. Cross-partition queries scan every partition and can consume a lot of RUs (default false)"`
+
+	PartitionKeyNone bool `json:"partitionKeyNone,omitempty" jsonschema:"Set to true to scope the query to the items that have no value for the partition key property, instead of providing partitionKey"`
+
+	BypassCache bool `json:"bypassCache,omitempty" jsonschema:"Set to true to always run the query against Azure Cosmos DB instead of returning cached results (default false)"`
+
+	FieldTypes map[string]string `json:"fieldTypes,omitempty" jsonschema:"Map of field path to type (string, number, integer, boolean, dateTime, epochSeconds or epochMillis) to return the fields of each result converted to that type in typed_fields, e.g. {\"_ts\": \"epochSeconds\"} (optional)"`
+
+	Pretty *bool `json:"pretty,omitempty" jsonschema:"Set to true to return the results as indented JSON for a human reader, or false for compact JSON. Compact JSON uses fewer tokens (default false, unless the server default is changed)"`
+
+	MaxFieldBytes *int `json:"maxFieldBytes,omitempty" jsonschema:"Replace string values larger than this number of bytes, such as base64 encoded data, with a placeholder like <elided 50KB>. Set to 0 to return all values in full (default 0, unless the server default is changed)"`
 }
 
 type ExecuteQueryToolResult struct {
 	//QueryResults []json.RawMessage `json:"results" jsonschema:"Query results as JSON objects"`
 	QueryResults []string `json:"results" jsonschema:"Query results as JSON strings"`
+	Count        int      `json:"count" jsonschema:"Number of results returned"`
+	HasMore      bool     `json:"has_more" jsonschema:"Whether more results exist that were not returned, i.e. the results are truncated"`
 	//QueryMetrics []string `json:"metrics" jsonschema:"Query execution metrics"`
+	ContinuationToken string `json:"continuation_token,omitempty" jsonschema:"Continuation token to resume the query from, set if the results are truncated (maxItems reached or the query failed part way through). Pass it as continuationToken to fetch the remaining results"`
+	Warning           string `json:"warning,omitempty" jsonschema:"Set if the query failed part way through and the results are incomplete"`
+	SessionToken      string `json:"session_token,omitempty" jsonschema:"Session token of the last response, pass it to subsequent reads and queries under Session consistency"`
+	Note              string `json:"note,omitempty" jsonschema:"Set if results were left out to keep the response within the size limit"`
+	Cached            bool   `json:"cached,omitempty" jsonschema:"Whether the results were served from the query cache instead of Azure Cosmos DB"`
+
+	TypedFields [][]TypedField `json:"typed_fields,omitempty" jsonschema:"The fields listed in fieldTypes converted to their type, one list per result in the order of the results"`
+
+	ElidedFields int `json:"elided_fields,omitempty" jsonschema:"Number of string values replaced with a placeholder because they are larger than maxFieldBytes"`
 }
 
 func ExecuteQueryToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input ExecuteQueryToolInput) (*mcp.CallToolResult, ExecuteQueryToolResult, error) {
@@ -116,15 +392,61 @@ func ExecuteQueryToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input
 	}
 
 	if input.Database == "" {
-		return nil, ExecuteQueryToolResult{}, errors.New("database name missing")
+		return nil, ExecuteQueryToolResult{}, newInputError("database name missing")
 	}
 
 	if input.Container == "" {
-		return nil, ExecuteQueryToolResult{}, errors.New("container name missing")
+		return nil, ExecuteQueryToolResult{}, newInputError("container name missing")
 	}
 
 	if input.Query == "" {
-		return nil, ExecuteQueryToolResult{}, errors.New("query string missing")
+		return nil, ExecuteQueryToolResult{}, newInputError("query string missing")
+	}
+
+	if input.MaxItems < 0 {
+		return nil, ExecuteQueryToolResult{}, newInputError("maxItems must not be negative")
+	}
+
+	if err := checkPartitionKeyNone(input.PartitionKey, input.PartitionKeyNone); err != nil {
+		return nil, ExecuteQueryToolResult{}, err
+	}
+
+	// guard against accidental full scans
+	if input.PartitionKey == "" && !input.PartitionKeyNone && !input.AllowCrossPartition {
+		return nil, ExecuteQueryToolResult{}, newInputError("partition key missing: provide a partitionKey to scope the query to a single partition, or set allowCrossPartition to true to run a cross-partition query that scans all partitions")
+	}
+
+	fieldTypes, err := parseFieldTypes(input.FieldTypes)
+	if err != nil {
+		return nil, ExecuteQueryToolResult{}, err
+	}
+
+	pretty, err := usePrettyJSON(input.Pretty)
+	if err != nil {
+		return nil, ExecuteQueryToolResult{}, err
+	}
+
+	maxFieldBytes, err := useMaxFieldBytes(input.MaxFieldBytes)
+	if err != nil {
+		return nil, ExecuteQueryToolResult{}, err
+	}
+
+	maxItems := input.MaxItems
+	if maxItems == 0 {
+		maxItems = defaultExecuteQueryMaxItems
+	}
+	if maxItems > maxExecuteQueryMaxItems {
+		maxItems = maxExecuteQueryMaxItems
+	}
+
+	pageSize, err := queryPageSize(maxItems, input.MaxItems != 0)
+	if err != nil {
+		return nil, ExecuteQueryToolResult{}, err
+	}
+
+	maxResponseBytes, err := GetMaxResponseBytes()
+	if err != nil {
+		return nil, ExecuteQueryToolResult{}, err
 	}
 
 	client, err := input.GetClient()
@@ -149,18 +471,100 @@ func ExecuteQueryToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input
 		partitionKey = azcosmos.PartitionKey{} // Empty partition key for cross-partition queries
 	}
 
-	queryPager := containerClient.NewQueryItemsPager(input.Query, partitionKey, nil)
+	if input.PartitionKeyNone {
+		ctx = withPartitionKeyNone(ctx)
+	}
+
+	options := &azcosmos.QueryOptions{PageSizeHint: pageSize}
+	if input.SessionToken != "" {
+		options.SessionToken = &input.SessionToken
+	}
+	if input.ContinuationToken != "" {
+		options.ContinuationToken = &input.ContinuationToken
+	}
+
+	queryPager := containerClient.NewQueryItemsPager(input.Query, partitionKey, options)
 
 	var response ExecuteQueryToolResult
+	// token to resume after the last page that was fetched
+	continuationToken := input.ContinuationToken
+	responseBytes := 0
 
 	for queryPager.More() {
-		queryResponse, err := queryPager.NextPage(ctx)
+		queryResponse, err := nextPageWithRetry(ctx, queryPager)
 		if err != nil {
-			return nil, ExecuteQueryToolResult{}, fmt.Errorf("query page error: %v", err)
+			if len(response.QueryResults) == 0 {
+				return nil, ExecuteQueryToolResult{}, fmt.Errorf("query page error: %w", withDiagnostics(err))
+			}
+			// don't discard the results fetched so far
+			response.Count = len(response.QueryResults)
+			response.HasMore = queryPager.More()
+			response.ContinuationToken = continuationToken
+			response.Warning = fmt.Sprintf("query failed after returning %d results, results are incomplete: %v", len(response.QueryResults), withDiagnostics(err))
+			response.TypedFields = resultTypedFields(response.QueryResults, fieldTypes)
+			if pretty {
+				prettyJSONResults(response.QueryResults)
+			}
+			return nil, response, nil
+		}
+
+		// stop before exceeding the cap, and resume from this page next time
+		if len(response.QueryResults)+len(queryResponse.Items) > maxItems && len(response.QueryResults) > 0 {
+			response.HasMore = true
+			response.ContinuationToken = continuationToken
+			break
+		}
+
+		// large values are elided before the size limit is applied, so that more results fit
+		elided := make([]int, len(queryResponse.Items))
+		for i, item := range queryResponse.Items {
+			queryResponse.Items[i], elided[i] = elideLargeFields(item, maxFieldBytes)
 		}
 
+		pageBytes := 0
 		for _, item := range queryResponse.Items {
+			pageBytes += len(item)
+		}
+
+		// the size limit is applied at page boundaries too, unless the first page alone exceeds it
+		if maxResponseBytes > 0 && responseBytes+pageBytes > maxResponseBytes {
+			response.HasMore = true
+			response.ContinuationToken = continuationToken
+
+			if len(response.QueryResults) > 0 {
+				response.Note = fmt.Sprintf("response size limit of %d bytes (%s) reached, pass continuation_token to fetch the next results", maxResponseBytes, MaxResponseBytesEnvVar)
+				break
+			}
+
+			items, omitted := limitResponseSize(queryResponse.Items, func(item []byte) int { return len(item) }, maxResponseBytes)
+			for i, item := range items {
+				response.QueryResults = append(response.QueryResults, string(item))
+				response.ElidedFields += elided[i]
+			}
+			response.Note = responseSizeNote(omitted, len(queryResponse.Items), maxResponseBytes) + " - select fewer fields or lower maxItems"
+			break
+		}
+		responseBytes += pageBytes
+
+		for i, item := range queryResponse.Items {
 			response.QueryResults = append(response.QueryResults, string(item))
+			response.ElidedFields += elided[i]
+		}
+
+		if queryResponse.ContinuationToken != nil {
+			continuationToken = *queryResponse.ContinuationToken
+		}
+
+		if sessionToken := responseSessionToken(queryResponse.Response); sessionToken != "" {
+			response.SessionToken = sessionToken
+		}
+
+		if len(response.QueryResults) >= maxItems {
+			response.HasMore = queryPager.More()
+			if response.HasMore {
+				response.ContinuationToken = continuationToken
+			}
+			break
 		}
 
 		// Append query metrics if available
@@ -170,5 +574,511 @@ func ExecuteQueryToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input
 		//response.QueryMetrics = append(response.QueryMetrics, *queryResponse.QueryMetrics)
 	}
 
+	response.Count = len(response.QueryResults)
+	response.TypedFields = resultTypedFields(response.QueryResults, fieldTypes)
+	// the size limit applies to the compact results, indentation is added afterwards
+	if pretty {
+		prettyJSONResults(response.QueryResults)
+	}
+
+	return nil, response, nil
+}
+
+// defaultPaginatedQueryLimit is the page size of paginated_query when limit is not specified
+const defaultPaginatedQueryLimit = 100
+
+// maxPaginatedQueryLimit is the upper bound for the limit argument of paginated_query
+const maxPaginatedQueryLimit = 1000
+
+// offsetLimitPattern and topPattern detect queries that already limit their results
+var (
+	offsetLimitPattern = regexp.MustCompile(`(?i)\bOFFSET\s+(\d+|@\w+)\s+LIMIT\b`)
+	topPattern         = regexp.MustCompile(`(?i)\bTOP\s+(\d+|@\w+)`)
+)
+
+func PaginatedQuery() *mcp.Tool {
+
+	return &mcp.Tool{
+		Name:        "paginated_query",
+		Description: "Execute a SQL query within a single logical partition of a container in Azure Cosmos DB or local emulator and return one page of results, skipping the first offset results. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. The OFFSET @offset LIMIT @limit clause is added to the query by this tool, so the query must not contain OFFSET LIMIT or TOP. A partition key value is REQUIRED because OFFSET LIMIT is not supported for cross-partition queries by the Azure Cosmos DB Gateway API (used by the Go SDK). Add an ORDER BY clause for stable pages. Use this to jump to a page (e.g. page 3 of 20 items is offset 40, limit 20) - the RU cost grows with the offset because skipped results are still read, so use execute_query with a continuation token to read all results in order.",
+	}
+}
+
+type PaginatedQueryToolInput struct {
+	ConnectionConfig
+	Database     string `json:"database" jsonschema:"Name of the database"`
+	Container    string `json:"container" jsonschema:"Name of the container to query"`
+	Query        string `json:"query" jsonschema:"The SQL query string to execute, without OFFSET LIMIT or TOP, e.g. SELECT * FROM c ORDER BY c.createdAt"`
+	PartitionKey string `json:"partitionKey" jsonschema:"Partition key value to scope the query to (required)"`
+	Offset       int    `json:"offset,omitempty" jsonschema:"Number of results to skip (default 0)"`
+	Limit        int    `json:"limit,omitempty" jsonschema:"Maximum number of results to return (default 100, maximum 1000)"`
+
+	Pretty *bool `json:"pretty,omitempty" jsonschema:"Set to true to return the results as indented JSON for a human reader, or false for compact JSON. Compact JSON uses fewer tokens (default false, unless the server default is changed)"`
+
+	MaxFieldBytes *int `json:"maxFieldBytes,omitempty" jsonschema:"Replace string values larger than this number of bytes, such as base64 encoded data, with a placeholder like <elided 50KB>. Set to 0 to return all values in full (default 0, unless the server default is changed)"`
+}
+
+type PaginatedQueryToolResult struct {
+	Query      string   `json:"query" jsonschema:"The query that was executed, the offset and limit are passed as the @offset and @limit parameters"`
+	Results    []string `json:"results" jsonschema:"Query results as JSON strings"`
+	Count      int      `json:"count" jsonschema:"Number of results returned"`
+	Offset     int      `json:"offset" jsonschema:"Number of results that were skipped"`
+	Limit      int      `json:"limit" jsonschema:"Maximum number of results that were requested"`
+	HasMore    bool     `json:"has_more" jsonschema:"Whether more results exist after this page"`
+	NextOffset int      `json:"next_offset,omitempty" jsonschema:"Offset of the next page, set if has_more is true"`
+	Note       string   `json:"note,omitempty" jsonschema:"Set if results were left out to keep the response within the size limit"`
+
+	ElidedFields int `json:"elided_fields,omitempty" jsonschema:"Number of string values replaced with a placeholder because they are larger than maxFieldBytes"`
+}
+
+func PaginatedQueryToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input PaginatedQueryToolInput) (*mcp.CallToolResult, PaginatedQueryToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, PaginatedQueryToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, PaginatedQueryToolResult{}, newInputError("database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, PaginatedQueryToolResult{}, newInputError("container name missing")
+	}
+
+	if input.PartitionKey == "" {
+		return nil, PaginatedQueryToolResult{}, newInputError("partition key missing: OFFSET LIMIT is only supported within a single partition")
+	}
+
+	if input.Offset < 0 {
+		return nil, PaginatedQueryToolResult{}, newInputError("offset must not be negative")
+	}
+
+	if input.Limit < 0 || input.Limit > maxPaginatedQueryLimit {
+		return nil, PaginatedQueryToolResult{}, inputErrorf("limit must be between 1 and %d", maxPaginatedQueryLimit)
+	}
+
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultPaginatedQueryLimit
+	}
+
+	maxResponseBytes, err := GetMaxResponseBytes()
+	if err != nil {
+		return nil, PaginatedQueryToolResult{}, err
+	}
+
+	pretty, err := usePrettyJSON(input.Pretty)
+	if err != nil {
+		return nil, PaginatedQueryToolResult{}, err
+	}
+
+	maxFieldBytes, err := useMaxFieldBytes(input.MaxFieldBytes)
+	if err != nil {
+		return nil, PaginatedQueryToolResult{}, err
+	}
+
+	query, err := offsetLimitQuery(input.Query)
+	if err != nil {
+		return nil, PaginatedQueryToolResult{}, err
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, PaginatedQueryToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, PaginatedQueryToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, PaginatedQueryToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	// one more than the limit is fetched to find out if there are more results
+	options := &azcosmos.QueryOptions{
+		QueryParameters: []azcosmos.QueryParameter{
+			{Name: "@offset", Value: input.Offset},
+			{Name: "@limit", Value: limit + 1},
+		},
+	}
+
+	items, err := collectQueryResults(ctx, containerClient, query, azcosmos.NewPartitionKeyString(input.PartitionKey), options)
+	if err != nil {
+		return nil, PaginatedQueryToolResult{}, err
+	}
+
+	hasMore := len(items) > limit
+	if hasMore {
+		items = items[:limit]
+	}
+
+	results := make([]string, 0, len(items))
+	elided := make([]int, len(items))
+	for i, item := range items {
+		item, elided[i] = elideLargeFields(item, maxFieldBytes)
+		results = append(results, string(item))
+	}
+
+	var note string
+	if kept, omitted := limitResponseSize(results, func(result string) int { return len(result) }, maxResponseBytes); omitted > 0 {
+		note = responseSizeNote(omitted, len(results), maxResponseBytes) + " - next_offset continues after the returned results"
+		results = kept
+		hasMore = true
+	}
+
+	elidedFields := 0
+	for _, count := range elided[:len(results)] {
+		elidedFields += count
+	}
+	if pretty {
+		prettyJSONResults(results)
+	}
+
+	result := PaginatedQueryToolResult{
+		Query:        query,
+		Results:      results,
+		Count:        len(results),
+		Offset:       input.Offset,
+		Limit:        limit,
+		HasMore:      hasMore,
+		Note:         note,
+		ElidedFields: elidedFields,
+	}
+	if hasMore {
+		result.NextOffset = input.Offset + len(results)
+	}
+
+	return nil, result, nil
+}
+
+// offsetLimitQuery appends a parameterized OFFSET LIMIT clause to the query.
+// The offset and limit are passed as the @offset and @limit parameters.
+func offsetLimitQuery(query string) (string, error) {
+	query = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(query), ";"))
+	if query == "" {
+		return "", newInputError("query string missing")
+	}
+
+	if offsetLimitPattern.MatchString(query) || topPattern.MatchString(query) {
+		return "", newInputError("query must not contain OFFSET LIMIT or TOP, use the offset and limit arguments instead")
+	}
+
+	return query + " OFFSET @offset LIMIT @limit", nil
+}
+
+func EstimateQueryCost() *mcp.Tool {
+
+	return &mcp.Tool{
+		Name:        "estimate_query_cost",
+		Description: "Estimate the Request Unit (RU) cost of a SQL query on a Cosmos DB container in Azure Cosmos DB or local emulator without returning its data. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Only the first page of results (a single item) is fetched and its request charge is reported. This is an ESTIMATE - the total cost of running the full query will be higher if more results exist. Use this to decide whether to proceed with an expensive scan.",
+	}
+}
+
+type EstimateQueryCostToolInput struct {
+	ConnectionConfig
+	Database     string `json:"database" jsonschema:"Name of the database"`
+	Container    string `json:"container" jsonschema:"Name of the container to query"`
+	Query        string `json:"query" jsonschema:"The SQL query string to estimate the cost for"`
+	PartitionKey string `json:"partitionKey,omitempty" jsonschema:"The partition key value for the query. If provided, the query will be scoped to this partition."`
+}
+
+type EstimateQueryCostToolResult struct {
+	FirstPageRequestCharge float32 `json:"first_page_request_charge" jsonschema:"Request charge (RU) of fetching the first page of results"`
+	FirstPageItemCount     int     `json:"first_page_item_count" jsonschema:"Number of items in the first page of results"`
+	HasMoreResults         bool    `json:"has_more_results" jsonschema:"Whether the query has more results beyond the first page"`
+	IsEstimate             bool    `json:"is_estimate" jsonschema:"Always true - the reported charge is an estimate, not the total cost of the query"`
+	Message                string  `json:"message"`
+}
+
+func EstimateQueryCostToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input EstimateQueryCostToolInput) (*mcp.CallToolResult, EstimateQueryCostToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, EstimateQueryCostToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, EstimateQueryCostToolResult{}, newInputError("database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, EstimateQueryCostToolResult{}, newInputError("container name missing")
+	}
+
+	if input.Query == "" {
+		return nil, EstimateQueryCostToolResult{}, newInputError("query string missing")
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, EstimateQueryCostToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, EstimateQueryCostToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, EstimateQueryCostToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	var partitionKey azcosmos.PartitionKey
+	if input.PartitionKey != "" {
+		partitionKey = azcosmos.NewPartitionKeyString(input.PartitionKey)
+	} else {
+		partitionKey = azcosmos.PartitionKey{} // Empty partition key for cross-partition queries
+	}
+
+	// fetch a single item to keep the cost of the estimate itself low
+	queryPager := containerClient.NewQueryItemsPager(input.Query, partitionKey, &azcosmos.QueryOptions{PageSizeHint: 1})
+
+	queryResponse, err := queryPager.NextPage(ctx)
+	if err != nil {
+		return nil, EstimateQueryCostToolResult{}, fmt.Errorf("query page error: %w", withDiagnostics(err))
+	}
+
+	hasMoreResults := queryResponse.ContinuationToken != nil
+
+	message := fmt.Sprintf("Estimated cost: fetching the first page of results consumed %.2f RUs.", queryResponse.RequestCharge)
+	if hasMoreResults {
+		message += " The query has more results, so the total cost of running it fully will be higher."
+	}
+
+	return nil, EstimateQueryCostToolResult{
+		FirstPageRequestCharge: queryResponse.RequestCharge,
+		FirstPageItemCount:     len(queryResponse.Items),
+		HasMoreResults:         hasMoreResults,
+		IsEstimate:             true,
+		Message:                message,
+	}, nil
+}
+
+// maxQueryAcrossContainersConcurrency is the upper bound for concurrent container queries
+const maxQueryAcrossContainersConcurrency = 10
+
+// defaultQueryAcrossContainersConcurrency is used when the concurrency is not specified
+const defaultQueryAcrossContainersConcurrency = 4
+
+func QueryAcrossContainers() *mcp.Tool {
+
+	return &mcp.Tool{
+		Name:        "query_across_containers",
+		Description: "Execute the same SQL query concurrently on multiple containers (with the same schema) in an Azure Cosmos DB database or local emulator, and merge the results. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Each result item is annotated with a _sourceContainer field containing the name of the container it came from. Errors are reported per container, so results from the containers that succeeded are still returned. The same gateway query limitations as execute_query apply.",
+	}
+}
+
+type QueryAcrossContainersToolInput struct {
+	ConnectionConfig
+	Database       string   `json:"database" jsonschema:"Name of the database"`
+	Containers     []string `json:"containers" jsonschema:"Names of the containers to query"`
+	Query          string   `json:"query" jsonschema:"The SQL query string to execute on each container"`
+	PartitionKey   string   `json:"partitionKey,omitempty" jsonschema:"The partition key value for the query. If provided, the query will be scoped to this partition in each container."`
+	MaxConcurrency int      `json:"maxConcurrency,omitempty" jsonschema:"Maximum number of containers to query concurrently (default 4, maximum 10)"`
+}
+
+type ContainerQueryError struct {
+	Container string `json:"container"`
+	Error     string `json:"error"`
+}
+
+type QueryAcrossContainersToolResult struct {
+	QueryResults []string              `json:"results" jsonschema:"Merged query results as JSON strings, each annotated with _sourceContainer"`
+	Errors       []ContainerQueryError `json:"errors,omitempty" jsonschema:"Errors for the containers that could not be queried"`
+	Note         string                `json:"note,omitempty" jsonschema:"Set if results were left out to keep the response within the size limit"`
+}
+
+func QueryAcrossContainersToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input QueryAcrossContainersToolInput) (*mcp.CallToolResult, QueryAcrossContainersToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, QueryAcrossContainersToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, QueryAcrossContainersToolResult{}, newInputError("database name missing")
+	}
+
+	if len(input.Containers) == 0 {
+		return nil, QueryAcrossContainersToolResult{}, newInputError("container names missing")
+	}
+
+	for _, container := range input.Containers {
+		if container == "" {
+			return nil, QueryAcrossContainersToolResult{}, newInputError("container name missing")
+		}
+	}
+
+	if input.Query == "" {
+		return nil, QueryAcrossContainersToolResult{}, newInputError("query string missing")
+	}
+
+	concurrency := input.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultQueryAcrossContainersConcurrency
+	}
+	if concurrency > maxQueryAcrossContainersConcurrency {
+		concurrency = maxQueryAcrossContainersConcurrency
+	}
+
+	maxResponseBytes, err := GetMaxResponseBytes()
+	if err != nil {
+		return nil, QueryAcrossContainersToolResult{}, err
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, QueryAcrossContainersToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, QueryAcrossContainersToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	type containerResult struct {
+		items []string
+		err   error
+	}
+
+	// results are stored by index so the merged output follows the order of the input containers
+	results := make([]containerResult, len(input.Containers))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				items, err := queryContainerWithSource(ctx, databaseClient, input.Containers[i], input.Query, input.PartitionKey)
+				results[i] = containerResult{items: items, err: err}
+			}
+		}()
+	}
+
+	for i := range input.Containers {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var response QueryAcrossContainersToolResult
+
+	for i, result := range results {
+		if result.err != nil {
+			response.Errors = append(response.Errors, ContainerQueryError{
+				Container: input.Containers[i],
+				Error:     result.err.Error(),
+			})
+			continue
+		}
+		response.QueryResults = append(response.QueryResults, result.items...)
+	}
+
+	if len(response.Errors) == len(input.Containers) {
+		return nil, QueryAcrossContainersToolResult{}, fmt.Errorf("query failed on all containers: %s", response.Errors[0].Error)
+	}
+
+	if kept, omitted := limitResponseSize(response.QueryResults, func(result string) int { return len(result) }, maxResponseBytes); omitted > 0 {
+		response.Note = responseSizeNote(omitted, len(response.QueryResults), maxResponseBytes)
+		response.QueryResults = kept
+	}
+
 	return nil, response, nil
 }
+
+// queryContainerWithSource runs the query on a single container and annotates each item with _sourceContainer
+func queryContainerWithSource(ctx context.Context, databaseClient *azcosmos.DatabaseClient, container, query, partitionKeyValue string) ([]string, error) {
+
+	containerClient, err := databaseClient.NewContainer(container)
+	if err != nil {
+		return nil, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	var partitionKey azcosmos.PartitionKey
+	if partitionKeyValue != "" {
+		partitionKey = azcosmos.NewPartitionKeyString(partitionKeyValue)
+	} else {
+		partitionKey = azcosmos.PartitionKey{} // Empty partition key for cross-partition queries
+	}
+
+	queryPager := containerClient.NewQueryItemsPager(query, partitionKey, nil)
+
+	items := []string{}
+
+	for queryPager.More() {
+		queryResponse, err := nextPageWithRetry(ctx, queryPager)
+		if err != nil {
+			return nil, fmt.Errorf("query page error: %w", withDiagnostics(err))
+		}
+
+		for _, item := range queryResponse.Items {
+			annotated, err := annotateSourceContainer(item, container)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, annotated)
+		}
+	}
+
+	return items, nil
+}
+
+// annotateSourceContainer adds a _sourceContainer field to a query result.
+// Results that are not JSON objects (e.g. from SELECT VALUE) are wrapped in an object with a value field.
+func annotateSourceContainer(item []byte, container string) (string, error) {
+	// the values are kept as raw JSON, decoding them would round integers above 2^53
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(item, &object); err != nil || object == nil {
+		object = map[string]json.RawMessage{"value": json.RawMessage(item)}
+	}
+
+	source, err := json.Marshal(container)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling result to JSON: %v", err)
+	}
+	object["_sourceContainer"] = source
+
+	annotated, err := json.Marshal(object)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling result to JSON: %v", err)
+	}
+
+	return string(annotated), nil
+}
+
+// sessionTokenHeader is the response header that carries the session token
+const sessionTokenHeader = "x-ms-session-token"
+
+// responseSessionToken returns the session token of a response that does not expose it directly (e.g. query responses)
+func responseSessionToken(response azcosmos.Response) string {
+	if response.RawResponse == nil {
+		return ""
+	}
+	return response.RawResponse.Header.Get(sessionTokenHeader)
+}
+
+// partitionKeyHeader is the request header that carries the partition key value
+const partitionKeyHeader = "x-ms-documentdb-partitionkey"
+
+// partitionKeyNoneValue is the partition key of items that have no value for the partition key property.
+// azcosmos can only build string, number, bool and null partition keys, so it is sent as a request header instead.
+const partitionKeyNoneValue = "[{}]"
+
+// withPartitionKeyNone returns a context that scopes the requests made with it to the items without a partition key value.
+// The SDK does not send a partition key header for an empty azcosmos.PartitionKey, so that is what the requests should use.
+func withPartitionKeyNone(ctx context.Context) context.Context {
+	return policy.WithHTTPHeader(ctx, http.Header{partitionKeyHeader: {partitionKeyNoneValue}})
+}
+
+// checkPartitionKeyNone makes sure that a partition key value and partitionKeyNone are not used together
+func checkPartitionKeyNone(partitionKey string, partitionKeyNone bool) error {
+	if partitionKeyNone && partitionKey != "" {
+		return newInputError("partitionKey and partitionKeyNone cannot be used together")
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package tools
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// QueryCacheTTLEnvVar enables caching of execute_query results when set, for the given number of seconds
+const QueryCacheTTLEnvVar = "COSMOS_QUERY_CACHE_TTL"
+
+// QueryCacheSizeEnvVar limits the number of cached query results, the least recently used result is evicted first
+const QueryCacheSizeEnvVar = "COSMOS_QUERY_CACHE_SIZE"
+
+// defaultQueryCacheSize is used when the cache size is not configured
+const defaultQueryCacheSize = 100
+
+// QueryCache is an in-memory LRU cache of query results whose entries expire after a TTL.
+// A cache with a TTL of 0 is disabled, it never returns or stores results.
+type QueryCache struct {
+	ttl     time.Duration
+	size    int
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type queryCacheEntry struct {
+	key       string
+	result    ExecuteQueryToolResult
+	expiresAt time.Time
+}
+
+// NewQueryCache creates a cache holding up to size results for ttl, a ttl of 0 disables the cache
+func NewQueryCache(ttl time.Duration, size int) *QueryCache {
+	return &QueryCache{
+		ttl:     ttl,
+		size:    size,
+		now:     time.Now,
+		entries: map[string]*list.Element{},
+		lru:     list.New(),
+	}
+}
+
+// Enabled reports whether results are cached
+func (c *QueryCache) Enabled() bool {
+	return c.ttl > 0 && c.size > 0
+}
+
+// Get returns the cached result for the key, if it has not expired
+func (c *QueryCache) Get(key string) (ExecuteQueryToolResult, bool) {
+	if !c.Enabled() {
+		return ExecuteQueryToolResult{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return ExecuteQueryToolResult{}, false
+	}
+
+	entry := element.Value.(*queryCacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.lru.Remove(element)
+		delete(c.entries, key)
+		return ExecuteQueryToolResult{}, false
+	}
+
+	c.lru.MoveToFront(element)
+	return entry.result, true
+}
+
+// Put caches the result for the key, evicting the least recently used results if the cache is full
+func (c *QueryCache) Put(key string, result ExecuteQueryToolResult) {
+	if !c.Enabled() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*queryCacheEntry)
+		entry.result = result
+		entry.expiresAt = expiresAt
+		c.lru.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&queryCacheEntry{key: key, result: result, expiresAt: expiresAt})
+
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*queryCacheEntry).key)
+	}
+}
+
+// Clear removes all cached results, e.g. after a tool modified data
+func (c *QueryCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = map[string]*list.Element{}
+	c.lru.Init()
+}
+
+// Len returns the number of cached results, including expired results that were not evicted yet
+func (c *QueryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// GetQueryCacheConfig returns the TTL and size of the query cache configured in the environment, a TTL of 0 means the cache is disabled
+func GetQueryCacheConfig() (time.Duration, int, error) {
+	ttlValue := strings.TrimSpace(os.Getenv(QueryCacheTTLEnvVar))
+	if ttlValue == "" {
+		return 0, 0, nil
+	}
+
+	ttl, err := strconv.Atoi(ttlValue)
+	if err != nil || ttl < 0 {
+		return 0, 0, fmt.Errorf("invalid value '%s' for %s, must be 0 (disabled) or a positive number of seconds", ttlValue, QueryCacheTTLEnvVar)
+	}
+
+	size := defaultQueryCacheSize
+	if sizeValue := strings.TrimSpace(os.Getenv(QueryCacheSizeEnvVar)); sizeValue != "" {
+		size, err = strconv.Atoi(sizeValue)
+		if err != nil || size < 1 {
+			return 0, 0, fmt.Errorf("invalid value '%s' for %s, must be a positive integer", sizeValue, QueryCacheSizeEnvVar)
+		}
+	}
+
+	return time.Duration(ttl) * time.Second, size, nil
+}
+
+// queryCacheKeyFields are the inputs of execute_query that affect its results
+type queryCacheKeyFields struct {
+	Account             string            `json:"account,omitempty"`
+	Endpoint            string            `json:"endpoint,omitempty"`
+	Database            string            `json:"database"`
+	Container           string            `json:"container"`
+	Query               string            `json:"query"`
+	PartitionKey        string            `json:"partitionKey,omitempty"`
+	PartitionKeyNone    bool              `json:"partitionKeyNone,omitempty"`
+	AllowCrossPartition bool              `json:"allowCrossPartition,omitempty"`
+	ContinuationToken   string            `json:"continuationToken,omitempty"`
+	MaxItems            int               `json:"maxItems,omitempty"`
+	FieldTypes          map[string]string `json:"fieldTypes,omitempty"`
+	Pretty              *bool             `json:"pretty,omitempty"`
+	MaxFieldBytes       *int              `json:"maxFieldBytes,omitempty"`
+}
+
+// queryCacheKey identifies the results of a query by the inputs that affect them: the resolved account (or emulator endpoint),
+// database, container, query, partition key, paging and formatting arguments. Options such as diagnostics are not part of it.
+func queryCacheKey(input ExecuteQueryToolInput) (string, error) {
+	config := input.withDefaultAccount()
+
+	fields := queryCacheKeyFields{
+		Account:             config.Account,
+		Database:            input.Database,
+		Container:           input.Container,
+		Query:               input.Query,
+		PartitionKey:        input.PartitionKey,
+		PartitionKeyNone:    input.PartitionKeyNone,
+		AllowCrossPartition: input.AllowCrossPartition,
+		ContinuationToken:   input.ContinuationToken,
+		MaxItems:            input.MaxItems,
+		FieldTypes:          input.FieldTypes,
+		Pretty:              input.Pretty,
+		MaxFieldBytes:       input.MaxFieldBytes,
+	}
+	if config.UseEmulator {
+		fields.Account = ""
+		fields.Endpoint = config.GetEndpoint()
+	}
+
+	key, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("error creating cache key: %v", err)
+	}
+	return string(key), nil
+}
+
+// CachedExecuteQueryToolHandler returns the handler for the execute_query tool, which serves repeated queries from the cache.
+// Queries with a session token, bypassCache or diagnostics (which describe the requests sent), and results that are incomplete
+// because the query failed part way through, are not cached.
+func CachedExecuteQueryToolHandler(cache *QueryCache) mcp.ToolHandlerFor[ExecuteQueryToolInput, ExecuteQueryToolResult] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input ExecuteQueryToolInput) (*mcp.CallToolResult, ExecuteQueryToolResult, error) {
+		if !cache.Enabled() || input.BypassCache || input.SessionToken != "" || input.Diagnostics {
+			return ExecuteQueryToolHandler(ctx, req, input)
+		}
+
+		key, err := queryCacheKey(input)
+		if err != nil {
+			return nil, ExecuteQueryToolResult{}, err
+		}
+
+		if result, ok := cache.Get(key); ok {
+			result.Cached = true
+			return nil, result, nil
+		}
+
+		callResult, result, err := ExecuteQueryToolHandler(ctx, req, input)
+		if err == nil && result.Warning == "" {
+			cache.Put(key, result)
+		}
+
+		return callResult, result, err
+	}
+}
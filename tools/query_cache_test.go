@@ -0,0 +1,198 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for the query cache that do not need the emulator
+
+func TestQueryCache(t *testing.T) {
+	now := time.Now()
+	cache := NewQueryCache(time.Minute, 2)
+	cache.now = func() time.Time { return now }
+
+	_, ok := cache.Get("q1")
+	assert.False(t, ok)
+
+	cache.Put("q1", ExecuteQueryToolResult{Count: 1})
+	cache.Put("q2", ExecuteQueryToolResult{Count: 2})
+
+	result, ok := cache.Get("q1")
+	require.True(t, ok)
+	assert.Equal(t, 1, result.Count)
+
+	// q2 is the least recently used result, since q1 was just read
+	cache.Put("q3", ExecuteQueryToolResult{Count: 3})
+	assert.Equal(t, 2, cache.Len())
+	_, ok = cache.Get("q2")
+	assert.False(t, ok, "least recently used result should be evicted")
+	_, ok = cache.Get("q3")
+	assert.True(t, ok)
+
+	// putting an existing key replaces the result
+	cache.Put("q3", ExecuteQueryToolResult{Count: 30})
+	result, ok = cache.Get("q3")
+	require.True(t, ok)
+	assert.Equal(t, 30, result.Count)
+
+	now = now.Add(time.Minute)
+	_, ok = cache.Get("q1")
+	assert.False(t, ok, "expired result should not be returned")
+
+	cache.Put("q4", ExecuteQueryToolResult{Count: 4})
+	cache.Clear()
+	assert.Equal(t, 0, cache.Len())
+	_, ok = cache.Get("q4")
+	assert.False(t, ok)
+}
+
+func TestQueryCache_Disabled(t *testing.T) {
+	cache := NewQueryCache(0, 0)
+	assert.False(t, cache.Enabled())
+
+	cache.Put("q1", ExecuteQueryToolResult{Count: 1})
+	_, ok := cache.Get("q1")
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestGetQueryCacheConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		ttl          string
+		size         string
+		expectedTTL  time.Duration
+		expectedSize int
+		expectError  bool
+	}{
+		{name: "not set", expectedTTL: 0, expectedSize: 0},
+		{name: "ttl with default size", ttl: "30", expectedTTL: 30 * time.Second, expectedSize: defaultQueryCacheSize},
+		{name: "ttl and size", ttl: "30", size: "500", expectedTTL: 30 * time.Second, expectedSize: 500},
+		{name: "disabled", ttl: "0", expectedTTL: 0, expectedSize: defaultQueryCacheSize},
+		{name: "negative ttl", ttl: "-1", expectError: true},
+		{name: "ttl as duration", ttl: "30s", expectError: true},
+		{name: "zero size", ttl: "30", size: "0", expectError: true},
+		{name: "invalid size", ttl: "30", size: "many", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(QueryCacheTTLEnvVar, tt.ttl)
+			t.Setenv(QueryCacheSizeEnvVar, tt.size)
+
+			ttl, size, err := GetQueryCacheConfig()
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTTL, ttl)
+			assert.Equal(t, tt.expectedSize, size)
+		})
+	}
+}
+
+func TestQueryCacheKey(t *testing.T) {
+	input := ExecuteQueryToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "account1"},
+		Database:         "db1",
+		Container:        "c1",
+		Query:            "SELECT * FROM c",
+		PartitionKey:     "user1",
+	}
+
+	key, err := queryCacheKey(input)
+	require.NoError(t, err)
+
+	bypass := input
+	bypass.BypassCache = true
+	bypassKey, err := queryCacheKey(bypass)
+	require.NoError(t, err)
+	assert.Equal(t, key, bypassKey, "bypassCache should not be part of the key")
+
+	diagnostics := input
+	diagnostics.Diagnostics = true
+	diagnosticsKey, err := queryCacheKey(diagnostics)
+	require.NoError(t, err)
+	assert.Equal(t, key, diagnosticsKey, "diagnostics should not be part of the key")
+
+	// the default account is resolved, so omitting the account hits the same entry
+	t.Setenv(DefaultAccountEnvVar, "account1")
+	defaultAccount := input
+	defaultAccount.Account = ""
+	defaultAccountKey, err := queryCacheKey(defaultAccount)
+	require.NoError(t, err)
+	assert.Equal(t, key, defaultAccountKey, "the default account should be resolved")
+
+	for name, change := range map[string]func(*ExecuteQueryToolInput){
+		"account":       func(i *ExecuteQueryToolInput) { i.Account = "account2" },
+		"database":      func(i *ExecuteQueryToolInput) { i.Database = "db2" },
+		"container":     func(i *ExecuteQueryToolInput) { i.Container = "c2" },
+		"query":         func(i *ExecuteQueryToolInput) { i.Query = "SELECT c.id FROM c" },
+		"partition key": func(i *ExecuteQueryToolInput) { i.PartitionKey = "user2" },
+		"max items":     func(i *ExecuteQueryToolInput) { i.MaxItems = 10 },
+		"emulator":      func(i *ExecuteQueryToolInput) { i.UseEmulator = true },
+		"cross partition": func(i *ExecuteQueryToolInput) {
+			i.PartitionKey = ""
+			i.AllowCrossPartition = true
+		},
+		"continuation token": func(i *ExecuteQueryToolInput) { i.ContinuationToken = "token" },
+		"field types":        func(i *ExecuteQueryToolInput) { i.FieldTypes = map[string]string{"_ts": "epochSeconds"} },
+	} {
+		changed := input
+		change(&changed)
+		changedKey, err := queryCacheKey(changed)
+		require.NoError(t, err)
+		assert.NotEqual(t, key, changedKey, "%s should be part of the key", name)
+	}
+}
+
+func TestCachedExecuteQueryToolHandler(t *testing.T) {
+	cache := NewQueryCache(time.Minute, 10)
+	input := ExecuteQueryToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "account1"},
+		Database:         "db1",
+		Container:        "c1",
+		Query:            "SELECT * FROM c",
+		PartitionKey:     "user1",
+	}
+
+	key, err := queryCacheKey(input)
+	require.NoError(t, err)
+	cache.Put(key, ExecuteQueryToolResult{QueryResults: []string{`{"id":"1"}`}, Count: 1})
+
+	handler := CachedExecuteQueryToolHandler(cache)
+
+	_, result, err := handler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.True(t, result.Cached)
+	assert.Equal(t, 1, result.Count)
+
+	// cached results are not used for freshness-sensitive queries, which then fail validation before reaching Azure Cosmos DB
+	input.Database = ""
+	cachedKey, err := queryCacheKey(input)
+	require.NoError(t, err)
+	cache.Put(cachedKey, ExecuteQueryToolResult{Count: 1})
+
+	input.BypassCache = true
+	_, _, err = handler(context.Background(), nil, input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "database name missing")
+
+	input.BypassCache = false
+	input.SessionToken = "0:1#2"
+	_, _, err = handler(context.Background(), nil, input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "database name missing")
+
+	input.SessionToken = ""
+	input.Diagnostics = true
+	_, _, err = handler(context.Background(), nil, input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "database name missing")
+}
@@ -0,0 +1,172 @@
+package tools
+
+import (
+	"container/list"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// QueryCursorTTLEnvVar enables short cursors in place of execute_query continuation tokens when set, valid for the given number of seconds
+const QueryCursorTTLEnvVar = "COSMOS_QUERY_CURSOR_TTL"
+
+// maxQueryCursors is the maximum number of cursors kept, the oldest cursors are dropped first
+const maxQueryCursors = 1000
+
+// queryCursorPrefix marks cursor ids, continuation tokens are JSON and never start with it
+const queryCursorPrefix = "cur_"
+
+// QueryCursorStore maps short opaque cursor ids to the continuation tokens of execute_query, so that the large tokens do not
+// have to go through the agent's context. Cursors expire after a TTL. A store with a TTL of 0 is disabled.
+type QueryCursorStore struct {
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	cursors map[string]*list.Element
+	// cursors in the order they were created, which is also the order they expire in
+	order *list.List
+}
+
+type queryCursor struct {
+	id                string
+	scope             string
+	continuationToken string
+	expiresAt         time.Time
+}
+
+// NewQueryCursorStore creates a store whose cursors are valid for ttl, a ttl of 0 disables the store
+func NewQueryCursorStore(ttl time.Duration) *QueryCursorStore {
+	return &QueryCursorStore{
+		ttl:     ttl,
+		now:     time.Now,
+		cursors: map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+// Enabled reports whether continuation tokens are replaced with cursors
+func (s *QueryCursorStore) Enabled() bool {
+	return s.ttl > 0
+}
+
+// Put stores the continuation token of a query and returns the id of a new cursor for it. The scope identifies the
+// query, a cursor can only be used to resume the same query.
+func (s *QueryCursorStore) Put(scope, continuationToken string) (string, error) {
+	id, err := newQueryCursorID()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for s.order.Len() > 0 {
+		oldest := s.order.Front()
+		if s.order.Len() < maxQueryCursors && now.Before(oldest.Value.(*queryCursor).expiresAt) {
+			break
+		}
+		s.order.Remove(oldest)
+		delete(s.cursors, oldest.Value.(*queryCursor).id)
+	}
+
+	s.cursors[id] = s.order.PushBack(&queryCursor{id: id, scope: scope, continuationToken: continuationToken, expiresAt: now.Add(s.ttl)})
+
+	return id, nil
+}
+
+// Get returns the continuation token of a cursor. Cursors are not removed when used, so that a page can be fetched again.
+func (s *QueryCursorStore) Get(scope, id string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.cursors[id]
+	if !ok || !s.now().Before(element.Value.(*queryCursor).expiresAt) {
+		return "", inputErrorf("cursor '%s' is unknown or expired (cursors are valid for %s and are lost when the server restarts), run the query again without continuationToken", id, s.ttl)
+	}
+
+	cursor := element.Value.(*queryCursor)
+	if cursor.scope != scope {
+		return "", inputErrorf("cursor '%s' belongs to a different query, it can only resume the query that returned it", id)
+	}
+
+	return cursor.continuationToken, nil
+}
+
+// Len returns the number of cursors, including expired cursors that were not dropped yet
+func (s *QueryCursorStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.order.Len()
+}
+
+// newQueryCursorID returns a random cursor id
+func newQueryCursorID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error creating cursor: %v", err)
+	}
+	return queryCursorPrefix + hex.EncodeToString(b), nil
+}
+
+// GetQueryCursorTTL returns how long cursors are valid as configured in the environment, 0 means cursors are disabled
+func GetQueryCursorTTL() (time.Duration, error) {
+	value := strings.TrimSpace(os.Getenv(QueryCursorTTLEnvVar))
+	if value == "" {
+		return 0, nil
+	}
+
+	ttl, err := strconv.Atoi(value)
+	if err != nil || ttl < 0 {
+		return 0, fmt.Errorf("invalid value '%s' for %s, must be 0 (disabled) or a positive number of seconds", value, QueryCursorTTLEnvVar)
+	}
+
+	return time.Duration(ttl) * time.Second, nil
+}
+
+// queryCursorScope identifies the query a cursor resumes: the account, database, container, query and partition key
+func queryCursorScope(input ExecuteQueryToolInput) string {
+	return strings.Join([]string{input.Account, input.Database, input.Container, input.Query, input.PartitionKey, strconv.FormatBool(input.PartitionKeyNone)}, "\x00")
+}
+
+// CursorExecuteQueryToolHandler wraps the handler of the execute_query tool, replacing the continuation tokens it returns with
+// cursors and accepting cursors in place of continuation tokens. Raw continuation tokens are still accepted.
+func CursorExecuteQueryToolHandler(cursors *QueryCursorStore, handler mcp.ToolHandlerFor[ExecuteQueryToolInput, ExecuteQueryToolResult]) mcp.ToolHandlerFor[ExecuteQueryToolInput, ExecuteQueryToolResult] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input ExecuteQueryToolInput) (*mcp.CallToolResult, ExecuteQueryToolResult, error) {
+		if !cursors.Enabled() {
+			return handler(ctx, req, input)
+		}
+
+		scope := queryCursorScope(input)
+
+		if strings.HasPrefix(input.ContinuationToken, queryCursorPrefix) {
+			continuationToken, err := cursors.Get(scope, input.ContinuationToken)
+			if err != nil {
+				return nil, ExecuteQueryToolResult{}, err
+			}
+			input.ContinuationToken = continuationToken
+		}
+
+		callResult, result, err := handler(ctx, req, input)
+		if err != nil || result.ContinuationToken == "" {
+			return callResult, result, err
+		}
+
+		id, err := cursors.Put(scope, result.ContinuationToken)
+		if err != nil {
+			return nil, ExecuteQueryToolResult{}, err
+		}
+		result.ContinuationToken = id
+
+		return callResult, result, nil
+	}
+}
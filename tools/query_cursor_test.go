@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for query cursors that do not need the emulator
+
+func TestQueryCursorStore(t *testing.T) {
+	now := time.Now()
+	cursors := NewQueryCursorStore(time.Minute)
+	cursors.now = func() time.Time { return now }
+	assert.True(t, cursors.Enabled())
+
+	token := `{"token":"` + strings.Repeat("x", 1000) + `","range":{"min":"","max":"FF"}}`
+	id, err := cursors.Put("q1", token)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(id, queryCursorPrefix))
+	assert.Len(t, id, len(queryCursorPrefix)+16)
+
+	continuationToken, err := cursors.Get("q1", id)
+	require.NoError(t, err)
+	assert.Equal(t, token, continuationToken)
+
+	// a cursor can be used more than once, e.g. to fetch a page again
+	_, err = cursors.Get("q1", id)
+	require.NoError(t, err)
+
+	_, err = cursors.Get("q2", id)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "belongs to a different query")
+
+	_, err = cursors.Get("q1", "cur_0000000000000000")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown or expired")
+
+	other, err := cursors.Put("q1", token)
+	require.NoError(t, err)
+	assert.NotEqual(t, id, other, "each page should get a new cursor")
+
+	now = now.Add(time.Minute)
+	_, err = cursors.Get("q1", id)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown or expired")
+
+	// expired cursors are dropped when the next cursor is created
+	_, err = cursors.Put("q1", token)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cursors.Len())
+}
+
+func TestQueryCursorStore_MaxCursors(t *testing.T) {
+	cursors := NewQueryCursorStore(time.Hour)
+
+	first, err := cursors.Put("q1", "token0")
+	require.NoError(t, err)
+	for i := 1; i < maxQueryCursors+10; i++ {
+		_, err := cursors.Put("q1", "token")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, maxQueryCursors, cursors.Len())
+	_, err = cursors.Get("q1", first)
+	assert.Error(t, err, "the oldest cursor should be dropped")
+}
+
+func TestGetQueryCursorTTL(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    time.Duration
+		expectError bool
+	}{
+		{name: "not set", expected: 0},
+		{name: "seconds", value: "900", expected: 15 * time.Minute},
+		{name: "disabled", value: "0", expected: 0},
+		{name: "negative", value: "-1", expectError: true},
+		{name: "duration", value: "15m", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(QueryCursorTTLEnvVar, tt.value)
+
+			ttl, err := GetQueryCursorTTL()
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ttl)
+		})
+	}
+}
+
+func TestCursorExecuteQueryToolHandler(t *testing.T) {
+	const token = `{"token":"-RID:~abc#RT:1#TRC:2#ISV:2#IEO:65567","range":{"min":"","max":"FF"}}`
+
+	// a fake execute_query handler that returns the continuation token it was given, or a new one
+	var received string
+	inner := func(_ context.Context, _ *mcp.CallToolRequest, input ExecuteQueryToolInput) (*mcp.CallToolResult, ExecuteQueryToolResult, error) {
+		received = input.ContinuationToken
+		return nil, ExecuteQueryToolResult{HasMore: true, ContinuationToken: token}, nil
+	}
+
+	input := ExecuteQueryToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "account1"},
+		Database:         "db1",
+		Container:        "c1",
+		Query:            "SELECT * FROM c",
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		handler := CursorExecuteQueryToolHandler(NewQueryCursorStore(0), inner)
+		_, result, err := handler(context.Background(), nil, input)
+		require.NoError(t, err)
+		assert.Equal(t, token, result.ContinuationToken)
+	})
+
+	handler := CursorExecuteQueryToolHandler(NewQueryCursorStore(time.Minute), inner)
+
+	_, result, err := handler(context.Background(), nil, input)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(result.ContinuationToken, queryCursorPrefix))
+	assert.Empty(t, received)
+
+	// the cursor is resolved to the continuation token for the next page
+	next := input
+	next.ContinuationToken = result.ContinuationToken
+	_, _, err = handler(context.Background(), nil, next)
+	require.NoError(t, err)
+	assert.Equal(t, token, received)
+
+	// raw continuation tokens are passed through
+	next.ContinuationToken = token
+	_, _, err = handler(context.Background(), nil, next)
+	require.NoError(t, err)
+	assert.Equal(t, token, received)
+
+	// the cursor can't resume another query
+	next.ContinuationToken = result.ContinuationToken
+	next.Query = "SELECT c.id FROM c"
+	_, _, err = handler(context.Background(), nil, next)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "belongs to a different query")
+}
@@ -0,0 +1,178 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// queryPlanHeaders turn a query request into a query plan request. The gateway returns the plan instead of executing the query.
+// The supported features are the ones the other Azure Cosmos DB SDKs announce, so that the plan describes every rewrite.
+var queryPlanHeaders = http.Header{
+	"x-ms-cosmos-is-query-plan-request":    {"True"},
+	"x-ms-cosmos-supported-query-features": {"Aggregate, CompositeAggregate, Distinct, GroupBy, MultipleAggregates, MultipleOrderBy, OffsetAndLimit, OrderBy, Top, NonValueAggregate, DCount"},
+	"x-ms-cosmos-query-version":            {"1.4"},
+}
+
+func GetQueryPlan() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "get_query_plan",
+		Description: "Get the query plan that the Azure Cosmos DB gateway generates for a SQL query on a container in Azure Cosmos DB or local emulator, without executing the query. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. The plan shows the rewritten query, the aggregates, ORDER BY, DISTINCT, TOP, OFFSET LIMIT and GROUP BY that would have to be applied across partitions, and the partition key ranges the query targets. single_partition is true if the query filters on a single partition key value. Use this to understand why a query is cross-partition, or why execute_query rejects it without a partition key. Use diagnose_query for index usage instead.",
+	}
+}
+
+type GetQueryPlanToolInput struct {
+	ConnectionConfig
+	Database  string `json:"database" jsonschema:"Name of the database"`
+	Container string `json:"container" jsonschema:"Name of the container to query"`
+	Query     string `json:"query" jsonschema:"The SQL query string to get the plan for"`
+}
+
+type GetQueryPlanToolResult struct {
+	Query           string   `json:"query"`
+	SinglePartition bool     `json:"single_partition" jsonschema:"Whether the query targets a single partition key value"`
+	QueryRanges     int      `json:"query_ranges" jsonschema:"Number of partition key ranges the query targets"`
+	RewrittenQuery  string   `json:"rewritten_query,omitempty" jsonschema:"The query sent to each partition, if the gateway rewrites it"`
+	Aggregates      []string `json:"aggregates,omitempty"`
+	OrderBy         []string `json:"order_by,omitempty" jsonschema:"ORDER BY expressions with their direction"`
+	GroupBy         []string `json:"group_by,omitempty" jsonschema:"GROUP BY expressions"`
+	Distinct        string   `json:"distinct,omitempty" jsonschema:"DISTINCT type: Ordered or Unordered"`
+	Top             *int     `json:"top,omitempty"`
+	Offset          *int     `json:"offset,omitempty"`
+	Limit           *int     `json:"limit,omitempty"`
+	Plan            any      `json:"plan" jsonschema:"The raw query plan returned by the gateway"`
+}
+
+func GetQueryPlanToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input GetQueryPlanToolInput) (*mcp.CallToolResult, GetQueryPlanToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, GetQueryPlanToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, GetQueryPlanToolResult{}, newInputError("database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, GetQueryPlanToolResult{}, newInputError("container name missing")
+	}
+
+	if input.Query == "" {
+		return nil, GetQueryPlanToolResult{}, newInputError("query string missing")
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, GetQueryPlanToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, GetQueryPlanToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, GetQueryPlanToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	// the plan is requested for the whole container, it tells which partitions the query targets
+	queryPager := containerClient.NewQueryItemsPager(input.Query, azcosmos.PartitionKey{}, nil)
+
+	queryResponse, err := nextPageWithRetry(policy.WithHTTPHeader(ctx, queryPlanHeaders), queryPager)
+	if err != nil {
+		return nil, GetQueryPlanToolResult{}, fmt.Errorf("error getting query plan: %w", withDiagnostics(err))
+	}
+
+	if queryResponse.RawResponse == nil {
+		return nil, GetQueryPlanToolResult{}, errors.New("error getting query plan: empty response")
+	}
+
+	// the SDK parses the response as query results, Payload returns the buffered body with the plan
+	body, err := runtime.Payload(queryResponse.RawResponse)
+	if err != nil {
+		return nil, GetQueryPlanToolResult{}, fmt.Errorf("error reading query plan: %v", err)
+	}
+
+	result, err := parseQueryPlan(body)
+	if err != nil {
+		return nil, GetQueryPlanToolResult{}, err
+	}
+	result.Query = input.Query
+
+	return nil, result, nil
+}
+
+// queryPlan is the part of the partitioned query execution info returned by the gateway that is summarized
+type queryPlan struct {
+	QueryInfo *struct {
+		DistinctType       string   `json:"distinctType"`
+		Top                *int     `json:"top"`
+		Offset             *int     `json:"offset"`
+		Limit              *int     `json:"limit"`
+		OrderBy            []string `json:"orderBy"`
+		OrderByExpressions []string `json:"orderByExpressions"`
+		GroupByExpressions []string `json:"groupByExpressions"`
+		Aggregates         []string `json:"aggregates"`
+		RewrittenQuery     string   `json:"rewrittenQuery"`
+	} `json:"queryInfo"`
+	QueryRanges []struct {
+		Min            string `json:"min"`
+		Max            string `json:"max"`
+		IsMinInclusive bool   `json:"isMinInclusive"`
+		IsMaxInclusive bool   `json:"isMaxInclusive"`
+	} `json:"queryRanges"`
+}
+
+// parseQueryPlan summarizes the query plan returned by the gateway, the raw plan is included as is
+func parseQueryPlan(body []byte) (GetQueryPlanToolResult, error) {
+	var plan queryPlan
+	if err := json.Unmarshal(body, &plan); err != nil {
+		return GetQueryPlanToolResult{}, fmt.Errorf("error parsing query plan: %v", err)
+	}
+
+	var raw any
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return GetQueryPlanToolResult{}, fmt.Errorf("error parsing query plan: %v", err)
+	}
+
+	result := GetQueryPlanToolResult{
+		QueryRanges: len(plan.QueryRanges),
+		Plan:        raw,
+	}
+
+	// a query on a single partition key value targets a single point range
+	if len(plan.QueryRanges) == 1 {
+		queryRange := plan.QueryRanges[0]
+		result.SinglePartition = queryRange.Min == queryRange.Max && queryRange.IsMinInclusive && queryRange.IsMaxInclusive
+	}
+
+	if info := plan.QueryInfo; info != nil {
+		result.RewrittenQuery = info.RewrittenQuery
+		result.Aggregates = info.Aggregates
+		result.GroupBy = info.GroupByExpressions
+		result.Top = info.Top
+		result.Offset = info.Offset
+		result.Limit = info.Limit
+
+		if info.DistinctType != "" && info.DistinctType != "None" {
+			result.Distinct = info.DistinctType
+		}
+
+		for i, expression := range info.OrderByExpressions {
+			if i < len(info.OrderBy) {
+				expression = fmt.Sprintf("%s %s", expression, info.OrderBy[i])
+			}
+			result.OrderBy = append(result.OrderBy, expression)
+		}
+	}
+
+	return result, nil
+}
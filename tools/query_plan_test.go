@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for query plan helpers that do not need the emulator
+
+func TestParseQueryPlan(t *testing.T) {
+	t.Run("cross partition query", func(t *testing.T) {
+		result, err := parseQueryPlan([]byte(`{
+			"partitionedQueryExecutionInfoVersion": 2,
+			"queryInfo": {
+				"distinctType": "None",
+				"top": 10,
+				"offset": null,
+				"limit": null,
+				"orderBy": ["Descending"],
+				"orderByExpressions": ["c.price"],
+				"groupByExpressions": [],
+				"aggregates": [],
+				"rewrittenQuery": "SELECT TOP 10 c._rid, [{\"item\": c.price}] AS orderByItems, c AS payload FROM c ORDER BY c.price DESC"
+			},
+			"queryRanges": [{"min": "", "max": "FF", "isMinInclusive": true, "isMaxInclusive": false}]
+		}`))
+		require.NoError(t, err)
+		assert.False(t, result.SinglePartition)
+		assert.Equal(t, 1, result.QueryRanges)
+		assert.Equal(t, []string{"c.price Descending"}, result.OrderBy)
+		require.NotNil(t, result.Top)
+		assert.Equal(t, 10, *result.Top)
+		assert.Nil(t, result.Offset)
+		assert.Empty(t, result.Distinct)
+		assert.Contains(t, result.RewrittenQuery, "orderByItems")
+		assert.Contains(t, result.Plan, "queryInfo")
+	})
+
+	t.Run("single partition query", func(t *testing.T) {
+		result, err := parseQueryPlan([]byte(`{
+			"queryInfo": {"distinctType": "Unordered", "aggregates": ["Count"], "rewrittenQuery": ""},
+			"queryRanges": [{"min": "05C1DFFFFFFFFC", "max": "05C1DFFFFFFFFC", "isMinInclusive": true, "isMaxInclusive": true}]
+		}`))
+		require.NoError(t, err)
+		assert.True(t, result.SinglePartition)
+		assert.Equal(t, "Unordered", result.Distinct)
+		assert.Equal(t, []string{"Count"}, result.Aggregates)
+	})
+
+	t.Run("invalid plan", func(t *testing.T) {
+		_, err := parseQueryPlan([]byte(`not json`))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,179 @@
+package tools
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for query helpers that do not need the emulator
+
+func TestAnnotateSourceContainer(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     string
+		expected string
+	}{
+		{
+			name:     "object result",
+			item:     `{"id":"1","value":"foo"}`,
+			expected: `{"_sourceContainer":"orders","id":"1","value":"foo"}`,
+		},
+		{
+			name:     "scalar result",
+			item:     `42`,
+			expected: `{"_sourceContainer":"orders","value":42}`,
+		},
+		{
+			name:     "null result",
+			item:     `null`,
+			expected: `{"_sourceContainer":"orders","value":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotated, err := annotateSourceContainer([]byte(tt.item), "orders")
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, annotated)
+		})
+	}
+}
+
+func TestAnnotateSourceContainer_LargeIntegers(t *testing.T) {
+	// 2^53 + 1 can't be represented as a float64
+	annotated, err := annotateSourceContainer([]byte(`{"id":"1","big":9007199254740993,"nested":{"n":12345678901234567890}}`), "orders")
+	require.NoError(t, err)
+	assert.Contains(t, annotated, `"big":9007199254740993`)
+	assert.Contains(t, annotated, `"n":12345678901234567890`)
+	assert.Contains(t, annotated, `"_sourceContainer":"orders"`)
+
+	annotated, err = annotateSourceContainer([]byte(`9007199254740993`), "orders")
+	require.NoError(t, err)
+	assert.Contains(t, annotated, `"value":9007199254740993`)
+}
+
+func TestProjectionQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		fields      []string
+		expected    string
+		expectError bool
+	}{
+		{name: "single field", fields: []string{"name"}, expected: "SELECT c.name AS f0 FROM c WHERE c.id = @id"},
+		{name: "nested fields", fields: []string{"address.city", "/billing/city"}, expected: "SELECT c.address.city AS f0, c.billing.city AS f1 FROM c WHERE c.id = @id"},
+		{name: "no fields", fields: nil, expectError: true},
+		{name: "invalid field", fields: []string{"name", "x FROM c"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := projectionQuery(tt.fields)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, query)
+		})
+	}
+}
+
+func TestItemMetadata(t *testing.T) {
+	item := `{"id":"1","_rid":"abc==","_self":"dbs/abc==/colls/def=/docs/ghi==/","_etag":"\"0000-0000\"","_attachments":"attachments/","_ts":1700000000}`
+
+	metadata, err := itemMetadata([]byte(item))
+	require.NoError(t, err)
+	assert.Equal(t, ItemMetadata{
+		LastModified: "2023-11-14T22:13:20Z",
+		Timestamp:    1700000000,
+		ETag:         `"0000-0000"`,
+		Self:         "dbs/abc==/colls/def=/docs/ghi==/",
+		ResourceID:   "abc==",
+	}, metadata)
+
+	metadata, err = itemMetadata([]byte(`{"id":"1"}`))
+	require.NoError(t, err)
+	assert.Empty(t, metadata.LastModified)
+
+	_, err = itemMetadata([]byte(`not json`))
+	require.Error(t, err)
+}
+
+func TestResponseSessionToken(t *testing.T) {
+	assert.Empty(t, responseSessionToken(azcosmos.Response{}))
+
+	response := azcosmos.Response{RawResponse: &http.Response{Header: http.Header{}}}
+	assert.Empty(t, responseSessionToken(response))
+
+	response.RawResponse.Header.Set("x-ms-session-token", "0:1#42")
+	assert.Equal(t, "0:1#42", responseSessionToken(response))
+}
+
+func TestOffsetLimitQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		expected    string
+		expectError bool
+	}{
+		{name: "simple query", query: "SELECT * FROM c", expected: "SELECT * FROM c OFFSET @offset LIMIT @limit"},
+		{name: "ordered query", query: "SELECT c.id FROM c WHERE c.status = 'open' ORDER BY c.createdAt", expected: "SELECT c.id FROM c WHERE c.status = 'open' ORDER BY c.createdAt OFFSET @offset LIMIT @limit"},
+		{name: "trailing semicolon", query: " SELECT * FROM c; ", expected: "SELECT * FROM c OFFSET @offset LIMIT @limit"},
+		{name: "field named limit", query: "SELECT c.limit, c.offset FROM c", expected: "SELECT c.limit, c.offset FROM c OFFSET @offset LIMIT @limit"},
+		{name: "missing query", query: " ", expectError: true},
+		{name: "existing offset limit", query: "SELECT * FROM c offset 10 limit 10", expectError: true},
+		{name: "existing top", query: "SELECT TOP 5 * FROM c", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := offsetLimitQuery(tt.query)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, query)
+		})
+	}
+}
+
+func TestWithPartitionKeyNone(t *testing.T) {
+	var header http.Header
+	options, err := newClientOptions()
+	require.NoError(t, err)
+	options.Transport = transporterFunc(func(req *http.Request) (*http.Response, error) {
+		header = req.Header.Clone()
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"id": "1"}`)), Request: req}, nil
+	})
+
+	cred, err := azcosmos.NewKeyCredential(getEmulatorKey())
+	require.NoError(t, err)
+	client, err := azcosmos.NewClientWithKey(DefaultEmulatorEndpoint, cred, options)
+	require.NoError(t, err)
+	containerClient, err := client.NewContainer("db", "container")
+	require.NoError(t, err)
+
+	_, err = containerClient.ReadItem(withPartitionKeyNone(context.Background()), azcosmos.NewPartitionKey(), "1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"[{}]"}, header.Values(partitionKeyHeader))
+
+	// requests made without the context are not affected
+	_, err = containerClient.ReadItem(context.Background(), azcosmos.NewPartitionKeyString("pk"), "1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`["pk"]`}, header.Values(partitionKeyHeader))
+}
+
+func TestCheckPartitionKeyNone(t *testing.T) {
+	assert.NoError(t, checkPartitionKeyNone("pk", false))
+	assert.NoError(t, checkPartitionKeyNone("", true))
+	assert.NoError(t, checkPartitionKeyNone("", false))
+	assert.Error(t, checkPartitionKeyNone("pk", true))
+}
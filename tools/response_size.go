@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MaxResponseBytesEnvVar limits the size of the results returned by query and read tools, results beyond the limit are omitted
+const MaxResponseBytesEnvVar = "COSMOS_MAX_RESPONSE_BYTES"
+
+// defaultMaxResponseBytes is used when the limit is not configured. Larger responses rarely fit in the context window of an agent.
+const defaultMaxResponseBytes = 256 * 1024
+
+// GetMaxResponseBytes returns the maximum size of the results of a tool call configured in the environment, 0 means no limit
+func GetMaxResponseBytes() (int, error) {
+	value := strings.TrimSpace(os.Getenv(MaxResponseBytesEnvVar))
+	if value == "" {
+		return defaultMaxResponseBytes, nil
+	}
+
+	max, err := strconv.Atoi(value)
+	if err != nil || max < 0 {
+		return 0, fmt.Errorf("invalid value '%s' for %s, must be 0 (no limit) or a positive number of bytes", value, MaxResponseBytesEnvVar)
+	}
+	return max, nil
+}
+
+// limitResponseSize returns the leading results whose total size (as reported by size) does not exceed maxBytes,
+// and the number of results that were left out. A maxBytes of 0 means no limit.
+func limitResponseSize[T any](results []T, size func(T) int, maxBytes int) ([]T, int) {
+	if maxBytes == 0 {
+		return results, 0
+	}
+
+	total := 0
+	for i, result := range results {
+		total += size(result)
+		if total > maxBytes {
+			return results[:i], len(results) - i
+		}
+	}
+
+	return results, 0
+}
+
+// responseSizeNote tells the agent that results were omitted to stay within the response size limit
+func responseSizeNote(omitted, total, maxBytes int) string {
+	return fmt.Sprintf("%d of %d results omitted to keep the response under %d bytes (%s), refine your query or paginate", omitted, total, maxBytes, MaxResponseBytesEnvVar)
+}
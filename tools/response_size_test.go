@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for the response size limit that do not need the emulator
+
+func TestGetMaxResponseBytes(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    int
+		expectError bool
+	}{
+		{name: "not set", value: "", expected: defaultMaxResponseBytes},
+		{name: "valid", value: "1048576", expected: 1048576},
+		{name: "no limit", value: "0", expected: 0},
+		{name: "negative", value: "-1", expectError: true},
+		{name: "not a number", value: "1MB", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(MaxResponseBytesEnvVar, tt.value)
+
+			max, err := GetMaxResponseBytes()
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, max)
+		})
+	}
+}
+
+func TestLimitResponseSize(t *testing.T) {
+	results := []string{`{"id":"1"}`, `{"id":"2"}`, `{"id":"3"}`}
+	size := func(result string) int { return len(result) }
+
+	tests := []struct {
+		name            string
+		maxBytes        int
+		expected        []string
+		expectedOmitted int
+	}{
+		{name: "no limit", maxBytes: 0, expected: results},
+		{name: "within limit", maxBytes: 30, expected: results},
+		{name: "exceeds limit", maxBytes: 25, expected: results[:2], expectedOmitted: 1},
+		{name: "first result exceeds limit", maxBytes: 5, expected: []string{}, expectedOmitted: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, omitted := limitResponseSize(results, size, tt.maxBytes)
+			assert.Equal(t, tt.expected, kept)
+			assert.Equal(t, tt.expectedOmitted, omitted)
+		})
+	}
+
+	assert.Equal(t, "1 of 3 results omitted to keep the response under 25 bytes (COSMOS_MAX_RESPONSE_BYTES), refine your query or paginate", responseSizeNote(1, 3, 25))
+}
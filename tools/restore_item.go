@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// restoreItemUnsupportedReason explains why an item can't be restored
+const restoreItemUnsupportedReason = "Point-in-time restore (continuous backup) is a control plane operation that restores a whole account into a new account, or a deleted database or container within the same account. It is not available through the data plane Azure SDK for Go used by this server, and individual items can't be restored."
+
+func RestoreItem() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "restore_item",
+		Description: "Restore a deleted item, or a prior version of an item, in Azure Cosmos DB using point-in-time restore (continuous backup), e.g. to recover from an accidental deletion. Restoring items is NOT available through this MCP server: the tool does not connect to Azure Cosmos DB or change anything, and always returns supported false, the reason and alternatives, such as restoring the container with the Azure CLI and copying the item back.",
+	}
+}
+
+type RestoreItemToolInput struct {
+	Database     string `json:"database,omitempty" jsonschema:"Name of the database (optional)"`
+	Container    string `json:"container,omitempty" jsonschema:"Name of the container (optional)"`
+	ItemID       string `json:"itemID,omitempty" jsonschema:"ID of the item to restore (optional)"`
+	PartitionKey string `json:"partitionKey,omitempty" jsonschema:"Partition key value of the item (optional)"`
+	RestoreTime  string `json:"restoreTime,omitempty" jsonschema:"Point in time to restore the item to, as an RFC 3339 timestamp (optional)"`
+}
+
+type RestoreItemToolResult struct {
+	Supported    bool     `json:"supported" jsonschema:"Whether the item can be restored, always false"`
+	Reason       string   `json:"reason" jsonschema:"Why the item can't be restored"`
+	Alternatives []string `json:"alternatives" jsonschema:"Other ways to recover the item"`
+	Message      string   `json:"message"`
+}
+
+func RestoreItemToolHandler(_ context.Context, _ *mcp.CallToolRequest, input RestoreItemToolInput) (*mcp.CallToolResult, RestoreItemToolResult, error) {
+
+	item := "items"
+	if input.ItemID != "" {
+		item = fmt.Sprintf("item '%s'", input.ItemID)
+	}
+
+	container := "<container>"
+	if input.Container != "" {
+		container = input.Container
+	}
+
+	return nil, RestoreItemToolResult{
+		Supported: false,
+		Reason:    restoreItemUnsupportedReason,
+		Alternatives: []string{
+			fmt.Sprintf("Restore the account into a new account as of the point in time with the Azure portal or Azure CLI (az cosmosdb restore), then read the item there and write it back with add_item_to_container. Restore only the '%s' container if it was deleted (az cosmosdb sql container restore).", container),
+			"To copy many items back, use export_container on the restored account and import_container on this one.",
+			"If the content of the item is known (e.g. from an audit or history container), recreate it with add_item_to_container.",
+		},
+		Message: fmt.Sprintf("Restoring %s is not available through this MCP server", item),
+	}, nil
+}
@@ -0,0 +1,31 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for restore_item that do not need the emulator
+
+func TestRestoreItemToolHandler(t *testing.T) {
+	_, result, err := RestoreItemToolHandler(context.Background(), nil, RestoreItemToolInput{
+		Database:    "db1",
+		Container:   "orders",
+		ItemID:      "order1",
+		RestoreTime: "2024-01-01T00:00:00Z",
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Supported)
+	assert.Contains(t, result.Reason, "control plane")
+	require.NotEmpty(t, result.Alternatives)
+	assert.Contains(t, result.Alternatives[0], "'orders' container")
+	assert.Contains(t, result.Message, "item 'order1'")
+
+	// the tool answers without any arguments too
+	_, result, err = RestoreItemToolHandler(context.Background(), nil, RestoreItemToolInput{})
+	require.NoError(t, err)
+	assert.False(t, result.Supported)
+}
@@ -0,0 +1,127 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// maxThrottleRetries is how many times a throttled (429) page request is retried before giving up
+const maxThrottleRetries = 5
+
+// defaultThrottleBackoff is the initial wait between retries when the service does not send a retry-after hint
+const defaultThrottleBackoff = 500 * time.Millisecond
+
+// maxThrottleBackoff caps the wait between retries
+const maxThrottleBackoff = 10 * time.Second
+
+// nextPageWithRetry fetches the next page, retrying with backoff if the request is throttled (429).
+// This is on top of the SDK retries, so that a long running query does not fail mid-way because of throttling.
+func nextPageWithRetry[T any](ctx context.Context, pager *runtime.Pager[T]) (T, error) {
+	backoff := defaultThrottleBackoff
+
+	for attempt := 0; ; attempt++ {
+		page, err := pager.NextPage(ctx)
+		if err == nil || attempt >= maxThrottleRetries || !isThrottled(err) {
+			return page, err
+		}
+
+		wait := retryAfter(err)
+		if wait <= 0 {
+			wait = backoff
+			backoff = min(backoff*2, maxThrottleBackoff)
+		}
+
+		select {
+		case <-ctx.Done():
+			return page, err
+		case <-time.After(min(wait, maxThrottleBackoff)):
+		}
+	}
+}
+
+// isThrottled checks if the error is because the request rate is too large (status code 429)
+func isThrottled(err error) bool {
+	var responseErr *azcore.ResponseError
+	if errors.As(err, &responseErr) {
+		return responseErr.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// retryAfter returns the wait duration suggested by the service, or zero if there is none.
+// Cosmos DB sends x-ms-retry-after-ms, the standard Retry-After header (in seconds) is used as a fallback.
+func retryAfter(err error) time.Duration {
+	var responseErr *azcore.ResponseError
+	if !errors.As(err, &responseErr) || responseErr.RawResponse == nil {
+		return 0
+	}
+
+	header := responseErr.RawResponse.Header
+
+	if ms, err := strconv.Atoi(header.Get("x-ms-retry-after-ms")); err == nil && ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	return 0
+}
+
+// waitUntilReadyTimeout is how long create operations wait for a new database or container when waitUntilReady is set
+const waitUntilReadyTimeout = 60 * time.Second
+
+// defaultReadyBackoff is the initial wait between reads of a resource that is not ready yet
+const defaultReadyBackoff = 250 * time.Millisecond
+
+// maxReadyBackoff caps the wait between reads of a resource that is not ready yet
+const maxReadyBackoff = 5 * time.Second
+
+// waitUntilReady calls read with exponential backoff until it succeeds, fails with an error that is not transient, or the timeout expires.
+// A database or container that was just created, especially with provisioned throughput, can return 404 (or 429/503) for a short while.
+func waitUntilReady(ctx context.Context, timeout time.Duration, read func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	backoff := defaultReadyBackoff
+
+	for {
+		err := read(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("not ready after %s: %w", timeout, err)
+		}
+		if !isNotReady(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("not ready after %s: %w", timeout, err)
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxReadyBackoff)
+	}
+}
+
+// isNotReady checks if the error means that a new resource is not available yet (status code 404, 429 or 503)
+func isNotReady(err error) bool {
+	var responseErr *azcore.ResponseError
+	if errors.As(err, &responseErr) {
+		switch responseErr.StatusCode {
+		case http.StatusNotFound, http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for the throttling retry helpers
+
+func throttledError(headers map[string]string) error {
+	response := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	for k, v := range headers {
+		response.Header.Set(k, v)
+	}
+	return &azcore.ResponseError{StatusCode: http.StatusTooManyRequests, RawResponse: response}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected time.Duration
+	}{
+		{
+			name:     "cosmos retry after header",
+			err:      throttledError(map[string]string{"x-ms-retry-after-ms": "250"}),
+			expected: 250 * time.Millisecond,
+		},
+		{
+			name:     "standard retry after header",
+			err:      throttledError(map[string]string{"Retry-After": "2"}),
+			expected: 2 * time.Second,
+		},
+		{
+			name:     "no retry after header",
+			err:      throttledError(nil),
+			expected: 0,
+		},
+		{
+			name:     "not a response error",
+			err:      errors.New("boom"),
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, retryAfter(tt.err))
+		})
+	}
+}
+
+func TestIsThrottled(t *testing.T) {
+	assert.True(t, isThrottled(throttledError(nil)))
+	assert.False(t, isThrottled(&azcore.ResponseError{StatusCode: http.StatusNotFound}))
+	assert.False(t, isThrottled(errors.New("boom")))
+}
+
+func TestNextPageWithRetry(t *testing.T) {
+	newPager := func(failures int, err error) (*runtime.Pager[int], *int) {
+		calls := 0
+		pager := runtime.NewPager(runtime.PagingHandler[int]{
+			More: func(int) bool { return false },
+			Fetcher: func(ctx context.Context, _ *int) (int, error) {
+				calls++
+				if calls <= failures {
+					return 0, err
+				}
+				return 42, nil
+			},
+		})
+		return pager, &calls
+	}
+
+	t.Run("succeeds after throttling", func(t *testing.T) {
+		pager, calls := newPager(2, throttledError(map[string]string{"x-ms-retry-after-ms": "1"}))
+
+		page, err := nextPageWithRetry(context.Background(), pager)
+		require.NoError(t, err)
+		assert.Equal(t, 42, page)
+		assert.Equal(t, 3, *calls)
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		pager, calls := newPager(maxThrottleRetries+1, throttledError(map[string]string{"x-ms-retry-after-ms": "1"}))
+
+		_, err := nextPageWithRetry(context.Background(), pager)
+		require.Error(t, err)
+		assert.Equal(t, maxThrottleRetries+1, *calls)
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		pager, calls := newPager(1, errors.New("boom"))
+
+		_, err := nextPageWithRetry(context.Background(), pager)
+		require.Error(t, err)
+		assert.Equal(t, 1, *calls)
+	})
+}
+
+func TestWaitUntilReady(t *testing.T) {
+	newRead := func(failures int, err error) (func(context.Context) error, *int) {
+		calls := 0
+		return func(context.Context) error {
+			calls++
+			if calls <= failures {
+				return err
+			}
+			return nil
+		}, &calls
+	}
+
+	t.Run("succeeds after not found", func(t *testing.T) {
+		read, calls := newRead(2, &azcore.ResponseError{StatusCode: http.StatusNotFound})
+
+		require.NoError(t, waitUntilReady(context.Background(), 10*time.Second, read))
+		assert.Equal(t, 3, *calls)
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		read, calls := newRead(1, &azcore.ResponseError{StatusCode: http.StatusForbidden})
+
+		err := waitUntilReady(context.Background(), 10*time.Second, read)
+		require.Error(t, err)
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("gives up after the timeout", func(t *testing.T) {
+		read, calls := newRead(1000, &azcore.ResponseError{StatusCode: http.StatusServiceUnavailable})
+
+		err := waitUntilReady(context.Background(), 100*time.Millisecond, read)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not ready after 100ms")
+		assert.True(t, isNotReady(err))
+		assert.Equal(t, 1, *calls)
+	})
+}
+
+func TestIsNotReady(t *testing.T) {
+	assert.True(t, isNotReady(&azcore.ResponseError{StatusCode: http.StatusNotFound}))
+	assert.True(t, isNotReady(throttledError(nil)))
+	assert.True(t, isNotReady(&azcore.ResponseError{StatusCode: http.StatusServiceUnavailable}))
+	assert.False(t, isNotReady(&azcore.ResponseError{StatusCode: http.StatusConflict}))
+	assert.False(t, isNotReady(errors.New("boom")))
+}
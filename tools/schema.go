@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// accountProperty is the name of the account parameter in the tool input schemas (see ConnectionConfig)
+const accountProperty = "account"
+
+// WithAccountSchema returns a copy of the tool whose input schema describes the account parameter according to the
+// default account configuration, or omits it entirely if the account parameter is hidden.
+// The tool is returned as is when no default account is configured or when its input has no account.
+func WithAccountSchema[In any](tool *mcp.Tool) (*mcp.Tool, error) {
+	defaultAccount := getDefaultAccount()
+	if defaultAccount == "" {
+		return tool, nil
+	}
+
+	hideAccount, err := getHideAccountParameter()
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := jsonschema.For[In](nil)
+	if err != nil {
+		return nil, fmt.Errorf("error inferring input schema for tool %s: %v", tool.Name, err)
+	}
+
+	account, ok := schema.Properties[accountProperty]
+	if !ok {
+		return tool, nil
+	}
+
+	if hideAccount {
+		// the input schema does not allow additional properties, so an account can't be passed at all
+		delete(schema.Properties, accountProperty)
+		schema.Required = slices.DeleteFunc(schema.Required, func(name string) bool { return name == accountProperty })
+	} else {
+		account.Description = fmt.Sprintf("Azure Cosmos DB account name. Optional - if not provided, the default account %s is used. Only set this when the user explicitly asks for a different account", defaultAccount)
+	}
+
+	adjusted := *tool
+	adjusted.InputSchema = schema
+	return &adjusted, nil
+}
+
+// getHideAccountParameter reports whether the account parameter is hidden in the environment
+func getHideAccountParameter() (bool, error) {
+	value := strings.TrimSpace(os.Getenv(HideAccountParameterEnvVar))
+	if value == "" {
+		return false, nil
+	}
+
+	hide, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value '%s' for %s, must be true or false", value, HideAccountParameterEnvVar)
+	}
+	return hide, nil
+}
@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for tool schema adjustments that do not need the emulator
+
+func TestWithAccountSchema(t *testing.T) {
+	t.Run("no default account", func(t *testing.T) {
+		t.Setenv(DefaultAccountEnvVar, "")
+
+		tool := ListDatabases()
+		adjusted, err := WithAccountSchema[ListDatabasesToolInput](tool)
+		require.NoError(t, err)
+		assert.Same(t, tool, adjusted)
+		assert.Nil(t, adjusted.InputSchema)
+	})
+
+	t.Run("default account", func(t *testing.T) {
+		t.Setenv(DefaultAccountEnvVar, "myaccount")
+
+		tool := ListDatabases()
+		adjusted, err := WithAccountSchema[ListDatabasesToolInput](tool)
+		require.NoError(t, err)
+		assert.Nil(t, tool.InputSchema, "original tool should not be modified")
+
+		schema, ok := adjusted.InputSchema.(*jsonschema.Schema)
+		require.True(t, ok)
+		account, ok := schema.Properties["account"]
+		require.True(t, ok)
+		assert.Contains(t, account.Description, "default account myaccount")
+		assert.NotContains(t, schema.Required, "account")
+	})
+
+	t.Run("input without account", func(t *testing.T) {
+		t.Setenv(DefaultAccountEnvVar, "myaccount")
+
+		tool := &mcp.Tool{Name: "no_account"}
+		adjusted, err := WithAccountSchema[struct {
+			Name string `json:"name"`
+		}](tool)
+		require.NoError(t, err)
+		assert.Same(t, tool, adjusted)
+	})
+}
+
+func TestWithAccountSchema_HiddenAccount(t *testing.T) {
+	t.Setenv(DefaultAccountEnvVar, "myaccount")
+	t.Setenv(HideAccountParameterEnvVar, "true")
+
+	adjusted, err := WithAccountSchema[ListDatabasesToolInput](ListDatabases())
+	require.NoError(t, err)
+
+	schema, ok := adjusted.InputSchema.(*jsonschema.Schema)
+	require.True(t, ok)
+	assert.NotContains(t, schema.Properties, "account")
+	assert.Contains(t, schema.Properties, "useEmulator")
+
+	t.Run("hidden without default account has no effect", func(t *testing.T) {
+		t.Setenv(DefaultAccountEnvVar, "")
+
+		tool := ListDatabases()
+		adjusted, err := WithAccountSchema[ListDatabasesToolInput](tool)
+		require.NoError(t, err)
+		assert.Same(t, tool, adjusted)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Setenv(HideAccountParameterEnvVar, "yes please")
+
+		_, err := WithAccountSchema[ListDatabasesToolInput](ListDatabases())
+		require.Error(t, err)
+	})
+}
+
+// TestWithAccountSchema_HiddenAccount_MCP verifies that the default account is used through the full MCP stack,
+// and that an account can't be passed when the account parameter is hidden
+func TestWithAccountSchema_HiddenAccount_MCP(t *testing.T) {
+	t.Setenv(DefaultAccountEnvVar, "myaccount")
+	t.Setenv(HideAccountParameterEnvVar, "true")
+
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-cosmosdb-server", Version: "0.0.1"}, nil)
+
+	tool, err := WithAccountSchema[ListDatabasesToolInput](ListDatabases())
+	require.NoError(t, err)
+
+	// the handler reports the account that would be used to connect
+	mcp.AddTool(server, tool, func(ctx context.Context, _ *mcp.CallToolRequest, input ListDatabasesToolInput) (*mcp.CallToolResult, ListDatabasesToolResult, error) {
+		if err := input.Validate(); err != nil {
+			return nil, ListDatabasesToolResult{}, err
+		}
+		return nil, ListDatabasesToolResult{Account: input.withDefaultAccount().Account, Databases: []string{}}, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	defer serverSession.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer clientSession.Close()
+
+	tools, err := clientSession.ListTools(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, tools.Tools, 1)
+	assert.NotContains(t, fmt.Sprint(tools.Tools[0].InputSchema), "account")
+
+	result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "list_databases", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var response ListDatabasesToolResult
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &response))
+	assert.Equal(t, "myaccount", response.Account)
+
+	_, err = clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "list_databases", Arguments: map[string]any{"account": "invented"}})
+	require.Error(t, err, "account should be rejected by the input schema")
+}
@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func SearchText() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "search_text",
+		Description: "Search for items in a container in Azure Cosmos DB or local emulator whose field contains a piece of text. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Use this instead of writing CONTAINS queries by hand. The search is case-insensitive unless caseSensitive is set to true. The field can be a top level property (name) or a nested one (address.city). Provide a partition key value to scope the search to a single partition, otherwise all partitions are searched.",
+	}
+}
+
+type SearchTextToolInput struct {
+	ConnectionConfig
+	Database      string `json:"database" jsonschema:"Name of the database"`
+	Container     string `json:"container" jsonschema:"Name of the container to search"`
+	Field         string `json:"field" jsonschema:"Field to search, e.g. name or address.city"`
+	Text          string `json:"text" jsonschema:"Text to search for"`
+	CaseSensitive bool   `json:"caseSensitive,omitempty" jsonschema:"Set to true for a case-sensitive search (default is case-insensitive)"`
+	PartitionKey  string `json:"partitionKey,omitempty" jsonschema:"The partition key value to scope the search to. If not provided, all partitions are searched."`
+}
+
+type SearchTextToolResult struct {
+	Query   string   `json:"query" jsonschema:"The query that was executed, the search text is passed as the @text parameter"`
+	Results []string `json:"results" jsonschema:"Matching items as JSON strings"`
+	Count   int      `json:"count" jsonschema:"Number of matching items returned"`
+	Note    string   `json:"note,omitempty" jsonschema:"Set if matching items were left out to keep the response within the size limit"`
+}
+
+func SearchTextToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input SearchTextToolInput) (*mcp.CallToolResult, SearchTextToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, SearchTextToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, SearchTextToolResult{}, newInputError("database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, SearchTextToolResult{}, newInputError("container name missing")
+	}
+
+	if input.Text == "" {
+		return nil, SearchTextToolResult{}, newInputError("search text missing")
+	}
+
+	query, err := searchTextQuery(input.Field, input.CaseSensitive)
+	if err != nil {
+		return nil, SearchTextToolResult{}, err
+	}
+
+	maxResponseBytes, err := GetMaxResponseBytes()
+	if err != nil {
+		return nil, SearchTextToolResult{}, err
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, SearchTextToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, SearchTextToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, SearchTextToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	var partitionKey azcosmos.PartitionKey
+	if input.PartitionKey != "" {
+		partitionKey = azcosmos.NewPartitionKeyString(input.PartitionKey)
+	}
+
+	options := &azcosmos.QueryOptions{
+		QueryParameters: []azcosmos.QueryParameter{{Name: "@text", Value: input.Text}},
+	}
+
+	items, err := collectQueryResults(ctx, containerClient, query, partitionKey, options)
+	if err != nil {
+		return nil, SearchTextToolResult{}, err
+	}
+
+	results := make([]string, 0, len(items))
+	for _, item := range items {
+		results = append(results, string(item))
+	}
+
+	var note string
+	if kept, omitted := limitResponseSize(results, func(result string) int { return len(result) }, maxResponseBytes); omitted > 0 {
+		note = responseSizeNote(omitted, len(results), maxResponseBytes)
+		results = kept
+	}
+
+	return nil, SearchTextToolResult{
+		Query:   query,
+		Results: results,
+		Count:   len(results),
+		Note:    note,
+	}, nil
+}
+
+// searchTextQuery builds a parameterized CONTAINS query for the field.
+// The search text is passed as the @text parameter and is never embedded in the query text.
+func searchTextQuery(field string, caseSensitive bool) (string, error) {
+	reference, err := fieldReference(field)
+	if err != nil {
+		return "", err
+	}
+
+	if caseSensitive {
+		return fmt.Sprintf("SELECT * FROM c WHERE CONTAINS(%s, @text)", reference), nil
+	}
+
+	// the third argument of CONTAINS enables a case-insensitive comparison
+	return fmt.Sprintf("SELECT * FROM c WHERE CONTAINS(%s, @text, true)", reference), nil
+}
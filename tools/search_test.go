@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for search helpers that do not need the emulator
+
+func TestSearchTextQuery(t *testing.T) {
+	tests := []struct {
+		name          string
+		field         string
+		caseSensitive bool
+		expected      string
+		expectError   bool
+	}{
+		{name: "case-insensitive", field: "name", expected: "SELECT * FROM c WHERE CONTAINS(c.name, @text, true)"},
+		{name: "case-sensitive nested field", field: "address.city", caseSensitive: true, expected: "SELECT * FROM c WHERE CONTAINS(c.address.city, @text)"},
+		{name: "missing field", field: "", expectError: true},
+		{name: "invalid field", field: "name, 'x') OR (true", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := searchTextQuery(tt.field, tt.caseSensitive)
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, query)
+		})
+	}
+}
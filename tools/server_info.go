@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func ServerInfo() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "server_info",
+		Description: "Get information about this MCP server: version, whether it runs in read-only mode, the maximum number of concurrent tool calls and how many tool calls are currently executing or waiting for a free slot, and how many times the Microsoft Entra ID access token was refreshed. Does not connect to Azure Cosmos DB.",
+	}
+}
+
+type ServerInfoToolInput struct{}
+
+type ServerInfoToolResult struct {
+	Version        string `json:"version"`
+	ReadOnly       bool   `json:"read_only"`
+	MaxConcurrency int    `json:"max_concurrency" jsonschema:"Maximum number of tool calls executed at the same time, 0 means no limit"`
+	InFlight       int64  `json:"in_flight" jsonschema:"Number of tool calls currently executing, not counting this one"`
+	Queued         int64  `json:"queued" jsonschema:"Number of tool calls waiting for a free slot"`
+	TokenRefreshes int64  `json:"token_refreshes" jsonschema:"Number of times the Microsoft Entra ID access token was refreshed (not used with keys or the emulator)"`
+}
+
+// ServerInfoToolHandler returns the handler for the server_info tool, reporting the state of the limiter used for tool calls
+func ServerInfoToolHandler(limiter *ConcurrencyLimiter, readOnly bool) mcp.ToolHandlerFor[ServerInfoToolInput, ServerInfoToolResult] {
+	return func(_ context.Context, _ *mcp.CallToolRequest, _ ServerInfoToolInput) (*mcp.CallToolResult, ServerInfoToolResult, error) {
+		return nil, ServerInfoToolResult{
+			Version:        ServerVersion,
+			ReadOnly:       readOnly,
+			MaxConcurrency: limiter.Max(),
+			InFlight:       limiter.InFlight(),
+			Queued:         limiter.Queued(),
+			TokenRefreshes: TokenRefreshes(),
+		}, nil
+	}
+}
@@ -0,0 +1,184 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Strategies reported by smart_read
+const (
+	SmartReadStrategyPointRead = "point_read"
+	SmartReadStrategyQuery     = "cross_partition_query"
+)
+
+// smartReadQuery looks up an item by id in all partitions
+const smartReadQuery = "SELECT * FROM c WHERE c.id = @id"
+
+func SmartRead() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "smart_read",
+		Description: "Read an item by id from a container in Azure Cosmos DB or local emulator when its partition key is unknown or uncertain. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. If partitionKey is provided, the item is read with a cheap point read first. If the item is not found (or no partitionKey is provided) and allowCrossPartition is true, the item is looked up by id with a query across all partitions, which costs more RUs. Returns the item, the strategy that found it (point_read or cross_partition_query) and its actual partition key value, to use with read_item next time. Item ids are only unique within a partition - if the id exists in several partitions, an error lists their partition key values.",
+	}
+}
+
+type SmartReadToolInput struct {
+	ConnectionConfig
+	Database            string `json:"database" jsonschema:"Name of the database"`
+	Container           string `json:"container" jsonschema:"Name of the container to read data from"`
+	ItemID              string `json:"itemID" jsonschema:"ID of the item to read"`
+	PartitionKey        string `json:"partitionKey,omitempty" jsonschema:"Partition key value to try a point read with (optional)"`
+	AllowCrossPartition bool   `json:"allowCrossPartition,omitempty" jsonschema:"Set to true to look the item up by id across all partitions if the point read does not find it or no partitionKey is provided (default false)"`
+}
+
+type SmartReadToolResult struct {
+	Item          string  `json:"item" jsonschema:"The item data as JSON string"`
+	Strategy      string  `json:"strategy" jsonschema:"How the item was found: point_read or cross_partition_query"`
+	PartitionKey  any     `json:"partition_key" jsonschema:"The partition key value of the item (a list for hierarchical partition keys, null if the item has no value for it)"`
+	RequestCharge float32 `json:"request_charge" jsonschema:"Total request charge (RU) of the point read and the query"`
+	Message       string  `json:"message"`
+}
+
+func SmartReadToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input SmartReadToolInput) (*mcp.CallToolResult, SmartReadToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, SmartReadToolResult{}, err
+	}
+
+	if input.Database == "" {
+		return nil, SmartReadToolResult{}, newInputError("database name missing")
+	}
+
+	if input.Container == "" {
+		return nil, SmartReadToolResult{}, newInputError("container name missing")
+	}
+
+	if input.ItemID == "" {
+		return nil, SmartReadToolResult{}, newInputError("item ID missing")
+	}
+
+	if input.PartitionKey == "" && !input.AllowCrossPartition {
+		return nil, SmartReadToolResult{}, newInputError("partition key missing: provide partitionKey, or set allowCrossPartition to true to look the item up by id across all partitions")
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, SmartReadToolResult{}, err
+	}
+
+	databaseClient, err := client.NewDatabase(input.Database)
+	if err != nil {
+		return nil, SmartReadToolResult{}, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containerClient, err := databaseClient.NewContainer(input.Container)
+	if err != nil {
+		return nil, SmartReadToolResult{}, fmt.Errorf("error creating container client: %v", err)
+	}
+
+	var result SmartReadToolResult
+
+	if input.PartitionKey != "" {
+		itemResponse, err := containerClient.ReadItem(ctx, azcosmos.NewPartitionKeyString(input.PartitionKey), input.ItemID, nil)
+		if err == nil {
+			result.Item = string(itemResponse.Value)
+			result.Strategy = SmartReadStrategyPointRead
+			result.PartitionKey = input.PartitionKey
+			result.RequestCharge = itemResponse.RequestCharge
+			result.Message = fmt.Sprintf("Item '%s' found with a point read", input.ItemID)
+			return nil, result, nil
+		}
+
+		var responseErr *azcore.ResponseError
+		if !errors.As(err, &responseErr) || responseErr.StatusCode != http.StatusNotFound {
+			return nil, SmartReadToolResult{}, fmt.Errorf("error reading item: %w", withDiagnostics(err))
+		}
+		if !input.AllowCrossPartition {
+			return nil, SmartReadToolResult{}, fmt.Errorf("item '%s' not found in partition '%s', set allowCrossPartition to true to look it up by id across all partitions: %w", input.ItemID, input.PartitionKey, withDiagnostics(err))
+		}
+		result.RequestCharge = responseRequestCharge(responseErr.RawResponse)
+	}
+
+	// the partition key paths are needed to report the partition key value of the item found by the query
+	containerResponse, err := containerClient.Read(ctx, nil)
+	if err != nil {
+		return nil, SmartReadToolResult{}, fmt.Errorf("error reading container: %w", withDiagnostics(err))
+	}
+	result.RequestCharge += containerResponse.RequestCharge
+	paths := containerResponse.ContainerProperties.PartitionKeyDefinition.Paths
+
+	options := &azcosmos.QueryOptions{
+		QueryParameters: []azcosmos.QueryParameter{{Name: "@id", Value: input.ItemID}},
+	}
+
+	items := [][]byte{}
+	queryPager := containerClient.NewQueryItemsPager(smartReadQuery, azcosmos.PartitionKey{}, options)
+	for queryPager.More() {
+		queryResponse, err := nextPageWithRetry(ctx, queryPager)
+		if err != nil {
+			return nil, SmartReadToolResult{}, fmt.Errorf("query page error: %w", withDiagnostics(err))
+		}
+		result.RequestCharge += queryResponse.RequestCharge
+		items = append(items, queryResponse.Items...)
+	}
+
+	switch len(items) {
+	case 0:
+		return nil, SmartReadToolResult{}, fmt.Errorf("item '%s' not found in any partition of container '%s'", input.ItemID, input.Container)
+	case 1:
+	default:
+		values := make([]string, 0, len(items))
+		for _, item := range items {
+			value, _ := json.Marshal(itemPartitionKey(item, paths))
+			values = append(values, string(value))
+		}
+		return nil, SmartReadToolResult{}, fmt.Errorf("item id '%s' exists in %d partitions with partition key values %s, read it with read_item and one of them", input.ItemID, len(items), strings.Join(values, ", "))
+	}
+
+	result.Item = string(items[0])
+	result.Strategy = SmartReadStrategyQuery
+	result.PartitionKey = itemPartitionKey(items[0], paths)
+	result.Message = fmt.Sprintf("Item '%s' found with a cross-partition query", input.ItemID)
+	if input.PartitionKey != "" {
+		result.Message += fmt.Sprintf(", it is not in partition '%s'", input.PartitionKey)
+	}
+
+	return nil, result, nil
+}
+
+// itemPartitionKey returns the partition key value of an item: the value at the path, a list of values for hierarchical
+// partition keys, or nil if the item has no value for it
+func itemPartitionKey(item []byte, paths []string) any {
+	var document map[string]any
+	if err := json.Unmarshal(item, &document); err != nil {
+		return nil
+	}
+
+	values := make([]any, 0, len(paths))
+	for _, path := range paths {
+		value, _ := valueAtPath(document, path)
+		values = append(values, value)
+	}
+
+	if len(values) == 1 {
+		return values[0]
+	}
+	return values
+}
+
+// responseRequestCharge returns the request charge of a response, e.g. of a failed request
+func responseRequestCharge(response *http.Response) float32 {
+	if response == nil {
+		return 0
+	}
+	charge, _ := strconv.ParseFloat(response.Header.Get("x-ms-request-charge"), 32)
+	return float32(charge)
+}
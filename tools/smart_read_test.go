@@ -0,0 +1,38 @@
+package tools
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Unit tests for smart_read that do not need the emulator
+
+func TestItemPartitionKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     string
+		paths    []string
+		expected any
+	}{
+		{name: "string", item: `{"id": "1", "tenant": "contoso"}`, paths: []string{"/tenant"}, expected: "contoso"},
+		{name: "number", item: `{"id": "1", "year": 2024}`, paths: []string{"/year"}, expected: float64(2024)},
+		{name: "nested", item: `{"id": "1", "address": {"city": "Seattle"}}`, paths: []string{"/address/city"}, expected: "Seattle"},
+		{name: "missing", item: `{"id": "1"}`, paths: []string{"/tenant"}, expected: nil},
+		{name: "hierarchical", item: `{"id": "1", "tenant": "contoso", "user": "alice"}`, paths: []string{"/tenant", "/user"}, expected: []any{"contoso", "alice"}},
+		{name: "not JSON", item: `not json`, paths: []string{"/tenant"}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, itemPartitionKey([]byte(tt.item), tt.paths))
+		})
+	}
+}
+
+func TestResponseRequestCharge(t *testing.T) {
+	assert.Equal(t, float32(0), responseRequestCharge(nil))
+	assert.Equal(t, float32(0), responseRequestCharge(&http.Response{Header: http.Header{}}))
+	assert.Equal(t, float32(1.24), responseRequestCharge(&http.Response{Header: http.Header{"X-Ms-Request-Charge": []string{"1.24"}}}))
+}
@@ -5,10 +5,14 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/tracing"
 	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
@@ -21,29 +25,65 @@ const (
 	testOperationDBName        = "testDatabase"
 	testOperationContainerName = "testContainer"
 	testPartitionKey           = "/id"
-	emulatorImage              = "mcr.microsoft.com/cosmosdb/linux/azure-cosmos-emulator:vnext-preview"
-	//emulatorImage    = "mcr.microsoft.com/cosmosdb/linux/azure-cosmos-emulator:latest"
-	emulatorPort = "8081"
-	healthPort   = "8080"
+	defaultEmulatorImage       = "mcr.microsoft.com/cosmosdb/linux/azure-cosmos-emulator:vnext-preview"
+	emulatorPort               = "8081"
+	healthPort                 = "8080"
 
 	//emulatorEndpoint = "http://localhost:8081"
-	emulatorKey = "C2y6yDjf5/R+ob0N8A7Cgv30VRDJIWEHLM+4QDU5DE2nQ9nDuVTqobD4b8mGGyPMbIZnqyMsEcaGQy67XIw/Jw=="
 )
 
+// emulatorImageEnvVar selects the emulator image of the integration tests, e.g.
+// mcr.microsoft.com/cosmosdb/linux/azure-cosmos-emulator:latest for the classic emulator
+const emulatorImageEnvVar = "COSMOS_TEST_EMULATOR_IMAGE"
+
+// emulatorProtocolEnvVar sets the protocol (http or https) the vNext emulator listens on, the classic emulator only supports https
+const emulatorProtocolEnvVar = "COSMOS_TEST_EMULATOR_PROTOCOL"
+
 var emulatorEndpoint string
 
+// getEmulatorImage returns the emulator image configured for the tests, the vNext emulator by default
+func getEmulatorImage() string {
+	if image := strings.TrimSpace(os.Getenv(emulatorImageEnvVar)); image != "" {
+		return image
+	}
+	return defaultEmulatorImage
+}
+
+// isVNextEmulator reports whether the image is the vNext emulator, which has a health port and a configurable protocol.
+// The other images are the classic emulator.
+func isVNextEmulator(image string) bool {
+	return strings.Contains(image, "vnext")
+}
+
 func setupCosmosEmulator(ctx context.Context) (testcontainers.Container, error) {
 
+	image := getEmulatorImage()
+
 	req := testcontainers.ContainerRequest{
-		Image:        emulatorImage,
-		ExposedPorts: []string{emulatorPort, healthPort},
-		WaitingFor:   wait.ForListeningPort(healthPort),
+		Image:        image,
+		ExposedPorts: []string{emulatorPort},
+		// the classic emulator logs "Started" once all partitions are up, which takes a few minutes
+		WaitingFor: wait.ForLog("Started").WithStartupTimeout(10 * time.Minute),
 		Env: map[string]string{
-			"ENABLE_EXPLORER": "false",
-			"PROTOCOL":        "https",
+			"AZURE_COSMOS_EMULATOR_PARTITION_COUNT":     "3",
+			"AZURE_COSMOS_EMULATOR_IP_ADDRESS_OVERRIDE": "127.0.0.1",
 		},
 	}
 
+	if isVNextEmulator(image) {
+		protocol := strings.TrimSpace(os.Getenv(emulatorProtocolEnvVar))
+		if protocol == "" {
+			protocol = "https"
+		}
+
+		req.ExposedPorts = []string{emulatorPort, healthPort}
+		req.WaitingFor = wait.ForListeningPort(healthPort)
+		req.Env = map[string]string{
+			"ENABLE_EXPLORER": "false",
+			"PROTOCOL":        protocol,
+		}
+	}
+
 	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
 		ContainerRequest: req,
 		Started:          true,
@@ -118,6 +158,8 @@ func getEmulatorClient(emulator testcontainers.Container) (*azcosmos.Client, err
 		os.Exit(1)
 	}
 
+	scheme := detectEmulatorScheme(fmt.Sprintf("localhost:%s", mappedPort.Port()))
+
 	baseTransport := &http.Transport{
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 	}
@@ -129,10 +171,12 @@ func getEmulatorClient(emulator testcontainers.Container) (*azcosmos.Client, err
 	}
 
 	options := &azcosmos.ClientOptions{ClientOptions: azcore.ClientOptions{
-		Transport: &http.Client{Transport: rewritingTransport},
+		Transport:        &http.Client{Transport: rewritingTransport},
+		PerCallPolicies:  []policy.Policy{diagnosticsCallPolicy{}},
+		PerRetryPolicies: []policy.Policy{diagnosticsPolicy{}},
 	}}
 
-	emulatorEndpoint = fmt.Sprintf("https://localhost:%s", mappedPort.Port())
+	emulatorEndpoint = fmt.Sprintf("%s://localhost:%s", scheme, mappedPort.Port())
 	fmt.Printf("Emulator endpoint: %s\n", emulatorEndpoint)
 
 	// Set up the CosmosDB client
@@ -146,6 +190,18 @@ func getEmulatorClient(emulator testcontainers.Container) (*azcosmos.Client, err
 	return client, nil
 }
 
+// detectEmulatorScheme returns https if the emulator at address accepts a TLS handshake and http otherwise, so that the
+// tests work whatever protocol the emulator was started with
+func detectEmulatorScheme(address string) string {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		return "http"
+	}
+	conn.Close()
+	return "https"
+}
+
 // deprecated
 func _getEmulatorClient() (*azcosmos.Client, error) {
 
@@ -159,7 +215,7 @@ func _getEmulatorClient() (*azcosmos.Client, error) {
 	}}
 
 	// Create credential with the emulator key
-	cred, err := azcosmos.NewKeyCredential(emulatorKey)
+	cred, err := azcosmos.NewKeyCredential(getEmulatorKey())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create key credential: %w", err)
 	}
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for the emulator setup of the integration tests that do not need the emulator
+
+func TestGetEmulatorImage(t *testing.T) {
+	t.Setenv(emulatorImageEnvVar, "")
+	assert.Equal(t, defaultEmulatorImage, getEmulatorImage())
+	assert.True(t, isVNextEmulator(getEmulatorImage()))
+
+	t.Setenv(emulatorImageEnvVar, "mcr.microsoft.com/cosmosdb/linux/azure-cosmos-emulator:latest")
+	assert.Equal(t, "mcr.microsoft.com/cosmosdb/linux/azure-cosmos-emulator:latest", getEmulatorImage())
+	assert.False(t, isVNextEmulator(getEmulatorImage()))
+}
+
+func TestDetectEmulatorScheme(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+	assert.Equal(t, "http", detectEmulatorScheme(strings.TrimPrefix(httpServer.URL, "http://")))
+
+	httpsServer := httptest.NewTLSServer(handler)
+	defer httpsServer.Close()
+	assert.Equal(t, "https", detectEmulatorScheme(strings.TrimPrefix(httpsServer.URL, "https://")))
+}
+
+// urlRecorder records the URL of the requests it receives
+type urlRecorder struct {
+	urls []string
+}
+
+func (r *urlRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.urls = append(r.urls, req.URL.String())
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestEmulatorTransport(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		// the vNext emulator advertises localhost, the classic emulator the address in AZURE_COSMOS_EMULATOR_IP_ADDRESS_OVERRIDE
+		{name: "vNext emulator", url: "http://localhost:8081/dbs", expected: "http://localhost:55123/dbs"},
+		{name: "classic emulator", url: "https://127.0.0.1:8081/dbs/testDatabase", expected: "https://localhost:55123/dbs/testDatabase"},
+		{name: "mapped port", url: "https://localhost:55123/dbs", expected: "https://localhost:55123/dbs"},
+		{name: "other port", url: "http://localhost:8080/ready", expected: "http://localhost:8080/ready"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &urlRecorder{}
+			transport := &emulatorTransport{transport: recorder, mappedPort: "55123"}
+
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			require.NoError(t, err)
+			_, err = transport.RoundTrip(req)
+			require.NoError(t, err)
+			assert.Equal(t, []string{tt.expected}, recorder.urls)
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultListThroughputConcurrency is used when the concurrency is not specified
+const defaultListThroughputConcurrency = 4
+
+// maxListThroughputConcurrency is the upper bound for the maxConcurrency argument of list_throughput
+const maxListThroughputConcurrency = 10
+
+// Throughput types reported by list_throughput
+const (
+	ThroughputTypeManual    = "manual"
+	ThroughputTypeAutoscale = "autoscale"
+	// the container uses the throughput of its database
+	ThroughputTypeShared = "shared"
+	// the database or container has no throughput of its own to share or use, e.g. in a serverless account
+	ThroughputTypeNone = "none"
+	// the throughput could not be read, e.g. the emulator does not implement offers
+	ThroughputTypeUnknown = "unknown"
+)
+
+func ListThroughput() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "list_throughput",
+		Description: "List the provisioned throughput of every database and container in the specified Azure Cosmos DB account or local emulator as a flat list, for cost audits. Set useEmulator to true to connect to the local Cosmos DB emulator instead of Azure service. Each entry has a type: manual (ru_per_second), autoscale (max_ru_per_second), shared (a container using the throughput of its database) or none (a database without shared throughput, or any entry of a serverless account). total_ru_per_second sums the manual throughput and the autoscale maximums, which is what the account is billed for at most. Throughput is read concurrently (maxConcurrency, default 4) and errors are reported per entry. Limit the audit to one database with database.",
+	}
+}
+
+type ListThroughputToolInput struct {
+	ConnectionConfig
+	Database       string `json:"database,omitempty" jsonschema:"Only list the throughput of this database and its containers (optional, default all databases)"`
+	MaxConcurrency int    `json:"maxConcurrency,omitempty" jsonschema:"Maximum number of throughput reads to run concurrently (default 4, maximum 10)"`
+}
+
+type ThroughputEntry struct {
+	Database       string `json:"database"`
+	Container      string `json:"container,omitempty" jsonschema:"Name of the container, empty for the throughput of the database"`
+	Type           string `json:"type" jsonschema:"manual, autoscale, shared, none or unknown"`
+	RUPerSecond    int32  `json:"ru_per_second,omitempty" jsonschema:"Provisioned throughput, set for manual throughput"`
+	MaxRUPerSecond int32  `json:"max_ru_per_second,omitempty" jsonschema:"Maximum throughput, set for autoscale throughput"`
+	Error          string `json:"error,omitempty" jsonschema:"Set if the throughput could not be read"`
+}
+
+type ListThroughputToolResult struct {
+	Account          string            `json:"account"`
+	Throughput       []ThroughputEntry `json:"throughput" jsonschema:"One entry per database followed by one entry per container of the database"`
+	Databases        int               `json:"databases"`
+	Containers       int               `json:"containers"`
+	TotalRUPerSecond int64             `json:"total_ru_per_second" jsonschema:"Sum of the manual throughput and of the autoscale maximum throughput of all entries"`
+}
+
+func ListThroughputToolHandler(ctx context.Context, _ *mcp.CallToolRequest, input ListThroughputToolInput) (*mcp.CallToolResult, ListThroughputToolResult, error) {
+
+	if err := input.Validate(); err != nil {
+		return nil, ListThroughputToolResult{}, err
+	}
+
+	concurrency := input.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultListThroughputConcurrency
+	}
+	if concurrency > maxListThroughputConcurrency {
+		concurrency = maxListThroughputConcurrency
+	}
+
+	client, err := input.GetClient()
+	if err != nil {
+		return nil, ListThroughputToolResult{}, err
+	}
+
+	databases := []string{input.Database}
+	if input.Database == "" {
+		databases = []string{}
+		queryPager := client.NewQueryDatabasesPager("select * from dbs d", nil)
+		for queryPager.More() {
+			queryResponse, err := queryPager.NextPage(ctx)
+			if err != nil {
+				return nil, ListThroughputToolResult{}, withDiagnostics(err)
+			}
+			for _, db := range queryResponse.Databases {
+				databases = append(databases, db.ID)
+			}
+		}
+	}
+
+	// entries are stored by index so the output follows the order of the databases and containers
+	entries := make([]ThroughputEntry, 0, len(databases))
+	for _, database := range databases {
+		containers, err := listContainerNames(ctx, client, database)
+		if err != nil {
+			if input.Database != "" {
+				return nil, ListThroughputToolResult{}, err
+			}
+			entries = append(entries, ThroughputEntry{Database: database, Type: ThroughputTypeUnknown, Error: err.Error()})
+			continue
+		}
+
+		entries = append(entries, ThroughputEntry{Database: database})
+		for _, container := range containers {
+			entries = append(entries, ThroughputEntry{Database: database, Container: container})
+		}
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				readThroughputEntry(ctx, client, &entries[i])
+			}
+		}()
+	}
+
+	for i := range entries {
+		// entries whose containers could not be listed already have their error
+		if entries[i].Type == "" {
+			jobs <- i
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	resolveSharedThroughput(entries)
+
+	result := ListThroughputToolResult{Account: input.Account, Throughput: entries, Databases: len(databases)}
+	for _, entry := range entries {
+		if entry.Container != "" {
+			result.Containers++
+		}
+		result.TotalRUPerSecond += int64(entry.RUPerSecond) + int64(entry.MaxRUPerSecond)
+	}
+
+	return nil, result, nil
+}
+
+// listContainerNames returns the names of the containers in the database
+func listContainerNames(ctx context.Context, client *azcosmos.Client, database string) ([]string, error) {
+	databaseClient, err := client.NewDatabase(database)
+	if err != nil {
+		return nil, fmt.Errorf("error creating database client: %v", err)
+	}
+
+	containers := []string{}
+
+	containerPager := databaseClient.NewQueryContainersPager("select * from c", nil)
+	for containerPager.More() {
+		containerResponse, err := containerPager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing containers of database '%s': %w", database, withDiagnostics(err))
+		}
+		for _, container := range containerResponse.Containers {
+			containers = append(containers, container.ID)
+		}
+	}
+
+	return containers, nil
+}
+
+// readThroughputEntry reads the throughput of the database or container of the entry and sets its type
+func readThroughputEntry(ctx context.Context, client *azcosmos.Client, entry *ThroughputEntry) {
+	databaseClient, err := client.NewDatabase(entry.Database)
+	if err != nil {
+		entry.Type, entry.Error = ThroughputTypeUnknown, fmt.Sprintf("error creating database client: %v", err)
+		return
+	}
+
+	var response azcosmos.ThroughputResponse
+	if entry.Container == "" {
+		response, err = databaseClient.ReadThroughput(ctx, nil)
+	} else {
+		var containerClient *azcosmos.ContainerClient
+		containerClient, err = databaseClient.NewContainer(entry.Container)
+		if err != nil {
+			entry.Type, entry.Error = ThroughputTypeUnknown, fmt.Sprintf("error creating container client: %v", err)
+			return
+		}
+		response, err = containerClient.ReadThroughput(ctx, nil)
+	}
+
+	if err != nil {
+		setThroughputError(entry, err)
+		return
+	}
+
+	setThroughputType(entry, response.ThroughputProperties)
+}
+
+// resolveSharedThroughput changes the containers without dedicated throughput to none if their database has no shared
+// throughput either, which is the case in serverless accounts
+func resolveSharedThroughput(entries []ThroughputEntry) {
+	databaseType := map[string]string{}
+	for _, entry := range entries {
+		if entry.Container == "" {
+			databaseType[entry.Database] = entry.Type
+		}
+	}
+
+	for i, entry := range entries {
+		if entry.Type != ThroughputTypeShared {
+			continue
+		}
+		if typ := databaseType[entry.Database]; typ != ThroughputTypeManual && typ != ThroughputTypeAutoscale {
+			entries[i].Type = ThroughputTypeNone
+		}
+	}
+}
+
+// setThroughputType sets the type and RU/s of the entry from its throughput properties
+func setThroughputType(entry *ThroughputEntry, properties *azcosmos.ThroughputProperties) {
+	if properties == nil {
+		entry.Type = ThroughputTypeUnknown
+		return
+	}
+
+	if manual, ok := properties.ManualThroughput(); ok {
+		entry.Type, entry.RUPerSecond = ThroughputTypeManual, manual
+		return
+	}
+
+	if maxRU, ok := properties.AutoscaleMaxThroughput(); ok {
+		entry.Type, entry.MaxRUPerSecond = ThroughputTypeAutoscale, maxRU
+		return
+	}
+
+	entry.Type = ThroughputTypeUnknown
+}
+
+// setThroughputError sets the type of an entry whose throughput could not be read. Like read_container_metadata,
+// a 404 means there is no dedicated throughput and a 400 usually means the emulator does not implement offers.
+func setThroughputError(entry *ThroughputEntry, err error) {
+	var responseErr *azcore.ResponseError
+	if errors.As(err, &responseErr) {
+		switch responseErr.StatusCode {
+		case http.StatusNotFound:
+			entry.Type = ThroughputTypeNone
+			if entry.Container != "" {
+				entry.Type = ThroughputTypeShared
+			}
+			return
+		case http.StatusBadRequest:
+			entry.Type, entry.Error = ThroughputTypeUnknown, "unable to read throughput (emulator limitation or unsupported operation)"
+			return
+		}
+	}
+
+	entry.Type, entry.Error = ThroughputTypeUnknown, fmt.Sprintf("failed to read throughput: %v", withDiagnostics(err))
+}
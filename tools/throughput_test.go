@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/stretchr/testify/assert"
+)
+
+// Unit tests for list_throughput that do not need the emulator
+
+func TestSetThroughputType(t *testing.T) {
+	manual := azcosmos.NewManualThroughputProperties(400)
+	entry := ThroughputEntry{Database: "db1"}
+	setThroughputType(&entry, &manual)
+	assert.Equal(t, ThroughputEntry{Database: "db1", Type: ThroughputTypeManual, RUPerSecond: 400}, entry)
+
+	autoscale := azcosmos.NewAutoscaleThroughputProperties(4000)
+	entry = ThroughputEntry{Database: "db1", Container: "c1"}
+	setThroughputType(&entry, &autoscale)
+	assert.Equal(t, ThroughputEntry{Database: "db1", Container: "c1", Type: ThroughputTypeAutoscale, MaxRUPerSecond: 4000}, entry)
+
+	entry = ThroughputEntry{Database: "db1"}
+	setThroughputType(&entry, nil)
+	assert.Equal(t, ThroughputTypeUnknown, entry.Type)
+}
+
+func TestSetThroughputError(t *testing.T) {
+	responseError := func(statusCode int) error {
+		return &azcore.ResponseError{StatusCode: statusCode, RawResponse: &http.Response{StatusCode: statusCode, Header: http.Header{}}}
+	}
+
+	tests := []struct {
+		name          string
+		entry         ThroughputEntry
+		err           error
+		expectedType  string
+		expectedError string
+	}{
+		{name: "database without throughput", entry: ThroughputEntry{Database: "db1"}, err: responseError(http.StatusNotFound), expectedType: ThroughputTypeNone},
+		{name: "container without throughput", entry: ThroughputEntry{Database: "db1", Container: "c1"}, err: responseError(http.StatusNotFound), expectedType: ThroughputTypeShared},
+		{name: "emulator", entry: ThroughputEntry{Database: "db1"}, err: responseError(http.StatusBadRequest), expectedType: ThroughputTypeUnknown, expectedError: "emulator limitation"},
+		{name: "forbidden", entry: ThroughputEntry{Database: "db1"}, err: responseError(http.StatusForbidden), expectedType: ThroughputTypeUnknown, expectedError: "status code: 403"},
+		{name: "not a response error", entry: ThroughputEntry{Database: "db1"}, err: errors.New("connection refused"), expectedType: ThroughputTypeUnknown, expectedError: "connection refused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := tt.entry
+			setThroughputError(&entry, tt.err)
+			assert.Equal(t, tt.expectedType, entry.Type)
+			if tt.expectedError == "" {
+				assert.Empty(t, entry.Error)
+				return
+			}
+			assert.Contains(t, entry.Error, tt.expectedError)
+		})
+	}
+}
+
+func TestResolveSharedThroughput(t *testing.T) {
+	entries := []ThroughputEntry{
+		{Database: "shared", Type: ThroughputTypeAutoscale, MaxRUPerSecond: 1000},
+		{Database: "shared", Container: "c1", Type: ThroughputTypeShared},
+		{Database: "dedicated", Type: ThroughputTypeNone},
+		{Database: "dedicated", Container: "c1", Type: ThroughputTypeManual, RUPerSecond: 400},
+		{Database: "serverless", Type: ThroughputTypeNone},
+		{Database: "serverless", Container: "c1", Type: ThroughputTypeShared},
+	}
+
+	resolveSharedThroughput(entries)
+
+	assert.Equal(t, ThroughputTypeShared, entries[1].Type)
+	assert.Equal(t, ThroughputTypeManual, entries[3].Type)
+	assert.Equal(t, ThroughputTypeNone, entries[5].Type)
+}
@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
@@ -128,6 +132,15 @@ func TestCreateDatabase(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "valid arguments with wait until ready",
+			input: CreateDatabaseToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         "newTestDatabase2",
+				WaitUntilReady:   true,
+			},
+			expectError: false,
+		},
 		{
 			name: "empty account name",
 			input: CreateDatabaseToolInput{
@@ -163,6 +176,9 @@ func TestCreateDatabase(t *testing.T) {
 			assert.Equal(t, "dummy_account_does_not_matter", response.Account)
 			assert.Equal(t, test.input.Database, response.Database)
 			assert.Contains(t, response.Message, "created successfully")
+			if test.input.WaitUntilReady {
+				assert.Contains(t, response.Message, "and is ready")
+			}
 		})
 	}
 }
@@ -221,8 +237,8 @@ func TestListContainers(t *testing.T) {
 			require.NoError(t, err)
 			assert.Equal(t, "dummy_account_does_not_matter", response.Account)
 			assert.Equal(t, testOperationDBName, response.Database)
-			assert.Equal(t, 1, len(response.Containers))
-			assert.Equal(t, test.expectedResult, response.Containers[0])
+			// other tests add containers to the test database
+			assert.Contains(t, response.Containers, test.expectedResult)
 		})
 	}
 }
@@ -304,6 +320,9 @@ func TestReadContainerMetadata(t *testing.T) {
 			assert.Contains(t, metadata, "partition_key_definition")
 			assert.Contains(t, metadata, "conflict_resolution_policy")
 			assert.Contains(t, metadata, "unique_key_policy")
+			assert.Contains(t, metadata, "analytical_store_ttl")
+			assert.Contains(t, metadata, "geospatial_config")
+			assert.Contains(t, metadata, "computed_properties")
 			assert.Contains(t, metadata, "throughput")
 
 			// Verify throughput structure
@@ -427,6 +446,42 @@ func TestCreateContainer(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "valid arguments with throughput and wait until ready",
+			input: CreateContainerToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        "testContainer_new_wait",
+				PartitionKeyPath: "/id",
+				Throughput:       func() *int32 { v := int32(400); return &v }(),
+				WaitUntilReady:   true,
+			},
+			expectError: false,
+		},
+		{
+			name: "valid arguments with conflict resolution policy",
+			input: CreateContainerToolInput{
+				ConnectionConfig:       ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:               testOperationDBName,
+				Container:              "testContainer_new_3",
+				PartitionKeyPath:       "/id",
+				ConflictResolutionMode: "lastWriterWins",
+				ConflictResolutionPath: "/version",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid conflict resolution mode",
+			input: CreateContainerToolInput{
+				ConnectionConfig:       ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:               testOperationDBName,
+				Container:              "testContainer",
+				PartitionKeyPath:       "/id",
+				ConflictResolutionMode: "firstWriterWins",
+			},
+			expectError:    true,
+			expectedErrMsg: "invalid conflict resolution mode",
+		},
 		{
 			name: "empty account name",
 			input: CreateContainerToolInput{
@@ -471,6 +526,30 @@ func TestCreateContainer(t *testing.T) {
 			expectError:    true,
 			expectedErrMsg: "partition key path missing",
 		},
+		{
+			name: "invalid analytical store ttl",
+			input: CreateContainerToolInput{
+				ConnectionConfig:   ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:           testOperationDBName,
+				Container:          "testContainer",
+				PartitionKeyPath:   "/id",
+				AnalyticalStoreTTL: func() *int32 { v := int32(0); return &v }(),
+			},
+			expectError:    true,
+			expectedErrMsg: "invalid analyticalStoreTTL 0",
+		},
+		{
+			name: "analytical store on emulator",
+			input: CreateContainerToolInput{
+				ConnectionConfig:   ConnectionConfig{UseEmulator: true},
+				Database:           testOperationDBName,
+				Container:          "testContainer",
+				PartitionKeyPath:   "/id",
+				AnalyticalStoreTTL: func() *int32 { v := int32(-1); return &v }(),
+			},
+			expectError:    true,
+			expectedErrMsg: "analytical store is not supported by the emulator",
+		},
 	}
 
 	for _, test := range tests {
@@ -489,6 +568,9 @@ func TestCreateContainer(t *testing.T) {
 			assert.Equal(t, testOperationDBName, response.Database)
 			assert.Equal(t, test.input.Container, response.Container)
 			assert.Contains(t, response.Message, "created successfully")
+			if test.input.WaitUntilReady {
+				assert.Contains(t, response.Message, "and is ready")
+			}
 		})
 	}
 }
@@ -596,6 +678,31 @@ func TestAddItemToContainer(t *testing.T) {
 			expectError:    true,
 			expectedErrMsg: "item JSON missing",
 		},
+		{
+			name: "item matching schema",
+			input: AddItemToContainerToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKey:     "user_add_item_schema",
+				Item:             `{"id": "user_add_item_schema", "email": "jane@foo.com"}`,
+				Schema:           `{"type": "object", "required": ["id", "email"], "properties": {"email": {"type": "string"}}}`,
+			},
+			expectError: false,
+		},
+		{
+			name: "item not matching schema",
+			input: AddItemToContainerToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKey:     "user_add_item_schema_invalid",
+				Item:             `{"id": "user_add_item_schema_invalid", "email": 42}`,
+				Schema:           `{"type": "object", "required": ["id", "email"], "properties": {"email": {"type": "string"}}}`,
+			},
+			expectError:    true,
+			expectedErrMsg: "item does not match the schema: validating root: validating /properties/email",
+		},
 	}
 
 	for _, test := range tests {
@@ -651,6 +758,18 @@ func TestReadItem(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "pretty-printed",
+			input: ReadItemToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				ItemID:           id,
+				PartitionKey:     partitionKeyValue,
+				Pretty:           to.Ptr(true),
+			},
+			expectError: false,
+		},
 		{
 			name: "empty account name",
 			input: ReadItemToolInput{
@@ -733,6 +852,9 @@ func TestReadItem(t *testing.T) {
 			require.NoError(t, err)
 			assert.Equal(t, id, item["id"].(string))
 			assert.Equal(t, "user2@foo.com", item["value"].(string))
+			if test.input.Pretty != nil {
+				assert.Contains(t, response.Item, "\n  \"id\": \"user2\"")
+			}
 		})
 	}
 }
@@ -770,14 +892,26 @@ func TestExecuteQuery(t *testing.T) {
 			expectError: false,
 		},
 		{
-			name: "valid arguments - no partition key",
+			name: "valid arguments - cross-partition query",
+			input: ExecuteQueryToolInput{
+				ConnectionConfig:    ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:            testOperationDBName,
+				Container:           testOperationContainerName,
+				Query:               "SELECT * FROM c",
+				AllowCrossPartition: true,
+			},
+			expectError: false,
+		},
+		{
+			name: "no partition key without allowCrossPartition",
 			input: ExecuteQueryToolInput{
 				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
 				Database:         testOperationDBName,
 				Container:        testOperationContainerName,
 				Query:            "SELECT * FROM c",
 			},
-			expectError: false,
+			expectError:    true,
+			expectedErrMsg: "set allowCrossPartition to true",
 		},
 		{
 			name: "empty account name",
@@ -838,6 +972,8 @@ func TestExecuteQuery(t *testing.T) {
 
 			require.NoError(t, err)
 			assert.NotEmpty(t, response.QueryResults)
+			assert.Equal(t, len(response.QueryResults), response.Count)
+			assert.False(t, response.HasMore)
 			// assert.NotEmpty(t, response.QueryMetrics)
 		})
 	}
@@ -1058,3 +1194,3205 @@ func generateItemsArrayWithPrefix(count int, partitionKey string) []string {
 	}
 	return items
 }
+
+func TestEstimateQueryCost(t *testing.T) {
+
+	partitionKeyValue := "user_estimate"
+
+	_, _, err := AddItemToContainerToolHandler(context.Background(), nil, AddItemToContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     partitionKeyValue,
+		Item:             `{"id": "user_estimate", "value": "user_estimate@foo.com"}`,
+	})
+
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		input          EstimateQueryCostToolInput
+		expectError    bool
+		expectedErrMsg string
+	}{
+		{
+			name: "valid arguments with partition key",
+			input: EstimateQueryCostToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				Query:            "SELECT * FROM c",
+				PartitionKey:     partitionKeyValue,
+			},
+			expectError: false,
+		},
+		{
+			name: "valid arguments - no partition key",
+			input: EstimateQueryCostToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				Query:            "SELECT * FROM c",
+			},
+			expectError: false,
+		},
+		{
+			name: "empty database name",
+			input: EstimateQueryCostToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Container:        testOperationContainerName,
+				Query:            "SELECT * FROM c",
+			},
+			expectError:    true,
+			expectedErrMsg: "database name missing",
+		},
+		{
+			name: "empty query string",
+			input: EstimateQueryCostToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+			},
+			expectError:    true,
+			expectedErrMsg: "query string missing",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, response, err := EstimateQueryCostToolHandler(context.Background(), nil, test.input)
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, response.IsEstimate)
+			assert.LessOrEqual(t, response.FirstPageItemCount, 1)
+			assert.Contains(t, response.Message, "Estimated cost")
+		})
+	}
+}
+
+func TestQueryAcrossContainers(t *testing.T) {
+
+	partitionKeyValue := "user_fanout"
+
+	_, _, err := AddItemToContainerToolHandler(context.Background(), nil, AddItemToContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     partitionKeyValue,
+		Item:             `{"id": "user_fanout", "value": "user_fanout@foo.com"}`,
+	})
+
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		input           QueryAcrossContainersToolInput
+		expectError     bool
+		expectedErrMsg  string
+		expectedErrored int
+	}{
+		{
+			name: "valid arguments",
+			input: QueryAcrossContainersToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Containers:       []string{testOperationContainerName},
+				Query:            "SELECT * FROM c",
+				PartitionKey:     partitionKeyValue,
+			},
+			expectError: false,
+		},
+		{
+			name: "one container does not exist",
+			input: QueryAcrossContainersToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Containers:       []string{testOperationContainerName, "container_does_not_exist"},
+				Query:            "SELECT * FROM c",
+				PartitionKey:     partitionKeyValue,
+			},
+			expectError:     false,
+			expectedErrored: 1,
+		},
+		{
+			name: "empty containers",
+			input: QueryAcrossContainersToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Query:            "SELECT * FROM c",
+			},
+			expectError:    true,
+			expectedErrMsg: "container names missing",
+		},
+		{
+			name: "empty query string",
+			input: QueryAcrossContainersToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Containers:       []string{testOperationContainerName},
+			},
+			expectError:    true,
+			expectedErrMsg: "query string missing",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, response, err := QueryAcrossContainersToolHandler(context.Background(), nil, test.input)
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotEmpty(t, response.QueryResults)
+			assert.Len(t, response.Errors, test.expectedErrored)
+
+			var item map[string]any
+			require.NoError(t, json.Unmarshal([]byte(response.QueryResults[0]), &item))
+			assert.Equal(t, testOperationContainerName, item["_sourceContainer"])
+		})
+	}
+}
+
+func TestExportContainer(t *testing.T) {
+
+	_, _, err := BatchCreateItemsToolHandler(context.Background(), nil, BatchCreateItemsToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     "export_item",
+		Items:            []string{`{"id": "export_item", "value": "export"}`},
+	})
+	require.NoError(t, err)
+
+	t.Run("inline content", func(t *testing.T) {
+		_, response, err := ExportContainerToolHandler(context.Background(), nil, ExportContainerToolInput{
+			ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+			Database:         testOperationDBName,
+			Container:        testOperationContainerName,
+		})
+
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, response.ItemsExported, 1)
+		assert.Contains(t, response.Content, `"export_item"`)
+		assert.Empty(t, response.OutputPath)
+	})
+
+	t.Run("output file", func(t *testing.T) {
+		exportDir := t.TempDir()
+		t.Setenv(ExportDirEnvVar, exportDir)
+
+		input := ExportContainerToolInput{
+			ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+			Database:         testOperationDBName,
+			Container:        testOperationContainerName,
+			OutputPath:       "export.ndjson",
+		}
+
+		_, response, err := ExportContainerToolHandler(context.Background(), nil, input)
+
+		require.NoError(t, err)
+		assert.Empty(t, response.Content)
+		assert.Equal(t, filepath.Join(exportDir, "export.ndjson"), response.OutputPath)
+
+		data, err := os.ReadFile(response.OutputPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"export_item"`)
+
+		// existing files are not overwritten
+		_, _, err = ExportContainerToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already exists")
+	})
+
+	t.Run("empty container name", func(t *testing.T) {
+		_, _, err := ExportContainerToolHandler(context.Background(), nil, ExportContainerToolInput{
+			ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+			Database:         testOperationDBName,
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "container name missing")
+	})
+}
+
+func TestImportContainer(t *testing.T) {
+
+	tests := []struct {
+		name             string
+		input            ImportContainerToolInput
+		expectError      bool
+		expectedErrMsg   string
+		expectedImported int
+		expectedFailed   int
+	}{
+		{
+			name: "valid content",
+			input: ImportContainerToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKeyPath: testPartitionKey,
+				Content:          "{\"id\": \"import_1\"}\n{\"id\": \"import_2\"}\n",
+			},
+			expectedImported: 2,
+		},
+		{
+			name: "invalid line is reported",
+			input: ImportContainerToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKeyPath: testPartitionKey,
+				Content:          "{\"id\": \"import_3\"}\nnot json",
+			},
+			expectedImported: 1,
+			expectedFailed:   1,
+		},
+		{
+			name: "empty partition key path",
+			input: ImportContainerToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				Content:          "{\"id\": \"import_4\"}",
+			},
+			expectError:    true,
+			expectedErrMsg: "partition key path missing",
+		},
+		{
+			name: "empty content",
+			input: ImportContainerToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKeyPath: testPartitionKey,
+			},
+			expectError:    true,
+			expectedErrMsg: "NDJSON content missing",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, response, err := ImportContainerToolHandler(context.Background(), nil, test.input)
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedImported, response.ItemsImported)
+			assert.Equal(t, test.expectedFailed, response.ItemsFailed)
+		})
+	}
+}
+
+func TestExplainIndexingPolicy(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		input          ExplainIndexingPolicyToolInput
+		expectError    bool
+		expectedErrMsg string
+	}{
+		{
+			name: "valid arguments",
+			input: ExplainIndexingPolicyToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+			},
+			expectError: false,
+		},
+		{
+			name: "empty container name",
+			input: ExplainIndexingPolicyToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+			},
+			expectError:    true,
+			expectedErrMsg: "container name missing",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, response, err := ExplainIndexingPolicyToolHandler(context.Background(), nil, test.input)
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testOperationContainerName, response.Container)
+			assert.NotEmpty(t, response.Explanation)
+		})
+	}
+}
+
+func TestCountDistinct(t *testing.T) {
+
+	partitionKeyValue := "user_distinct"
+
+	_, _, err := AddItemToContainerToolHandler(context.Background(), nil, AddItemToContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     partitionKeyValue,
+		Item:             `{"id": "user_distinct", "category": "books"}`,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		input          CountDistinctToolInput
+		expectError    bool
+		expectedErrMsg string
+	}{
+		{
+			name: "valid arguments",
+			input: CountDistinctToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKey:     partitionKeyValue,
+				Field:            "category",
+			},
+			expectError: false,
+		},
+		{
+			name: "missing partition key",
+			input: CountDistinctToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				Field:            "category",
+			},
+			expectError:    true,
+			expectedErrMsg: "partition key missing",
+		},
+		{
+			name: "invalid field",
+			input: CountDistinctToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKey:     partitionKeyValue,
+				Field:            "category FROM c",
+			},
+			expectError:    true,
+			expectedErrMsg: "invalid field name",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, response, err := CountDistinctToolHandler(context.Background(), nil, test.input)
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, 1, response.DistinctCount)
+			assert.Equal(t, []any{"books"}, response.DistinctValues)
+		})
+	}
+}
+
+func TestGroupByAggregate(t *testing.T) {
+
+	partitionKeyValue := "user_groupby"
+
+	_, _, err := AddItemToContainerToolHandler(context.Background(), nil, AddItemToContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     partitionKeyValue,
+		Item:             `{"id": "user_groupby", "category": "books", "price": 10}`,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		input          GroupByAggregateToolInput
+		expectError    bool
+		expectedErrMsg string
+	}{
+		{
+			name: "count",
+			input: GroupByAggregateToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKey:     partitionKeyValue,
+				GroupByField:     "category",
+				Aggregate:        "count",
+			},
+			expectError: false,
+		},
+		{
+			name: "sum",
+			input: GroupByAggregateToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKey:     partitionKeyValue,
+				GroupByField:     "category",
+				Aggregate:        "sum",
+				AggregateField:   "price",
+			},
+			expectError: false,
+		},
+		{
+			name: "missing partition key",
+			input: GroupByAggregateToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				GroupByField:     "category",
+				Aggregate:        "count",
+			},
+			expectError:    true,
+			expectedErrMsg: "partition key missing",
+		},
+		{
+			name: "invalid aggregate",
+			input: GroupByAggregateToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKey:     partitionKeyValue,
+				GroupByField:     "category",
+				Aggregate:        "median",
+			},
+			expectError:    true,
+			expectedErrMsg: "invalid aggregate",
+		},
+		{
+			name: "sum without aggregate field",
+			input: GroupByAggregateToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKey:     partitionKeyValue,
+				GroupByField:     "category",
+				Aggregate:        "sum",
+			},
+			expectError:    true,
+			expectedErrMsg: "aggregate field",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, response, err := GroupByAggregateToolHandler(context.Background(), nil, test.input)
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, response.Groups, 1)
+			assert.Equal(t, "books", response.Groups[0].Key)
+		})
+	}
+}
+
+func TestHistogramByField(t *testing.T) {
+	const container = "test_histogram_by_field"
+
+	_, _, err := CreateContainerToolHandler(context.Background(), nil, CreateContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		PartitionKeyPath: "/customerId",
+	})
+	require.NoError(t, err)
+
+	for i, status := range []string{"shipped", "pending", "shipped", "shipped", ""} {
+		item := fmt.Sprintf(`{"id": "order_%d", "customerId": "customer_1", "status": "%s"}`, i, status)
+		if status == "" {
+			item = fmt.Sprintf(`{"id": "order_%d", "customerId": "customer_1"}`, i)
+		}
+		_, _, err := AddItemToContainerToolHandler(context.Background(), nil, AddItemToContainerToolInput{
+			ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+			Database:         testOperationDBName,
+			Container:        container,
+			PartitionKey:     "customer_1",
+			Item:             item,
+		})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name           string
+		input          HistogramByFieldToolInput
+		expectError    bool
+		expectedErrMsg string
+	}{
+		{
+			name: "valid arguments",
+			input: HistogramByFieldToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        container,
+				PartitionKey:     "customer_1",
+				Field:            "status",
+			},
+			expectError: false,
+		},
+		{
+			name: "empty partition key",
+			input: HistogramByFieldToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        container,
+				Field:            "status",
+			},
+			expectError:    true,
+			expectedErrMsg: "partition key missing",
+		},
+		{
+			name: "invalid top",
+			input: HistogramByFieldToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        container,
+				PartitionKey:     "customer_1",
+				Field:            "status",
+				Top:              -1,
+			},
+			expectError:    true,
+			expectedErrMsg: "top must be between 1 and 1000",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, response, err := HistogramByFieldToolHandler(context.Background(), nil, test.input)
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, []HistogramBucket{{Value: "shipped", Count: 3}, {Value: "pending", Count: 1}}, response.Buckets)
+			assert.Equal(t, 1, response.MissingCount)
+			assert.Equal(t, 5, response.TotalCount)
+		})
+	}
+}
+
+func TestClientSideAggregate(t *testing.T) {
+
+	_, _, err := BatchCreateItemsToolHandler(context.Background(), nil, BatchCreateItemsToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     "user_client_side_aggregate",
+		Items:            []string{`{"id": "user_client_side_aggregate", "kind": "client_side_aggregate", "price": 15}`},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		input          ClientSideAggregateToolInput
+		expectError    bool
+		expectedErrMsg string
+		expectedValue  float64
+	}{
+		{
+			name: "count across partitions",
+			input: ClientSideAggregateToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				Aggregate:        "count",
+				Filter:           "c.kind = 'client_side_aggregate'",
+			},
+			expectedValue: 1,
+		},
+		{
+			name: "sum across partitions",
+			input: ClientSideAggregateToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				Aggregate:        "sum",
+				Field:            "price",
+				Filter:           "c.kind = 'client_side_aggregate'",
+			},
+			expectedValue: 15,
+		},
+		{
+			name: "sum without field",
+			input: ClientSideAggregateToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				Aggregate:        "sum",
+			},
+			expectError:    true,
+			expectedErrMsg: "field name missing",
+		},
+		{
+			name: "invalid aggregate",
+			input: ClientSideAggregateToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				Aggregate:        "median",
+			},
+			expectError:    true,
+			expectedErrMsg: "invalid aggregate",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, response, err := ClientSideAggregateToolHandler(context.Background(), nil, test.input)
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, response.Value)
+			assert.Equal(t, test.expectedValue, *response.Value)
+			assert.True(t, response.ComputedClientSide)
+			assert.False(t, response.Truncated)
+		})
+	}
+}
+
+func TestPatchWhere(t *testing.T) {
+
+	_, _, err := BatchCreateItemsToolHandler(context.Background(), nil, BatchCreateItemsToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     "user_patch_where",
+		Items:            []string{`{"id": "user_patch_where", "status": "pending", "visits": 1}`},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		input           PatchWhereToolInput
+		expectError     bool
+		expectedErrMsg  string
+		expectedMatched int
+		expectedPatched int
+		expectedItem    map[string]any
+	}{
+		{
+			name: "patch matching item",
+			input: PatchWhereToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKey:     "user_patch_where",
+				Filter:           "c.status = 'pending'",
+				Operations: []PatchOperation{
+					{Op: "set", Path: "/status", Value: "done"},
+					{Op: "increment", Path: "/visits", Value: float64(2)},
+				},
+			},
+			expectedMatched: 1,
+			expectedPatched: 1,
+			expectedItem:    map[string]any{"status": "done", "visits": float64(3)},
+		},
+		{
+			name: "transactional patch with no matching items",
+			input: PatchWhereToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKey:     "user_patch_where",
+				Filter:           "WHERE c.status = 'pending'",
+				Operations:       []PatchOperation{{Op: "remove", Path: "/visits"}},
+				Transactional:    true,
+			},
+			expectedMatched: 0,
+			expectedPatched: 0,
+			expectedItem:    map[string]any{"status": "done", "visits": float64(3)},
+		},
+		{
+			name: "transactional patch",
+			input: PatchWhereToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKey:     "user_patch_where",
+				Filter:           "c.status = 'done'",
+				Operations:       []PatchOperation{{Op: "replace", Path: "/status", Value: "archived"}},
+				Transactional:    true,
+			},
+			expectedMatched: 1,
+			expectedPatched: 1,
+			expectedItem:    map[string]any{"status": "archived", "visits": float64(3)},
+		},
+		{
+			name: "missing partition key",
+			input: PatchWhereToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				Filter:           "c.status = 'done'",
+				Operations:       []PatchOperation{{Op: "set", Path: "/status", Value: "archived"}},
+			},
+			expectError:    true,
+			expectedErrMsg: "partition key value missing",
+		},
+		{
+			name: "missing filter",
+			input: PatchWhereToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKey:     "user_patch_where",
+				Operations:       []PatchOperation{{Op: "set", Path: "/status", Value: "archived"}},
+			},
+			expectError:    true,
+			expectedErrMsg: "filter missing",
+		},
+		{
+			name: "unsupported operation",
+			input: PatchWhereToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKey:     "user_patch_where",
+				Filter:           "c.status = 'done'",
+				Operations:       []PatchOperation{{Op: "move", Path: "/status"}},
+			},
+			expectError:    true,
+			expectedErrMsg: "unsupported patch operation",
+		},
+		{
+			name: "max items above limit",
+			input: PatchWhereToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKey:     "user_patch_where",
+				Filter:           "c.status = 'done'",
+				Operations:       []PatchOperation{{Op: "set", Path: "/status", Value: "archived"}},
+				MaxItems:         101,
+			},
+			expectError:    true,
+			expectedErrMsg: "maxItems must be between 1 and 100",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, response, err := PatchWhereToolHandler(context.Background(), nil, test.input)
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedMatched, response.ItemsMatched)
+			assert.Equal(t, test.expectedPatched, response.ItemsPatched)
+			assert.Empty(t, response.Failures)
+
+			_, readResponse, err := ReadItemToolHandler(context.Background(), nil, ReadItemToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				ItemID:           "user_patch_where",
+				PartitionKey:     "user_patch_where",
+			})
+			require.NoError(t, err)
+
+			var item map[string]any
+			require.NoError(t, json.Unmarshal([]byte(readResponse.Item), &item))
+			for key, value := range test.expectedItem {
+				assert.Equal(t, value, item[key])
+			}
+		})
+	}
+}
+
+func TestDeleteWhere(t *testing.T) {
+
+	_, _, err := BatchCreateItemsToolHandler(context.Background(), nil, BatchCreateItemsToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     "user_delete_where",
+		Items:            []string{`{"id": "user_delete_where", "status": "expired"}`},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		input           DeleteWhereToolInput
+		expectError     bool
+		expectedErrMsg  string
+		expectedDeleted int
+	}{
+		{
+			name: "no matching items",
+			input: DeleteWhereToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKey:     "user_delete_where",
+				Filter:           "c.status = 'active'",
+			},
+			expectedDeleted: 0,
+		},
+		{
+			name: "delete matching item",
+			input: DeleteWhereToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKey:     "user_delete_where",
+				Filter:           "c.status = 'expired'",
+			},
+			expectedDeleted: 1,
+		},
+		{
+			name: "missing partition key",
+			input: DeleteWhereToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				Filter:           "c.status = 'expired'",
+			},
+			expectError:    true,
+			expectedErrMsg: "partition key value missing",
+		},
+		{
+			name: "max items above limit",
+			input: DeleteWhereToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKey:     "user_delete_where",
+				Filter:           "c.status = 'expired'",
+				MaxItems:         101,
+			},
+			expectError:    true,
+			expectedErrMsg: "maxItems must be between 1 and 100",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, response, err := DeleteWhereToolHandler(context.Background(), nil, test.input)
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedDeleted, response.ItemsDeleted)
+			assert.Empty(t, response.Failures)
+			assert.False(t, response.MayHaveMore)
+		})
+	}
+
+	_, _, err = ReadItemToolHandler(context.Background(), nil, ReadItemToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		ItemID:           "user_delete_where",
+		PartitionKey:     "user_delete_where",
+	})
+	require.Error(t, err)
+}
+
+func TestReadItemFields(t *testing.T) {
+
+	_, _, err := BatchCreateItemsToolHandler(context.Background(), nil, BatchCreateItemsToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     "user_read_item_fields",
+		Items:            []string{`{"id": "user_read_item_fields", "name": "Jane", "address": {"city": "Seattle"}, "billing": {"city": "Redmond"}}`},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		input           ReadItemFieldsToolInput
+		expectError     bool
+		expectedErrMsg  string
+		expectedFields  map[string]any
+		expectedMissing []string
+	}{
+		{
+			name: "read nested fields with the same name",
+			input: ReadItemFieldsToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				ItemID:           "user_read_item_fields",
+				PartitionKey:     "user_read_item_fields",
+				Fields:           []string{"name", "address.city", "billing.city", "phone"},
+			},
+			expectedFields:  map[string]any{"name": "Jane", "address.city": "Seattle", "billing.city": "Redmond"},
+			expectedMissing: []string{"phone"},
+		},
+		{
+			name: "item not found",
+			input: ReadItemFieldsToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				ItemID:           "user_read_item_fields_missing",
+				PartitionKey:     "user_read_item_fields_missing",
+				Fields:           []string{"name"},
+			},
+			expectError:    true,
+			expectedErrMsg: "not found",
+		},
+		{
+			name: "no fields",
+			input: ReadItemFieldsToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				ItemID:           "user_read_item_fields",
+				PartitionKey:     "user_read_item_fields",
+			},
+			expectError:    true,
+			expectedErrMsg: "fields missing",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, response, err := ReadItemFieldsToolHandler(context.Background(), nil, test.input)
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedFields, response.Fields)
+			assert.Equal(t, test.expectedMissing, response.MissingFields)
+		})
+	}
+}
+
+func TestSearchText(t *testing.T) {
+
+	_, _, err := BatchCreateItemsToolHandler(context.Background(), nil, BatchCreateItemsToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     "user_search_text",
+		Items:            []string{`{"id": "user_search_text", "description": "Cosmos DB Search Text Example"}`},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		input          SearchTextToolInput
+		expectError    bool
+		expectedErrMsg string
+		expectedCount  int
+	}{
+		{
+			name: "case-insensitive search across partitions",
+			input: SearchTextToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				Field:            "description",
+				Text:             "search text",
+			},
+			expectedCount: 1,
+		},
+		{
+			name: "case-sensitive search within partition",
+			input: SearchTextToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				Field:            "description",
+				Text:             "search text",
+				CaseSensitive:    true,
+				PartitionKey:     "user_search_text",
+			},
+			expectedCount: 0,
+		},
+		{
+			name: "missing search text",
+			input: SearchTextToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				Field:            "description",
+			},
+			expectError:    true,
+			expectedErrMsg: "search text missing",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, response, err := SearchTextToolHandler(context.Background(), nil, test.input)
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedCount, response.Count)
+			assert.Len(t, response.Results, test.expectedCount)
+		})
+	}
+}
+
+func TestListDatabasesDetailed(t *testing.T) {
+
+	_, response, err := ListDatabasesDetailedToolHandler(context.Background(), nil, ListDatabasesDetailedToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+	})
+	require.NoError(t, err)
+
+	var testDatabase *DatabaseDetails
+	for i := range response.Databases {
+		if response.Databases[i].Name == testOperationDBName {
+			testDatabase = &response.Databases[i]
+		}
+	}
+
+	require.NotNil(t, testDatabase, "Should contain the test database")
+	assert.Empty(t, testDatabase.Error)
+	assert.GreaterOrEqual(t, testDatabase.ContainerCount, 1, "Should have at least one container")
+
+	_, _, err = ListDatabasesDetailedToolHandler(context.Background(), nil, ListDatabasesDetailedToolInput{
+		ConnectionConfig: ConnectionConfig{Account: ""},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "account name is required")
+}
+
+func TestListContainersDetailed(t *testing.T) {
+
+	_, response, err := ListContainersDetailedToolHandler(context.Background(), nil, ListContainersDetailedToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "dummy_account_does_not_matter", response.Account)
+	assert.Equal(t, testOperationDBName, response.Database)
+
+	var testContainer *ContainerDetails
+	for i := range response.Containers {
+		if response.Containers[i].ID == testOperationContainerName {
+			testContainer = &response.Containers[i]
+		}
+	}
+
+	require.NotNil(t, testContainer, "Should contain the test container")
+	assert.Equal(t, []string{testPartitionKey}, testContainer.PartitionKeyPaths)
+
+	_, _, err = ListContainersDetailedToolHandler(context.Background(), nil, ListContainersDetailedToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         "",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cosmos db database name missing")
+}
+
+func TestReadItemWithMetadata(t *testing.T) {
+
+	_, _, err := BatchCreateItemsToolHandler(context.Background(), nil, BatchCreateItemsToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     "user_read_item_with_metadata",
+		Items:            []string{`{"id": "user_read_item_with_metadata", "name": "Jane"}`},
+	})
+	require.NoError(t, err)
+
+	_, response, err := ReadItemWithMetadataToolHandler(context.Background(), nil, ReadItemToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		ItemID:           "user_read_item_with_metadata",
+		PartitionKey:     "user_read_item_with_metadata",
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, response.Item, "Jane")
+	assert.NotEmpty(t, response.Metadata.ETag)
+	assert.NotEmpty(t, response.Metadata.Self)
+	assert.NotEmpty(t, response.Metadata.ResourceID)
+
+	lastModified, err := time.Parse(time.RFC3339, response.Metadata.LastModified)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), lastModified, time.Hour)
+
+	_, _, err = ReadItemWithMetadataToolHandler(context.Background(), nil, ReadItemToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     "user_read_item_with_metadata",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "item ID missing")
+}
+
+func TestDiagnoseQuery(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		input          DiagnoseQueryToolInput
+		expectError    bool
+		expectedErrMsg string
+	}{
+		{
+			name: "valid query",
+			input: DiagnoseQueryToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				Query:            "SELECT * FROM c WHERE c.id = 'user1'",
+			},
+		},
+		{
+			name: "empty query",
+			input: DiagnoseQueryToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+			},
+			expectError:    true,
+			expectedErrMsg: "query string missing",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, response, err := DiagnoseQueryToolHandler(context.Background(), nil, test.input)
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.input.Query, response.Query)
+			assert.NotEmpty(t, response.Recommendations)
+		})
+	}
+}
+
+func TestSessionToken(t *testing.T) {
+
+	_, addResponse, err := AddItemToContainerToolHandler(context.Background(), nil, AddItemToContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     "user_session_token",
+		Item:             `{"id": "user_session_token", "name": "Jane"}`,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, addResponse.SessionToken, "write should return a session token")
+
+	_, readResponse, err := ReadItemToolHandler(context.Background(), nil, ReadItemToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		ItemID:           "user_session_token",
+		PartitionKey:     "user_session_token",
+		SessionToken:     addResponse.SessionToken,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, readResponse.Item, "Jane")
+	assert.NotEmpty(t, readResponse.SessionToken)
+
+	_, queryResponse, err := ExecuteQueryToolHandler(context.Background(), nil, ExecuteQueryToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		Query:            "SELECT * FROM c WHERE c.id = 'user_session_token'",
+		PartitionKey:     "user_session_token",
+		SessionToken:     addResponse.SessionToken,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, queryResponse.Count)
+}
+
+func TestTruncateContainer(t *testing.T) {
+
+	const container = "test_truncate_container"
+
+	_, _, err := CreateContainerToolHandler(context.Background(), nil, CreateContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		PartitionKeyPath: "/tenant/id",
+	})
+	require.NoError(t, err)
+
+	for _, tenant := range []string{"tenant_a", "tenant_b"} {
+		_, _, err := BatchCreateItemsToolHandler(context.Background(), nil, BatchCreateItemsToolInput{
+			ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+			Database:         testOperationDBName,
+			Container:        container,
+			PartitionKey:     tenant,
+			Items: []string{
+				fmt.Sprintf(`{"id": "%s_1", "tenant": {"id": "%s"}}`, tenant, tenant),
+				fmt.Sprintf(`{"id": "%s_2", "tenant": {"id": "%s"}}`, tenant, tenant),
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name            string
+		input           TruncateContainerToolInput
+		expectError     bool
+		expectedErrMsg  string
+		expectedDeleted int
+		expectedHasMore bool
+	}{
+		{
+			name: "not confirmed",
+			input: TruncateContainerToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        container,
+			},
+			expectError:    true,
+			expectedErrMsg: "confirm must be set to true",
+		},
+		{
+			name: "truncate up to max items",
+			input: TruncateContainerToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        container,
+				Confirm:          true,
+				MaxItems:         3,
+			},
+			expectedDeleted: 3,
+			expectedHasMore: true,
+		},
+		{
+			name: "truncate remaining items",
+			input: TruncateContainerToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        container,
+				Confirm:          true,
+			},
+			expectedDeleted: 1,
+		},
+		{
+			name: "already empty",
+			input: TruncateContainerToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        container,
+				Confirm:          true,
+			},
+			expectedDeleted: 0,
+		},
+		{
+			name: "max items above limit",
+			input: TruncateContainerToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        container,
+				Confirm:          true,
+				MaxItems:         10001,
+			},
+			expectError:    true,
+			expectedErrMsg: "maxItems must be between 1 and 10000",
+		},
+		{
+			name: "missing container",
+			input: TruncateContainerToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Confirm:          true,
+			},
+			expectError:    true,
+			expectedErrMsg: "container name missing",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, response, err := TruncateContainerToolHandler(context.Background(), nil, test.input)
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedDeleted, response.ItemsDeleted)
+			assert.Equal(t, test.expectedHasMore, response.HasMore)
+			assert.Empty(t, response.Failures)
+		})
+	}
+
+	// the container itself is kept
+	_, metadata, err := ReadContainerMetadataToolHandler(context.Background(), nil, ReadContainerMetadataToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, metadata)
+}
+
+func TestCloneContainerConfig(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		input          CloneContainerConfigToolInput
+		expectError    bool
+		expectedErrMsg string
+	}{
+		{
+			name: "clone container config",
+			input: CloneContainerConfigToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				SourceContainer:  testOperationContainerName,
+				TargetContainer:  "test_clone_container_config",
+			},
+		},
+		{
+			name: "same source and target",
+			input: CloneContainerConfigToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				SourceContainer:  testOperationContainerName,
+				TargetContainer:  testOperationContainerName,
+			},
+			expectError:    true,
+			expectedErrMsg: "target container must be different from the source container",
+		},
+		{
+			name: "source container does not exist",
+			input: CloneContainerConfigToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				SourceContainer:  "non_existent_container",
+				TargetContainer:  "test_clone_container_config_2",
+			},
+			expectError:    true,
+			expectedErrMsg: "error reading source container",
+		},
+		{
+			name: "missing target container",
+			input: CloneContainerConfigToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				SourceContainer:  testOperationContainerName,
+			},
+			expectError:    true,
+			expectedErrMsg: "target container name missing",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, response, err := CloneContainerConfigToolHandler(context.Background(), nil, test.input)
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testOperationDBName, response.TargetDatabase)
+			partitionKeyDefinition, ok := response.PartitionKeyDefinition.(azcosmos.PartitionKeyDefinition)
+			require.True(t, ok)
+			assert.Equal(t, []string{testPartitionKey}, partitionKeyDefinition.Paths)
+			require.NotNil(t, response.DefaultTTL)
+			assert.Equal(t, int32(60), *response.DefaultTTL)
+
+			_, containers, err := ListContainersToolHandler(context.Background(), nil, ListContainersToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+			})
+			require.NoError(t, err)
+			assert.Contains(t, containers.Containers, test.input.TargetContainer)
+		})
+	}
+}
+
+func TestContainerStats(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		input          ContainerStatsToolInput
+		expectError    bool
+		expectedErrMsg string
+	}{
+		{
+			name: "missing container",
+			input: ContainerStatsToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+			},
+			expectError:    true,
+			expectedErrMsg: "container name missing",
+		},
+		{
+			name: "container does not exist",
+			input: ContainerStatsToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        "non_existent_container",
+			},
+			expectError:    true,
+			expectedErrMsg: "error reading container",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, _, err := ContainerStatsToolHandler(context.Background(), nil, test.input)
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestQueryToCSV(t *testing.T) {
+
+	_, _, err := BatchCreateItemsToolHandler(context.Background(), nil, BatchCreateItemsToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     "user_query_to_csv",
+		Items:            []string{`{"id": "user_query_to_csv", "name": "Jane", "address": {"city": "Seattle"}}`},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		input          QueryToCSVToolInput
+		expectError    bool
+		expectedErrMsg string
+		expectedCSV    string
+	}{
+		{
+			name: "query to csv",
+			input: QueryToCSVToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				Query:            "SELECT * FROM c WHERE c.id = 'user_query_to_csv'",
+				Columns:          []string{"id", "name", "address.city", "missing"},
+			},
+			expectedCSV: "id,name,address.city,missing\nuser_query_to_csv,Jane,Seattle,\n",
+		},
+		{
+			name: "missing query",
+			input: QueryToCSVToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+			},
+			expectError:    true,
+			expectedErrMsg: "query string missing",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			result, response, err := QueryToCSVToolHandler(context.Background(), nil, test.input)
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedCSV, response.CSV)
+			assert.Equal(t, 1, response.RowCount)
+			require.Len(t, result.Content, 1)
+			assert.Equal(t, test.expectedCSV, result.Content[0].(*mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestInferSchema(t *testing.T) {
+
+	_, _, err := BatchCreateItemsToolHandler(context.Background(), nil, BatchCreateItemsToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     "user_infer_schema",
+		Items:            []string{`{"id": "user_infer_schema", "name": "Jane", "address": {"city": "Seattle"}}`},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		input          InferSchemaToolInput
+		expectError    bool
+		expectedErrMsg string
+	}{
+		{
+			name: "infer schema of a partition",
+			input: InferSchemaToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				PartitionKey:     "user_infer_schema",
+			},
+		},
+		{
+			name: "negative sample size",
+			input: InferSchemaToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        testOperationContainerName,
+				SampleSize:       -1,
+			},
+			expectError:    true,
+			expectedErrMsg: "sampleSize must not be negative",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, response, err := InferSchemaToolHandler(context.Background(), nil, test.input)
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, 1, response.ItemsSampled)
+			assert.Contains(t, response.Fields, FieldSchema{Path: "address.city", Types: []string{"string"}, Count: 1, Frequency: 1})
+			for _, field := range response.Fields {
+				assert.NotEqual(t, "_rid", field.Path)
+			}
+		})
+	}
+}
+
+func TestExecuteQuery_ContinuationToken(t *testing.T) {
+
+	ids := []string{"resume_query_1", "resume_query_2", "resume_query_3"}
+	for _, id := range ids {
+		_, _, err := AddItemToContainerToolHandler(context.Background(), nil, AddItemToContainerToolInput{
+			ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+			Database:         testOperationDBName,
+			Container:        testOperationContainerName,
+			PartitionKey:     id,
+			Item:             fmt.Sprintf(`{"id": "%s"}`, id),
+		})
+		require.NoError(t, err)
+	}
+
+	query := "SELECT c.id FROM c WHERE STARTSWITH(c.id, 'resume_query_')"
+
+	// fetch the first page with the SDK to get a continuation token
+	client, err := ConnectionConfig{Account: "dummy_account_does_not_matter"}.GetClient()
+	require.NoError(t, err)
+	containerClient, err := client.NewContainer(testOperationDBName, testOperationContainerName)
+	require.NoError(t, err)
+
+	pager := containerClient.NewQueryItemsPager(query, azcosmos.PartitionKey{}, &azcosmos.QueryOptions{PageSizeHint: 1})
+	firstPage, err := pager.NextPage(context.Background())
+	require.NoError(t, err)
+	require.Len(t, firstPage.Items, 1)
+	require.NotNil(t, firstPage.ContinuationToken)
+
+	_, response, err := ExecuteQueryToolHandler(context.Background(), nil, ExecuteQueryToolInput{
+		ConnectionConfig:    ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:            testOperationDBName,
+		Container:           testOperationContainerName,
+		Query:               query,
+		ContinuationToken:   *firstPage.ContinuationToken,
+		AllowCrossPartition: true,
+	})
+	require.NoError(t, err)
+
+	// the resumed query returns the remaining results only
+	assert.Equal(t, len(ids)-1, response.Count)
+	assert.NotContains(t, response.QueryResults, string(firstPage.Items[0]))
+}
+
+func TestExecuteQuery_MaxItems(t *testing.T) {
+
+	ids := []string{"max_items_query_1", "max_items_query_2", "max_items_query_3"}
+	for _, id := range ids {
+		_, _, err := AddItemToContainerToolHandler(context.Background(), nil, AddItemToContainerToolInput{
+			ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+			Database:         testOperationDBName,
+			Container:        testOperationContainerName,
+			PartitionKey:     id,
+			Item:             fmt.Sprintf(`{"id": "%s"}`, id),
+		})
+		require.NoError(t, err)
+	}
+
+	input := ExecuteQueryToolInput{
+		ConnectionConfig:    ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:            testOperationDBName,
+		Container:           testOperationContainerName,
+		Query:               "SELECT c.id FROM c WHERE STARTSWITH(c.id, 'max_items_query_')",
+		MaxItems:            2,
+		AllowCrossPartition: true,
+	}
+
+	_, response, err := ExecuteQueryToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.Count)
+	assert.True(t, response.HasMore)
+	require.NotEmpty(t, response.ContinuationToken)
+
+	// resume from the continuation token to get the remaining result
+	input.ContinuationToken = response.ContinuationToken
+	_, remaining, err := ExecuteQueryToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.Equal(t, 1, remaining.Count)
+	assert.False(t, remaining.HasMore)
+	assert.Empty(t, remaining.ContinuationToken)
+	assert.NotContains(t, response.QueryResults, remaining.QueryResults[0])
+
+	t.Run("negative max items", func(t *testing.T) {
+		input := input
+		input.MaxItems = -1
+		_, _, err := ExecuteQueryToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "maxItems must not be negative")
+	})
+
+	t.Run("default page size", func(t *testing.T) {
+		// pages of a single result are fetched until the default maxItems cap is reached
+		t.Setenv(DefaultPageSizeEnvVar, "1")
+
+		input := input
+		input.MaxItems = 0
+		input.ContinuationToken = ""
+		_, response, err := ExecuteQueryToolHandler(context.Background(), nil, input)
+		require.NoError(t, err)
+		assert.Equal(t, 3, response.Count)
+		assert.False(t, response.HasMore)
+	})
+}
+
+func TestPatchWhere_MaxItems(t *testing.T) {
+
+	const container = "test_patch_where_max_items"
+
+	_, _, err := CreateContainerToolHandler(context.Background(), nil, CreateContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		PartitionKeyPath: "/tenant",
+	})
+	require.NoError(t, err)
+
+	_, _, err = BatchCreateItemsToolHandler(context.Background(), nil, BatchCreateItemsToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		PartitionKey:     "tenant_a",
+		Items: []string{
+			`{"id": "1", "tenant": "tenant_a", "status": "pending"}`,
+			`{"id": "2", "tenant": "tenant_a", "status": "pending"}`,
+			`{"id": "3", "tenant": "tenant_a", "status": "pending"}`,
+		},
+	})
+	require.NoError(t, err)
+
+	input := PatchWhereToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		PartitionKey:     "tenant_a",
+		Filter:           "c.status = 'pending'",
+		Operations:       []PatchOperation{{Op: "set", Path: "/status", Value: "done"}},
+		MaxItems:         2,
+	}
+
+	t.Run("transactional patch above the cap fails", func(t *testing.T) {
+		input := input
+		input.Transactional = true
+		_, _, err := PatchWhereToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "filter matched more than 2 items")
+	})
+
+	_, response, err := PatchWhereToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.ItemsPatched)
+	assert.True(t, response.HasMore)
+
+	// the patched items no longer match the filter, so the next call patches the rest
+	_, response, err = PatchWhereToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.ItemsPatched)
+	assert.False(t, response.HasMore)
+}
+
+func TestAddCompositeIndex(t *testing.T) {
+
+	const container = "test_add_composite_index"
+
+	_, _, err := CreateContainerToolHandler(context.Background(), nil, CreateContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		PartitionKeyPath: "/id",
+	})
+	require.NoError(t, err)
+
+	input := AddCompositeIndexToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		Paths:            []CompositeIndexPath{{Path: "/name"}, {Path: "/age", Order: "descending"}},
+	}
+
+	_, response, err := AddCompositeIndexToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.True(t, response.Added)
+	require.Len(t, response.CompositeIndexes, 1)
+
+	// adding the same composite index again does not change the policy
+	_, response, err = AddCompositeIndexToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.False(t, response.Added)
+	assert.Len(t, response.CompositeIndexes, 1)
+
+	// a different one is appended to the existing composite indexes
+	input.Paths = []CompositeIndexPath{{Path: "/city"}, {Path: "/name"}}
+	_, response, err = AddCompositeIndexToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.True(t, response.Added)
+	assert.Len(t, response.CompositeIndexes, 2)
+
+	// the rest of the policy is unchanged
+	_, explained, err := ExplainIndexingPolicyToolHandler(context.Background(), nil, ExplainIndexingPolicyToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, explained.Explanation, "All paths are indexed")
+
+	t.Run("container does not exist", func(t *testing.T) {
+		input := input
+		input.Container = "non_existent_container"
+		_, _, err := AddCompositeIndexToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error reading container")
+	})
+
+	t.Run("single path", func(t *testing.T) {
+		input := input
+		input.Paths = []CompositeIndexPath{{Path: "/name"}}
+		_, _, err := AddCompositeIndexToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "at least two paths")
+	})
+}
+
+func TestGeoWithinDistance(t *testing.T) {
+
+	const container = "test_geo_within_distance"
+
+	_, _, err := CreateContainerToolHandler(context.Background(), nil, CreateContainerToolInput{
+		ConnectionConfig:  ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:          testOperationDBName,
+		Container:         container,
+		PartitionKeyPath:  "/city",
+		SpatialIndexPaths: []string{"/location"},
+	})
+	require.NoError(t, err)
+
+	_, _, err = BatchCreateItemsToolHandler(context.Background(), nil, BatchCreateItemsToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		PartitionKey:     "seattle",
+		Items: []string{
+			// roughly 1.1 km, 0 m and 11 km away from the center
+			`{"id": "near", "city": "seattle", "location": {"type": "Point", "coordinates": [-122.33, 47.61]}}`,
+			`{"id": "center", "city": "seattle", "location": {"type": "Point", "coordinates": [-122.33, 47.60]}}`,
+			`{"id": "far", "city": "seattle", "location": {"type": "Point", "coordinates": [-122.33, 47.70]}}`,
+		},
+	})
+	require.NoError(t, err)
+
+	input := GeoWithinDistanceToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		Field:            "location",
+		Longitude:        -122.33,
+		Latitude:         47.60,
+		RadiusMeters:     5000,
+		PartitionKey:     "seattle",
+		OrderByDistance:  true,
+	}
+
+	_, response, err := GeoWithinDistanceToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	require.Equal(t, 2, response.Count)
+	assert.False(t, response.HasMore)
+	assert.Contains(t, response.Results[0].Item, `"center"`)
+	assert.Contains(t, response.Results[1].Item, `"near"`)
+	assert.Less(t, response.Results[0].DistanceMeters, response.Results[1].DistanceMeters)
+
+	t.Run("max items", func(t *testing.T) {
+		input := input
+		input.MaxItems = 1
+		_, response, err := GeoWithinDistanceToolHandler(context.Background(), nil, input)
+		require.NoError(t, err)
+		assert.Equal(t, 1, response.Count)
+		assert.True(t, response.HasMore)
+	})
+
+	t.Run("order by distance without partition key", func(t *testing.T) {
+		input := input
+		input.PartitionKey = ""
+		_, _, err := GeoWithinDistanceToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "orderByDistance requires a partitionKey")
+	})
+
+	t.Run("invalid radius", func(t *testing.T) {
+		input := input
+		input.RadiusMeters = 0
+		_, _, err := GeoWithinDistanceToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "radiusMeters must be greater than 0")
+	})
+
+	t.Run("invalid latitude", func(t *testing.T) {
+		input := input
+		input.Latitude = 91
+		_, _, err := GeoWithinDistanceToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "latitude must be between -90 and 90")
+	})
+}
+
+func TestAddItemToContainer_TTL(t *testing.T) {
+
+	ttlSeconds := 3600
+
+	input := AddItemToContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     "item_with_ttl",
+		Item:             `{"id": "item_with_ttl", "value": "expires"}`,
+		TTLSeconds:       &ttlSeconds,
+	}
+
+	_, _, err := AddItemToContainerToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+
+	_, response, err := ReadItemToolHandler(context.Background(), nil, ReadItemToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		ItemID:           "item_with_ttl",
+		PartitionKey:     "item_with_ttl",
+	})
+	require.NoError(t, err)
+
+	var item map[string]any
+	require.NoError(t, json.Unmarshal([]byte(response.Item), &item))
+	assert.Equal(t, float64(ttlSeconds), item["ttl"])
+
+	t.Run("conflicting ttl in the item", func(t *testing.T) {
+		input := input
+		input.Item = `{"id": "item_with_conflicting_ttl", "ttl": 60}`
+		input.PartitionKey = "item_with_conflicting_ttl"
+		_, _, err := AddItemToContainerToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not match ttlSeconds")
+	})
+}
+
+func TestSetFieldValue(t *testing.T) {
+
+	const container = "test_set_field_value"
+
+	_, _, err := CreateContainerToolHandler(context.Background(), nil, CreateContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		PartitionKeyPath: "/tenant",
+	})
+	require.NoError(t, err)
+
+	_, _, err = BatchCreateItemsToolHandler(context.Background(), nil, BatchCreateItemsToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		PartitionKey:     "tenant_a",
+		Items: []string{
+			`{"id": "1", "tenant": "tenant_a", "type": "order", "state": "WA"}`,
+			`{"id": "2", "tenant": "tenant_a", "type": "order", "state": "OR"}`,
+			`{"id": "3", "tenant": "tenant_a", "type": "invoice"}`,
+		},
+	})
+	require.NoError(t, err)
+
+	readItem := func(id string) map[string]any {
+		_, response, err := ReadItemToolHandler(context.Background(), nil, ReadItemToolInput{
+			ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+			Database:         testOperationDBName,
+			Container:        container,
+			ItemID:           id,
+			PartitionKey:     "tenant_a",
+		})
+		require.NoError(t, err)
+		var item map[string]any
+		require.NoError(t, json.Unmarshal([]byte(response.Item), &item))
+		return item
+	}
+
+	input := SetFieldValueToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		PartitionKey:     "tenant_a",
+		Filter:           "c.type = 'order'",
+		Field:            "/status",
+		Value:            "migrated",
+	}
+
+	_, response, err := SetFieldValueToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.ItemsModified)
+	assert.False(t, response.HasMore)
+	assert.Equal(t, "migrated", readItem("1")["status"])
+	assert.NotContains(t, readItem("3"), "status")
+
+	// rename state to region, the invoice has no state and is left unchanged
+	input.Filter = "true"
+	input.Value = nil
+	input.Field = "/region"
+	input.CopyFrom = "/state"
+	input.RemoveSource = true
+
+	_, response, err = SetFieldValueToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.ItemsModified)
+	item := readItem("2")
+	assert.Equal(t, "OR", item["region"])
+	assert.NotContains(t, item, "state")
+	assert.NotContains(t, readItem("3"), "region")
+
+	t.Run("max items", func(t *testing.T) {
+		input := input
+		input.CopyFrom = ""
+		input.RemoveSource = false
+		input.Field = "/checked"
+		input.Value = true
+		input.MaxItems = 2
+		_, response, err := SetFieldValueToolHandler(context.Background(), nil, input)
+		require.NoError(t, err)
+		assert.Equal(t, 2, response.ItemsModified)
+		assert.True(t, response.HasMore)
+	})
+
+	t.Run("value and copyFrom", func(t *testing.T) {
+		input := input
+		input.Value = "x"
+		_, _, err := SetFieldValueToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "provide either value or copyFrom")
+	})
+
+	t.Run("neither value nor copyFrom", func(t *testing.T) {
+		input := input
+		input.CopyFrom = ""
+		input.RemoveSource = false
+		_, _, err := SetFieldValueToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "provide either value or copyFrom")
+		assert.Contains(t, readItem("1"), "status", "no item should be changed")
+	})
+
+	t.Run("missing partition key", func(t *testing.T) {
+		input := input
+		input.PartitionKey = ""
+		_, _, err := SetFieldValueToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "partition key value missing")
+	})
+}
+
+func TestBuildQuery(t *testing.T) {
+
+	_, _, err := BatchCreateItemsToolHandler(context.Background(), nil, BatchCreateItemsToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     "user_build_query",
+		Items:            []string{`{"id": "user_build_query", "name": "Jane", "age": 30}`},
+	})
+	require.NoError(t, err)
+
+	_, built, err := BuildQueryToolHandler(context.Background(), nil, BuildQueryToolInput{
+		Select: []string{"name"},
+		Filters: []QueryFilter{
+			{Field: "age", Operator: "ge", Value: float64(21)},
+			{Field: "name", Operator: "startsWith", Value: "Ja"},
+		},
+		Limit:        1,
+		PartitionKey: "user_build_query",
+	})
+	require.NoError(t, err)
+	assert.False(t, built.CrossPartition)
+
+	// the inline query is valid and can be run as is
+	_, response, err := ExecuteQueryToolHandler(context.Background(), nil, ExecuteQueryToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		Query:            built.InlineQuery,
+		PartitionKey:     built.PartitionKey,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, response.Count)
+	assert.JSONEq(t, `{"name": "Jane"}`, response.QueryResults[0])
+}
+
+func TestPaginatedQuery(t *testing.T) {
+
+	const container = "test_paginated_query"
+
+	_, _, err := CreateContainerToolHandler(context.Background(), nil, CreateContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		PartitionKeyPath: "/category",
+	})
+	require.NoError(t, err)
+
+	_, _, err = BatchCreateItemsToolHandler(context.Background(), nil, BatchCreateItemsToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		PartitionKey:     "books",
+		Items: []string{
+			`{"id": "1", "category": "books", "rank": 1}`,
+			`{"id": "2", "category": "books", "rank": 2}`,
+			`{"id": "3", "category": "books", "rank": 3}`,
+			`{"id": "4", "category": "books", "rank": 4}`,
+			`{"id": "5", "category": "books", "rank": 5}`,
+		},
+	})
+	require.NoError(t, err)
+
+	input := PaginatedQueryToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		Query:            "SELECT VALUE c.id FROM c ORDER BY c.rank",
+		PartitionKey:     "books",
+		Offset:           1,
+		Limit:            2,
+	}
+
+	_, response, err := PaginatedQueryToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`"2"`, `"3"`}, response.Results)
+	assert.Equal(t, 2, response.Count)
+	assert.True(t, response.HasMore)
+	assert.Equal(t, 3, response.NextOffset)
+	assert.Equal(t, "SELECT VALUE c.id FROM c ORDER BY c.rank OFFSET @offset LIMIT @limit", response.Query)
+
+	t.Run("last page", func(t *testing.T) {
+		input := input
+		input.Offset = response.NextOffset
+		_, response, err := PaginatedQueryToolHandler(context.Background(), nil, input)
+		require.NoError(t, err)
+		assert.Equal(t, []string{`"4"`, `"5"`}, response.Results)
+		assert.False(t, response.HasMore)
+		assert.Zero(t, response.NextOffset)
+	})
+
+	t.Run("missing partition key", func(t *testing.T) {
+		input := input
+		input.PartitionKey = ""
+		_, _, err := PaginatedQueryToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "partition key missing")
+	})
+
+	t.Run("limit too large", func(t *testing.T) {
+		input := input
+		input.Limit = maxPaginatedQueryLimit + 1
+		_, _, err := PaginatedQueryToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "limit must be between 1 and 1000")
+	})
+
+	t.Run("query with offset limit", func(t *testing.T) {
+		input := input
+		input.Query = "SELECT * FROM c OFFSET 0 LIMIT 1"
+		_, _, err := PaginatedQueryToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must not contain OFFSET LIMIT or TOP")
+	})
+}
+
+func TestBatchReadItems(t *testing.T) {
+
+	const container = "test_batch_read_items"
+
+	_, _, err := CreateContainerToolHandler(context.Background(), nil, CreateContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		PartitionKeyPath: "/category",
+	})
+	require.NoError(t, err)
+
+	for category, items := range map[string][]string{
+		"books": {`{"id": "b1", "category": "books"}`, `{"id": "b2", "category": "books"}`},
+		"games": {`{"id": "g1", "category": "games"}`},
+	} {
+		_, _, err = BatchCreateItemsToolHandler(context.Background(), nil, BatchCreateItemsToolInput{
+			ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+			Database:         testOperationDBName,
+			Container:        container,
+			PartitionKey:     category,
+			Items:            items,
+		})
+		require.NoError(t, err)
+	}
+
+	input := BatchReadItemsToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		Items: []ItemReference{
+			{ID: "b1", PartitionKey: "books"},
+			{ID: "b2", PartitionKey: "books"},
+			{ID: "missing", PartitionKey: "books"},
+			{ID: "g1", PartitionKey: "games"},
+			{ID: "g2", PartitionKey: "games"},
+		},
+	}
+
+	_, response, err := BatchReadItemsToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.Equal(t, 3, response.Found)
+	assert.Equal(t, 2, response.Failed)
+	assert.Equal(t, 2, response.Partitions)
+	require.Len(t, response.Items, 5)
+
+	for _, id := range []string{"b1", "b2", "g1"} {
+		assert.Contains(t, response.Items[id].Item, fmt.Sprintf(`"id":"%s"`, id))
+		assert.Empty(t, response.Items[id].Error)
+	}
+	assert.Equal(t, "item not found", response.Items["missing"].Error)
+	assert.Equal(t, "books", response.Items["missing"].PartitionKey)
+	assert.Equal(t, "item not found", response.Items["g2"].Error)
+
+	t.Run("single item per partition", func(t *testing.T) {
+		input := input
+		input.Items = []ItemReference{{ID: "b1", PartitionKey: "books"}, {ID: "g1", PartitionKey: "games"}}
+		_, response, err := BatchReadItemsToolHandler(context.Background(), nil, input)
+		require.NoError(t, err)
+		assert.Equal(t, 2, response.Found)
+		assert.Zero(t, response.Failed)
+	})
+
+	t.Run("too many items", func(t *testing.T) {
+		input := input
+		input.Items = make([]ItemReference, maxBatchReadItems+1)
+		_, _, err := BatchReadItemsToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "too many items")
+	})
+
+	t.Run("missing container", func(t *testing.T) {
+		input := input
+		input.Container = "test_batch_read_items_does_not_exist"
+		input.Items = []ItemReference{{ID: "b1", PartitionKey: "books"}, {ID: "b2", PartitionKey: "books"}}
+		_, _, err := BatchReadItemsToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "reading items failed on all partitions")
+	})
+}
+
+func TestGetQueryPlan(t *testing.T) {
+
+	input := GetQueryPlanToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		Query:            "SELECT TOP 5 c.id FROM c ORDER BY c.id DESC",
+	}
+
+	_, response, err := GetQueryPlanToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.Equal(t, input.Query, response.Query)
+	assert.False(t, response.SinglePartition)
+	assert.NotZero(t, response.QueryRanges)
+	assert.NotNil(t, response.Plan)
+
+	t.Run("missing query", func(t *testing.T) {
+		input := input
+		input.Query = ""
+		_, _, err := GetQueryPlanToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "query string missing")
+	})
+}
+
+func TestReadItem_PartitionKeyNone(t *testing.T) {
+
+	const container = "test_partition_key_none"
+
+	_, _, err := CreateContainerToolHandler(context.Background(), nil, CreateContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		PartitionKeyPath: "/tenant",
+	})
+	require.NoError(t, err)
+
+	// the item has no tenant property, so it is stored without a partition key value
+	client, err := ConnectionConfig{Account: "dummy_account_does_not_matter"}.GetClient()
+	require.NoError(t, err)
+	containerClient, err := client.NewContainer(testOperationDBName, container)
+	require.NoError(t, err)
+	_, err = containerClient.CreateItem(withPartitionKeyNone(context.Background()), azcosmos.NewPartitionKey(), []byte(`{"id": "legacy_1"}`), nil)
+	require.NoError(t, err)
+
+	_, response, err := ReadItemToolHandler(context.Background(), nil, ReadItemToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		ItemID:           "legacy_1",
+		PartitionKeyNone: true,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, response.Item, `"legacy_1"`)
+
+	_, queryResponse, err := ExecuteQueryToolHandler(context.Background(), nil, ExecuteQueryToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		Query:            "SELECT c.id FROM c",
+		PartitionKeyNone: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, queryResponse.Count)
+	assert.Contains(t, queryResponse.QueryResults[0], `"legacy_1"`)
+
+	t.Run("partition key and partition key none", func(t *testing.T) {
+		_, _, err := ReadItemToolHandler(context.Background(), nil, ReadItemToolInput{
+			ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+			Database:         testOperationDBName,
+			Container:        container,
+			ItemID:           "legacy_1",
+			PartitionKey:     "tenant1",
+			PartitionKeyNone: true,
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "partitionKey and partitionKeyNone cannot be used together")
+	})
+}
+
+func TestExecuteQuery_MaxResponseBytes(t *testing.T) {
+
+	ids := []string{"max_bytes_query_1", "max_bytes_query_2", "max_bytes_query_3"}
+	for _, id := range ids {
+		_, _, err := AddItemToContainerToolHandler(context.Background(), nil, AddItemToContainerToolInput{
+			ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+			Database:         testOperationDBName,
+			Container:        testOperationContainerName,
+			PartitionKey:     id,
+			Item:             fmt.Sprintf(`{"id": "%s"}`, id),
+		})
+		require.NoError(t, err)
+	}
+
+	// each result ({"id":"max_bytes_query_N"}) is 26 bytes, so only two fit
+	t.Setenv(MaxResponseBytesEnvVar, "60")
+
+	_, response, err := ExecuteQueryToolHandler(context.Background(), nil, ExecuteQueryToolInput{
+		ConnectionConfig:    ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:            testOperationDBName,
+		Container:           testOperationContainerName,
+		Query:               "SELECT c.id FROM c WHERE STARTSWITH(c.id, 'max_bytes_query_')",
+		AllowCrossPartition: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.Count)
+	assert.True(t, response.HasMore)
+	assert.Contains(t, response.Note, MaxResponseBytesEnvVar)
+
+	t.Run("search text", func(t *testing.T) {
+		_, response, err := SearchTextToolHandler(context.Background(), nil, SearchTextToolInput{
+			ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+			Database:         testOperationDBName,
+			Container:        testOperationContainerName,
+			Field:            "id",
+			Text:             "max_bytes_query_",
+		})
+		require.NoError(t, err)
+		// whole items include the system properties, so none of them fits
+		assert.Zero(t, response.Count)
+		assert.Contains(t, response.Note, "3 of 3 results omitted")
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		t.Setenv(MaxResponseBytesEnvVar, "lots")
+		_, _, err := ExecuteQueryToolHandler(context.Background(), nil, ExecuteQueryToolInput{
+			ConnectionConfig:    ConnectionConfig{Account: "dummy_account_does_not_matter"},
+			Database:            testOperationDBName,
+			Container:           testOperationContainerName,
+			Query:               "SELECT c.id FROM c",
+			AllowCrossPartition: true,
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), MaxResponseBytesEnvVar)
+	})
+}
+
+func TestCheckPermissions(t *testing.T) {
+
+	input := CheckPermissionsToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+	}
+
+	_, response, err := CheckPermissionsToolHandler(false)(context.Background(), nil, input)
+	require.NoError(t, err)
+	// the emulator client is injected by the tests, so its credential is not known
+	assert.Equal(t, AuthTypeUnknown, response.AuthType)
+	assert.Equal(t, PermissionPermitted, response.Read)
+	assert.Equal(t, PermissionNotChecked, response.Write)
+	assert.Equal(t, PermissionNotChecked, response.Manage)
+	require.Len(t, response.Checks, 3)
+	for _, check := range response.Checks {
+		assert.Equal(t, PermissionCategoryRead, check.Category, check.Operation)
+		assert.Equal(t, PermissionPermitted, check.Status, check.Operation)
+	}
+
+	t.Run("read-only", func(t *testing.T) {
+		_, response, err := CheckPermissionsToolHandler(true)(context.Background(), nil, input)
+		require.NoError(t, err)
+		assert.Equal(t, PermissionPermitted, response.Read)
+		assert.Equal(t, PermissionNotChecked, response.Write)
+		assert.Equal(t, PermissionNotChecked, response.Manage)
+		require.Len(t, response.Checks, 3)
+		assert.Contains(t, strings.Join(response.Notes, " "), "read-only")
+	})
+
+	t.Run("missing container", func(t *testing.T) {
+		input := input
+		input.Container = "test_check_permissions_does_not_exist"
+		_, response, err := CheckPermissionsToolHandler(false)(context.Background(), nil, input)
+		require.NoError(t, err)
+		assert.Equal(t, PermissionUnknown, response.Read)
+		assert.Equal(t, PermissionNotChecked, response.Write)
+		require.Len(t, response.Checks, 2)
+		assert.Equal(t, PermissionError, response.Checks[1].Status)
+	})
+
+	t.Run("database without container", func(t *testing.T) {
+		input := input
+		input.Container = ""
+		_, _, err := CheckPermissionsToolHandler(false)(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "database and container must be provided together")
+	})
+}
+
+func TestMaterializeView(t *testing.T) {
+
+	const target = "test_materialize_view"
+
+	_, _, err := CreateContainerToolHandler(context.Background(), nil, CreateContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        target,
+		PartitionKeyPath: "/customerId",
+	})
+	require.NoError(t, err)
+
+	_, _, err = BatchCreateItemsToolHandler(context.Background(), nil, BatchCreateItemsToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     "order_materialize_view",
+		Items:            []string{`{"id": "order_materialize_view", "customer": {"id": "c1", "name": "Jane"}, "total": 42}`},
+	})
+	require.NoError(t, err)
+
+	input := MaterializeViewToolInput{
+		ConnectionConfig:       ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:               testOperationDBName,
+		SourceContainer:        testOperationContainerName,
+		Query:                  "SELECT * FROM c",
+		PartitionKey:           "order_materialize_view",
+		TargetContainer:        target,
+		TargetPartitionKeyPath: "/customerId",
+		Mappings: []FieldMapping{
+			{Source: "id", Target: "id"},
+			{Source: "customer.id", Target: "customerId"},
+			{Source: "customer.name", Target: "customerName"},
+			{Source: "total", Target: "total"},
+		},
+	}
+
+	_, response, err := MaterializeViewToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.SourceItems)
+	assert.Equal(t, 1, response.Upserted)
+	assert.Empty(t, response.Failures)
+	assert.False(t, response.HasMore)
+
+	_, readResponse, err := ReadItemToolHandler(context.Background(), nil, ReadItemToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        target,
+		ItemID:           "order_materialize_view",
+		PartitionKey:     "c1",
+	})
+	require.NoError(t, err)
+
+	var item map[string]any
+	require.NoError(t, json.Unmarshal([]byte(readResponse.Item), &item))
+	assert.Equal(t, "Jane", item["customerName"])
+	assert.Equal(t, float64(42), item["total"])
+	assert.NotContains(t, item, "customer")
+
+	t.Run("refresh is idempotent", func(t *testing.T) {
+		_, response, err := MaterializeViewToolHandler(context.Background(), nil, input)
+		require.NoError(t, err)
+		assert.Equal(t, 1, response.Upserted)
+	})
+
+	t.Run("missing target partition key", func(t *testing.T) {
+		input := input
+		input.Mappings = []FieldMapping{{Source: "id", Target: "id"}}
+		_, response, err := MaterializeViewToolHandler(context.Background(), nil, input)
+		require.NoError(t, err)
+		assert.Equal(t, 0, response.Upserted)
+		require.Len(t, response.Failures, 1)
+		assert.Contains(t, response.Failures[0].Error, "partition key path /customerId not found")
+	})
+
+	t.Run("cross-partition without allowCrossPartition", func(t *testing.T) {
+		input := input
+		input.PartitionKey = ""
+		_, _, err := MaterializeViewToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "partition key missing")
+	})
+}
+
+func TestDiffContainers(t *testing.T) {
+
+	const container = "test_diff_containers"
+
+	_, _, err := CreateContainerToolHandler(context.Background(), nil, CreateContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		PartitionKeyPath: "/category",
+	})
+	require.NoError(t, err)
+
+	input := DiffContainersToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		OtherContainer:   container,
+	}
+
+	_, response, err := DiffContainersToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.False(t, response.Identical)
+	assert.Contains(t, response.Differences, ContainerDifference{
+		Property: "partition_key.paths",
+		Left:     []string{testPartitionKey},
+		Right:    []string{"/category"},
+	})
+
+	t.Run("same container", func(t *testing.T) {
+		input := input
+		input.OtherContainer = input.Container
+		_, _, err := DiffContainersToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "are the same container")
+	})
+
+	t.Run("missing container", func(t *testing.T) {
+		input := input
+		input.OtherContainer = "test_diff_containers_does_not_exist"
+		_, _, err := DiffContainersToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error reading container 'test_diff_containers_does_not_exist'")
+	})
+}
+
+func TestEstimateItemSize(t *testing.T) {
+
+	item := `{"id": "user_estimate_size", "name": "Jane"}`
+
+	_, estimate, err := EstimateItemSizeToolHandler(context.Background(), nil, EstimateItemSizeToolInput{Item: item})
+	require.NoError(t, err)
+	require.False(t, estimate.ExceedsLimit)
+
+	// an item within the limit can be added
+	_, _, err = AddItemToContainerToolHandler(context.Background(), nil, AddItemToContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     "user_estimate_size",
+		Item:             item,
+	})
+	require.NoError(t, err)
+}
+
+func TestPurgeOlderThan(t *testing.T) {
+
+	const partition = "user_purge_older_than"
+
+	_, _, err := BatchCreateItemsToolHandler(context.Background(), nil, BatchCreateItemsToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     partition,
+		Items:            []string{`{"id": "user_purge_older_than"}`},
+	})
+	require.NoError(t, err)
+
+	// _ts has a resolution of one second, the cutoff must be after the item was written
+	time.Sleep(2 * time.Second)
+	cutoff := time.Now().UTC().Format(time.RFC3339)
+
+	input := PurgeOlderThanToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     partition,
+		OlderThan:        cutoff,
+	}
+
+	_, _, err = PurgeOlderThanToolHandler(context.Background(), nil, input)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "confirm must be set to true")
+
+	dryRun := input
+	dryRun.DryRun = true
+	_, response, err := PurgeOlderThanToolHandler(context.Background(), nil, dryRun)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_purge_older_than"}, response.ItemIDs)
+	assert.Equal(t, 0, response.ItemsDeleted)
+
+	olderCutoff := input
+	olderCutoff.OlderThan = "2020-01-01T00:00:00Z"
+	olderCutoff.Confirm = true
+	_, response, err = PurgeOlderThanToolHandler(context.Background(), nil, olderCutoff)
+	require.NoError(t, err)
+	assert.Equal(t, 0, response.ItemsMatched)
+
+	input.Confirm = true
+	_, response, err = PurgeOlderThanToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.ItemsDeleted)
+	assert.Empty(t, response.Failures)
+	assert.False(t, response.HasMore)
+
+	_, _, err = ReadItemToolHandler(context.Background(), nil, ReadItemToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		ItemID:           "user_purge_older_than",
+		PartitionKey:     partition,
+	})
+	require.Error(t, err)
+}
+
+func TestDistinctValues(t *testing.T) {
+
+	for _, item := range []struct{ id, category string }{
+		{"user_distinct_values_1", "books"},
+		{"user_distinct_values_2", "games"},
+		{"user_distinct_values_3", "books"},
+	} {
+		_, _, err := AddItemToContainerToolHandler(context.Background(), nil, AddItemToContainerToolInput{
+			ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+			Database:         testOperationDBName,
+			Container:        testOperationContainerName,
+			PartitionKey:     item.id,
+			Item:             fmt.Sprintf(`{"id": "%s", "kind": "distinct_values", "category": "%s"}`, item.id, item.category),
+		})
+		require.NoError(t, err)
+	}
+
+	_, response, err := DistinctValuesToolHandler(context.Background(), nil, DistinctValuesToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		Field:            "category",
+		Filter:           "c.kind = 'distinct_values'",
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []any{"books", "games"}, response.DistinctValues)
+	assert.Equal(t, 3, response.ValuesFetched)
+	assert.False(t, response.Truncated)
+	assert.True(t, response.DeduplicatedClientSide)
+
+	_, response, err = DistinctValuesToolHandler(context.Background(), nil, DistinctValuesToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		Field:            "category",
+		Filter:           "c.kind = 'distinct_values'",
+		MaxItems:         1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.DistinctCount)
+	assert.True(t, response.Truncated)
+
+	_, _, err = DistinctValuesToolHandler(context.Background(), nil, DistinctValuesToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+	})
+	require.Error(t, err)
+}
+
+func TestReadItemAndExecuteQuery_FieldTypes(t *testing.T) {
+
+	_, _, err := AddItemToContainerToolHandler(context.Background(), nil, AddItemToContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     "user_field_types",
+		Item:             `{"id": "user_field_types", "zip": "01234"}`,
+	})
+	require.NoError(t, err)
+
+	fieldTypes := map[string]string{"_ts": FieldTypeEpochSeconds, "zip": FieldTypeString}
+
+	_, item, err := ReadItemToolHandler(context.Background(), nil, ReadItemToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		ItemID:           "user_field_types",
+		PartitionKey:     "user_field_types",
+		FieldTypes:       fieldTypes,
+	})
+	require.NoError(t, err)
+	require.Len(t, item.TypedFields, 2)
+	assert.Equal(t, "_ts", item.TypedFields[0].Field)
+	assert.Empty(t, item.TypedFields[0].Error)
+	assert.NotEmpty(t, item.TypedFields[0].Typed)
+	assert.Equal(t, "01234", item.TypedFields[1].Typed)
+
+	_, response, err := ExecuteQueryToolHandler(context.Background(), nil, ExecuteQueryToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		Query:            "SELECT * FROM c",
+		PartitionKey:     "user_field_types",
+		FieldTypes:       fieldTypes,
+	})
+	require.NoError(t, err)
+	require.Len(t, response.TypedFields, response.Count)
+	assert.Equal(t, item.TypedFields, response.TypedFields[0])
+
+	_, _, err = ReadItemToolHandler(context.Background(), nil, ReadItemToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		ItemID:           "user_field_types",
+		PartitionKey:     "user_field_types",
+		FieldTypes:       map[string]string{"_ts": "timestamp"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid type 'timestamp'")
+}
+
+func TestReplaceContainerConfig(t *testing.T) {
+
+	const container = "test_replace_container_config"
+
+	_, _, err := CreateContainerToolHandler(context.Background(), nil, CreateContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		PartitionKeyPath: "/id",
+	})
+	require.NoError(t, err)
+
+	input := ReplaceContainerConfigToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		Configuration: `{
+			"id": "test_replace_container_config",
+			"partitionKey": {"paths": ["/id"], "kind": "Hash"},
+			"indexingPolicy": {"indexingMode": "consistent", "includedPaths": [{"path": "/name/?"}], "excludedPaths": [{"path": "/*"}]},
+			"defaultTtl": 3600
+		}`,
+	}
+
+	_, response, err := ReplaceContainerConfigToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.True(t, response.Replaced)
+	assert.Contains(t, response.Message, "re-indexed")
+
+	var changed []string
+	for _, change := range response.Changes {
+		changed = append(changed, change.Property)
+	}
+	assert.Contains(t, changed, "default_ttl")
+	assert.Contains(t, changed, "indexing_policy.included_paths")
+	assert.Contains(t, changed, "indexing_policy.excluded_paths")
+
+	properties, err := readContainerProperties(context.Background(), input.ConnectionConfig, testOperationDBName, container)
+	require.NoError(t, err)
+	require.NotNil(t, properties.DefaultTimeToLive)
+	assert.Equal(t, int32(3600), *properties.DefaultTimeToLive)
+	assert.Equal(t, []azcosmos.ExcludedPath{{Path: "/*"}}, properties.IndexingPolicy.ExcludedPaths)
+
+	// replacing with the same configuration does not change anything
+	_, response, err = ReplaceContainerConfigToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.False(t, response.Replaced)
+	assert.Empty(t, response.Changes)
+
+	t.Run("partition key can't be changed", func(t *testing.T) {
+		input := input
+		input.Configuration = `{"partitionKey": {"paths": ["/name"], "kind": "Hash"}}`
+		_, _, err := ReplaceContainerConfigToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "partitionKey can't be changed")
+	})
+
+	t.Run("container does not exist", func(t *testing.T) {
+		input := input
+		input.Container = "non_existent_container"
+		_, _, err := ReplaceContainerConfigToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error reading container")
+	})
+
+	t.Run("missing configuration", func(t *testing.T) {
+		input := input
+		input.Configuration = ""
+		_, _, err := ReplaceContainerConfigToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "configuration missing")
+	})
+}
+
+func TestSetIndexingMode(t *testing.T) {
+
+	const container = "test_set_indexing_mode"
+
+	_, _, err := CreateContainerToolHandler(context.Background(), nil, CreateContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		PartitionKeyPath: "/id",
+	})
+	require.NoError(t, err)
+
+	input := SetIndexingModeToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		Mode:             "none",
+	}
+
+	_, response, err := SetIndexingModeToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.True(t, response.Changed)
+	assert.Equal(t, "consistent", response.PreviousMode)
+	assert.Equal(t, "none", response.Mode)
+	assert.Contains(t, response.Warning, "all indexes were dropped")
+	require.NotNil(t, response.PreviousIndexingPolicy)
+
+	// setting the same mode again does not change the policy
+	_, response, err = SetIndexingModeToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.False(t, response.Changed)
+
+	input.Mode = "consistent"
+	_, response, err = SetIndexingModeToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.True(t, response.Changed)
+	assert.Equal(t, "none", response.PreviousMode)
+
+	_, explained, err := ExplainIndexingPolicyToolHandler(context.Background(), nil, ExplainIndexingPolicyToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, explained.Explanation, "All paths are indexed")
+
+	t.Run("invalid mode", func(t *testing.T) {
+		input := input
+		input.Mode = "off"
+		_, _, err := SetIndexingModeToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid indexing mode")
+	})
+
+	t.Run("container does not exist", func(t *testing.T) {
+		input := input
+		input.Container = "non_existent_container"
+		_, _, err := SetIndexingModeToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error reading container")
+	})
+}
+
+func TestDryRunQuery(t *testing.T) {
+	const container = "test_dry_run_query"
+
+	_, _, err := CreateContainerToolHandler(context.Background(), nil, CreateContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        container,
+		PartitionKeyPath: "/id",
+	})
+	require.NoError(t, err)
+
+	for _, item := range []string{
+		`{"id": "order_1", "status": "shipped", "total": 120}`,
+		`{"id": "order_2", "status": "pending", "total": 80}`,
+		`{"id": "order_3", "total": 200}`,
+	} {
+		var parsed map[string]any
+		require.NoError(t, json.Unmarshal([]byte(item), &parsed))
+		_, _, err := AddItemToContainerToolHandler(context.Background(), nil, AddItemToContainerToolInput{
+			ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+			Database:         testOperationDBName,
+			Container:        container,
+			PartitionKey:     parsed["id"].(string),
+			Item:             item,
+		})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name              string
+		query             string
+		expectedMatched   int
+		expectedUndefined int
+		expectError       bool
+		expectedErrMsg    string
+	}{
+		{
+			name:              "filter on a property some items lack",
+			query:             "SELECT * FROM c WHERE c.status = 'shipped' AND c.total > 100",
+			expectedMatched:   1,
+			expectedUndefined: 1,
+		},
+		{
+			name:            "no WHERE condition",
+			query:           "SELECT c.id FROM c",
+			expectedMatched: 3,
+		},
+		{
+			name:           "unsupported query",
+			query:          "SELECT * FROM c JOIN t IN c.tags",
+			expectError:    true,
+			expectedErrMsg: "JOIN is not supported",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, response, err := DryRunQueryToolHandler(context.Background(), nil, DryRunQueryToolInput{
+				ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+				Database:         testOperationDBName,
+				Container:        container,
+				Query:            test.query,
+			})
+
+			if test.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, 3, response.ItemsSampled)
+			assert.Equal(t, test.expectedMatched, response.Matched)
+			assert.Equal(t, test.expectedUndefined, response.Undefined)
+		})
+	}
+}
+
+func TestReadItem_MaxFieldBytes(t *testing.T) {
+	blob := strings.Repeat("QUJD", 2048)
+
+	_, _, err := AddItemToContainerToolHandler(context.Background(), nil, AddItemToContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     "user_attachment",
+		Item:             fmt.Sprintf(`{"id": "user_attachment", "attachment": "%s"}`, blob),
+	})
+	require.NoError(t, err)
+
+	input := ReadItemToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		ItemID:           "user_attachment",
+		PartitionKey:     "user_attachment",
+		MaxFieldBytes:    to.Ptr(1024),
+	}
+
+	_, response, err := ReadItemToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.ElidedFields)
+	assert.Contains(t, response.Item, `"attachment":"<elided 8KB>"`)
+
+	_, queryResponse, err := ExecuteQueryToolHandler(context.Background(), nil, ExecuteQueryToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		Query:            "SELECT c.id, c.attachment FROM c WHERE c.id = 'user_attachment'",
+		PartitionKey:     "user_attachment",
+		MaxFieldBytes:    to.Ptr(1024),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, queryResponse.ElidedFields)
+	assert.Equal(t, []string{`{"id":"user_attachment","attachment":"<elided 8KB>"}`}, queryResponse.QueryResults)
+}
+
+func TestCreateLeasesContainer(t *testing.T) {
+	input := CreateLeasesContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+	}
+
+	_, response, err := CreateLeasesContainerToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.True(t, response.Created)
+	assert.Equal(t, "leases", response.Container)
+	assert.Empty(t, response.Warning)
+
+	// calling it again is a no-op
+	_, response, err = CreateLeasesContainerToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.False(t, response.Created)
+	assert.Empty(t, response.Warning)
+
+	t.Run("existing container with another partition key", func(t *testing.T) {
+		_, _, err := CreateContainerToolHandler(context.Background(), nil, CreateContainerToolInput{
+			ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+			Database:         testOperationDBName,
+			Container:        "test_not_leases",
+			PartitionKeyPath: "/customerId",
+		})
+		require.NoError(t, err)
+
+		input := input
+		input.Container = "test_not_leases"
+		_, response, err := CreateLeasesContainerToolHandler(context.Background(), nil, input)
+		require.NoError(t, err)
+		assert.False(t, response.Created)
+		assert.Contains(t, response.Warning, "the partition key is /customerId instead of /id")
+	})
+
+	t.Run("empty database name", func(t *testing.T) {
+		input := input
+		input.Database = ""
+		_, _, err := CreateLeasesContainerToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "database name missing")
+	})
+}
+
+func TestListThroughput(t *testing.T) {
+	input := ListThroughputToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+	}
+
+	_, response, err := ListThroughputToolHandler(context.Background(), nil, input)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Databases)
+	require.NotEmpty(t, response.Throughput)
+	assert.Equal(t, testOperationDBName, response.Throughput[0].Database)
+	assert.Empty(t, response.Throughput[0].Container)
+	assert.Equal(t, len(response.Throughput)-1, response.Containers)
+
+	// the test container has manual throughput, unless the emulator does not implement offers
+	for _, entry := range response.Throughput {
+		assert.NotEmpty(t, entry.Type)
+		if entry.Container == testOperationContainerName && entry.Type != ThroughputTypeUnknown {
+			assert.Equal(t, ThroughputTypeManual, entry.Type)
+			assert.Positive(t, entry.RUPerSecond)
+		}
+	}
+
+	t.Run("database does not exist", func(t *testing.T) {
+		input := input
+		input.Database = "non_existent_database"
+		_, _, err := ListThroughputToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error listing containers of database 'non_existent_database'")
+	})
+}
+
+func TestSmartRead(t *testing.T) {
+
+	const id = "smart_read_item"
+
+	_, _, err := AddItemToContainerToolHandler(context.Background(), nil, AddItemToContainerToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		PartitionKey:     id,
+		Item:             fmt.Sprintf(`{"id": "%s", "name": "smart"}`, id),
+	})
+	require.NoError(t, err)
+
+	input := SmartReadToolInput{
+		ConnectionConfig: ConnectionConfig{Account: "dummy_account_does_not_matter"},
+		Database:         testOperationDBName,
+		Container:        testOperationContainerName,
+		ItemID:           id,
+		PartitionKey:     id,
+	}
+
+	t.Run("point read", func(t *testing.T) {
+		_, response, err := SmartReadToolHandler(context.Background(), nil, input)
+		require.NoError(t, err)
+		assert.Equal(t, SmartReadStrategyPointRead, response.Strategy)
+		assert.Equal(t, id, response.PartitionKey)
+		var item map[string]any
+		require.NoError(t, json.Unmarshal([]byte(response.Item), &item))
+		assert.Equal(t, "smart", item["name"])
+	})
+
+	t.Run("wrong partition key without fallback", func(t *testing.T) {
+		input := input
+		input.PartitionKey = "wrong_partition"
+		_, _, err := SmartReadToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "set allowCrossPartition to true")
+	})
+
+	t.Run("wrong partition key with fallback", func(t *testing.T) {
+		input := input
+		input.PartitionKey = "wrong_partition"
+		input.AllowCrossPartition = true
+		_, response, err := SmartReadToolHandler(context.Background(), nil, input)
+		require.NoError(t, err)
+		assert.Equal(t, SmartReadStrategyQuery, response.Strategy)
+		// the partition key path of the test container is /id
+		assert.Equal(t, id, response.PartitionKey)
+		var item map[string]any
+		require.NoError(t, json.Unmarshal([]byte(response.Item), &item))
+		assert.Equal(t, "smart", item["name"])
+		assert.Contains(t, response.Message, "it is not in partition 'wrong_partition'")
+	})
+
+	t.Run("no partition key", func(t *testing.T) {
+		input := input
+		input.PartitionKey = ""
+		_, _, err := SmartReadToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "partition key missing")
+
+		input.AllowCrossPartition = true
+		_, response, err := SmartReadToolHandler(context.Background(), nil, input)
+		require.NoError(t, err)
+		assert.Equal(t, SmartReadStrategyQuery, response.Strategy)
+	})
+
+	t.Run("item does not exist", func(t *testing.T) {
+		input := input
+		input.ItemID = "smart_read_missing_item"
+		input.AllowCrossPartition = true
+		_, _, err := SmartReadToolHandler(context.Background(), nil, input)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found in any partition")
+	})
+}
@@ -0,0 +1,233 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// indexingPolicyKeys are the indexing policy properties known by the SDK, others are reported as not validated
+var indexingPolicyKeys = []string{"automatic", "indexingMode", "includedPaths", "excludedPaths", "spatialIndexes", "compositeIndexes"}
+
+// indexPathSegmentPattern matches the segments of an index path that don't need quotes: property names, array indexes ([]) and numbers.
+// Property names with other characters must be quoted, e.g. /"order-date"/?
+var indexPathSegmentPattern = regexp.MustCompile(`^(\[\]|[A-Za-z0-9_$]+|"[^"]+")$`)
+
+func ValidateIndexingPolicy() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        "validate_indexing_policy",
+		Description: "Validate a proposed Azure Cosmos DB indexing policy (JSON) before applying it with replace_container_config, without connecting to Azure Cosmos DB or changing any container. The policy can be provided on its own or as the indexingPolicy property of a container configuration. Checks the indexing mode, the syntax of the included, excluded, composite and spatial index paths (e.g. included and excluded paths must end with /? or /*), that the root path /* is included or excluded, and conflicts such as a path that is both included and excluded. Returns valid, the problems that would make the service reject the policy or break queries, and warnings about settings that are allowed but likely unintended.",
+	}
+}
+
+type ValidateIndexingPolicyToolInput struct {
+	IndexingPolicy string `json:"indexingPolicy" jsonschema:"The indexing policy as a JSON object, example {\"indexingMode\": \"consistent\", \"automatic\": true, \"includedPaths\": [{\"path\": \"/*\"}], \"excludedPaths\": [{\"path\": \"/\\\"_etag\\\"/?\"}]}"`
+}
+
+type ValidateIndexingPolicyToolResult struct {
+	Valid          bool                     `json:"valid" jsonschema:"Whether the policy has no problems, warnings don't make it invalid"`
+	Problems       []string                 `json:"problems" jsonschema:"Errors that must be fixed before applying the policy"`
+	Warnings       []string                 `json:"warnings,omitempty" jsonschema:"Settings that are allowed but likely unintended"`
+	IndexingPolicy *azcosmos.IndexingPolicy `json:"indexing_policy,omitempty" jsonschema:"The policy as understood by the SDK, set if it could be parsed"`
+	Message        string                   `json:"message"`
+}
+
+func ValidateIndexingPolicyToolHandler(_ context.Context, _ *mcp.CallToolRequest, input ValidateIndexingPolicyToolInput) (*mcp.CallToolResult, ValidateIndexingPolicyToolResult, error) {
+
+	if strings.TrimSpace(input.IndexingPolicy) == "" {
+		return nil, ValidateIndexingPolicyToolResult{}, newInputError("indexing policy missing")
+	}
+
+	policy, problems, warnings := validateIndexingPolicy(input.IndexingPolicy)
+
+	result := ValidateIndexingPolicyToolResult{
+		Valid:          len(problems) == 0,
+		Problems:       problems,
+		Warnings:       warnings,
+		IndexingPolicy: policy,
+	}
+
+	switch {
+	case !result.Valid:
+		result.Message = fmt.Sprintf("The indexing policy has %d problem(s), fix them before applying it", len(problems))
+	case len(warnings) > 0:
+		result.Message = fmt.Sprintf("The indexing policy is valid, but review the %d warning(s) before applying it with replace_container_config", len(warnings))
+	default:
+		result.Message = "The indexing policy is valid and can be applied with replace_container_config"
+	}
+
+	return nil, result, nil
+}
+
+// validateIndexingPolicy parses an indexing policy, either on its own or as the indexingPolicy property of a container
+// configuration, and checks it. It returns the parsed policy (nil if it can't be parsed), the problems and the warnings.
+func validateIndexingPolicy(document string) (*azcosmos.IndexingPolicy, []string, []string) {
+	var properties map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(document), &properties); err != nil {
+		return nil, []string{fmt.Sprintf("the indexing policy is not a valid JSON object: %v", err)}, nil
+	}
+
+	if nested, ok := properties["indexingPolicy"]; ok {
+		properties = nil
+		if err := json.Unmarshal(nested, &properties); err != nil {
+			return nil, []string{fmt.Sprintf("indexingPolicy is not a valid JSON object: %v", err)}, nil
+		}
+		document = string(nested)
+	}
+
+	warnings := []string{}
+	for key := range properties {
+		if !slices.Contains(indexingPolicyKeys, key) {
+			warnings = append(warnings, fmt.Sprintf("property '%s' is not supported by this server and was not validated", key))
+		}
+	}
+	slices.Sort(warnings)
+
+	var policy azcosmos.IndexingPolicy
+	if err := json.Unmarshal([]byte(document), &policy); err != nil {
+		return nil, []string{fmt.Sprintf("invalid indexing policy: %v", err)}, warnings
+	}
+
+	// automatic defaults to true in the service (except with mode none), but to false in the SDK type
+	if _, ok := properties["automatic"]; !ok {
+		policy.Automatic = !strings.EqualFold(string(policy.IndexingMode), string(azcosmos.IndexingModeNone))
+	}
+
+	problems, policyWarnings := indexingPolicyProblems(policy)
+
+	return &policy, problems, append(warnings, policyWarnings...)
+}
+
+// indexingPolicyProblems checks a parsed indexing policy and returns its problems and warnings
+func indexingPolicyProblems(policy azcosmos.IndexingPolicy) ([]string, []string) {
+	problems := []string{}
+	warnings := []string{}
+
+	mode := azcosmos.IndexingModeConsistent
+	if policy.IndexingMode != "" {
+		var err error
+		if mode, err = parseIndexingMode(string(policy.IndexingMode)); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if mode == azcosmos.IndexingModeNone {
+		if len(policy.IncludedPaths) > 0 || len(policy.ExcludedPaths) > 0 || len(policy.CompositeIndexes) > 0 || len(policy.SpatialIndexes) > 0 {
+			problems = append(problems, "indexing mode none does not allow included or excluded paths, composite or spatial indexes - remove them or use mode consistent")
+		}
+		if policy.Automatic {
+			problems = append(problems, "indexing mode none requires automatic to be false")
+		}
+		return problems, warnings
+	}
+
+	if mode == indexingModeLazy {
+		warnings = append(warnings, "indexing mode lazy is deprecated and rejected by most accounts, use consistent")
+	}
+
+	if !policy.Automatic {
+		warnings = append(warnings, "automatic is false: items are only indexed if explicitly requested when writing them, which the Go SDK can't do")
+	}
+
+	included := map[string]bool{}
+	for _, path := range policy.IncludedPaths {
+		if problem := indexPathProblem(path.Path); problem != "" {
+			problems = append(problems, fmt.Sprintf("invalid included path '%s': %s", path.Path, problem))
+		}
+		if included[path.Path] {
+			problems = append(problems, fmt.Sprintf("duplicate included path '%s'", path.Path))
+		}
+		included[path.Path] = true
+	}
+
+	excluded := map[string]bool{}
+	for _, path := range policy.ExcludedPaths {
+		if problem := indexPathProblem(path.Path); problem != "" {
+			problems = append(problems, fmt.Sprintf("invalid excluded path '%s': %s", path.Path, problem))
+		}
+		if excluded[path.Path] {
+			problems = append(problems, fmt.Sprintf("duplicate excluded path '%s'", path.Path))
+		}
+		if included[path.Path] {
+			problems = append(problems, fmt.Sprintf("path '%s' is both included and excluded", path.Path))
+		}
+		excluded[path.Path] = true
+	}
+
+	// without any paths the service indexes all paths
+	switch {
+	case len(included) == 0 && len(excluded) == 0:
+	case !included["/*"] && !excluded["/*"]:
+		problems = append(problems, "the root path /* must be either included or excluded")
+	case excluded["/*"] && len(included) == 0:
+		warnings = append(warnings, "all paths are excluded and none included: filters and ORDER BY on item properties will scan")
+	}
+
+	for i, composite := range policy.CompositeIndexes {
+		paths := make([]CompositeIndexPath, 0, len(composite))
+		for _, index := range composite {
+			paths = append(paths, CompositeIndexPath{Path: index.Path, Order: string(index.Order)})
+		}
+		index, err := newCompositeIndex(paths)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("composite index %d: %v", i+1, err))
+			continue
+		}
+		if hasCompositeIndex(policy.CompositeIndexes[:i], index) {
+			problems = append(problems, fmt.Sprintf("composite index %d is a duplicate of a previous composite index", i+1))
+		}
+	}
+
+	for _, spatial := range policy.SpatialIndexes {
+		if !strings.HasPrefix(spatial.Path, "/") || !strings.HasSuffix(spatial.Path, "/*") || spatial.Path == "/*" {
+			problems = append(problems, fmt.Sprintf("invalid spatial index path '%s': must be the path of a property followed by /*, e.g. /location/*", spatial.Path))
+		}
+		if len(spatial.SpatialTypes) == 0 {
+			problems = append(problems, fmt.Sprintf("spatial index '%s' has no types", spatial.Path))
+		}
+		for _, spatialType := range spatial.SpatialTypes {
+			switch spatialType {
+			case azcosmos.SpatialTypePoint, azcosmos.SpatialTypePolygon, azcosmos.SpatialTypeLineString, azcosmos.SpatialTypeMultiPolygon:
+			default:
+				problems = append(problems, fmt.Sprintf("invalid type '%s' for spatial index '%s', must be one of: Point, Polygon, LineString, MultiPolygon", spatialType, spatial.Path))
+			}
+		}
+	}
+
+	return problems, warnings
+}
+
+// indexPathProblem checks the syntax of an included or excluded path and describes the problem, if any
+func indexPathProblem(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		return "path must start with /"
+	}
+	if !strings.HasSuffix(path, "/?") && !strings.HasSuffix(path, "/*") {
+		return "path must end with /? (the value of the property) or /* (the property and everything below it)"
+	}
+	if path == "/*" {
+		return ""
+	}
+	if path == "/?" {
+		return "the root path is /*"
+	}
+
+	for _, segment := range strings.Split(path[1:len(path)-2], "/") {
+		switch {
+		case segment == "":
+			return "path has an empty segment"
+		case segment == "*" || segment == "?":
+			return "wildcards are only allowed at the end of the path"
+		case !indexPathSegmentPattern.MatchString(segment):
+			return fmt.Sprintf(`segment '%s' has special characters and must be quoted, e.g. /"%s"/?`, segment, segment)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,150 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for validate_indexing_policy that do not need the emulator
+
+func TestValidateIndexingPolicy(t *testing.T) {
+	tests := []struct {
+		name             string
+		policy           string
+		expectedProblems []string
+		expectedWarnings []string
+	}{
+		{
+			name:   "default policy",
+			policy: `{"indexingMode": "consistent", "automatic": true, "includedPaths": [{"path": "/*"}], "excludedPaths": [{"path": "/\"_etag\"/?"}]}`,
+		},
+		{
+			name:   "container configuration",
+			policy: `{"defaultTtl": 3600, "indexingPolicy": {"includedPaths": [{"path": "/*"}]}}`,
+		},
+		{
+			name:   "no paths",
+			policy: `{"indexingMode": "consistent"}`,
+		},
+		{
+			name:   "composite and spatial indexes",
+			policy: `{"includedPaths": [{"path": "/*"}], "compositeIndexes": [[{"path": "/name", "order": "ascending"}, {"path": "/age", "order": "descending"}]], "spatialIndexes": [{"path": "/location/*", "types": ["Point"]}]}`,
+		},
+		{
+			name:   "only some paths indexed",
+			policy: `{"includedPaths": [{"path": "/status/?"}, {"path": "/tags/[]/?"}, {"path": "/\"order-date\"/?"}], "excludedPaths": [{"path": "/*"}]}`,
+		},
+		{
+			name:             "not JSON",
+			policy:           `indexingMode: consistent`,
+			expectedProblems: []string{"the indexing policy is not a valid JSON object"},
+		},
+		{
+			name:             "wrong type",
+			policy:           `{"includedPaths": "/*"}`,
+			expectedProblems: []string{"invalid indexing policy"},
+		},
+		{
+			name:             "invalid mode",
+			policy:           `{"indexingMode": "eventual"}`,
+			expectedProblems: []string{"invalid indexing mode 'eventual'"},
+		},
+		{
+			name:   "mode none",
+			policy: `{"indexingMode": "none"}`,
+		},
+		{
+			name:             "mode none with paths",
+			policy:           `{"indexingMode": "none", "automatic": true, "includedPaths": [{"path": "/*"}]}`,
+			expectedProblems: []string{"indexing mode none does not allow", "indexing mode none requires automatic to be false"},
+		},
+		{
+			name:             "lazy and manual",
+			policy:           `{"indexingMode": "lazy", "automatic": false, "includedPaths": [{"path": "/*"}]}`,
+			expectedWarnings: []string{"indexing mode lazy is deprecated", "automatic is false"},
+		},
+		{
+			name:   "invalid paths",
+			policy: `{"includedPaths": [{"path": "/*"}, {"path": "name/?"}, {"path": "/name"}, {"path": "/a//b/?"}, {"path": "/a/*/b/?"}, {"path": "/order-date/?"}]}`,
+			expectedProblems: []string{
+				"invalid included path 'name/?': path must start with /",
+				"invalid included path '/name': path must end with /?",
+				"invalid included path '/a//b/?': path has an empty segment",
+				"invalid included path '/a/*/b/?': wildcards are only allowed at the end of the path",
+				`invalid included path '/order-date/?': segment 'order-date' has special characters and must be quoted, e.g. /"order-date"/?`,
+			},
+		},
+		{
+			name:             "conflicting paths",
+			policy:           `{"includedPaths": [{"path": "/*"}, {"path": "/name/?"}, {"path": "/name/?"}], "excludedPaths": [{"path": "/name/?"}, {"path": "/*"}]}`,
+			expectedProblems: []string{"duplicate included path '/name/?'", "path '/name/?' is both included and excluded", "path '/*' is both included and excluded"},
+		},
+		{
+			name:             "root path missing",
+			policy:           `{"includedPaths": [{"path": "/name/?"}]}`,
+			expectedProblems: []string{"the root path /* must be either included or excluded"},
+		},
+		{
+			name:             "nothing indexed",
+			policy:           `{"excludedPaths": [{"path": "/*"}]}`,
+			expectedWarnings: []string{"all paths are excluded and none included"},
+		},
+		{
+			name:             "invalid composite indexes",
+			policy:           `{"includedPaths": [{"path": "/*"}], "compositeIndexes": [[{"path": "/name"}], [{"path": "/name/?"}, {"path": "/age"}], [{"path": "/name"}, {"path": "/age"}], [{"path": "/name", "order": "ascending"}, {"path": "/age", "order": "ascending"}]]}`,
+			expectedProblems: []string{"composite index 1: a composite index needs at least two paths", "composite index 2: invalid composite index path '/name/?'", "composite index 4 is a duplicate of a previous composite index"},
+		},
+		{
+			name:             "invalid spatial indexes",
+			policy:           `{"includedPaths": [{"path": "/*"}], "spatialIndexes": [{"path": "/location", "types": ["Point"]}, {"path": "/area/*", "types": []}, {"path": "/route/*", "types": ["Line"]}]}`,
+			expectedProblems: []string{"invalid spatial index path '/location'", "spatial index '/area/*' has no types", "invalid type 'Line' for spatial index '/route/*'"},
+		},
+		{
+			name:             "unknown property",
+			policy:           `{"includedPaths": [{"path": "/*"}], "vectorIndexes": [{"path": "/embedding", "type": "diskANN"}]}`,
+			expectedWarnings: []string{"property 'vectorIndexes' is not supported by this server and was not validated"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, problems, warnings := validateIndexingPolicy(tt.policy)
+
+			require.Len(t, problems, len(tt.expectedProblems), "problems: %v", problems)
+			for i, expected := range tt.expectedProblems {
+				assert.Contains(t, problems[i], expected)
+			}
+
+			require.Len(t, warnings, len(tt.expectedWarnings), "warnings: %v", warnings)
+			for i, expected := range tt.expectedWarnings {
+				assert.Contains(t, warnings[i], expected)
+			}
+		})
+	}
+}
+
+func TestValidateIndexingPolicyToolHandler(t *testing.T) {
+	_, result, err := ValidateIndexingPolicyToolHandler(context.Background(), nil, ValidateIndexingPolicyToolInput{
+		IndexingPolicy: `{"includedPaths": [{"path": "/*"}]}`,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Empty(t, result.Problems)
+	require.NotNil(t, result.IndexingPolicy)
+	assert.True(t, result.IndexingPolicy.Automatic)
+	assert.Contains(t, result.Message, "replace_container_config")
+
+	_, result, err = ValidateIndexingPolicyToolHandler(context.Background(), nil, ValidateIndexingPolicyToolInput{
+		IndexingPolicy: `{"includedPaths": [{"path": "/name"}]}`,
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Len(t, result.Problems, 2)
+
+	_, _, err = ValidateIndexingPolicyToolHandler(context.Background(), nil, ValidateIndexingPolicyToolInput{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "indexing policy missing")
+}